@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// defaultAgendaDays is how far ahead the agenda looks unless told otherwise.
+const defaultAgendaDays = 30
+
+// agendaMode is toggled with the "a" key and shows the agenda instead of the clocks.
+var agendaMode bool
+
+// agendaItem is one notable time event: an offset change, a configured holiday, or a saved event.
+type agendaItem struct {
+	Kind  string // "dst", "holiday", or "event"
+	Title string
+	// Start is in the item's location; holidays start at local midnight and last the day.
+	Start    time.Time
+	Duration time.Duration
+	AllDay   bool
+	// Zones are the configured zones the item concerns, e.g. every zone in a location that changes offset.
+	Zones []string
+}
+
+// Flags of the `kairos agenda` command.
+var (
+	agendaICS bool
+	agendaOut string
+)
+
+// agendaCommand builds the `kairos agenda` command.
+func agendaCommand() *command {
+	return &command{
+		Name: "agenda", Usage: "[days]", MaxArgs: 1,
+		Short: "Lists upcoming DST changes, holidays, and events across all zones (--ics or --out file.ics to export)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&agendaICS, "ics", false, "Print the agenda as iCalendar")
+			fs.StringVar(&agendaOut, "out", "", "Write the agenda as iCalendar to this file")
+		},
+		Run: func(args []string) error {
+			days := defaultAgendaDays
+			if len(args) == 1 {
+				var err error
+				if days, err = strconv.Atoi(args[0]); err != nil || days < 1 || days > 366 {
+					return fmt.Errorf("invalid number of days '%s' (expected 1 to 366)", args[0])
+				}
+			}
+			return printAgenda(days, agendaICS, agendaOut)
+		},
+	}
+}
+
+/**
+ * This function gathers the agenda: every offset change in the zones' locations, every
+ * holiday set with the holidays option, and every saved event, from now for a number
+ * of days. Zones that share a location share its offset changes.
+ *
+ * @param now - The current time.
+ * @param days - How many days ahead to look.
+ * @returns The items, soonest first.
+ */
+func agendaItems(now time.Time, days int) []agendaItem {
+	end := now.AddDate(0, 0, days)
+	var items []agendaItem
+	// changes holds the indices of each location's offset changes in items.
+	changes := map[string][]int{}
+	for _, tz := range timezones {
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			continue
+		}
+		if indices, ok := changes[loc.String()]; ok {
+			for _, i := range indices {
+				items[i].Zones = append(items[i].Zones, tz.Name)
+			}
+		} else {
+			changes[loc.String()] = nil
+			for _, tr := range tzutil.TransitionsBetween(loc, now, end) {
+				changes[loc.String()] = append(changes[loc.String()], len(items))
+				items = append(items, agendaItem{Kind: "dst", Title: describeOffsetChange(tr), Start: tr.At.In(loc), Zones: []string{tz.Name}})
+			}
+		}
+		for _, date := range tz.Holidays {
+			day, err := time.ParseInLocation("2006-01-02", date, loc)
+			if err != nil || !day.AddDate(0, 0, 1).After(now) || !day.Before(end) {
+				continue
+			}
+			items = append(items, agendaItem{Kind: "holiday", Title: "Holiday", Start: day, Duration: 24 * time.Hour, AllDay: true, Zones: []string{tz.Name}})
+		}
+	}
+	for _, e := range events {
+		start, err := eventStart(e)
+		if err != nil || start.Before(now) || !start.Before(end) {
+			continue
+		}
+		items = append(items, agendaItem{Kind: "event", Title: e.Title, Start: start, Duration: time.Duration(e.Duration) * time.Minute, Zones: []string{e.Zone}})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Start.Before(items[j].Start) })
+	return items
+}
+
+// describeOffsetChange describes a transition for the agenda, e.g. "Clocks go back 1h (CEST → CET)".
+func describeOffsetChange(tr tzutil.Transition) string {
+	shift := tr.After - tr.Before
+	direction := "forward"
+	if shift < 0 {
+		direction, shift = "back", -shift
+	}
+	amount := fmt.Sprintf("%dh", shift/3600)
+	if shift%3600 != 0 {
+		amount = fmt.Sprintf("%dm", shift/60)
+	}
+	return fmt.Sprintf("Clocks go %s %s (%s → %s)", direction, amount, tr.BeforeName, tr.AfterName)
+}
+
+// formatAgendaItem formats an item as one line: its local date and time, zones, and title.
+func formatAgendaItem(item agendaItem) string {
+	when := item.Start.Format("Mon 02 Jan 15:04 MST")
+	if item.AllDay {
+		when = item.Start.Format("Mon 02 Jan") + " all day  "
+	}
+	colors := map[string]string{"dst": "\x1b[35m", "holiday": "\x1b[33m", "event": "\x1b[32m"}
+	title := item.Title
+	if item.Kind == "event" && item.Duration > 0 {
+		title += fmt.Sprintf(" (%dm)", int(item.Duration.Minutes()))
+	}
+	return fmt.Sprintf("%s%-20s\x1b[0m %-14s %s", colors[item.Kind], when, strings.Join(item.Zones, ", "), title)
+}
+
+/**
+ * This function handles `kairos agenda`: it prints the agenda, or exports it as an
+ * iCalendar document (holidays as all-day events) to stdout or a file.
+ *
+ * @param days - How many days ahead to look.
+ * @param ics - Whether to print iCalendar instead of the list.
+ * @param out - The file to write the iCalendar document to, or "".
+ * @returns An error if the file cannot be written.
+ */
+func printAgenda(days int, ics bool, out string) error {
+	now := appClock.Now(time.UTC)
+	items := agendaItems(now, days)
+	if ics || out != "" {
+		var list []icsEvent
+		for _, item := range items {
+			list = append(list, icsEvent{Title: fmt.Sprintf("%s: %s", strings.Join(item.Zones, ", "), item.Title),
+				Start: item.Start, Duration: item.Duration, AllDay: item.AllDay})
+		}
+		data := buildICS(list, now, "")
+		if out == "" {
+			fmt.Print(data)
+			return nil
+		}
+		if err := os.WriteFile(out, []byte(data), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", out, err)
+		}
+		fmt.Printf("Wrote %d agenda item(s) to %s\n", len(list), out)
+		return nil
+	}
+
+	fmt.Printf("\n\x1b[36m\x1b[1mAGENDA\x1b[0m next %d days\n", days)
+	if len(items) == 0 {
+		fmt.Printf("\x1b[90mNo offset changes, holidays, or events.\x1b[0m\n\n")
+		return nil
+	}
+	for _, item := range items {
+		fmt.Println("  " + formatAgendaItem(item))
+	}
+	fmt.Println()
+	return nil
+}
+
+// agendaPanelLines lists the agenda for the dashboard's agenda view.
+func agendaPanelLines(now time.Time) []string {
+	items := agendaItems(now, defaultAgendaDays)
+	if len(items) == 0 {
+		return []string{" No offset changes, holidays, or events in the next 30 days", "", " \x1b[90ma closes\x1b[0m"}
+	}
+	var lines []string
+	for _, item := range items {
+		lines = append(lines, " "+formatAgendaItem(item))
+	}
+	return append(lines, "", " \x1b[90mExport with kairos agenda --out agenda.ics; a closes\x1b[0m")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// airRefresh is how often the air quality and UV index are fetched.
+const airRefresh = 30 * time.Minute
+
+// airReading is a zone's current US AQI and UV index.
+type airReading struct {
+	AQI float64
+	UV  float64
+}
+
+var (
+	// airMu guards airReadings, which the air worker writes and the dashboard reads.
+	airMu sync.Mutex
+	// airReadings holds the latest reading of each zone, by zone name.
+	airReadings = map[string]airReading{}
+)
+
+/**
+ * This function starts the air worker when the air_quality setting is on. Every 30
+ * minutes it fetches the AQI and UV index at each zone's position in the background; a
+ * failed fetch keeps the last reading.
+ */
+func startAirWorker() {
+	if !settings.AirQuality {
+		return
+	}
+	zones := append([]TimezoneConfig(nil), timezones...)
+	goWorker("air worker", func(ctx context.Context) {
+		lastErr := map[string]string{}
+		for {
+			for _, tz := range zones {
+				coords, ok := zoneCoordinates(tz)
+				if !ok {
+					continue
+				}
+				reading, err := fetchAirReading(coords)
+				if err != nil {
+					if err.Error() != lastErr[tz.Name] {
+						logger.Warn("air quality fetch failed", "zone", tz.Name, "err", err)
+					}
+					lastErr[tz.Name] = err.Error()
+					continue
+				}
+				delete(lastErr, tz.Name)
+				airMu.Lock()
+				airReadings[tz.Name] = reading
+				airMu.Unlock()
+			}
+			if !sleepCtx(ctx, airRefresh) {
+				return
+			}
+		}
+	})
+}
+
+/**
+ * This function fetches the current US AQI and UV index at a position from the
+ * Open-Meteo air quality API, which is free and needs no key.
+ *
+ * @param c - The position.
+ * @returns The reading, or an error.
+ */
+func fetchAirReading(c Coordinates) (airReading, error) {
+	params := url.Values{
+		"latitude":  {strconv.FormatFloat(c.Lat, 'f', 4, 64)},
+		"longitude": {strconv.FormatFloat(c.Lon, 'f', 4, 64)},
+		"current":   {"us_aqi,uv_index"},
+	}
+	resp, err := httpClient.Get("https://air-quality-api.open-meteo.com/v1/air-quality?" + params.Encode())
+	if err != nil {
+		return airReading{}, err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Reason  string `json:"reason"`
+		Current struct {
+			AQI *float64 `json:"us_aqi"`
+			UV  *float64 `json:"uv_index"`
+		} `json:"current"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return airReading{}, fmt.Errorf("unexpected air quality response: %s %v", resp.Status, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return airReading{}, fmt.Errorf("air quality API rejected the request: %s %s", resp.Status, body.Reason)
+	}
+	// Remote places can lack a value; the badge then leaves it out.
+	reading := airReading{AQI: -1, UV: -1}
+	if body.Current.AQI != nil {
+		reading.AQI = *body.Current.AQI
+	}
+	if body.Current.UV != nil {
+		reading.UV = *body.Current.UV
+	}
+	return reading, nil
+}
+
+// aqiLevel names a US AQI level and gives its color: good, moderate, or unhealthy.
+func aqiLevel(aqi float64) (string, string) {
+	switch {
+	case aqi <= 50:
+		return "good", "\x1b[32m"
+	case aqi <= 100:
+		return "moderate", "\x1b[33m"
+	}
+	return "unhealthy", "\x1b[31m"
+}
+
+// uvLevel names a UV index level and gives its color: low, moderate, or high.
+func uvLevel(uv float64) (string, string) {
+	switch {
+	case uv < 3:
+		return "low", "\x1b[32m"
+	case uv < 6:
+		return "moderate", "\x1b[33m"
+	}
+	return "high", "\x1b[31m"
+}
+
+// zoneAirReading returns the zone's latest air reading, if there is one.
+func zoneAirReading(tz TimezoneConfig) (airReading, bool) {
+	airMu.Lock()
+	defer airMu.Unlock()
+	reading, ok := airReadings[tz.Name]
+	return reading, ok
+}
+
+// airLine returns the zone's AQI and UV badge for its view, e.g. "AQI 42 · UV 3", colored by level, or "".
+func airLine(tz TimezoneConfig) string {
+	reading, ok := zoneAirReading(tz)
+	if !ok {
+		return ""
+	}
+	var parts []string
+	if reading.AQI >= 0 {
+		_, color := aqiLevel(reading.AQI)
+		parts = append(parts, fmt.Sprintf("%sAQI %.0f\x1b[0m", color, reading.AQI))
+	}
+	if reading.UV >= 0 {
+		_, color := uvLevel(reading.UV)
+		parts = append(parts, fmt.Sprintf("%sUV %.0f\x1b[0m", color, reading.UV))
+	}
+	return strings.Join(parts, " · ")
+}
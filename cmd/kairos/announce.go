@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+var (
+	// announceDays is set by `kairos announce add --days`.
+	announceDays string
+	// lastAnnounceCheck remembers the last instant checked for announcements.
+	lastAnnounceCheck time.Time
+	// announceClient is used for every webhook post.
+	announceClient = &http.Client{Timeout: 15 * time.Second}
+)
+
+// announceDayNames are the day names accepted in an announcement's days, Sunday first like time.Weekday.
+var announceDayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// announceFields are the placeholders of an announcement's message.
+var announceFields = map[string]func(tz TimezoneConfig, local time.Time) string{
+	"zone":    func(tz TimezoneConfig, _ time.Time) string { return tz.Name },
+	"time":    func(_ TimezoneConfig, local time.Time) string { return local.Format("15:04") },
+	"date":    func(_ TimezoneConfig, local time.Time) string { return local.Format("Mon 2 Jan") },
+	"weekday": func(_ TimezoneConfig, local time.Time) string { return local.Weekday().String() },
+	"utc":     func(_ TimezoneConfig, local time.Time) string { return local.UTC().Format("15:04 UTC") },
+	"offset":  func(_ TimezoneConfig, local time.Time) string { return "UTC" + local.Format("-07:00") },
+}
+
+// announceCommand builds the `kairos announce` command group.
+func announceCommand() *command {
+	return &command{
+		Name:  "announce",
+		Short: "Posts messages to a Slack or Teams channel at local times in a zone (see announce_webhook)",
+		Subcommands: []*command{
+			{Name: "add", Usage: `"Zone" "HH:MM" "Message"`, MinArgs: 3, MaxArgs: 3,
+				Short: "Saves an announcement, e.g. \"Berlin\" \"09:00\" \"EMEA support opens now\" --days weekdays",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&announceDays, "days", "daily", "The days to post on: daily, weekdays (skipping the zone's holidays), weekends, or e.g. mon,wed,fri")
+				},
+				Run: addAnnouncement},
+			{Name: "list", Short: "Lists the announcements with their next post", Run: func(args []string) error { return listAnnouncements() }},
+			{Name: "remove", Usage: "N", MinArgs: 1, MaxArgs: 1, Short: "Removes the announcement numbered N in the list",
+				Run: func(args []string) error { return removeAnnouncement(args[0]) }},
+			{Name: "test", Usage: "N", MinArgs: 1, MaxArgs: 1, Short: "Posts the announcement numbered N now, to check the webhook",
+				Run: func(args []string) error { return testAnnouncement(args[0]) }},
+		},
+	}
+}
+
+/**
+ * This function checks an announcement's days: "daily", "weekdays", "weekends", or a
+ * comma-separated list of day names.
+ *
+ * @param days - The days.
+ * @returns The days as stored ("" for daily), or an error naming the accepted values.
+ */
+func parseAnnounceDays(days string) (string, error) {
+	days = strings.ToLower(strings.ReplaceAll(days, " ", ""))
+	switch days {
+	case "", "daily":
+		return "", nil
+	case "weekdays", "weekends":
+		return days, nil
+	}
+	for _, day := range strings.Split(days, ",") {
+		if !containsString(announceDayNames, day) {
+			return "", fmt.Errorf("invalid days '%s' (expected daily, weekdays, weekends, or a list such as mon,wed,fri)", days)
+		}
+	}
+	return days, nil
+}
+
+// announcesOn reports whether an announcement is posted on a local day in its zone.
+func announcesOn(a Announcement, tz TimezoneConfig, local time.Time) bool {
+	weekend := local.Weekday() == time.Saturday || local.Weekday() == time.Sunday
+	switch a.Days {
+	case "":
+		return true
+	case "weekdays":
+		return !weekend && !isHoliday(tz, local)
+	case "weekends":
+		return weekend
+	}
+	return containsString(strings.Split(a.Days, ","), announceDayNames[local.Weekday()])
+}
+
+// announceZone resolves an announcement's zone: the configured zone (for its holidays), or a bare location.
+func announceZone(a Announcement) (TimezoneConfig, *time.Location, error) {
+	if tz, loc, err := findZone(a.Zone); err == nil {
+		return tz, loc, nil
+	}
+	loc, label, err := resolveZone(a.Zone)
+	if err != nil {
+		return TimezoneConfig{}, nil, err
+	}
+	return TimezoneConfig{Name: label, Location: a.Zone}, loc, nil
+}
+
+/**
+ * This function returns when an announcement is posted on a given local date, and
+ * whether it is posted that day. A time skipped by a DST change is posted when the clocks
+ * have moved on, and a repeated one the first time round.
+ *
+ * @param a - The announcement.
+ * @param tz - Its zone.
+ * @param loc - The zone's location.
+ * @param day - Any time on the local date.
+ * @returns The instant, and false if the announcement is not posted that day.
+ */
+func announcementAt(a Announcement, tz TimezoneConfig, loc *time.Location, day time.Time) (time.Time, bool) {
+	clock, err := time.Parse("15:04", a.Time)
+	if err != nil {
+		return time.Time{}, false
+	}
+	local := day.In(loc)
+	at, _ := tzutil.ResolveWallClock(time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC), loc)
+	return at, announcesOn(a, tz, at)
+}
+
+// formatAnnouncement expands an announcement's placeholders for the time it is posted.
+func formatAnnouncement(a Announcement, tz TimezoneConfig, local time.Time) string {
+	return footerPlaceholder.ReplaceAllStringFunc(a.Message, func(m string) string {
+		if field, ok := announceFields[m[1:len(m)-1]]; ok {
+			return field(tz, local)
+		}
+		return m
+	})
+}
+
+/**
+ * This function builds the body of a webhook post for the service the URL belongs to:
+ * an Adaptive Card for Microsoft Teams (Workflows and connector webhooks), and the
+ * "text" payload that Slack, Mattermost, Rocket.Chat, and Google Chat all accept.
+ *
+ * @param webhook - The webhook URL.
+ * @param text - The message.
+ * @returns The JSON body.
+ */
+func webhookPayload(webhook, text string) ([]byte, error) {
+	u, err := url.Parse(webhook)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("expected an https:// URL")
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, teams := range []string{"office.com", "logic.azure.com", "powerplatform.com"} {
+		if host == teams || strings.HasSuffix(host, "."+teams) {
+			card := map[string]interface{}{
+				"type": "AdaptiveCard", "version": "1.4",
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"body":    []map[string]interface{}{{"type": "TextBlock", "text": text, "wrap": true}},
+			}
+			return json.Marshal(map[string]interface{}{
+				"type":        "message",
+				"attachments": []map[string]interface{}{{"contentType": "application/vnd.microsoft.card.adaptive", "content": card}},
+			})
+		}
+	}
+	return json.Marshal(map[string]string{"text": text})
+}
+
+/**
+ * This function posts a message to a channel's webhook.
+ *
+ * @param webhook - The webhook URL (announce_webhook).
+ * @param text - The message.
+ * @returns An error if no webhook is set or the service rejects the post.
+ */
+func postAnnouncement(webhook, text string) error {
+	if webhook == "" {
+		return fmt.Errorf("no webhook is set; run kairos config set announce_webhook https://hooks.slack.com/services/...")
+	}
+	data, err := webhookPayload(webhook, text)
+	if err != nil {
+		return fmt.Errorf("invalid announce_webhook: %v", err)
+	}
+	resp, err := announceClient.Post(webhook, "application/json", bytes.NewReader(data))
+	if err != nil {
+		// The error names the URL, which holds the webhook's secret.
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("the webhook rejected the post: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+/**
+ * This function posts every announcement that came due since the previous check, in the
+ * background. It runs with the other timers, in the daemon or in a dashboard that is not
+ * attached to one, so announcements are posted once even with no dashboard open.
+ *
+ * @param now - The current time.
+ */
+func checkAnnouncements(now time.Time) {
+	since := lastAnnounceCheck
+	lastAnnounceCheck = now
+	if since.IsZero() || len(settings.Announcements) == 0 {
+		return
+	}
+	for _, a := range settings.Announcements {
+		tz, loc, err := announceZone(a)
+		if err != nil {
+			continue
+		}
+		// The previous check may have been on the day before, in the zone's time.
+		days := []time.Time{now}
+		if since.In(loc).Format("2006-01-02") != now.In(loc).Format("2006-01-02") {
+			days = append(days, since)
+		}
+		for _, day := range days {
+			at, ok := announcementAt(a, tz, loc, day)
+			if !ok || !at.After(since) || at.After(now) {
+				continue
+			}
+			text, webhook := formatAnnouncement(a, tz, at), settings.AnnounceWebhook
+			go func() {
+				defer recoverWorker("announcement")
+				if err := postAnnouncement(webhook, text); err != nil {
+					logger.Warn("announcement failed", "zone", a.Zone, "time", a.Time, "err", err)
+					showWarning("Announcement failed: " + err.Error())
+					return
+				}
+				logger.Info("announcement posted", "zone", a.Zone, "time", a.Time)
+			}()
+		}
+	}
+}
+
+/**
+ * This function handles `kairos announce add`.
+ *
+ * @param args - "Zone" "HH:MM" "Message".
+ * @returns An error if any argument is invalid or the config cannot be saved.
+ */
+func addAnnouncement(args []string) error {
+	a := Announcement{Zone: args[0], Message: args[2]}
+	if _, _, err := announceZone(a); err != nil {
+		return err
+	}
+	clock, err := time.Parse("15:04", args[1])
+	if err != nil {
+		return fmt.Errorf("invalid time '%s' (expected HH:MM)", args[1])
+	}
+	a.Time = clock.Format("15:04")
+	if a.Days, err = parseAnnounceDays(announceDays); err != nil {
+		return err
+	}
+	for _, m := range footerPlaceholder.FindAllStringSubmatch(a.Message, -1) {
+		if _, ok := announceFields[m[1]]; !ok {
+			return fmt.Errorf("unknown placeholder {%s} (expected any of: %s)", m[1], strings.Join(announceFieldNames(), ", "))
+		}
+	}
+	settings.Announcements = append(settings.Announcements, a)
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Added announcement %d: %s %s in %s\n", len(settings.Announcements), a.Time, defaultString(a.Days, "daily"), a.Zone)
+	if settings.AnnounceWebhook == "" {
+		fmt.Println("\x1b[33mSet the channel with kairos config set announce_webhook URL; kairos announce test checks it.\x1b[0m")
+	}
+	return nil
+}
+
+/**
+ * This function lints the announcements for kairos config check: zones that cannot be
+ * found, times and days that do not parse, unknown placeholders, and a missing webhook.
+ *
+ * @returns A check for each problem.
+ */
+func checkAnnouncementConfig() []doctorCheck {
+	section := "announcements"
+	var checks []doctorCheck
+	for i, a := range settings.Announcements {
+		name := fmt.Sprintf("announcement %d", i+1)
+		fix := fmt.Sprintf("; remove it with kairos announce remove %d and add it again", i+1)
+		if _, _, err := announceZone(a); err != nil {
+			checks = append(checks, doctorCheck{section, name, checkFail, err.Error() + fix})
+		}
+		if _, err := time.Parse("15:04", a.Time); err != nil {
+			checks = append(checks, doctorCheck{section, name, checkFail, fmt.Sprintf("invalid time '%s' (expected HH:MM)%s", a.Time, fix)})
+		}
+		if _, err := parseAnnounceDays(a.Days); err != nil {
+			checks = append(checks, doctorCheck{section, name, checkFail, err.Error() + fix})
+		}
+		for _, m := range footerPlaceholder.FindAllStringSubmatch(a.Message, -1) {
+			if _, ok := announceFields[m[1]]; !ok {
+				checks = append(checks, doctorCheck{section, name, checkWarn, fmt.Sprintf("unknown placeholder {%s} is posted as is%s", m[1], fix)})
+			}
+		}
+	}
+	if len(settings.Announcements) > 0 && settings.AnnounceWebhook == "" {
+		checks = append(checks, doctorCheck{section, "announce_webhook", checkWarn, "no webhook is set, so nothing is posted; fix it with kairos config set announce_webhook URL"})
+	} else if _, err := webhookPayload(settings.AnnounceWebhook, ""); settings.AnnounceWebhook != "" && err != nil {
+		checks = append(checks, doctorCheck{section, "announce_webhook", checkFail, "the webhook is not an https:// URL; fix it with kairos config set announce_webhook URL"})
+	}
+	return checks
+}
+
+// announceFieldNames returns the message placeholders, sorted.
+func announceFieldNames() []string {
+	var names []string
+	for name := range announceFields {
+		names = append(names, "{"+name+"}")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// listAnnouncements handles `kairos announce list`.
+func listAnnouncements() error {
+	if len(settings.Announcements) == 0 {
+		fmt.Println("No announcements configured. Add one with kairos announce add \"Zone\" \"HH:MM\" \"Message\".")
+		return nil
+	}
+	now := appClock.Now(time.UTC)
+	fmt.Println("\n\x1b[36m\x1b[1mANNOUNCEMENTS\x1b[0m")
+	fmt.Printf("%-3s %-15s %-6s %-14s %-22s %s\n", "#", "ZONE", "TIME", "DAYS", "NEXT", "MESSAGE")
+	fmt.Println(strings.Repeat("-", 90))
+	for i, a := range settings.Announcements {
+		next := "-"
+		if tz, loc, err := announceZone(a); err != nil {
+			next = "invalid zone"
+		} else {
+			// The next post is within a week, or two for a single weekday after a holiday.
+			for d := 0; d < 14; d++ {
+				if at, ok := announcementAt(a, tz, loc, now.In(loc).AddDate(0, 0, d)); ok && at.After(now) {
+					next = at.Format("Mon 2 Jan 15:04 MST")
+					break
+				}
+			}
+		}
+		fmt.Printf("%-3d %-15s %-6s %-14s %-22s %s\n", i+1, a.Zone, a.Time, defaultString(a.Days, "daily"), next, a.Message)
+	}
+	if settings.AnnounceWebhook == "" {
+		fmt.Println("\x1b[33mNo webhook is set: kairos config set announce_webhook URL\x1b[0m")
+	}
+	fmt.Println()
+	return nil
+}
+
+// announcementIndex parses an announcement number from kairos announce list.
+func announcementIndex(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(settings.Announcements) {
+		return 0, fmt.Errorf("no announcement %s (see kairos announce list)", arg)
+	}
+	return n - 1, nil
+}
+
+// removeAnnouncement handles `kairos announce remove`.
+func removeAnnouncement(arg string) error {
+	i, err := announcementIndex(arg)
+	if err != nil {
+		return err
+	}
+	a := settings.Announcements[i]
+	settings.Announcements = append(settings.Announcements[:i], settings.Announcements[i+1:]...)
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed the announcement at %s in %s\n", a.Time, a.Zone)
+	return nil
+}
+
+// testAnnouncement handles `kairos announce test`, posting the message as it would read today.
+func testAnnouncement(arg string) error {
+	i, err := announcementIndex(arg)
+	if err != nil {
+		return err
+	}
+	a := settings.Announcements[i]
+	tz, loc, err := announceZone(a)
+	if err != nil {
+		return err
+	}
+	at, _ := announcementAt(a, tz, loc, appClock.Now(loc))
+	text := formatAnnouncement(a, tz, at)
+	if err := postAnnouncement(settings.AnnounceWebhook, text); err != nil {
+		return err
+	}
+	fmt.Printf("Posted: %s\n", text)
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/workhours"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// Output formats supported by `kairos availability`.
+var availabilityFormats = []string{"markdown", "json", "plain"}
+
+var (
+	// availabilityMe is set by `kairos availability --me`.
+	availabilityMe string
+	// availabilityFormat is set by `kairos availability --format`.
+	availabilityFormat string
+)
+
+// availabilityCommand builds the `kairos availability` command.
+func availabilityCommand() *command {
+	return &command{
+		Name:  "availability",
+		Short: "Prints your working hours in every teammate's timezone (--me, --format markdown|json|plain)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&availabilityMe, "me", "", "Your zone, whose business hours are shown (default the primary zone)")
+			fs.StringVar(&availabilityFormat, "format", "markdown", "Output format: "+strings.Join(availabilityFormats, ", ")+" (md for markdown)")
+		},
+		Run: func(args []string) error {
+			return printAvailability(availabilityMe, strings.ToLower(availabilityFormat))
+		},
+	}
+}
+
+// availabilityWindow is one of my working windows in a teammate's zone.
+type availabilityWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// DayOffset is the teammate's calendar day at the start of the window relative to mine, e.g. 1 for their tomorrow.
+	DayOffset int `json:"day_offset"`
+}
+
+// availabilityZone is my working hours as one teammate's zone sees them.
+type availabilityZone struct {
+	Zone     string               `json:"zone"`
+	Location string               `json:"location"`
+	Windows  []availabilityWindow `json:"windows"`
+	// OverlapMinutes is how much of my working time falls in the zone's own business hours.
+	OverlapMinutes int `json:"overlap_minutes"`
+}
+
+// String formats the window as "22:00-06:00", with the day offset, e.g. "22:00-06:00 (+1 day)".
+func (w availabilityWindow) String() string {
+	text := w.Start + "-" + w.End
+	if w.DayOffset != 0 {
+		text += fmt.Sprintf(" (%+d day)", w.DayOffset)
+	}
+	return text
+}
+
+// availabilityWindows joins the windows of a zone, e.g. "03:00-06:00, 07:00-10:00".
+func availabilityWindows(z availabilityZone) string {
+	var parts []string
+	for _, w := range z.Windows {
+		parts = append(parts, w.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+/**
+ * This function handles `kairos availability`, the inverse of the overlap finder: it
+ * converts my business hours into every other configured zone ("my 9-5 is your 22-6"),
+ * with how much of it falls within that zone's own business hours, for pasting into a
+ * team handbook. Offsets change with DST, so the hours are converted on one working day,
+ * my next (today if it is one), which the output names.
+ *
+ * @param me - The --me flag: a configured zone, or "" for the primary zone.
+ * @param format - One of availabilityFormats, or "md".
+ * @returns An error if the zone or format is invalid.
+ */
+func printAvailability(me, format string) error {
+	if format == "md" {
+		format = "markdown"
+	}
+	if !containsString(availabilityFormats, format) {
+		return fmt.Errorf("unknown format '%s' (expected one of: %s)", format, strings.Join(availabilityFormats, ", "))
+	}
+	if me == "" {
+		zones := displayZones()
+		if len(zones) == 0 {
+			return fmt.Errorf("no timezones configured; add one with 'kairos add'")
+		}
+		me = zones[0].Name
+	}
+	mine, loc, err := findZone(me)
+	if err != nil {
+		return err
+	}
+	day := appClock.Now(loc)
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i < 14 && !isWorkday(mine, day); i++ {
+		day = day.AddDate(0, 0, 1)
+	}
+	hours := zones.BusinessHours(mine)
+
+	var list []availabilityZone
+	for _, tz := range timezones {
+		if tz.Name == mine.Name {
+			continue
+		}
+		theirs, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			continue
+		}
+		z := availabilityZone{Zone: tz.Name, Location: tz.Location}
+		for _, b := range hours {
+			start := b.StartOn(day)
+			end := start.Add(b.Length())
+			z.Windows = append(z.Windows, availabilityWindow{
+				Start:     start.In(theirs).Format("15:04"),
+				End:       end.In(theirs).Format("15:04"),
+				DayOffset: tzutil.CalendarDayDiff(start.In(theirs), start),
+			})
+			overlap, _ := businessTimeBetween(tz, start.In(theirs), end.In(theirs))
+			z.OverlapMinutes += int(overlap.Minutes())
+		}
+		list = append(list, z)
+	}
+
+	headline := fmt.Sprintf("My working hours: %s in %s (%s), Monday to Friday", hours, mine.Name, day.Format("MST"))
+	asOf := day.Format("Mon 02 Jan 2006")
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(map[string]interface{}{
+			"me":       mine.Name,
+			"location": mine.Location,
+			"hours":    hours.String(),
+			"date":     day.Format("2006-01-02"),
+			"zones":    list,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	case "markdown":
+		fmt.Printf("**%s**\n\n_Converted on %s; daylight saving time can shift them by an hour._\n\n", headline, asOf)
+		fmt.Println("| Zone | My hours in their time | Within their business hours |\n|---|---|---|")
+		for _, z := range list {
+			fmt.Printf("| %s | %s | %s |\n", z.Zone, availabilityWindows(z), workhours.FormatCountdown(time.Duration(z.OverlapMinutes)*time.Minute))
+		}
+	case "plain":
+		fmt.Printf("%s, converted on %s\n", headline, asOf)
+		for _, z := range list {
+			fmt.Printf("  %-15s %-30s %s overlap\n", z.Zone, availabilityWindows(z), workhours.FormatCountdown(time.Duration(z.OverlapMinutes)*time.Minute))
+		}
+	}
+	return nil
+}
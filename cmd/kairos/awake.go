@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iamstoick/kairos/zones"
+)
+
+// lastAwakeCheck is when checkAwakeNotifications last ran.
+var lastAwakeCheck time.Time
+
+// awakeStatus describes whether a zone is within its humane hours, for the info panel.
+func awakeStatus(tz TimezoneConfig, local time.Time) string {
+	b := zones.AwakeHours(tz)
+	if b.CoversClock(local) {
+		return fmt.Sprintf("%s (awake)", b)
+	}
+	return fmt.Sprintf("%s (asleep)", b)
+}
+
+/**
+ * This function shows a notification when a zone with wake_notify on crosses into or
+ * out of its humane hours ("Tokyo just woke up"). It is called on every UI tick and
+ * compares each zone's state at the previous check with its state now.
+ *
+ * @param now - The current time.
+ */
+func checkAwakeNotifications(now time.Time) {
+	since := lastAwakeCheck
+	lastAwakeCheck = now
+	if since.IsZero() {
+		return
+	}
+	for _, tz := range timezones {
+		if !tz.WakeNotify {
+			continue
+		}
+		loc, ok := locations[tz.Name]
+		if !ok {
+			continue
+		}
+		b := zones.AwakeHours(tz)
+		was, is := b.CoversClock(since.In(loc)), b.CoversClock(now.In(loc))
+		switch {
+		case is && !was:
+			showNotification(fmt.Sprintf("%s just woke up (%s there)", tz.Name, now.In(loc).Format("15:04")))
+		case was && !is:
+			showNotification(fmt.Sprintf("%s is winding down for the night (%s there)", tz.Name, now.In(loc).Format("15:04")))
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// backgroundChoices are the values of the background setting.
+var backgroundChoices = []string{"auto", "dark", "light"}
+
+var (
+	// detectedBackground is "light" or "dark" as detected by detectBackground, or "" if unknown.
+	detectedBackground string
+	// backgroundSource says how the background was detected, for `kairos doctor`.
+	backgroundSource string
+)
+
+/**
+ * This function works out whether the terminal has a light or a dark background, so the
+ * default theme can pick readable colors. It asks the terminal for its background color
+ * (an OSC 11 query, answered by most modern terminals and by tmux) and falls back to the
+ * COLORFGBG variable that some terminals set. It does nothing when the background setting
+ * is not auto or stdout is not a terminal, and must run before the terminal UI starts.
+ */
+func detectBackground() {
+	if settings.Background != "" || !stdoutIsTerminal() || os.Getenv("TERM") == "dumb" {
+		return
+	}
+	if reply, err := queryBackgroundColor(); err == nil {
+		if light, ok := parseOSCColor(reply); ok {
+			detectedBackground, backgroundSource = lightOrDark(light), "the terminal's reply to OSC 11"
+			return
+		}
+	} else {
+		logger.Debug("background color query failed", "err", err)
+	}
+	if light, ok := parseColorFGBG(os.Getenv("COLORFGBG")); ok {
+		detectedBackground, backgroundSource = lightOrDark(light), "COLORFGBG"
+	}
+}
+
+// queryBackgroundColor asks the terminal for its background color with an OSC 11 query, returning the reply after "11;".
+func queryBackgroundColor() (string, error) {
+	reply, err := queryTerminal("\x1b]11;?\x1b\\")
+	if err != nil {
+		return "", err
+	}
+	if i := strings.Index(reply, "]11;"); i >= 0 {
+		return reply[i+len("]11;"):], nil
+	}
+	return "", fmt.Errorf("the terminal did not report its background color")
+}
+
+// backgroundIsLight reports whether the default theme should use its light-background colors.
+func backgroundIsLight() bool {
+	if settings.Background != "" {
+		return settings.Background == "light"
+	}
+	return detectedBackground == "light"
+}
+
+// lightOrDark names a background.
+func lightOrDark(light bool) string {
+	if light {
+		return "light"
+	}
+	return "dark"
+}
+
+/**
+ * This function reads a terminal's reply to an OSC 11 query, e.g.
+ * "\x1b]11;rgb:ffff/ffff/dddd\x1b\\", where each channel has one to four hex digits.
+ *
+ * @param reply - The reply, or the part of it after "11;".
+ * @returns Whether the color is light (by its relative luminance), and false if the reply is malformed.
+ */
+func parseOSCColor(reply string) (light, ok bool) {
+	i := strings.Index(reply, "rgb:")
+	if i < 0 {
+		return false, false
+	}
+	spec := reply[i+len("rgb:"):]
+	// The color ends at the BEL or ESC \ that terminates the reply.
+	if end := strings.IndexAny(spec, "\x07\x1b"); end >= 0 {
+		spec = spec[:end]
+	}
+	channels := strings.Split(spec, "/")
+	if len(channels) != 3 {
+		return false, false
+	}
+	var rgb [3]float64
+	for c, hex := range channels {
+		v, err := strconv.ParseUint(hex, 16, 16)
+		if err != nil || len(hex) == 0 || len(hex) > 4 {
+			return false, false
+		}
+		rgb[c] = float64(v) / float64(uint64(1)<<(4*len(hex))-1)
+	}
+	return 0.2126*rgb[0]+0.7152*rgb[1]+0.0722*rgb[2] > 0.5, true
+}
+
+/**
+ * This function reads the COLORFGBG variable that rxvt, Konsole, and some other terminals
+ * set, e.g. "0;15" for black on white. Its last field is the background's color number.
+ *
+ * @param value - The variable's value.
+ * @returns Whether the background is light (white or light gray), and false if it is not set or not a number.
+ */
+func parseColorFGBG(value string) (light, ok bool) {
+	bg, err := strconv.Atoi(value[strings.LastIndex(value, ";")+1:])
+	if err != nil {
+		return false, false
+	}
+	return bg == 7 || bg == 15, true
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package main
+
+import "fmt"
+
+// queryTerminal is not supported here; the background and emoji width come from the environment and the settings.
+func queryTerminal(query string) (string, error) {
+	return "", fmt.Errorf("querying the terminal is not supported on this platform")
+}
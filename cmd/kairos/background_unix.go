@@ -0,0 +1,59 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package main
+
+import (
+	"regexp"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// deviceAttributes matches a terminal's reply to the primary device attributes query.
+var deviceAttributes = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+
+/**
+ * This function sends a query to the terminal and reads its reply, with the terminal in
+ * raw mode so that the reply is neither echoed nor line buffered. A device attributes
+ * query follows it, which every terminal answers after any reply to the query, so a
+ * terminal that ignores the query is noticed at once instead of after the timeout.
+ *
+ * @param query - The escape sequences to send.
+ * @returns Everything the terminal replied, or an error if it cannot be queried.
+ */
+func queryTerminal(query string) (string, error) {
+	fd, err := unix.Open("/dev/tty", unix.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer unix.Close(fd)
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return "", err
+	}
+	// The reply is read without echo or line buffering, each read waiting at most 0.1s.
+	raw := *saved
+	raw.Lflag &^= unix.ICANON | unix.ECHO
+	raw.Cc[unix.VMIN], raw.Cc[unix.VTIME] = 0, 1
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return "", err
+	}
+	defer unix.IoctlSetTermios(fd, ioctlSetTermios, saved)
+
+	if _, err := unix.Write(fd, []byte(query+"\x1b[c")); err != nil {
+		return "", err
+	}
+	var reply []byte
+	buf := make([]byte, 128)
+	for deadline := time.Now().Add(500 * time.Millisecond); time.Now().Before(deadline); {
+		n, err := unix.Read(fd, buf)
+		if err != nil && err != unix.EINTR && err != unix.EAGAIN {
+			return "", err
+		}
+		reply = append(reply, buf[:max(n, 0)]...)
+		if deviceAttributes.Match(reply) {
+			break
+		}
+	}
+	return string(reply), nil
+}
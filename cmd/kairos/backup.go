@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/config"
+)
+
+// maxBackups is how many config backups are kept; older ones are pruned on every save.
+const maxBackups = 20
+
+// backupTimeLayout names backups so that they sort chronologically.
+const backupTimeLayout = "20060102-150405.000"
+
+/**
+ * This function returns the directory holding config backups, next to the config file.
+ *
+ * @returns The backup directory path.
+ */
+func backupDir() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), ".kairos_backups")
+}
+
+// backupPrefix returns the file name prefix of the current config's backups, e.g. "kairos_config-".
+func backupPrefix() string {
+	base := strings.TrimSuffix(filepath.Base(getConfigPath()), filepath.Ext(getConfigPath()))
+	return strings.TrimPrefix(base, ".") + "-"
+}
+
+/**
+ * This function copies the current config file into the backup directory under a
+ * timestamped name and prunes the oldest backups beyond maxBackups.
+ * It is called before every write, so each change can be undone.
+ *
+ * @returns An error if the backup could not be written; a missing config is not an error.
+ */
+func backupConfig() error {
+	data, err := os.ReadFile(getConfigPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(backupDir(), 0755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s%s.json", backupPrefix(), time.Now().Format(backupTimeLayout))
+	if err := os.WriteFile(filepath.Join(backupDir(), name), data, configFileMode()); err != nil {
+		return err
+	}
+	list := listBackups()
+	for _, old := range list[min(len(list), maxBackups):] {
+		os.Remove(filepath.Join(backupDir(), old))
+	}
+	return nil
+}
+
+/**
+ * This function lists the backups of the current config file.
+ *
+ * @returns The backup file names, newest first.
+ */
+func listBackups() []string {
+	entries, err := os.ReadDir(backupDir())
+	if err != nil {
+		return nil
+	}
+	prefix := backupPrefix()
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names
+}
+
+/**
+ * This function handles the `kairos undo` command.
+ * It restores the most recent backup and discards it, so running it again steps further back.
+ *
+ * @returns An error if the backup cannot be read or restored.
+ */
+func runUndo() error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	list := listBackups()
+	if len(list) == 0 {
+		fmt.Println("Nothing to undo: no config backups found.")
+		return nil
+	}
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	path := filepath.Join(backupDir(), list[0])
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup %s: %v", list[0], err)
+	}
+	if err := writeFileAtomic(getConfigPath(), data, configFileMode()); err != nil {
+		return fmt.Errorf("failed to restore %s: %v", list[0], err)
+	}
+	os.Remove(path)
+	fmt.Printf("Restored the config from %s (%s)\n", list[0], describeBackup(data))
+	return nil
+}
+
+/**
+ * This function handles the `kairos restore` command.
+ * Without arguments it lists the available backups; with a backup name or its number
+ * from that list it restores it. The current config is backed up first, so a restore
+ * can itself be undone.
+ *
+ * @param args - The arguments after "restore".
+ * @returns An error if the backup does not exist or cannot be restored.
+ */
+func runRestore(args []string) error {
+	list := listBackups()
+	if len(args) == 0 {
+		if len(list) == 0 {
+			fmt.Println("No config backups found.")
+			return nil
+		}
+		fmt.Println("\n\x1b[36m\x1b[1mCONFIG BACKUPS\x1b[0m (newest first)")
+		for i, name := range list {
+			data, _ := os.ReadFile(filepath.Join(backupDir(), name))
+			fmt.Printf("  %2d  %-38s \x1b[90m%s\x1b[0m\n", i+1, name, describeBackup(data))
+		}
+		fmt.Println("\nUse: kairos restore <number|name>")
+		return nil
+	}
+
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	name := args[0]
+	if n, err := strconv.Atoi(name); err == nil {
+		if n < 1 || n > len(list) {
+			return fmt.Errorf("no backup number %d (there are %d)", n, len(list))
+		}
+		name = list[n-1]
+	}
+	data, err := os.ReadFile(filepath.Join(backupDir(), filepath.Base(name)))
+	if err != nil {
+		return fmt.Errorf("backup '%s' not found", name)
+	}
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := backupConfig(); err != nil {
+		return fmt.Errorf("failed to back up the current config: %v", err)
+	}
+	if err := writeFileAtomic(getConfigPath(), data, configFileMode()); err != nil {
+		return fmt.Errorf("failed to restore %s: %v", name, err)
+	}
+	fmt.Printf("Restored the config from %s (%s)\n", filepath.Base(name), describeBackup(data))
+	return nil
+}
+
+// describeBackup summarizes a backed-up config, e.g. "4 timezone(s), 1 event(s)".
+func describeBackup(data []byte) string {
+	var cfg Config
+	data, _, err := config.Migrate(data)
+	if err != nil || json.Unmarshal(data, &cfg) != nil {
+		return "unreadable"
+	}
+	return fmt.Sprintf("%d timezone(s), %d event(s)", len(cfg.Timezones), len(cfg.Events))
+}
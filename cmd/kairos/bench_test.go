@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// benchSizes are the terminal sizes the renderer is timed at: a classic terminal, the
+// default `kairos render` frame, and a large monitor.
+var benchSizes = [][2]int{{80, 24}, {120, 40}, {200, 60}}
+
+// benchZoneCounts are the numbers of zones the renderer is timed with.
+var benchZoneCounts = []int{1, 4, 7, 13}
+
+// benchLocations are the zones rendered, the first N of them for N zones.
+var benchLocations = []string{
+	"America/Los_Angeles", "America/New_York", "Europe/London", "Europe/Berlin", "Asia/Kolkata",
+	"Asia/Tokyo", "Australia/Sydney", "America/Sao_Paulo", "Africa/Lagos", "Asia/Dubai",
+	"Asia/Singapore", "Pacific/Auckland", "UTC",
+}
+
+/**
+ * This benchmark times one full dashboard frame: the layout, every view's text, and the
+ * composed screen, as `kairos render` produces it, at every size and zone count. Each
+ * frame is a second after the one before, so nothing is reused between frames. CI
+ * compares the results with the base branch's (see .github/workflows/bench.yml), so that
+ * a change that would make a kiosk on a small board miss the one-second tick is caught.
+ */
+func BenchmarkRenderFrame(b *testing.B) {
+	for _, size := range benchSizes {
+		for _, zones := range benchZoneCounts {
+			width, height, zones := size[0], size[1], zones
+			b.Run(fmt.Sprintf("%dx%d/zones=%d", width, height, zones), func(b *testing.B) {
+				setupGolden(b)
+				timezones = nil
+				for _, location := range benchLocations[:zones] {
+					timezones = append(timezones, TimezoneConfig{Name: location, Location: location})
+				}
+				loadLocations()
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					now := goldenNow.Add(time.Duration(i) * time.Second)
+					render.Compose(renderDashboard(now, width, height), width, height)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"github.com/iamstoick/kairos/workhours"
+)
+
+// breakSnooze is how long the "b" key postpones the next break reminder.
+const breakSnooze = 10 * time.Minute
+
+var (
+	// dashboardStarted is when the dashboard was opened; the break timer counts from here.
+	dashboardStarted time.Time
+	// nextBreak is when the next break reminder is due.
+	nextBreak time.Time
+)
+
+/**
+ * This function reminds the user to take a break (the 20-20-20 rule: every 20 minutes,
+ * look at something 20 feet away for 20 seconds) once the break_every interval of
+ * continuous dashboard time has passed. It is called on every UI tick.
+ *
+ * @param now - The current time.
+ */
+func checkBreakReminder(now time.Time) {
+	if settings.BreakEvery == "" {
+		return
+	}
+	interval, err := time.ParseDuration(settings.BreakEvery)
+	if err != nil {
+		return
+	}
+	if dashboardStarted.IsZero() {
+		dashboardStarted = now
+	}
+	if nextBreak.IsZero() {
+		nextBreak = now.Add(interval)
+		return
+	}
+	if now.Before(nextBreak) {
+		return
+	}
+	nextBreak = now.Add(interval)
+	msg := fmt.Sprintf("☕ Break time: %s on screen. Look 20 ft away for 20 s (b snoozes)",
+		workhours.FormatCountdown(now.Sub(dashboardStarted)))
+	// The reminder stays up for the length of the break it asks for.
+	showNotificationFor(msg, 20*time.Second)
+	// notify_desktop may send it already.
+	if settings.BreakDesktop && !routes(notifyDesktopRoute(), levelInfo) {
+		if err := desktopNotify("kairos", msg); err != nil {
+			logger.Warn("desktop notification failed", "err", err)
+		}
+	}
+}
+
+// snoozeBreak postpones the next break reminder (the "b" key).
+func snoozeBreak() {
+	if settings.BreakEvery == "" {
+		showNotification("Break reminders are off; enable them with kairos config set break_every 50m")
+		return
+	}
+	nextBreak = appClock.Now(localZone()).Add(breakSnooze)
+	showNotification("Break snoozed until " + nextBreak.Format("15:04"))
+}
+
+/**
+ * This function shows a desktop notification with notify-send on Linux and the BSDs,
+ * or osascript on macOS. The command runs in the background.
+ *
+ * @param title - The notification title.
+ * @param body - The notification text.
+ * @returns An error if the platform is unsupported or the command could not be started.
+ */
+func desktopNotify(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", body, title))
+	case "windows":
+		return fmt.Errorf("desktop notifications are not supported on windows")
+	default:
+		cmd = exec.Command("notify-send", title, body)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		defer recoverWorker("desktop notification")
+		cmd.Wait()
+	}()
+	return nil
+}
+
+// setBreakEvery validates and stores the break reminder interval ("off" disables it).
+func setBreakEvery(v string) error {
+	if v == "off" || v == "none" || v == "" {
+		settings.BreakEvery = ""
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Minute {
+		return fmt.Errorf("invalid break interval '%s' (expected e.g. 20m, 50m, or off)", v)
+	}
+	settings.BreakEvery = v
+	return nil
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Supported alternative calendar systems for the optional second date line.
+const (
+	calendarHijri   = "hijri"
+	calendarHebrew  = "hebrew"
+	calendarChinese = "chinese"
+)
+
+var (
+	// calendarNames lists the accepted values for a zone's "calendar" option.
+	calendarNames = []string{calendarHijri, calendarHebrew, calendarChinese}
+
+	hijriMonths = []string{
+		"Muharram", "Safar", "Rabi' al-Awwal", "Rabi' al-Thani", "Jumada al-Ula", "Jumada al-Akhirah",
+		"Rajab", "Sha'ban", "Ramadan", "Shawwal", "Dhu al-Qa'dah", "Dhu al-Hijjah",
+	}
+
+	// Hebrew months are numbered from Nisan, as in the Torah; the civil year starts at Tishrei (7).
+	hebrewMonths = []string{
+		"Nisan", "Iyar", "Sivan", "Tammuz", "Av", "Elul",
+		"Tishrei", "Cheshvan", "Kislev", "Tevet", "Shevat", "Adar", "Adar II",
+	}
+
+	chineseStems    = []string{"Jia", "Yi", "Bing", "Ding", "Wu", "Ji", "Geng", "Xin", "Ren", "Gui"}
+	chineseBranches = []string{"Zi", "Chou", "Yin", "Mao", "Chen", "Si", "Wu", "Wei", "Shen", "You", "Xu", "Hai"}
+	chineseAnimals  = []string{"Rat", "Ox", "Tiger", "Rabbit", "Dragon", "Snake", "Horse", "Goat", "Monkey", "Rooster", "Dog", "Pig"}
+)
+
+const (
+	// Epochs expressed as fixed day numbers (R.D. 1 = Monday, January 1, 1 CE Gregorian).
+	unixEpochFixed   = 719163
+	islamicEpochFix  = 227015   // July 16, 622 CE (Julian)
+	hebrewEpochFixed = -1373427 // October 7, 3761 BCE (Julian)
+)
+
+/**
+ * This function formats the local calendar date of t in the requested alternative calendar.
+ *
+ * @param calendar - One of the names in calendarNames.
+ * @param t - The time whose local date should be converted.
+ * @returns The formatted date, or an error if the calendar is unknown.
+ */
+func alternateDate(calendar string, t time.Time) (string, error) {
+	fixed := fixedFromDate(t)
+	switch strings.ToLower(calendar) {
+	case calendarHijri:
+		y, m, d := islamicFromFixed(fixed)
+		return fmt.Sprintf("%d %s %d AH", d, hijriMonths[m-1], y), nil
+	case calendarHebrew:
+		y, m, d := hebrewFromFixed(fixed)
+		name := hebrewMonths[m-1]
+		// In leap years the twelfth month is Adar I.
+		if m == 12 && hebrewLeapYear(y) {
+			name = "Adar I"
+		}
+		return fmt.Sprintf("%d %s %d", d, name, y), nil
+	case calendarChinese:
+		y, m, d, leap := chineseFromDate(t.Year(), int(t.Month()), t.Day())
+		month := fmt.Sprintf("month %d", m)
+		if leap {
+			month = "leap " + month
+		}
+		stem, branch := chineseStems[(y+6)%10], chineseBranches[(y+8)%12]
+		return fmt.Sprintf("Lunar %s, day %d · %s-%s (%s)", month, d, stem, strings.ToLower(branch), chineseAnimals[(y+8)%12]), nil
+	}
+	return "", fmt.Errorf("unknown calendar %q (expected one of: %s)", calendar, strings.Join(calendarNames, ", "))
+}
+
+/**
+ * This function converts the local date of t into a fixed day number (R.D.).
+ * Only the year, month and day are used, so the result is independent of the time of day.
+ *
+ * @param t - The time whose local date should be converted.
+ * @returns The fixed day number.
+ */
+func fixedFromDate(t time.Time) int {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(math.Floor(float64(midnight.Unix())/86400)) + unixEpochFixed
+}
+
+// floorDiv performs integer division rounding towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// floorMod returns the remainder of floorDiv, which always has the sign of b.
+func floorMod(a, b int) int {
+	return a - b*floorDiv(a, b)
+}
+
+/**
+ * Tabular (arithmetic) Islamic calendar, as used by the Kuwaiti algorithm.
+ * Observational calendars may differ by a day depending on moon sighting.
+ */
+func fixedFromIslamic(year, month, day int) int {
+	return day + 29*(month-1) + floorDiv(6*month-1, 11) + (year-1)*354 + floorDiv(3+11*year, 30) + islamicEpochFix - 1
+}
+
+func islamicFromFixed(date int) (year, month, day int) {
+	year = floorDiv(30*(date-islamicEpochFix)+10646, 10631)
+	priorDays := date - fixedFromIslamic(year, 1, 1)
+	month = floorDiv(11*priorDays+330, 325)
+	day = date - fixedFromIslamic(year, month, 1) + 1
+	return year, month, day
+}
+
+/**
+ * Arithmetic Hebrew calendar, following Reingold & Dershowitz, "Calendrical Calculations".
+ */
+func hebrewLeapYear(year int) bool {
+	return floorMod(7*year+1, 19) < 7
+}
+
+func hebrewLastMonth(year int) int {
+	if hebrewLeapYear(year) {
+		return 13
+	}
+	return 12
+}
+
+func hebrewElapsedDays(year int) int {
+	monthsElapsed := floorDiv(235*year-234, 19)
+	partsElapsed := 12084 + 13753*monthsElapsed
+	days := 29*monthsElapsed + floorDiv(partsElapsed, 25920)
+	if floorMod(3*(days+1), 7) < 3 {
+		return days + 1
+	}
+	return days
+}
+
+func hebrewYearLengthCorrection(year int) int {
+	ny0 := hebrewElapsedDays(year - 1)
+	ny1 := hebrewElapsedDays(year)
+	ny2 := hebrewElapsedDays(year + 1)
+	switch {
+	case ny2-ny1 == 356:
+		return 2
+	case ny1-ny0 == 382:
+		return 1
+	}
+	return 0
+}
+
+func hebrewNewYear(year int) int {
+	return hebrewEpochFixed + hebrewElapsedDays(year) + hebrewYearLengthCorrection(year)
+}
+
+func hebrewDaysInYear(year int) int {
+	return hebrewNewYear(year+1) - hebrewNewYear(year)
+}
+
+func hebrewLastDayOfMonth(month, year int) int {
+	days := hebrewDaysInYear(year)
+	switch {
+	case month == 2 || month == 4 || month == 6 || month == 10 || month == 13:
+		return 29
+	case month == 12 && !hebrewLeapYear(year):
+		return 29
+	case month == 8 && days%10 != 5: // Cheshvan is long only in 355/385-day years.
+		return 29
+	case month == 9 && days%10 == 3: // Kislev is short in 353/383-day years.
+		return 29
+	}
+	return 30
+}
+
+func fixedFromHebrew(year, month, day int) int {
+	date := hebrewNewYear(year) + day - 1
+	if month < 7 {
+		for m := 7; m <= hebrewLastMonth(year); m++ {
+			date += hebrewLastDayOfMonth(m, year)
+		}
+		for m := 1; m < month; m++ {
+			date += hebrewLastDayOfMonth(m, year)
+		}
+	} else {
+		for m := 7; m < month; m++ {
+			date += hebrewLastDayOfMonth(m, year)
+		}
+	}
+	return date
+}
+
+func hebrewFromFixed(date int) (year, month, day int) {
+	approx := int(math.Floor(float64(date-hebrewEpochFixed)/(35975351.0/98496.0))) + 1
+	year = approx - 1
+	for hebrewNewYear(year+1) <= date {
+		year++
+	}
+	start := 1
+	if date < fixedFromHebrew(year, 1, 1) {
+		start = 7
+	}
+	month = start
+	for date > fixedFromHebrew(year, month, hebrewLastDayOfMonth(month, year)) {
+		month++
+	}
+	day = date - fixedFromHebrew(year, month, 1) + 1
+	return year, month, day
+}
+
+/**
+ * Chinese lunisolar calendar, computed astronomically for the meridian of
+ * Beijing (UTC+8) using Meeus' new moon and solar longitude approximations.
+ */
+const chineseTimeZone = 8.0
+
+func julianDayNumber(day, month, year int) int {
+	a := (14 - month) / 12
+	y := year + 4800 - a
+	m := month + 12*a - 3
+	jd := day + (153*m+2)/5 + 365*y + y/4 - y/100 + y/400 - 32045
+	if jd < 2299161 {
+		jd = day + (153*m+2)/5 + 365*y + y/4 - 32083
+	}
+	return jd
+}
+
+func newMoon(k int) float64 {
+	kf := float64(k)
+	T := kf / 1236.85
+	T2 := T * T
+	T3 := T2 * T
+	dr := math.Pi / 180
+	jd1 := 2415020.75933 + 29.53058868*kf + 0.0001178*T2 - 0.000000155*T3
+	jd1 += 0.00033 * math.Sin((166.56+132.87*T-0.009173*T2)*dr)
+	M := 359.2242 + 29.10535608*kf - 0.0000333*T2 - 0.00000347*T3
+	Mpr := 306.0253 + 385.81691806*kf + 0.0107306*T2 + 0.00001236*T3
+	F := 21.2964 + 390.67050646*kf - 0.0016528*T2 - 0.00000239*T3
+	c1 := (0.1734-0.000393*T)*math.Sin(M*dr) + 0.0021*math.Sin(2*dr*M)
+	c1 -= 0.4068*math.Sin(Mpr*dr) + 0.0161*math.Sin(dr*2*Mpr)
+	c1 -= 0.0004 * math.Sin(dr*3*Mpr)
+	c1 += 0.0104*math.Sin(dr*2*F) - 0.0051*math.Sin(dr*(M+Mpr))
+	c1 -= 0.0074*math.Sin(dr*(M-Mpr)) + 0.0004*math.Sin(dr*(2*F+M))
+	c1 -= 0.0004*math.Sin(dr*(2*F-M)) - 0.0006*math.Sin(dr*(2*F+Mpr))
+	c1 += 0.0010*math.Sin(dr*(2*F-Mpr)) + 0.0005*math.Sin(dr*(2*Mpr+M))
+	var deltaT float64
+	if T < -11 {
+		deltaT = 0.001 + 0.000839*T + 0.0002261*T2 - 0.00000845*T3 - 0.000000081*T*T3
+	} else {
+		deltaT = -0.000278 + 0.000265*T + 0.000262*T2
+	}
+	return jd1 + c1 - deltaT
+}
+
+func newMoonDay(k int) int {
+	return int(math.Floor(newMoon(k) + 0.5 + chineseTimeZone/24))
+}
+
+// sunLongitude returns the apparent solar longitude in radians for a Julian date.
+func sunLongitude(jdn float64) float64 {
+	T := (jdn - 2451545.0) / 36525
+	T2 := T * T
+	dr := math.Pi / 180
+	M := 357.52910 + 35999.05030*T - 0.0001559*T2 - 0.00000048*T*T2
+	L0 := 280.46645 + 36000.76983*T + 0.0003032*T2
+	DL := (1.914600 - 0.004817*T - 0.000014*T2) * math.Sin(dr*M)
+	DL += (0.019993-0.000101*T)*math.Sin(dr*2*M) + 0.000290*math.Sin(dr*3*M)
+	L := (L0 + DL) * dr
+	return L - 2*math.Pi*math.Floor(L/(2*math.Pi))
+}
+
+// sunSector returns which of the 12 major solar terms (30° sectors) a day falls in.
+func sunSector(dayNumber int) int {
+	return int(math.Floor(sunLongitude(float64(dayNumber)-0.5-chineseTimeZone/24) / math.Pi * 6))
+}
+
+// lunarMonth11 returns the day number of the start of the month containing the winter solstice.
+func lunarMonth11(year int) int {
+	off := julianDayNumber(31, 12, year) - 2415021
+	k := int(math.Floor(float64(off) / 29.530588853))
+	nm := newMoonDay(k)
+	if sunSector(nm) >= 9 {
+		nm = newMoonDay(k - 1)
+	}
+	return nm
+}
+
+// leapMonthOffset finds the first month after a11 that contains no major solar term.
+func leapMonthOffset(a11 int) int {
+	k := int(math.Floor((float64(a11)-2415021.076998695)/29.530588853 + 0.5))
+	i := 1
+	arc := sunSector(newMoonDay(k + i))
+	for {
+		last := arc
+		i++
+		arc = sunSector(newMoonDay(k + i))
+		if arc == last || i >= 14 {
+			break
+		}
+	}
+	return i - 1
+}
+
+func chineseFromDate(year, month, day int) (lunarYear, lunarMonth, lunarDay int, leap bool) {
+	dayNumber := julianDayNumber(day, month, year)
+	k := int(math.Floor((float64(dayNumber) - 2415021.076998695) / 29.530588853))
+	monthStart := newMoonDay(k + 1)
+	if monthStart > dayNumber {
+		monthStart = newMoonDay(k)
+	}
+	a11 := lunarMonth11(year)
+	b11 := a11
+	if a11 >= monthStart {
+		lunarYear = year
+		a11 = lunarMonth11(year - 1)
+	} else {
+		lunarYear = year + 1
+		b11 = lunarMonth11(year + 1)
+	}
+	lunarDay = dayNumber - monthStart + 1
+	diff := (monthStart - a11) / 29
+	lunarMonth = diff + 11
+	if b11-a11 > 365 {
+		leapDiff := leapMonthOffset(a11)
+		if diff >= leapDiff {
+			lunarMonth = diff + 10
+			leap = diff == leapDiff
+		}
+	}
+	if lunarMonth > 12 {
+		lunarMonth -= 12
+	}
+	if lunarMonth >= 11 && diff < 4 {
+		lunarYear--
+	}
+	return lunarYear, lunarMonth, lunarDay, leap
+}
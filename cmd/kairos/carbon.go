@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+const (
+	// carbonRefresh is how often grid carbon intensities and forecasts are fetched.
+	carbonRefresh = 30 * time.Minute
+	// defaultGreenThreshold is the carbon intensity (gCO2eq/kWh) at or below which an hour is green.
+	defaultGreenThreshold = 200
+	// nationalGridAPI is the free GB carbon intensity API, used for the GB grid.
+	nationalGridAPI = "https://api.carbonintensity.org.uk"
+	// electricityMapsAPI serves every other grid zone and needs carbon_token.
+	electricityMapsAPI = "https://api.electricitymap.org/v3"
+)
+
+// gridZonePattern matches an Electricity Maps grid zone code, e.g. "DE", "US-CAL-CISO", or "IN-WE".
+var gridZonePattern = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]+)*$`)
+
+// carbonPoint is a grid's carbon intensity, in gCO2eq/kWh, from a time until the next point.
+type carbonPoint struct {
+	At        time.Time `json:"at"`
+	Intensity float64   `json:"intensity"`
+}
+
+// carbonReading is a grid's current carbon intensity and its forecast, if the API gives one.
+type carbonReading struct {
+	Intensity float64       `json:"intensity"`
+	Forecast  []carbonPoint `json:"forecast,omitempty"`
+}
+
+var (
+	// carbonMu guards carbonReadings, which the carbon worker writes and the dashboard reads.
+	carbonMu sync.Mutex
+	// carbonReadings holds the latest reading of each zone, by zone name.
+	carbonReadings = map[string]carbonReading{}
+)
+
+// parseGridZone normalizes a zone's carbon option to a grid zone code, e.g. "de" to "DE".
+func parseGridZone(v string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(v))
+	if !gridZonePattern.MatchString(code) {
+		return "", fmt.Errorf("invalid grid zone '%s' (expected an Electricity Maps zone such as GB, DE, or US-CAL-CISO)", v)
+	}
+	return code, nil
+}
+
+// greenThreshold returns the green_threshold setting in gCO2eq/kWh.
+func greenThreshold() float64 {
+	if v, err := strconv.ParseFloat(settings.GreenThreshold, 64); err == nil {
+		return v
+	}
+	return defaultGreenThreshold
+}
+
+// setGreenThreshold validates and stores the green threshold ("default" restores 200 g/kWh).
+func setGreenThreshold(v string) error {
+	if v == "default" || v == "none" || v == "" {
+		settings.GreenThreshold = ""
+		return nil
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 || n > 2000 {
+		return fmt.Errorf("invalid green threshold '%s' (expected gCO2eq/kWh, e.g. 150)", v)
+	}
+	settings.GreenThreshold = v
+	return nil
+}
+
+/**
+ * This function starts the carbon worker if any zone has a grid zone. Every 30 minutes it
+ * fetches each grid's carbon intensity and forecast in the background; a failed fetch
+ * keeps the last reading.
+ */
+func startCarbonWorker() {
+	var zones []TimezoneConfig
+	for _, tz := range timezones {
+		if tz.Carbon != "" {
+			zones = append(zones, tz)
+		}
+	}
+	if len(zones) == 0 {
+		return
+	}
+	goWorker("carbon worker", func(ctx context.Context) {
+		lastErr := map[string]string{}
+		for {
+			for _, tz := range zones {
+				reading, err := fetchCarbonReading(tz.Carbon, time.Now())
+				if err != nil {
+					if err.Error() != lastErr[tz.Name] {
+						logger.Warn("carbon intensity fetch failed", "zone", tz.Name, "grid", tz.Carbon, "err", err)
+					}
+					lastErr[tz.Name] = err.Error()
+					continue
+				}
+				delete(lastErr, tz.Name)
+				carbonMu.Lock()
+				carbonReadings[tz.Name] = reading
+				carbonMu.Unlock()
+			}
+			if !sleepCtx(ctx, carbonRefresh) {
+				return
+			}
+		}
+	})
+}
+
+/**
+ * This function fetches a grid's carbon intensity and forecast. The GB grid comes from
+ * the free National Grid ESO API, with a half-hourly forecast for the next 24 hours; any
+ * other zone comes from Electricity Maps, whose forecast is left out when the token's
+ * plan does not include it.
+ *
+ * @param grid - The grid zone code, e.g. "GB" or "DE".
+ * @param now - The current time.
+ * @returns The reading, or an error.
+ */
+func fetchCarbonReading(grid string, now time.Time) (carbonReading, error) {
+	if grid == "GB" {
+		return fetchNationalGrid(now)
+	}
+	if settings.CarbonToken == "" {
+		return carbonReading{}, fmt.Errorf("set carbon_token to use the %s grid (only GB works without one)", grid)
+	}
+	var latest struct {
+		CarbonIntensity *float64 `json:"carbonIntensity"`
+	}
+	if err := electricityMapsGet("/carbon-intensity/latest", grid, &latest); err != nil {
+		return carbonReading{}, err
+	}
+	if latest.CarbonIntensity == nil {
+		return carbonReading{}, fmt.Errorf("Electricity Maps has no carbon intensity for %s", grid)
+	}
+	reading := carbonReading{Intensity: *latest.CarbonIntensity}
+	var forecast struct {
+		Forecast []struct {
+			CarbonIntensity float64   `json:"carbonIntensity"`
+			Datetime        time.Time `json:"datetime"`
+		} `json:"forecast"`
+	}
+	if err := electricityMapsGet("/carbon-intensity/forecast", grid, &forecast); err != nil {
+		logger.Debug("carbon forecast unavailable", "grid", grid, "err", err)
+		return reading, nil
+	}
+	for _, p := range forecast.Forecast {
+		reading.Forecast = append(reading.Forecast, carbonPoint{At: p.Datetime, Intensity: p.CarbonIntensity})
+	}
+	return reading, nil
+}
+
+// electricityMapsGet calls an Electricity Maps API path for a grid zone and decodes the response into out.
+func electricityMapsGet(path, grid string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, electricityMapsAPI+path+"?"+url.Values{"zone": {grid}}.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("auth-token", settings.CarbonToken)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var body struct {
+			Message string `json:"message"`
+			Error   string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("Electricity Maps rejected the request: %s %s", resp.Status, defaultString(body.Message, body.Error))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("unexpected Electricity Maps response: %v", err)
+	}
+	return nil
+}
+
+// fetchNationalGrid fetches the GB grid's half-hourly carbon intensity for the next 24 hours.
+func fetchNationalGrid(now time.Time) (carbonReading, error) {
+	resp, err := httpClient.Get(nationalGridAPI + "/intensity/" + now.UTC().Format("2006-01-02T15:04Z") + "/fw24h")
+	if err != nil {
+		return carbonReading{}, err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Data []struct {
+			From      string `json:"from"`
+			Intensity struct {
+				Forecast *float64 `json:"forecast"`
+				Actual   *float64 `json:"actual"`
+			} `json:"intensity"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return carbonReading{}, fmt.Errorf("unexpected carbon intensity response: %s %v", resp.Status, err)
+	}
+	if resp.StatusCode/100 != 2 || len(body.Data) == 0 {
+		return carbonReading{}, fmt.Errorf("the carbon intensity API returned no data: %s", resp.Status)
+	}
+	var reading carbonReading
+	for i, d := range body.Data {
+		at, err := time.Parse("2006-01-02T15:04Z", d.From)
+		if err != nil || d.Intensity.Forecast == nil {
+			continue
+		}
+		reading.Forecast = append(reading.Forecast, carbonPoint{At: at, Intensity: *d.Intensity.Forecast})
+		if i == 0 {
+			// The current half hour has a measured value once it is under way.
+			reading.Intensity = *d.Intensity.Forecast
+			if d.Intensity.Actual != nil {
+				reading.Intensity = *d.Intensity.Actual
+			}
+		}
+	}
+	return reading, nil
+}
+
+/**
+ * This function finds the next green window in a reading's forecast: the first run of
+ * forecast periods at or below the threshold that has not ended yet. Each period lasts
+ * until the next point; the last one as long as the one before it.
+ *
+ * @param r - The reading.
+ * @param now - The current time.
+ * @param threshold - The greenest intensity that counts, in gCO2eq/kWh.
+ * @returns The window's start (now if it has begun) and end, and whether there is one.
+ */
+func greenWindow(r carbonReading, now time.Time, threshold float64) (time.Time, time.Time, bool) {
+	var start, end time.Time
+	for i, p := range r.Forecast {
+		step := time.Hour
+		if i+1 < len(r.Forecast) {
+			step = r.Forecast[i+1].At.Sub(p.At)
+		} else if i > 0 {
+			step = p.At.Sub(r.Forecast[i-1].At)
+		}
+		if !p.At.Add(step).After(now) {
+			continue
+		}
+		if p.Intensity > threshold {
+			if !start.IsZero() {
+				break
+			}
+			continue
+		}
+		if start.IsZero() {
+			start = p.At
+		}
+		end = p.At.Add(step)
+	}
+	if start.IsZero() {
+		return start, end, false
+	}
+	if start.Before(now) {
+		start = now
+	}
+	return start, end, true
+}
+
+// zoneCarbonReading returns the zone's latest carbon reading, if there is one.
+func zoneCarbonReading(tz TimezoneConfig) (carbonReading, bool) {
+	carbonMu.Lock()
+	defer carbonMu.Unlock()
+	reading, ok := carbonReadings[tz.Name]
+	return reading, ok
+}
+
+// formatGreenTime formats a time in a zone as HH:MM, with the weekday when it is not today.
+func formatGreenTime(t time.Time, loc *time.Location, now time.Time) string {
+	local := t.In(loc)
+	if local.YearDay() != now.In(loc).YearDay() {
+		return local.Format("Mon 15:04")
+	}
+	return local.Format("15:04")
+}
+
+/**
+ * This function describes a grid's green hours, e.g. "green until 16:00" while the
+ * current intensity is at or below the threshold, or "green 13:00-16:00" for the next
+ * forecast window, in the zone's local time.
+ *
+ * @param r - The reading.
+ * @param loc - The zone's location.
+ * @param now - The current time.
+ * @returns The description, or "" when there is no forecast to tell.
+ */
+func greenHoursText(r carbonReading, loc *time.Location, now time.Time) string {
+	threshold := greenThreshold()
+	start, end, ok := greenWindow(r, now, threshold)
+	switch {
+	case r.Intensity <= threshold && ok && !start.After(now):
+		return "green until " + formatGreenTime(end, loc, now)
+	case r.Intensity <= threshold:
+		return "green now"
+	case ok:
+		return "green " + formatGreenTime(start, loc, now) + "-" + formatGreenTime(end, loc, now)
+	case len(r.Forecast) > 0:
+		return "no green hours forecast"
+	}
+	return ""
+}
+
+// carbonLine returns the zone's carbon badge for its view, e.g. "🌱 142 g/kWh · green until 16:00", or "".
+func carbonLine(tz TimezoneConfig, now time.Time) string {
+	reading, ok := zoneCarbonReading(tz)
+	if tz.Carbon == "" || !ok {
+		return ""
+	}
+	loc, ok := locations[tz.Name]
+	if !ok {
+		return ""
+	}
+	badge := fmt.Sprintf("\x1b[90m⚡ %.0f g/kWh\x1b[0m", reading.Intensity)
+	if reading.Intensity <= greenThreshold() {
+		badge = fmt.Sprintf("\x1b[32m🌱 %.0f g/kWh\x1b[0m", reading.Intensity)
+	}
+	if text := greenHoursText(reading, loc, now); text != "" {
+		return badge + " · " + text
+	}
+	return badge
+}
+
+// greenCommand returns the `kairos green` command.
+func greenCommand() *command {
+	return &command{
+		Name:  "green",
+		Short: "Shows each zone's grid carbon intensity and its next green hours, for scheduling batch jobs (--json for machine-readable output)",
+		Run:   func(args []string) error { return printGreenHours(time.Now()) },
+	}
+}
+
+/**
+ * This function handles `kairos green`: for every zone with a grid zone, it prints the
+ * current carbon intensity and the next green window, in the zone's time and the local
+ * time, so heavy jobs can be put where and when the grid is cleanest.
+ *
+ * @param now - The current time.
+ * @returns An error if no zone has a grid zone.
+ */
+func printGreenHours(now time.Time) error {
+	type greenZone struct {
+		Zone      string     `json:"zone"`
+		Grid      string     `json:"grid"`
+		Intensity float64    `json:"intensity"`
+		Green     bool       `json:"green"`
+		Start     *time.Time `json:"green_start,omitempty"`
+		End       *time.Time `json:"green_end,omitempty"`
+		Error     string     `json:"error,omitempty"`
+		loc       *time.Location
+		forecast  bool
+	}
+	threshold := greenThreshold()
+	var rows []greenZone
+	for _, tz := range timezones {
+		if tz.Carbon == "" {
+			continue
+		}
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			return err
+		}
+		row := greenZone{Zone: tz.Name, Grid: tz.Carbon, loc: loc}
+		reading, err := fetchCarbonReading(tz.Carbon, now)
+		if err != nil {
+			row.Error = err.Error()
+			rows = append(rows, row)
+			continue
+		}
+		row.Intensity, row.Green, row.forecast = reading.Intensity, reading.Intensity <= threshold, len(reading.Forecast) > 0
+		if start, end, ok := greenWindow(reading, now, threshold); ok {
+			row.Start, row.End = &start, &end
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("no zone has a grid zone; set one with kairos set \"Zone\" carbon GB")
+	}
+	if jsonOutput {
+		out, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Printf("\n\x1b[36m\x1b[1mGREEN HOURS\x1b[0m \x1b[90m(at or below %g gCO2eq/kWh)\x1b[0m\n", threshold)
+	for _, row := range rows {
+		if row.Error != "" {
+			fmt.Printf("  %-16s %-12s \x1b[31m%s\x1b[0m\n", row.Zone, row.Grid, row.Error)
+			continue
+		}
+		intensity := fmt.Sprintf("%4.0f g/kWh", row.Intensity)
+		if row.Green {
+			intensity = "\x1b[32m" + intensity + "\x1b[0m"
+		}
+		window := "\x1b[90mno forecast\x1b[0m"
+		switch {
+		case row.Start != nil:
+			window = fmt.Sprintf("green %s-%s (%s-%s here)", formatGreenTime(*row.Start, row.loc, now), formatGreenTime(*row.End, row.loc, now),
+				formatGreenTime(*row.Start, localZone(), now), formatGreenTime(*row.End, localZone(), now))
+		case row.forecast:
+			window = "\x1b[90mno green hours forecast\x1b[0m"
+		}
+		fmt.Printf("  %-16s %-12s %s  %s\n", row.Zone, row.Grid, intensity, window)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+var (
+	// cycleSeconds is set by --cycle: how often the primary view moves to the next zone (0 disables it).
+	cycleSeconds int
+	// carouselPaused is toggled with the "p" key.
+	carouselPaused bool
+	// lastRotation is when the carousel last moved (or was started or resumed).
+	lastRotation time.Time
+)
+
+/**
+ * This function advances the carousel of primary zones when it is due: the primary
+ * zone moves to the end of the list and the next one takes the top view. The order is
+ * only changed in memory and is never saved. It is called on every UI tick.
+ *
+ * @param now - The current time.
+ */
+func checkCarousel(now time.Time) {
+	// Zulu stays pinned at the top in military mode, so there is nothing to rotate.
+	if cycleSeconds <= 0 || carouselPaused || settings.Military || len(timezones) < 2 {
+		return
+	}
+	if lastRotation.IsZero() {
+		lastRotation = now
+		return
+	}
+	if now.Sub(lastRotation) < time.Duration(cycleSeconds)*time.Second {
+		return
+	}
+	lastRotation = now
+	timezones = append(timezones[1:], timezones[0])
+}
+
+/**
+ * This function pauses or resumes the carousel (the "p" key). Resuming restarts the
+ * interval, so the current zone stays on top for a full period.
+ */
+func toggleCarousel() {
+	if cycleSeconds <= 0 {
+		showNotification("Carousel is off; start kairos with --cycle N to rotate zones")
+		return
+	}
+	carouselPaused = !carouselPaused
+	if carouselPaused {
+		showNotification(fmt.Sprintf("Carousel paused on %s", timezones[0].Name))
+		return
+	}
+	lastRotation = time.Time{}
+	showNotification(fmt.Sprintf("Carousel resumed (every %ds)", cycleSeconds))
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/jroimartin/gocui"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// celebrationDuration is how long a celebration plays in its view.
+const celebrationDuration = 6 * time.Second
+
+// celebrationFrameInterval is how often the view is redrawn while a celebration plays.
+const celebrationFrameInterval = 100 * time.Millisecond
+
+// celebrationColors are the ANSI colors of the confetti and the fireworks.
+var celebrationColors = []int{31, 32, 33, 34, 35, 36}
+
+// celebrationState is a celebration playing in one zone's view.
+type celebrationState struct {
+	// Kind is "confetti" or "fireworks".
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// Zone is the zone whose view shows it; "" is the primary view.
+	Zone  string    `json:"zone,omitempty"`
+	Start time.Time `json:"start"`
+}
+
+var (
+	// celebration is the celebration playing, if any.
+	celebration *celebrationState
+	// celebrationStarted wakes the celebration worker when one starts.
+	celebrationStarted = make(chan struct{}, 1)
+	// celebrationCountdown is the countdown at the last check, so that reaching zero is noticed once.
+	celebrationCountdown countdown
+	// celebratedNewYear is the last year each zone's New Year was celebrated.
+	celebratedNewYear = map[string]int{}
+	// celebrateZone and celebrateFireworks are set by `kairos celebrate --zone` and `--fireworks`.
+	celebrateZone      string
+	celebrateFireworks bool
+)
+
+// celebrateCommand returns the `kairos celebrate` command.
+func celebrateCommand() *command {
+	return &command{
+		Name:    "celebrate",
+		Usage:   "[message]",
+		Short:   "Plays confetti (or fireworks) in the running dashboard, e.g. when a deploy ships",
+		MaxArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&celebrateZone, "zone", "", "The zone whose view shows it (the primary view by default)")
+			fs.BoolVar(&celebrateFireworks, "fireworks", false, "Fireworks instead of confetti")
+		},
+		Run: func(args []string) error {
+			params := map[string]string{"message": "Hooray!", "zone": celebrateZone, "kind": "confetti"}
+			if len(args) == 1 && strings.TrimSpace(args[0]) != "" {
+				params["message"] = args[0]
+			}
+			if celebrateFireworks {
+				params["kind"] = "fireworks"
+			}
+			_, err := callControl("celebrate", params)
+			return err
+		},
+	}
+}
+
+/**
+ * This function starts a celebration: the message is shown as a notification and, unless
+ * reduced_motion is on, confetti or fireworks play in the zone's view for a few seconds.
+ * A daemon only keeps it for the dashboards attached to it.
+ *
+ * @param kind - "confetti" or "fireworks".
+ * @param message - The message shown in the middle of the view.
+ * @param zone - The zone whose view shows it, or "" for the primary view.
+ */
+func celebrate(kind, message, zone string) {
+	celebration = &celebrationState{Kind: kind, Message: message, Zone: zone, Start: appClock.Now(time.UTC)}
+	logger.Info("celebration", "kind", kind, "zone", zone, "message", message)
+	showNotificationFor("🎉 "+message, celebrationDuration)
+	if settings.ReducedMotion || daemonMode {
+		return
+	}
+	select {
+	case celebrationStarted <- struct{}{}:
+	default:
+	}
+}
+
+// startCelebrationWorker starts the worker that redraws the dashboard while a celebration plays.
+func startCelebrationWorker(g *gocui.Gui) {
+	goWorker("celebration worker", func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-celebrationStarted:
+			}
+			for end := time.Now().Add(celebrationDuration); time.Now().Before(end); {
+				if !sleepCtx(ctx, celebrationFrameInterval) {
+					return
+				}
+				g.Update(func(*gocui.Gui) error {
+					takeSnapshot()
+					return nil
+				})
+			}
+		}
+	})
+}
+
+/**
+ * This function starts the celebrations that come with the time, on every tick: confetti
+ * in the primary view when the countdown reaches zero (see countdown.go), and fireworks
+ * in a zone's view at midnight on its New Year.
+ *
+ * @param now - The current time.
+ */
+func checkCelebrations(now time.Time) {
+	c := currentCountdown(now)
+	if c.OK && celebrationCountdown.OK && c.Title == celebrationCountdown.Title && celebrationCountdown.Left > 0 && c.Left <= 0 {
+		celebrate("confetti", c.Title+" is here!", "")
+	}
+	celebrationCountdown = c
+
+	for _, tz := range displayZones() {
+		loc, ok := locations[tz.Name]
+		if !ok {
+			continue
+		}
+		local := now.In(loc)
+		if local.YearDay() != 1 || local.Hour() != 0 || local.Minute() != 0 || celebratedNewYear[tz.Name] == local.Year() {
+			continue
+		}
+		celebratedNewYear[tz.Name] = local.Year()
+		celebrate("fireworks", fmt.Sprintf("Happy New Year %d, %s!", local.Year(), tz.Name), tz.Name)
+	}
+}
+
+// celebrationView returns the name of the view that shows the celebration: the zone's view if it is shown, else the primary one.
+func celebrationView(c *celebrationState) string {
+	switch {
+	case zenMode:
+		return "zen"
+	case countdownMode:
+		return "countdown"
+	}
+	zones := displayZones()
+	for i, tz := range zones {
+		if i > 0 && i < visibleViews && strings.EqualFold(tz.Name, c.Zone) {
+			if i == 1 && splitActive(zones) {
+				return "split"
+			}
+			return fmt.Sprintf("bottom%d", i)
+		}
+	}
+	return "top"
+}
+
+/**
+ * This function draws the celebration that is playing over its view, in place of the
+ * view's own lines. Once it is over, or with reduced_motion on, the frames are unchanged.
+ *
+ * @param frames - The dashboard's views.
+ * @param now - The instant being drawn.
+ * @returns The views.
+ */
+func applyCelebration(frames []viewFrame, now time.Time) []viewFrame {
+	c := celebration
+	if c == nil || settings.ReducedMotion {
+		return frames
+	}
+	elapsed := now.Sub(c.Start)
+	if elapsed < 0 || elapsed >= celebrationDuration {
+		return frames
+	}
+	name := celebrationView(c)
+	for i := range frames {
+		if frames[i].Name == name {
+			f := &frames[i]
+			f.Lines = celebrationLines(c, elapsed, f.X1-f.X0-1, f.Y1-f.Y0-1)
+		}
+	}
+	return frames
+}
+
+/**
+ * This function draws one frame of a celebration. Confetti falls from the top, swaying
+ * as it goes; fireworks rise from the bottom and burst into rings that fade. The pieces
+ * are laid out from the start time, so every frame of a celebration draws the same ones.
+ *
+ * @param c - The celebration.
+ * @param elapsed - How long it has been playing.
+ * @param width - The inner width of the view.
+ * @param height - The inner height of the view.
+ * @returns The view's lines, with the message in the middle.
+ */
+func celebrationLines(c *celebrationState, elapsed time.Duration, width, height int) []string {
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+	chars := make([][]rune, height)
+	colors := make([][]int, height)
+	for y := range chars {
+		chars[y] = []rune(strings.Repeat(" ", width))
+		colors[y] = make([]int, width)
+	}
+	put := func(x, y int, ch rune, color int) {
+		if x >= 0 && x < width && y >= 0 && y < height {
+			chars[y][x], colors[y][x] = ch, color
+		}
+	}
+
+	rng := rand.New(rand.NewSource(c.Start.UnixNano()))
+	t := elapsed.Seconds()
+	total := celebrationDuration.Seconds()
+	if c.Kind == "fireworks" {
+		for i := 0; i < max(3, width/12); i++ {
+			cx := rng.Intn(width)
+			cy := height/5 + rng.Intn(max(height*2/5, 1))
+			launch := rng.Float64() * total * 0.6
+			color := celebrationColors[rng.Intn(len(celebrationColors))]
+			const rise = 0.6
+			switch age := t - launch; {
+			case age < 0:
+			case age < rise:
+				put(cx, height-1-int(float64(height-1-cy)*age/rise), '|', 33)
+			case age < rise+1.4:
+				age -= rise
+				ch := '*'
+				if age > 0.7 {
+					ch = '.'
+				}
+				radius := age * float64(height) / 2
+				for a := 0; a < 12; a++ {
+					angle := float64(a) * math.Pi / 6
+					// Cells are about twice as tall as they are wide.
+					put(cx+int(math.Round(2*radius*math.Cos(angle))), cy+int(math.Round(radius*math.Sin(angle))), ch, color)
+				}
+			}
+		}
+	} else {
+		pieces := []rune("*+o.~'")
+		for i := 0; i < max(width*height/10, 8); i++ {
+			x0 := rng.Intn(width)
+			delay := rng.Float64() * total * 0.5
+			speed := float64(height) * (0.4 + rng.Float64()*0.6)
+			phase := rng.Float64() * 2 * math.Pi
+			ch, color := pieces[rng.Intn(len(pieces))], celebrationColors[rng.Intn(len(celebrationColors))]
+			if t < delay {
+				continue
+			}
+			sway := int(math.Round(1.5 * math.Sin(3*t+phase)))
+			put(x0+sway, int((t-delay)*speed), ch, color)
+		}
+	}
+
+	row := func(y, from, to int) string {
+		var b strings.Builder
+		for x := from; x < to; x++ {
+			if colors[y][x] != 0 {
+				fmt.Fprintf(&b, "\x1b[%dm%c\x1b[0m", colors[y][x], chars[y][x])
+			} else {
+				b.WriteRune(chars[y][x])
+			}
+		}
+		return b.String()
+	}
+	message := runewidth.Truncate(c.Message, width, "…")
+	msgWidth := render.TextWidth(message)
+	mid := height / 2
+	lines := make([]string, height)
+	for y := range lines {
+		if y == mid {
+			left := (width - msgWidth) / 2
+			lines[y] = row(y, 0, left) + "\x1b[1m" + message + "\x1b[0m" + row(y, left+msgWidth, width)
+			continue
+		}
+		lines[y] = row(y, 0, width)
+	}
+	return lines
+}
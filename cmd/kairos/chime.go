@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/workhours"
+)
+
+// lastChimeCheck is when checkChime last ran.
+var lastChimeCheck time.Time
+
+/**
+ * This function sounds the chime when the primary zone's wall clock reaches a multiple of
+ * the chime interval (e.g. every full hour for 60m), unless it is within the quiet hours.
+ * It is called on every UI tick.
+ *
+ * @param now - The current time.
+ */
+func checkChime(now time.Time) {
+	since := lastChimeCheck
+	lastChimeCheck = now
+	if since.IsZero() || settings.Chime == "" || len(timezones) == 0 {
+		return
+	}
+	primary := displayZones()[0]
+	loc, ok := locations[primary.Name]
+	if !ok {
+		return
+	}
+	local := now.In(loc)
+	// Chimes are aligned to the wall clock, so half-hour zones still chime on their own hour.
+	if local.Truncate(time.Minute).Equal(since.In(loc).Truncate(time.Minute)) {
+		return
+	}
+	interval, err := parseChimeInterval(settings.Chime)
+	if err != nil || (local.Hour()*60+local.Minute())%interval != 0 {
+		return
+	}
+	if settings.QuietHours != "" {
+		if h, err := workhours.Parse(settings.QuietHours); err == nil && h.CoversClock(local) {
+			return
+		}
+	}
+	if err := soundChime(local, primary.Name); err != nil {
+		logger.Warn("chime failed", "err", err)
+		showWarning("Chime failed: " + err.Error())
+	}
+}
+
+/**
+ * This function plays the chime: the chime_command setting if one is set, and otherwise
+ * the terminal bell. The command runs in the background through the shell with
+ * KAIROS_TIME (HH:MM) and KAIROS_ZONE set, so it never blocks the dashboard.
+ *
+ * @param local - The chime time in the primary zone.
+ * @param zone - The primary zone's name.
+ * @returns An error if the bell could not be written or the command could not be started.
+ */
+func soundChime(local time.Time, zone string) error {
+	if settings.ChimeCommand == "" {
+		_, err := os.Stdout.WriteString("\a")
+		return err
+	}
+	cmd := shellCommand(context.Background(), settings.ChimeCommand)
+	cmd.Env = append(os.Environ(), "KAIROS_TIME="+local.Format("15:04"), "KAIROS_ZONE="+zone)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		defer recoverWorker("chime command")
+		if err := cmd.Wait(); err != nil {
+			logger.Warn("chime command failed", "command", settings.ChimeCommand, "err", err)
+		}
+	}()
+	logger.Debug("chime command started", "command", settings.ChimeCommand, "time", local.Format("15:04"))
+	return nil
+}
+
+/**
+ * This function parses a chime interval such as "60m", "30m", or "2h".
+ *
+ * @param v - The interval.
+ * @returns The interval in minutes, or an error unless it is a whole number of minutes
+ * that divides a day evenly (so the chimes fall on the same times every day).
+ */
+func parseChimeInterval(v string) (int, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Minute || d%time.Minute != 0 || (24*time.Hour)%d != 0 {
+		return 0, fmt.Errorf("invalid chime interval '%s' (expected e.g. 15m, 30m, 60m, or off)", v)
+	}
+	return int(d / time.Minute), nil
+}
+
+// setChime validates and stores the chime interval ("off" disables the chime).
+func setChime(v string) error {
+	v = strings.ToLower(v)
+	if v == "off" || v == "none" || v == "" {
+		settings.Chime = ""
+		return nil
+	}
+	if v == "on" || v == "hourly" {
+		v = "60m"
+	}
+	if _, err := parseChimeInterval(v); err != nil {
+		return err
+	}
+	settings.Chime = v
+	return nil
+}
+
+// setQuietHours validates and stores the quiet hours ("off" disables them).
+func setQuietHours(v string) error {
+	if v == "off" || v == "none" || v == "" {
+		settings.QuietHours = ""
+		return nil
+	}
+	h, err := workhours.Parse(v)
+	if err != nil {
+		return err
+	}
+	settings.QuietHours = h.String()
+	return nil
+}
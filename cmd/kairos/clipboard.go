@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Formats accepted by the copy_format setting.
+var copyFormats = []string{"iso", "epoch", "slack"}
+
+/**
+ * This function formats a time for copying to the clipboard.
+ *
+ * @param now - The time, in the zone being copied.
+ * @param format - One of copyFormats; unknown values fall back to ISO 8601.
+ * @returns The formatted timestamp.
+ */
+func formatForCopy(now time.Time, format string) string {
+	switch format {
+	case "epoch":
+		return fmt.Sprintf("%d", now.Unix())
+	case "slack":
+		return fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|%s>", now.Unix(), now.Format("2006-01-02 15:04 MST"))
+	}
+	return now.Format(time.RFC3339)
+}
+
+/**
+ * This function copies text to the system clipboard using the OSC 52 escape sequence.
+ * The terminal emulator performs the copy, so this also works over SSH.
+ *
+ * @param text - The text to copy.
+ * @returns An error if the sequence could not be written.
+ */
+func copyToClipboard(text string) error {
+	return writeTerminalSequence("\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07")
+}
+
+// writeTerminalSequence writes an escape sequence for the terminal emulator itself; inside
+// tmux it is wrapped in a passthrough so it reaches the outer terminal.
+func writeTerminalSequence(seq string) error {
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := os.Stdout.WriteString(seq)
+	return err
+}
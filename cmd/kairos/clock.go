@@ -0,0 +1,1159 @@
+// Author Name: Gerald Z. Villorente
+// Author email: geraldvillorente@gmail.com
+// @2025-2026
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/iamstoick/kairos/config"
+	"github.com/iamstoick/kairos/stats"
+	"github.com/iamstoick/kairos/workhours"
+	"github.com/iamstoick/kairos/zones"
+	"github.com/jroimartin/gocui"
+)
+
+// The config file's schema lives in the config package; these aliases keep its names short here.
+type (
+	Config         = config.Config
+	TimezoneConfig = config.TimezoneConfig
+	EventConfig    = config.EventConfig
+	HoursState     = config.HoursState
+	Coordinates    = config.Coordinates
+	Settings       = config.Settings
+	Announcement   = config.Announcement
+	CommandTile    = config.CommandTile
+	TravelPlan     = config.TravelPlan
+)
+
+var (
+	locations map[string]*time.Location
+	timezones []TimezoneConfig
+	events    []EventConfig
+
+	// configPath is the config file given with --config; it takes precedence over $KAIROS_CONFIG.
+	configPath string
+
+	// configErr records why the config file could not be loaded; while it is set,
+	// saveConfig refuses to overwrite the file so that its contents are not lost.
+	configErr error
+)
+
+func main() {
+	// Commands are parsed and dispatched by execute (see commands.go); with no command
+	// it loads the config and launches the dashboard.
+	enableVirtualTerminal()
+	os.Exit(execute(os.Args[1:]))
+}
+
+/**
+ * This function initializes and runs the terminal-based GUI application using the gocui library.
+ * It sets up the GUI, loads timezone locations, defines the layout, keybindings, and starts the main event loop.
+ *
+ * @returns An error if there is nothing to show or the terminal UI fails (or panics).
+ */
+func runGUI() (err error) {
+	// The exit summary counts from here.
+	started := appClock.Now(time.UTC)
+	// A dashboard started while a daemon runs, or with --server, attaches to it and takes its zones (see daemon.go).
+	if ok, err := attachToDaemon(); !ok && serverAddr != "" {
+		return fmt.Errorf("cannot attach to the kairos server at %s: %v", serverAddr, err)
+	}
+	if len(timezones) == 0 {
+		// On the very first run, walk the user through picking their zones.
+		if _, err := os.Stat(getConfigPath()); os.IsNotExist(err) && runWizard() {
+			fmt.Printf("Saved %d timezone(s) to %s\n", len(timezones), getConfigPath())
+		} else {
+			return fmt.Errorf("No timezones configured. Use: kairos add \"Name\" \"Location\" (e.g. kairos add \"PHL\" \"Asia/Manila\")")
+		}
+	}
+
+	// A planned trip starts the dashboard in travel mode (see travel.go).
+	travelMode = settings.Travel != nil
+	// The terminal is asked for its background color and measured before the UI takes it over.
+	detectBackground()
+	detectTermCaps()
+	// Initialize the GUI
+	g, err := newGUI()
+	if err != nil {
+		logger.Error("gocui init failed", "term", os.Getenv("TERM"), "err", err)
+		return fmt.Errorf("failed to start the terminal UI: %v", err)
+	}
+	// Ensures that the GUI resources are properly released when the program exits,
+	// and that a panic in the layout restores the terminal instead of leaving it in raw mode.
+	defer closeGUI()
+	defer recoverGUI(&err)
+	defer quitOnSignals(g)()
+	// The workers started below stop when the dashboard exits, before the GUI is closed.
+	defer startWorkers()()
+	// Other processes can drive a dashboard that is not attached to a daemon through its control socket (see control.go).
+	if !attached {
+		defer startControlSocket(func(f func()) {
+			done := make(chan struct{})
+			g.Update(func(*gocui.Gui) error {
+				defer close(done)
+				f()
+				return nil
+			})
+			<-done
+		})()
+	}
+
+	// Load timezones into memory for quick access during updates.
+	loadLocations()
+	// The running work session, if any, is shown in the footer.
+	if err := loadSessions(); err != nil {
+		logger.Warn("sessions not loaded", "err", err)
+	}
+	// The dashboard reopens as it was left, and is saved again when it exits (see runstate.go).
+	restoreRuntimeState()
+	defer saveRuntimeState()
+
+	// The timers, unless the daemon runs them, and the dashboard's own checks follow the clock tick (see eventbus.go).
+	defer subscribeComponents()()
+	if !attached {
+		defer subscribeTimers()()
+	}
+	defer subscribeAll(map[busTopic][]busSubscriber{
+		topicTick: {
+			onTick("carousel", checkCarousel),
+			onTick("break reminder", checkBreakReminder),
+			onTick("celebrations", checkCelebrations),
+			onTick("interactions", checkInteractions),
+			onTick("system timezone", checkSystemZone),
+		},
+	})()
+
+	// Set the layout function that will be called to draw the UI.
+	g.SetManagerFunc(layout)
+	// Esc is read as its own key (it closes the session prompt) rather than as an Alt prefix.
+	g.InputEsc = true
+	// The terminal reports focus changes, which dim the dashboard and quiet its notifications (see termfocus.go).
+	enableFocusEvents()
+	// Set up keybindings for user interactions (swapping timezones and quitting the application).
+	if err := KeyBindings(g); err != nil {
+		return fmt.Errorf("failed to create keybindings: %v", err)
+	}
+
+	// With usage_stats on, the session and what it has turned on are counted for kairos stats.
+	countDashboardFeatures()
+	// Start the stats worker to update CPU and memory usage.
+	startStatsWorker()
+	// Start the NTP worker if the footer shows the clock offset.
+	startNTPWorker()
+	// Start the ticker worker if the footer shows stock or crypto prices.
+	startTickerWorker()
+	// Start the air worker if air quality badges are on.
+	startAirWorker()
+	// Start the on-call worker if any zone shows an on-call schedule.
+	startOnCallWorker()
+	// Start the Slack worker if a Slack token is configured.
+	startSlackWorker()
+	// Start the MQTT publisher if a broker is configured, unless the daemon publishes.
+	if !attached {
+		startMQTTWorker()
+	}
+	// Start the metric worker if any zone shows a sparkline.
+	startMetricWorker()
+	// Start the health worker if any zone has a health check URL.
+	startZoneHealthWorker()
+	// Start the carbon worker if any zone has a grid zone.
+	startCarbonWorker()
+	// Start a tile worker for each command tile.
+	startTileWorker()
+	// Start the flip worker if the primary clock flips on minute changes.
+	startFlipWorker(g)
+	// Start the heartbeat watchdog, which marks a dashboard that stopped redrawing as stale.
+	startHeartbeatWatchdog(g)
+	// Start the celebration worker, which redraws while confetti or fireworks play.
+	startCelebrationWorker(g)
+	// Run the Lua widget scripts, if there are any (see widgets.go).
+	startWidgetWorkers()
+
+	// Update the UI every second to reflect the current time.
+	goWorker("clock ticker", func(ctx context.Context) {
+		// Creates a ticker that sends a value on a channel every second, on the second,
+		// so the clocks change when the seconds do rather than up to a second later.
+		start := appClock.Now(time.UTC)
+		if !sleepCtx(ctx, start.Truncate(time.Second).Add(time.Second).Sub(start)) {
+			return
+		}
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+		for {
+			var tick time.Time
+			select {
+			case <-ctx.Done():
+				return
+			case tick = <-ticker.C:
+			}
+			// An attached dashboard leaves the timers to the daemon and follows its state.
+			var state map[string]interface{}
+			var stateErr error
+			if attached {
+				state, stateErr = callControl("state", nil)
+			}
+			// Calls the Update method of the GUI to trigger a redraw of the UI.
+			// Time-based notifications are checked on the GUI goroutine before the redraw.
+			g.Update(func(g *gocui.Gui) error {
+				// How late the update runs shows on the performance overlay (see perfhud.go).
+				recordTick(tick, time.Now())
+				// The tick's snapshot is what every view draws until the next one.
+				now := takeSnapshot().Now.In(localZone())
+				appState.ExpireNotification(time.Now())
+				if attached {
+					applyDaemonState(state, stateErr)
+				}
+				bus.Publish(topicTick, now)
+				return nil
+			})
+		}
+	})
+
+	// Start the main event loop for the GUI.
+	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
+		logger.Error("gocui main loop failed", "err", err)
+		return err
+	}
+	logger.Debug("dashboard closed")
+	// With exit_summary on, the recap is left in the terminal once the GUI has restored it (see exitsummary.go).
+	if settings.ExitSummary {
+		closeGUI()
+		printExitSummary(started, appClock.Now(time.UTC))
+	}
+	return nil
+}
+
+/**
+ * This function is responsible for setting up the layout of the terminal UI using the gocui library.
+ * The content of every view is produced by renderDashboard (see render.go), so the TUI and
+ * headless rendering share the same layout math; this function only applies it to gocui views.
+ *
+ * @param g - The gocui.Gui object representing the terminal UI.
+ * @returns An error if any issues occur during view creation or layout setup.
+ */
+func layout(g *gocui.Gui) error {
+	// Retrieves the current width (maxX) and height (maxY) of your terminal window.
+	maxX, maxY := g.Size()
+	// While the terminal is being resized, the views keep their previous layout (see layout.go).
+	if !resizeSettled(g, maxX, maxY) {
+		return nil
+	}
+	defer recordLayout(time.Now())
+	// The heartbeat watchdog marks the screen stale when this stops running (see heartbeat.go).
+	recordRedraw(maxX, maxY)
+	// Views are drawn from the snapshot of the last tick, not the clock (see snapshot.go).
+	now := currentSnapshot().Now
+	frames := renderDashboard(now, maxX, maxY)
+	// The performance overlay (Ctrl+D) is drawn over the corner of the dashboard.
+	if perfHUD {
+		frames = append(frames, perfHUDFrame(maxX, activeTheme(now.In(localZone()))))
+	}
+	// Views that are no longer rendered (e.g. when zen mode starts) are removed.
+	var stale []string
+	for _, v := range g.Views() {
+		keep := !tooSmall(maxX, maxY) &&
+			((trackPromptOpen && v.Name() == "track") || (paletteOpen && strings.HasPrefix(v.Name(), "palette")) ||
+				(browserOpen && strings.HasPrefix(v.Name(), "browser")) || (peekLocation != "" && strings.HasPrefix(v.Name(), "peek")))
+		for _, f := range frames {
+			keep = keep || f.Name == v.Name() || f.Name+titleViewSuffix == v.Name()
+		}
+		if !keep {
+			stale = append(stale, v.Name())
+		}
+	}
+	for _, name := range stale {
+		g.DeleteView(name)
+	}
+	// gocui draws the current view's border in SelFgColor, which marks the focused view.
+	g.Highlight = false
+	restack := false
+	for _, f := range frames {
+		// If the view already exists, it is reused; otherwise, a new view is created.
+		v, err := g.SetView(f.Name, f.X0, f.Y0, f.X1, f.Y1)
+		if err != nil {
+			if err != gocui.ErrUnknownView {
+				return err
+			}
+			// The help footer is drawn without a frame.
+			v.Frame = f.Frame
+			v.BgColor = gocui.ColorDefault
+		}
+		// Colors follow the active theme, which can change at night (see theme.go).
+		if f.Focused {
+			g.Highlight, g.SelFgColor = true, f.FrameColor
+			g.SetCurrentView(f.Name)
+		} else {
+			g.FgColor = f.FrameColor
+		}
+		v.FgColor = f.FgColor
+		created, err := drawTitle(g, v, f.Title, f.FrameColor)
+		if err != nil {
+			return err
+		}
+		restack = restack || created
+		// Wipes the previous frame so the new content can be drawn without leaving "ghost" characters behind.
+		v.Clear()
+		// Fprint is used instead of Fprintln to avoid an extra newline
+		// that might trigger a scroll-down in a 1-line view.
+		fmt.Fprint(v, consoleSafe(strings.Join(f.Lines, "\n")))
+	}
+	// A new title view goes on top of every view; each is moved back just above its own view.
+	if restack {
+		for _, f := range frames {
+			g.SetViewOnTop(f.Name)
+			g.SetViewOnTop(f.Name + titleViewSuffix)
+		}
+	}
+	// The prompts wait until the terminal is big enough again.
+	if perfHUD {
+		g.SetViewOnTop("perfhud")
+	}
+	if tooSmall(maxX, maxY) {
+		return nil
+	}
+	if err := layoutTrackPrompt(g); err != nil {
+		return err
+	}
+	if err := layoutPeek(g, now); err != nil {
+		return err
+	}
+	if err := layoutPalette(g); err != nil {
+		return err
+	}
+	return layoutBrowser(g, now)
+}
+
+/**
+ * This function returns the global lines shown only in the primary view, such as the epoch strip.
+ *
+ * @param now - The current time.
+ * @returns The lines to show; empty if none are enabled.
+ */
+func primaryStripLines(now time.Time) []string {
+	var lines []string
+	if settings.EpochStrip {
+		lines = append(lines, formatEpoch(now))
+	}
+	if settings.PrecisionStrip {
+		lines = append(lines, formatPrecisionStrip(now))
+	}
+	return lines
+}
+
+/**
+ * This function collects the optional detail lines configured for a timezone, in display order.
+ *
+ * @param tz - The timezone configuration.
+ * @param now - The current time in the timezone.
+ * @param width - The inner width of the view, which long notes scroll within.
+ * @returns The lines to show below the date; empty if no options are configured.
+ */
+func zoneDetailLines(tz TimezoneConfig, now time.Time, width int) []string {
+	var lines []string
+	if line := incidentLine(tz, now); line != "" {
+		lines = append(lines, line)
+	}
+	if line := noteLine(tz, now, width); line != "" {
+		lines = append(lines, line)
+	}
+	if line := onCallLine(tz); line != "" {
+		lines = append(lines, line)
+	}
+	if line := interactionLine(tz, now); line != "" {
+		lines = append(lines, line)
+	}
+	if line := slackLine(tz); line != "" {
+		lines = append(lines, line)
+	}
+	if line := airLine(tz); line != "" {
+		lines = append(lines, line)
+	}
+	if line := metricLine(tz); line != "" {
+		lines = append(lines, line)
+	}
+	if line := healthLine(tz); line != "" {
+		lines = append(lines, line)
+	}
+	if line := carbonLine(tz, now); line != "" {
+		lines = append(lines, line)
+	}
+	lines = append(lines, widgetTileLines(tz)...)
+	if tz.Calendar != "" {
+		if alt, err := alternateDate(tz.Calendar, now); err == nil {
+			lines = append(lines, alt)
+		}
+	}
+	if line := nextPrayerLine(tz, now); line != "" {
+		lines = append(lines, line)
+	}
+	return append(lines, representationLines(tz, now)...)
+}
+
+/**
+ * This function returns the glyph of a timezone's business-hours state: 🟢 within its
+ * working hours (9:00 AM to 5:00 PM by default, Monday through Friday), ⚫ outside them,
+ * or the glyph of the custom state it is in (see hoursstates.go).
+ *
+ * @param {time.Time} now - The current time in the timezone to check.
+ * @param {TimezoneConfig} tz - The timezone configuration holding the business hours.
+ * @return {string} - A visual indicator (🟢 for business hours, ⚫ for non-business hours, or a state's glyph).
+ */
+func getBusinessHoursIndicator(now time.Time, tz TimezoneConfig) string {
+	return zoneHoursState(now, tz).Glyph
+}
+
+/**
+ * This function renders a progress bar for the timezone, measuring either the calendar day
+ * (midnight to midnight) or, when the zone's "progress" option is "workday", its business hours.
+ *
+ * @param {time.Time} now - The current time in the timezone to check.
+ * @param {int} width - The width of the terminal window. This is used to calculate the size of the progress bar.
+ * @param {TimezoneConfig} tz - The timezone configuration holding the progress mode and business hours.
+ * @return {string} - The colored progress bar followed by the remaining time.
+ */
+func getDayProgressBar(now time.Time, width int, tz TimezoneConfig) string {
+	// 1. Calculate elapsed and remaining time
+	// This converts the current time into total seconds passed since midnight.
+	// Since there are exactly $86,400$ seconds in a day, dividing by this number gives a decimal percentage ($0.0$ to $1.0$).
+	secondsElapsed := float64(now.Hour()*3600 + now.Minute()*60 + now.Second())
+	totalSeconds := 86400.0
+	percent := secondsElapsed / totalSeconds
+
+	// In workday mode the bar spans the business hours window instead.
+	var timeRemaining string
+	if tz.Progress == "workday" {
+		// With a lunch break or split shift, the bar runs from the first start to the last end.
+		percent, timeRemaining = workdayProgress(now, zones.BusinessHours(tz).Span())
+	} else {
+		// Calculate remaining time in hours and minutes for the time remaining display.
+		// It changes once a minute, so the text is reused in between.
+		timeRemaining = dayRemainingLabel(int(totalSeconds-secondsElapsed) / 60)
+	}
+
+	// 2. Dynamic Color Logic (see dayPhaseColor)
+	color := dayPhaseColor(now)
+
+	// 3. Construct the final string, leaving room for the countdown text after the bar.
+	return renderProgressBar(percent, timeRemaining, width, color)
+}
+
+// dayRemainingLabels caches the " 10h 50m left" text of the day progress bar by minutes left.
+var dayRemainingLabels [24*60 + 1]atomic.Pointer[string]
+
+// dayRemainingLabel returns the day progress bar's text for the minutes left in the day.
+func dayRemainingLabel(minutes int) string {
+	if minutes < 0 || minutes >= len(dayRemainingLabels) {
+		return fmt.Sprintf(" %dh %dm left", minutes/60, minutes%60)
+	}
+	if label := dayRemainingLabels[minutes].Load(); label != nil {
+		return *label
+	}
+	label := fmt.Sprintf(" %dh %dm left", minutes/60, minutes%60)
+	dayRemainingLabels[minutes].Store(&label)
+	return label
+}
+
+/**
+ * This function picks the color of the part of the day, as shown by the progress bar and,
+ * with the digit_color setting, the clock digits.
+ *
+ * @param now - The current time in the timezone.
+ * @returns The ANSI color code.
+ */
+func dayPhaseColor(now time.Time) string {
+	// Green: The default color for morning and daytime. Active during standard
+	// business hours (9:00 AM to 5:00 PM).
+	color := "\x1b[32m"
+	// Yellow: Triggered between 5:00 PM and 9:00 PM, signaling the end of the day.
+	if now.Hour() >= 17 && now.Hour() < 21 {
+		color = "\x1b[33m"
+	}
+	// Red: Triggered from 9:00 PM until 5:00 AM, indicating late-night hours.
+	if now.Hour() >= 21 || now.Hour() < 5 {
+		color = "\x1b[31m"
+	}
+	return color
+}
+
+/**
+ * This function measures progress through the current business-hours window.
+ * Before the window opens the bar is empty and counts down to the start; once it has
+ * closed (or on a weekend) the bar is full.
+ *
+ * @param now - The current time in the timezone.
+ * @param b - The timezone's business hours.
+ * @returns The fraction elapsed (0.0 to 1.0) and the text shown after the bar.
+ */
+func workdayProgress(now time.Time, b workhours.Hours) (float64, string) {
+	start, end := b.Window(now)
+	if now.Before(end) && !now.Before(start) {
+		elapsed := now.Sub(start)
+		remaining := end.Sub(now)
+		percent := float64(elapsed) / float64(end.Sub(start))
+		left := int(remaining.Minutes())
+		return percent, fmt.Sprintf(" %dh %dm left (%d%%)", left/60, left%60, int((1-percent)*100))
+	}
+	// The next window starts later today; show a countdown to it.
+	next := b.NextOpen(now)
+	if next.YearDay() == now.YearDay() {
+		wait := int(next.Sub(now).Minutes())
+		return 0, fmt.Sprintf(" starts in %dh %dm", wait/60, wait%60)
+	}
+	return 1, " workday over"
+}
+
+/**
+ * This function returns the designator suffix for view titles in military mode, e.g. " (I India)".
+ *
+ * @param now - The current time in the view's timezone.
+ * @returns The suffix, or an empty string outside military mode or for zones without a designator.
+ */
+func designatorTitle(now time.Time) string {
+	if !settings.Military {
+		return ""
+	}
+	if letter, name, ok := militaryDesignator(now); ok {
+		return fmt.Sprintf(" (%s %s)", letter, name)
+	}
+	return ""
+}
+
+/**
+ * This function returns a sun or moon icon based on the current time.
+ * @param now - The current time.
+ * @returns The sun or moon icon as a string.
+ */
+func getDayNightIcon(now time.Time) string {
+	if now.Hour() >= 6 && now.Hour() < 18 {
+		return "🌞"
+	}
+	return "🌙"
+}
+
+/**
+ * This function displays a notification message for 3 seconds (or notification_duration).
+ * @param msg - The message to display.
+ */
+func showNotification(msg string) {
+	showNotificationFor(msg, notificationDuration())
+}
+
+/**
+ * This function displays a notification message for the given duration, once the
+ * notifications before it have been shown. Warnings and alerts have levels of their own
+ * (see notify.go).
+ * @param msg - The message to display.
+ * @param d - How long the message stays in the footer.
+ */
+func showNotificationFor(msg string, d time.Duration) {
+	notify(levelInfo, msg, d)
+}
+
+// headlessCPUInterval is how long a headless frame (kairos render or snapshot) measures CPU usage over.
+const headlessCPUInterval = 500 * time.Millisecond
+
+/**
+ * This function starts a worker goroutine that periodically updates the CPU and memory usage statistics.
+ * The worker runs every 2 seconds and stores them in appState with the latest statistics.
+ * When CPU usage cannot be read (e.g. gopsutil does not support the platform), the footer
+ * shows it as unavailable and the worker retries with exponential backoff (see stats.Backoff).
+ */
+func startStatsWorker() {
+	// Initialize CPU usage to avoid showing "0.0%" on the first run
+	bus.Publish(topicStatsUpdated, statsUpdate{"cpu", "CPU: Calculating..."})
+	bus.Publish(topicStatsUpdated, statsUpdate{"mem", "MEM: Calculating..."})
+	goWorker("stats worker", func(ctx context.Context) {
+		ticker := time.NewTicker(stats.Interval)
+		defer ticker.Stop()
+		// failures counts the CPU reads that failed in a row, and retryAt is when to try again.
+		failures := 0
+		var retryAt time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				// Memory usage comes from the runtime, so it stays current while CPU usage is backing off.
+				sampleMemory()
+				if now.Before(retryAt) {
+					continue
+				}
+				if err := sampleCPU(0); err != nil {
+					failures++
+					backoff := stats.Backoff(failures)
+					retryAt = now.Add(backoff)
+					// A persistent failure is logged once, and then only at debug level.
+					if failures == 1 {
+						logger.Warn("reading CPU usage failed", "err", err, "retry", backoff)
+					} else {
+						logger.Debug("reading CPU usage failed", "err", err, "failures", failures, "retry", backoff)
+					}
+				} else if failures > 0 {
+					logger.Info("reading CPU usage recovered", "failures", failures)
+					failures, retryAt = 0, time.Time{}
+				}
+			}
+		}
+	})
+}
+
+// sampleStats reads the memory usage, and the CPU usage over headlessCPUInterval, for a headless frame; it returns an error if the CPU usage cannot be read.
+func sampleStats() error {
+	sampleMemory()
+	return sampleCPU(headlessCPUInterval)
+}
+
+/**
+ * This function reads the CPU usage (see stats.CPU) and publishes it for the footer.
+ *
+ * @param interval - How long to measure over, or 0 for the time since the previous call.
+ * @returns An error if the CPU usage cannot be read, in which case the footer shows it as unavailable.
+ */
+func sampleCPU(interval time.Duration) error {
+	usage, err := stats.CPU(interval)
+	if err != nil {
+		bus.Publish(topicStatsUpdated, statsUpdate{"cpu", "CPU: \x1b[33munavailable\x1b[0m"})
+		return err
+	}
+	// Set the color to green by default.
+	color := "\x1b[32m"
+	// If CPU usage exceeds 50%, change the color to yellow to indicate moderate usage.
+	if usage > 50 {
+		color = "\x1b[33m"
+	}
+	// If CPU usage exceeds 80%, change the color to red to indicate high usage.
+	if usage > 80 {
+		color = "\x1b[31m"
+	}
+	bus.Publish(topicStatsUpdated, statsUpdate{"cpu", fmt.Sprintf("CPU: %s%.1f%%\x1b[0m", color, usage)})
+	return nil
+}
+
+// sampleMemory reads the memory usage and publishes it for the footer.
+func sampleMemory() {
+	alloc, usagePercent := stats.Memory()
+	// Set the color to green by default.
+	color := "\x1b[32m"
+	// If memory usage exceeds 50%, change the color to yellow to indicate moderate usage.
+	if usagePercent > 50 {
+		color = "\x1b[33m"
+	}
+	bus.Publish(topicStatsUpdated, statsUpdate{"mem", fmt.Sprintf("MEM: %s%dMB\x1b[0m", color, alloc/1024/1024)})
+}
+
+/**
+ * This function sets up keybindings for user interactions within the terminal UI.
+ * It allows users to swap the primary timezone with any of the additional timezones by pressing keys 1-6.
+ * It also binds Ctrl+C to quit the application gracefully.
+ *
+ * @param g - The gocui.Gui object representing the terminal UI.
+ * @returns An error if any issues occur during keybinding setup.
+ */
+func KeyBindings(g *gocui.Gui) error {
+	// A locked kiosk has no keys at all; it is stopped with SIGTERM (see quitOnSignals).
+	if kioskLock {
+		return nil
+	}
+	// Binds the Ctrl+C key combination to a function that quits the application.
+	g.SetKeybinding("", gocui.KeyCtrlC, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error { return gocui.ErrQuit })
+	// Kiosk mode is read-only: quitting is the only key.
+	if kioskMode {
+		return nil
+	}
+	// The single-key shortcuts depend on the input mode (see modes.go).
+	keys := keymap{}
+	// Binds "z" to toggle zen (presentation) mode, a single giant clock for screen sharing.
+	keys.bind(modeNormal, 'z', func(g *gocui.Gui) error {
+		zenMode = !zenMode
+		forwardToDaemon("zen", map[string]string{"state": formatSwitch(zenMode)})
+		return nil
+	})
+	// Binds "c" to show or hide the full-screen countdown to the next event (see countdown.go).
+	keys.bind(modeNormal, 'c', func(g *gocui.Gui) error {
+		countdownMode = !countdownMode
+		return nil
+	})
+	// Binds "j" to show or hide the home and destination of a planned trip (see travel.go).
+	keys.bind(modeNormal, 'j', func(g *gocui.Gui) error {
+		if settings.Travel == nil {
+			showNotification("No trip planned; start one with kairos travel start \"Home\" \"Destination\"")
+			return nil
+		}
+		travelMode = !travelMode
+		return nil
+	})
+	// Binds "b" to snooze the break reminder.
+	keys.bind(modeNormal, 'b', func(g *gocui.Gui) error {
+		snoozeBreak()
+		return nil
+	})
+	// Binds "t" to start or stop tracking a work session (see tracker.go).
+	keys.bind(modeNormal, 't', func(g *gocui.Gui) error {
+		toggleSession(g)
+		return nil
+	})
+	// Binds "f" to hide or show the footer, and Shift+letter keys to toggle its widgets (see footer.go).
+	keys.bind(modeNormal, 'f', func(g *gocui.Gui) error {
+		toggleFooter()
+		return nil
+	})
+	for key, name := range footerWidgetKeys {
+		name := name
+		keys.bind(modeNormal, key, func(g *gocui.Gui) error {
+			toggleFooterWidget(name)
+			return nil
+		})
+	}
+	// "[" and "I" are bound only so that focus sequences reach the keymap, which takes them out (see termfocus.go).
+	keys.bind(modeNormal, '[', func(g *gocui.Gui) error { return nil })
+	keys.bind(modeNormal, 'I', func(g *gocui.Gui) error { return nil })
+	// Binds "h" to reveal or hide the hidden zones (see hidden.go).
+	keys.bind(modeNormal, 'h', func(g *gocui.Gui) error {
+		toggleHidden()
+		return nil
+	})
+	// Binds "m" to show or hide the world map (see worldmap.go).
+	keys.bind(modeNormal, 'm', func(g *gocui.Gui) error {
+		mapMode = !mapMode
+		return nil
+	})
+	// Binds "a" to show or hide the agenda of offset changes, holidays, and events (see agenda.go).
+	keys.bind(modeNormal, 'a', func(g *gocui.Gui) error {
+		agendaMode = !agendaMode
+		return nil
+	})
+	// Binds "o" to show or hide today's and tomorrow's holidays across the zones (see holidays.go).
+	keys.bind(modeNormal, 'o', func(g *gocui.Gui) error {
+		toggleHolidays()
+		return nil
+	})
+	// Binds "g" to show or hide the workweek heat map of business hours across the zones (see heatmap.go).
+	keys.bind(modeNormal, 'g', func(g *gocui.Gui) error {
+		heatmapMode = !heatmapMode
+		return nil
+	})
+	// Binds "i" to open the browser of every IANA zone, with a preview of the selected one (see zonebrowser.go).
+	keys.bind(modeNormal, 'i', func(g *gocui.Gui) error {
+		browserOpen = true
+		return nil
+	})
+	// Binds "p" to pause or resume the carousel of primary zones.
+	keys.bind(modeNormal, 'p', func(g *gocui.Gui) error {
+		toggleCarousel()
+		return nil
+	})
+	// Binds "w" to show the stopwatch, entering timer mode, where "w" (or Esc) hides it again
+	// and space, "l", and "r" run it (see stopwatch.go).
+	keys.bind(modeNormal, 'w', func(g *gocui.Gui) error {
+		stopwatchMode = true
+		return nil
+	})
+	keys.bind(modeTimer, 'w', func(g *gocui.Gui) error {
+		stopwatchMode = false
+		return nil
+	})
+	for _, key := range []interface{}{gocui.KeySpace, 'l', 'r'} {
+		ch := typedRune(key)
+		keys.bind(modeTimer, key, func(g *gocui.Gui) error {
+			stopwatchKey(ch)
+			return nil
+		})
+	}
+	// Binds "y" to copy the primary timezone's current time to the clipboard, or in timer mode the stopwatch laps.
+	keys.bind(modeNormal, 'y', func(g *gocui.Gui) error {
+		loc, ok := locations[displayZones()[0].Name]
+		if !ok {
+			return nil
+		}
+		text := formatForCopy(appClock.Now(loc), settings.CopyFormat)
+		if err := copyToClipboard(text); err != nil {
+			showWarning("Copy failed: " + err.Error())
+			return nil
+		}
+		showNotification("Copied " + text)
+		return nil
+	})
+	keys.bind(modeTimer, 'y', func(g *gocui.Gui) error {
+		if err := copyToClipboard(stopwatchClipboardText(appClock.Now(time.UTC))); err != nil {
+			showWarning("Copy failed: " + err.Error())
+			return nil
+		}
+		showNotification(fmt.Sprintf("Copied the stopwatch time and %d lap(s)", len(watch.Laps)))
+		return nil
+	})
+	// Binds "!" to declare an incident, entering incident mode, where "x" exports its timeline
+	// stub and "!" resolves it (see incident.go).
+	keys.bind(modeNormal, '!', func(g *gocui.Gui) error {
+		declareIncident(appClock.Now(time.UTC))
+		return nil
+	})
+	keys.bind(modeIncident, '!', func(g *gocui.Gui) error {
+		resolveIncident(appClock.Now(time.UTC))
+		return nil
+	})
+	keys.bind(modeIncident, 'x', func(g *gocui.Gui) error {
+		path, err := exportIncidentTimeline(appClock.Now(time.UTC), false)
+		if err != nil {
+			showWarning("Export failed: " + err.Error())
+			return nil
+		}
+		showNotification("Saved the incident timeline to " + path)
+		return nil
+	})
+	// Binds "v" to speak the focused zone's time aloud, or the primary zone's (see speech.go).
+	keys.bind(modeNormal, 'v', func(g *gocui.Gui) error {
+		speakFocusedTime()
+		return nil
+	})
+	// Binds "s" to save what the dashboard shows to a file, e.g. for a bug report (see screenshot.go).
+	keys.bind(modeNormal, 's', func(g *gocui.Gui) error {
+		saveDashboardSnapshot(g.Size())
+		return nil
+	})
+	for i := 1; i <= 6; i++ {
+		idx := i
+		// Binds the number key (1-6) to a function that swaps the primary timezone with the selected timezone.
+		keys.bind(modeNormal, rune('0'+i), func(g *gocui.Gui) error {
+			zones := displayZones()
+			if idx >= len(zones) {
+				return nil
+			}
+			// Zulu stays pinned at the top in military mode.
+			if settings.Military {
+				showNotification("Zulu is pinned in military mode")
+				return nil
+			}
+			promoteZone(zones[idx].Name)
+			touchZone(zones[idx].Name)
+			forwardToDaemon("swap", map[string]string{"zone": zones[idx].Name})
+			showNotification(fmt.Sprintf("Swapped %s with %s", zones[0].Name, zones[idx].Name))
+			return nil
+		})
+	}
+	if err := keys.install(g); err != nil {
+		return err
+	}
+	if err := focusBindings(g); err != nil {
+		return err
+	}
+	if err := paletteBindings(g); err != nil {
+		return err
+	}
+	if err := browserBindings(g); err != nil {
+		return err
+	}
+	if err := peekBindings(g); err != nil {
+		return err
+	}
+	if err := perfHUDBindings(g); err != nil {
+		return err
+	}
+	return trackPromptBindings(g)
+}
+
+/**
+ * Retrieves the path to the configuration file: the --config flag if given, then
+ * $KAIROS_CONFIG, and otherwise .kairos_config.json in the home directory (%USERPROFILE%
+ * on Windows). A leading "~" in the path is expanded. Separate paths allow independent
+ * setups (e.g. work and personal) and keeping the config in a synced repository.
+ *
+ * @returns The full path to the configuration file.
+ */
+func getConfigPath() string {
+	if configPath != "" {
+		return expandHome(configPath)
+	}
+	if env := os.Getenv("KAIROS_CONFIG"); env != "" {
+		return expandHome(env)
+	}
+	return filepath.Join(homeDir(), ".kairos_config.json")
+}
+
+// homeDir returns the user's home directory ($HOME, or %USERPROFILE% on Windows), or the
+// user config directory when neither is set, as for some service accounts.
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	dir, _ := os.UserConfigDir()
+	return dir
+}
+
+// expandHome replaces a leading "~" with the home directory, for shells such as cmd.exe and PowerShell that leave it to the program.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		return filepath.Join(homeDir(), path[1:])
+	}
+	return path
+}
+
+/**
+ * Saves the current timezones and events configuration to a JSON file in the user's home directory.
+ * The previous file is kept as a timestamped backup so that `kairos undo` can bring it back.
+ *
+ * @returns An error if the config could not be backed up or written, or if the file on
+ * disk could not be parsed when it was loaded (it is never overwritten in that case).
+ */
+func saveConfig() error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	return writeConfig()
+}
+
+// writeConfig is saveConfig without the read-only check, for unlocking a read-only config.
+func writeConfig() error {
+	if configErr != nil {
+		return fmt.Errorf("refusing to overwrite %s because it could not be loaded; fix it or run 'kairos restore' first", getConfigPath())
+	}
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := checkConfigUnchanged(); err != nil {
+		return err
+	}
+	// The zones are saved after every change to them, so the workers follow from here.
+	appState.PublishZones(timezones, locations)
+	if err := backupConfig(); err != nil {
+		return fmt.Errorf("failed to back up the config: %v", err)
+	}
+	stored, err := storedSettings(settings)
+	if err != nil {
+		return fmt.Errorf("failed to save the config: %v", err)
+	}
+	data, err := json.Marshal(Config{Version: config.CurrentVersion, Timezones: timezones, Events: events, Settings: stored})
+	if err != nil {
+		return fmt.Errorf("failed to encode the config: %v", err)
+	}
+	if err := writeFileAtomic(getConfigPath(), data, configFileMode()); err != nil {
+		return fmt.Errorf("failed to save the config: %v", err)
+	}
+	loadedConfigStamp = stampOf(data)
+	logger.Debug("config saved", "path", getConfigPath(), "timezones", len(timezones), "events", len(events))
+	return nil
+}
+
+/**
+ * Writes a file atomically: the data goes to a temporary file in the same directory,
+ * which is synced and then renamed over the target, so a crash or a full disk never
+ * leaves a half-written file behind.
+ *
+ * @param path - The file to write.
+ * @param data - The new contents.
+ * @param perm - The permissions of the file.
+ * @returns An error if any step fails; the original file is untouched in that case.
+ */
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// Removing the temporary file fails harmlessly once it has been renamed.
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+/**
+ * Loads the timezones configuration from a JSON file in the user's home directory.
+ * A missing file is not an error; it simply means nothing has been configured yet.
+ *
+ * @returns An error if the file exists but cannot be read, parsed, or validated.
+ */
+func loadConfig() error {
+	// Attempts to read the configuration file from the user's home directory.
+	data, err := os.ReadFile(getConfigPath())
+	if os.IsNotExist(err) {
+		loadedConfigStamp = ""
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %v", getConfigPath(), err)
+	}
+	// Saving checks the file against this, so that changes made meanwhile are not overwritten (see configlock.go).
+	loadedConfigStamp = stampOf(data)
+
+	cfg, version, err := config.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid config: %v", getConfigPath(), err)
+	}
+
+	if version < config.CurrentVersion {
+		logger.Info("config upgraded", "path", getConfigPath(), "from", version, "to", config.CurrentVersion)
+	}
+	timezones = cfg.Timezones
+	events = cfg.Events
+	settings = cfg.Settings
+	resolveSecrets()
+	logger.Debug("config loaded", "path", getConfigPath(), "version", version, "timezones", len(timezones), "events", len(events))
+	return nil
+}
+
+/**
+ * This function prints the command-line usage instructions for the Kairos application.
+ * It guides users on how to add, remove, and launch the timezone dashboard.
+ *
+ * @param w - Where to print them; see pageHelp for the terminal.
+ */
+func printHelp(w io.Writer) {
+	fmt.Fprintf(w, "\n\x1b[36m\x1b[1mKAIROS %s - World Clock Dashboard\x1b[0m\n", getBuildInfo().Version)
+	fmt.Fprintln(w, "A terminal-based timezone monitor and system health dashboard.")
+	fmt.Fprintln(w, "\n\x1b[1mUSAGE:\x1b[0m")
+	fmt.Fprintf(w, "  %-36s \x1b[90m# Launches the dashboard\x1b[0m\n", "kairos")
+	// The command list comes from the command table so it never drifts from what is accepted.
+	for _, c := range commands {
+		usage := strings.TrimSpace(c.Name + " " + c.Usage)
+		if len(c.Subcommands) > 0 {
+			var names []string
+			for _, sub := range c.Subcommands {
+				names = append(names, sub.Name)
+			}
+			usage = c.Name + " " + strings.Join(names, "|")
+		}
+		fmt.Fprintf(w, "  %-36s \x1b[90m# %s\x1b[0m\n", "kairos "+usage, c.Short)
+	}
+	fmt.Fprintln(w, "\n  Run 'kairos <command> --help' for a command's arguments and flags.")
+
+	fmt.Fprintln(w, "\n\x1b[1mGLOBAL FLAGS:\x1b[0m")
+	fmt.Fprintln(w, "  \x1b[33m--config [P]\x1b[0m  : Use the config file at P (default ~/.kairos_config.json, or $KAIROS_CONFIG)")
+	fmt.Fprintln(w, "  \x1b[33m--profile [N]\x1b[0m : Use the named profile ~/.kairos_config.N.json")
+	fmt.Fprintln(w, "  \x1b[33m--json\x1b[0m        : Machine-readable output (list, doctor)")
+	fmt.Fprintln(w, "  \x1b[33m--debug\x1b[0m       : Record debug details in the log file (~/.cache/kairos/kairos.log, or $KAIROS_LOG)")
+	fmt.Fprintln(w, "  \x1b[33m--version\x1b[0m     : Print the version and exit (kairos version for the build and tz database)")
+
+	fmt.Fprintln(w, "\n\x1b[1mDASHBOARD FLAGS:\x1b[0m (also accepted by render)")
+	fmt.Fprintln(w, "  \x1b[33m--kiosk\x1b[0m       : Read-only display: only Ctrl+C works and the footer hints are hidden")
+	fmt.Fprintln(w, "  \x1b[33m--kiosk-lock\x1b[0m  : Kiosk mode without Ctrl+C; stop it with SIGTERM")
+	fmt.Fprintln(w, "  \x1b[33m--scale [N]\x1b[0m   : Enlarge the clock digits N times (1-4) where they fit")
+	fmt.Fprintln(w, "  \x1b[33m--cycle [N]\x1b[0m   : Rotate the primary view through the zones every N seconds (p pauses)")
+	fmt.Fprintln(w, "  \x1b[33m--countdown [T]\x1b[0m : Open the full-screen countdown to event or time T (c closes)")
+	fmt.Fprintln(w, "  \x1b[33m--obs [F]\x1b[0m     : Write the countdown line to file F for OBS instead of the dashboard (see obs_format)")
+
+	fmt.Fprintln(w, "\n\x1b[1mADD ARGUMENTS:\x1b[0m")
+	fmt.Fprintln(w, "  \x1b[33m[N]\x1b[0m : Display Name (e.g., \"Manila\", \"NYC\")")
+	fmt.Fprintln(w, "  \x1b[33m[L]\x1b[0m : IANA Location (e.g., \"Asia/Manila\", \"America/New_York\") or UTC offset (e.g., \"+08:30\")")
+
+	fmt.Fprintln(w, "\n\x1b[1mOPTIONS (kairos set):\x1b[0m")
+	fmt.Fprintln(w, "  \x1b[33mcalendar\x1b[0m      : Second date line (hijri, hebrew, chinese, or none)")
+	fmt.Fprintln(w, "  \x1b[33mcoords\x1b[0m        : Latitude and longitude, e.g. \"21.42,39.83\"")
+	fmt.Fprintln(w, "  \x1b[33mprayer\x1b[0m        : Prayer-time method (mwl, isna, egypt, makkah, karachi, or none)")
+	fmt.Fprintln(w, "  \x1b[33masr\x1b[0m           : Asr calculation (standard or hanafi)")
+	fmt.Fprintln(w, "  \x1b[33mprayer_notify\x1b[0m : Notify when each prayer time begins (on or off)")
+	fmt.Fprintln(w, "  \x1b[33mhours\x1b[0m         : Business hours, e.g. \"09:00-17:00\" or \"09:00-12:00,13:00-18:00\" (Monday to Friday)")
+	fmt.Fprintln(w, "  \x1b[33mstates\x1b[0m        : Custom indicator states, e.g. \"core hours 10:00-15:00 🟢 green; overlap 15:00-17:00 🟡 yellow\"")
+	fmt.Fprintln(w, "  \x1b[33mfocus\x1b[0m         : No-meeting focus blocks the meeting grid avoids, e.g. \"09:00-11:00,14:00-15:00\" (Monday to Friday)")
+	fmt.Fprintln(w, "  \x1b[33mholidays\x1b[0m      : Days without business hours for 'kairos sla', e.g. \"2026-12-25,2026-12-26\"")
+	fmt.Fprintln(w, "  \x1b[33mawake\x1b[0m         : Humane hours for pinging, every day (default \"08:00-22:00\")")
+	fmt.Fprintln(w, "  \x1b[33mwake_notify\x1b[0m   : Notify when the zone wakes up or winds down (on or off)")
+	fmt.Fprintln(w, "  \x1b[33moncall\x1b[0m        : PagerDuty or Opsgenie schedule whose on-call people are shown (see 'kairos config')")
+	fmt.Fprintln(w, "  \x1b[33mmetric\x1b[0m        : Command or \"prom:QUERY\" drawn as a sparkline, e.g. \"prom:sum(rate(http_requests_total{region='eu'}[5m]))\"")
+	fmt.Fprintln(w, "  \x1b[33mhealth\x1b[0m        : Health check URL shown as an up/down badge, e.g. \"https://status.example.com/healthz\"")
+	fmt.Fprintln(w, "  \x1b[33mcarbon\x1b[0m        : Electricity grid whose carbon intensity and green hours are shown, e.g. GB or DE (see 'kairos green')")
+	fmt.Fprintln(w, "  \x1b[33mshift\x1b[0m         : The team covering the zone, with optional shift hours, e.g. \"EMEA 07:00-15:00\"")
+	fmt.Fprintln(w, "  \x1b[33mprogress\x1b[0m      : What the progress bar measures (day or workday)")
+	fmt.Fprintln(w, "  \x1b[33mbars\x1b[0m          : Extra progress bars, e.g. \"year,month,week\"")
+	fmt.Fprintln(w, "  \x1b[33mstyle\x1b[0m         : How the clock is drawn (digits, binary, or words)")
+	fmt.Fprintln(w, "  \x1b[33mnote\x1b[0m          : Free-text note shown in the details panel, e.g. \"office closed Fridays\"")
+	fmt.Fprintln(w, "  \x1b[33mnote_tile\x1b[0m     : Also show the note in the zone's view, scrolling if long (on or off)")
+	fmt.Fprintln(w, "  \x1b[33mhour_format\x1b[0m   : 12h or 24h for the zone's clock, overriding the hour_format setting (or none)")
+	fmt.Fprintln(w, "  \x1b[33mvoice\x1b[0m         : Voice the v key speaks the time in, e.g. \"Kyoko\" for say or \"ja\" for espeak (or none)")
+	fmt.Fprintln(w, "  \x1b[33mtimes\x1b[0m         : Alternate time lines, e.g. \"beats,decimal\" (beats, decimal, epoch, tai, sidereal, daylight, golden)")
+	fmt.Fprintln(w, "  \x1b[33msubtime\x1b[0m       : A smaller second time line under the clock (24h, 12h, utc, iso, or a time representation)")
+
+	fmt.Fprintln(w, "\n\x1b[1mEXAMPLES:\x1b[0m")
+	fmt.Fprintln(w, "  kairos add \"Tokyo\" \"Asia/Tokyo\"")
+	fmt.Fprintln(w, "  kairos add \"Ship\" \"+08:30\"")
+	fmt.Fprintln(w, "  kairos remove \"Tokyo\"")
+	fmt.Fprintln(w, "  kairos set \"Riyadh\" calendar hijri")
+	fmt.Fprintln(w, "  kairos set \"Riyadh\" coords 24.71,46.68")
+	fmt.Fprintln(w, "  kairos set \"Riyadh\" prayer makkah")
+	fmt.Fprintln(w, "  kairos set \"Berlin\" progress workday")
+	fmt.Fprintln(w, "  kairos set \"UTC\" times beats,decimal")
+	fmt.Fprintln(w, "  kairos set \"Tokyo\" style words")
+	fmt.Fprintln(w, "  kairos set \"LA\" subtime 24h")
+	fmt.Fprintln(w, "  kairos set \"Observatory\" times sidereal")
+	fmt.Fprintln(w, "  kairos diff \"NYC\" \"Tokyo\"")
+	fmt.Fprintln(w, "  kairos at \"1999-04-04 12:00\" UTC")
+	fmt.Fprintln(w, "  kairos transitions Europe/Dublin 2024")
+	fmt.Fprintln(w, "  kairos share \"2026-01-15 16:00\" UTC --format slack")
+	fmt.Fprintln(w, "  kairos event add \"Launch\" \"2026-03-01 09:00\" \"Tokyo\" 60 10m")
+	fmt.Fprintln(w, "  kairos ics \"Sync\" \"2026-01-15 16:00\" UTC 45 --out sync.ics")
+	fmt.Fprintln(w, "  kairos config set copy_format epoch")
+	fmt.Fprintln(w, "  kairos config set military on")
+	fmt.Fprintln(w, "  kairos parse 1767225600")
+	fmt.Fprintln(w, "  kairos explain 2025-06-30T23:59:60Z")
+	fmt.Fprintln(w, "  kairos explain \"backup_20250701_0930.tar.gz\"")
+
+	fmt.Fprintln(w, "\n\x1b[1mCONTROLS (Inside Dashboard):\x1b[0m")
+	fmt.Fprintln(w, "  • \x1b[32mKeys 1-6\x1b[0m : Swap secondary timezone with the primary (top) view.")
+	fmt.Fprintln(w, "  • \x1b[32my\x1b[0m        : Copy the primary timezone's time to the clipboard (see copy_format).")
+	fmt.Fprintln(w, "  • \x1b[31mCtrl+C\x1b[0m   : Quit the application.")
+	fmt.Fprintln(w)
+}
+
+/**
+ * This function displays a list of all currently configured timezones in a table format,
+ * or as JSON (--json) or CSV (--csv) with each zone's current state for scripts. It helps users verify their settings before launching the dashboard.
+ *
+ * @returns An error if the JSON output cannot be encoded.
+ */
+func printList() error {
+	if listCSV {
+		return printListCSV(os.Stdout, zones.Statuses(timezones, appClock.Now(time.UTC)))
+	}
+	if jsonOutput {
+		data, err := json.MarshalIndent(zones.Statuses(timezones, appClock.Now(time.UTC)), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(timezones) == 0 {
+		fmt.Println("\x1b[31mNo timezones configured.\x1b[0m Use 'kairos help' to see how to add some.")
+		return nil
+	}
+
+	fmt.Println("\n\x1b[36m\x1b[1mCONFIGURED TIMEZONES\x1b[0m")
+	fmt.Printf("%-5s %-15s %-25s\n", "ID", "NAME", "IANA LOCATION")
+	fmt.Println(strings.Repeat("-", 45))
+
+	for i, tz := range timezones {
+		label := fmt.Sprintf(" %d", i)
+		// Mark the Primary/Top timezone with a green [P] label for easy identification.
+		if i == 0 {
+			label = "\x1b[32m[P]  \x1b[0m"
+		}
+		hidden := ""
+		if tz.Hidden {
+			hidden = "\x1b[90m(hidden)\x1b[0m"
+		}
+		fmt.Printf("%-5s %-15s %-25s %s\n", label, tz.Name, tz.Location, hidden)
+	}
+	fmt.Println("\x1b[90m(P) = Primary Timezone (Top View)\x1b[0m")
+	return nil
+}
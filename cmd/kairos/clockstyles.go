@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// clockStyleNames are the values of the per-zone "style" option; digits is the default.
+var clockStyleNames = []string{"digits", "binary", "words"}
+
+// clockStyles draw a zone's clock in place of the digits, by the zone's style. Each returns
+// the lines of the clock for a view of the given width; renderZoneLines centers them.
+var clockStyles = map[string]func(now time.Time, width int) []string{
+	"binary": binaryClockLines,
+	"words":  wordClockLines,
+}
+
+/**
+ * This function draws a binary clock: one column of dots per digit of the 24-hour time
+ * (HH MM SS), with the bits 8, 4, 2, and 1 from the top, and the digits themselves below.
+ * Bits a digit can never have, such as 8 for the tens of the minutes, are left blank.
+ *
+ * @param now - The current time in the zone.
+ * @param width - The width of the view (the clock is 13 columns wide).
+ * @returns The five lines of the clock.
+ */
+func binaryClockLines(now time.Time, width int) []string {
+	digits := now.Format("150405")
+	// The largest value of each digit, e.g. 5 for the tens of the minutes.
+	highest := "295959"
+	lines := make([]string, 5)
+	for i := range digits {
+		sep := ""
+		if i > 0 {
+			sep = " "
+			if i%2 == 0 {
+				sep = "  "
+			}
+		}
+		d, max := int(digits[i]-'0'), int(highest[i]-'0')
+		for row, bit := range []int{8, 4, 2, 1} {
+			dot := " "
+			if bit <= max {
+				dot = "○"
+				if d&bit != 0 {
+					dot = "●"
+				}
+			}
+			lines[row] += sep + dot
+		}
+		lines[4] += sep + string(digits[i])
+	}
+	return lines
+}
+
+// hourWords are the hours of a 12-hour clock in words, from twelve.
+var hourWords = []string{"twelve", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten", "eleven"}
+
+// minuteWords are the minutes past or to the hour in words, by five minutes.
+var minuteWords = []string{"", "five", "ten", "quarter", "twenty", "twenty-five", "half"}
+
+/**
+ * This function tells the time in words to the nearest five minutes, like a word clock,
+ * e.g. "It is quarter past three", "It is twenty to four", or "It is noon", wrapped to the
+ * width of the view.
+ *
+ * @param now - The current time in the zone.
+ * @param width - The width of the view.
+ * @returns The lines of the sentence.
+ */
+func wordClockLines(now time.Time, width int) []string {
+	rounded := now.Add(150 * time.Second).Truncate(5 * time.Minute)
+	h, m := rounded.Hour(), rounded.Minute()/5
+	var sentence string
+	switch {
+	case m == 0 && h == 0:
+		sentence = "It is midnight"
+	case m == 0 && h == 12:
+		sentence = "It is noon"
+	case m == 0:
+		sentence = fmt.Sprintf("It is %s o'clock", hourWords[h%12])
+	case m <= 6:
+		sentence = fmt.Sprintf("It is %s past %s", minuteWords[m], hourWords[h%12])
+	default:
+		sentence = fmt.Sprintf("It is %s to %s", minuteWords[12-m], hourWords[(h+1)%12])
+	}
+
+	var lines []string
+	for _, word := range strings.Fields(sentence) {
+		if n := len(lines); n > 0 && len(lines[n-1])+1+len(word) <= width {
+			lines[n-1] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+	return lines
+}
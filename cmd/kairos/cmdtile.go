@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultTileInterval is how often a command tile runs unless it sets its own interval.
+	defaultTileInterval = time.Minute
+	// minTileInterval keeps a command tile from running its command back to back.
+	minTileInterval = 2 * time.Second
+	// tileTimeout bounds one run of a tile's command.
+	tileTimeout = 30 * time.Second
+	// maxTileLines is the most lines of output a tile keeps.
+	maxTileLines = 100
+)
+
+// tileOutput is what a tile's command last printed.
+type tileOutput struct {
+	Lines []string
+	// Err is why the last run failed, if it did; the output it printed is still shown.
+	Err string
+	At  time.Time
+}
+
+var (
+	// tileMu guards tileOutputs, which the tile workers write and the dashboard reads.
+	tileMu sync.Mutex
+	// tileOutputs maps each tile's command to its last output.
+	tileOutputs = map[string]tileOutput{}
+	// tileEvery is set by `kairos tile add --every`.
+	tileEvery string
+)
+
+// tileControlSequence matches the escape sequences a tile's output may not keep: everything but colors.
+var tileControlSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-ln-z]|\x1b[^\[]`)
+
+// tileCommand builds the `kairos tile` command group.
+func tileCommand() *command {
+	return &command{
+		Name:  "tile",
+		Short: "Shows a shell command's output in a grid tile next to the clocks, rerun on an interval",
+		Subcommands: []*command{
+			{Name: "add", Usage: `"Title" "command"`, MinArgs: 2, MaxArgs: 2,
+				Short: "Adds a tile, e.g. \"Deploy\" \"kubectl rollout status deploy/api\" --every 30s",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&tileEvery, "every", "1m", "How often the command runs, e.g. 30s or 5m")
+				},
+				Run: func(args []string) error { return addTile(args[0], args[1], tileEvery) }},
+			{Name: "list", Short: "Lists the tiles", Run: func(args []string) error { return listTiles() }},
+			{Name: "remove", Usage: "N", MinArgs: 1, MaxArgs: 1, Short: "Removes the tile numbered N in the list",
+				Run: func(args []string) error { return removeTile(args[0]) }},
+		},
+	}
+}
+
+// tileInterval returns how often a tile runs.
+func tileInterval(t CommandTile) time.Duration {
+	if d, err := time.ParseDuration(t.Every); err == nil {
+		return max(d, minTileInterval)
+	}
+	return defaultTileInterval
+}
+
+// parseTileEvery validates a tile's interval, returning it as stored ("" for a minute).
+func parseTileEvery(v string) (string, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(v))
+	if err != nil || d < minTileInterval {
+		return "", fmt.Errorf("invalid interval '%s' (expected a duration of at least %s, such as 30s or 5m)", v, minTileInterval)
+	}
+	if d == defaultTileInterval {
+		return "", nil
+	}
+	return d.String(), nil
+}
+
+// addTile handles `kairos tile add`.
+func addTile(title, command, every string) error {
+	t := CommandTile{Title: strings.TrimSpace(title), Command: strings.TrimSpace(command)}
+	if t.Title == "" || t.Command == "" {
+		return fmt.Errorf("a tile needs a title and a command")
+	}
+	var err error
+	if t.Every, err = parseTileEvery(every); err != nil {
+		return err
+	}
+	settings.Tiles = append(settings.Tiles, t)
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Added tile %d: %s, every %s. It takes a free slot in the grid after the zones.\n", len(settings.Tiles), t.Title, tileInterval(t))
+	return nil
+}
+
+// listTiles handles `kairos tile list`.
+func listTiles() error {
+	if len(settings.Tiles) == 0 {
+		fmt.Println("No tiles configured. Add one with kairos tile add \"Title\" \"command\".")
+		return nil
+	}
+	fmt.Println("\n\x1b[36m\x1b[1mTILES\x1b[0m")
+	fmt.Printf("%-3s %-20s %-8s %s\n", "#", "TITLE", "EVERY", "COMMAND")
+	fmt.Println(strings.Repeat("-", 70))
+	for i, t := range settings.Tiles {
+		fmt.Printf("%-3d %-20s %-8s %s\n", i+1, t.Title, tileInterval(t), t.Command)
+	}
+	fmt.Println()
+	return nil
+}
+
+// removeTile handles `kairos tile remove`.
+func removeTile(arg string) error {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(settings.Tiles) {
+		return fmt.Errorf("no tile %s (see kairos tile list)", arg)
+	}
+	t := settings.Tiles[n-1]
+	settings.Tiles = append(settings.Tiles[:n-1], settings.Tiles[n:]...)
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed the %s tile\n", t.Title)
+	return nil
+}
+
+// startTileWorker starts a worker for each command tile, which reruns its command on the tile's interval.
+func startTileWorker() {
+	primary := ""
+	if zones := displayZones(); len(zones) > 0 {
+		primary = zones[0].Name
+	}
+	for _, t := range settings.Tiles {
+		t := t
+		goWorker("tile worker", func(ctx context.Context) {
+			lastErr := ""
+			for {
+				out := runTileCommand(ctx, t, primary)
+				if out.Err != "" && out.Err != lastErr {
+					logger.Warn("tile command failed", "tile", t.Title, "err", out.Err)
+				}
+				lastErr = out.Err
+				tileMu.Lock()
+				tileOutputs[t.Command] = out
+				tileMu.Unlock()
+				if !sleepCtx(ctx, tileInterval(t)) {
+					return
+				}
+			}
+		})
+	}
+}
+
+/**
+ * This function runs a tile's command in the shell, with the primary zone as
+ * KAIROS_PRIMARY. Its output keeps its colors, but not the escape sequences that move
+ * the cursor or clear the screen, and tabs are expanded.
+ *
+ * @param ctx - Stops the command when the dashboard exits.
+ * @param t - The tile.
+ * @param primary - The primary zone's name.
+ * @returns The output, with the error if the command failed or ran past tileTimeout.
+ */
+func runTileCommand(ctx context.Context, t CommandTile, primary string) tileOutput {
+	ctx, cancel := context.WithTimeout(ctx, tileTimeout)
+	defer cancel()
+	cmd := shellCommand(ctx, t.Command)
+	cmd.Env = append(os.Environ(), "KAIROS_PRIMARY="+primary)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	out := tileOutput{At: appClock.Now(time.UTC)}
+	text := strings.ReplaceAll(tileControlSequence.ReplaceAllString(string(stdout), ""), "\t", "    ")
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if len(out.Lines) == maxTileLines {
+			break
+		}
+		out.Lines = append(out.Lines, strings.TrimRight(line, " \r"))
+	}
+	if err != nil {
+		// The first line of stderr usually says why.
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		out.Err = err.Error()
+	}
+	return out
+}
+
+/**
+ * This function renders a command tile: the command's last output, cut to the tile's
+ * height, under the error if its last run failed. The title says when it last ran.
+ *
+ * @param t - The tile.
+ * @param height - The inner height of the view.
+ * @returns The view's title and lines.
+ */
+func renderTileLines(t CommandTile, height int) (string, []string) {
+	tileMu.Lock()
+	out, ok := tileOutputs[t.Command]
+	tileMu.Unlock()
+	if !ok {
+		return fmt.Sprintf(" %s ", t.Title), []string{"\x1b[33mrunning…\x1b[0m"}
+	}
+	title := fmt.Sprintf(" %s · %s ", t.Title, out.At.In(localZone()).Format("15:04:05"))
+	var lines []string
+	if out.Err != "" {
+		lines = append(lines, "\x1b[31m✗ "+out.Err+"\x1b[0m")
+	}
+	lines = append(lines, out.Lines...)
+	return title, lines[:min(len(lines), max(height, 0))]
+}
+
+/**
+ * This function lints the command tiles for kairos config check: tiles without a
+ * command, and intervals that do not parse.
+ *
+ * @returns A check for each problem.
+ */
+func checkTileConfig() []doctorCheck {
+	var checks []doctorCheck
+	for i, t := range settings.Tiles {
+		name := fmt.Sprintf("tile %d", i+1)
+		fix := fmt.Sprintf("; remove it with kairos tile remove %d and add it again", i+1)
+		if strings.TrimSpace(t.Command) == "" {
+			checks = append(checks, doctorCheck{"tiles", name, checkFail, "the tile has no command" + fix})
+		}
+		if _, err := parseTileEvery(defaultString(t.Every, defaultTileInterval.String())); err != nil {
+			checks = append(checks, doctorCheck{"tiles", name, checkWarn, err.Error() + ", so it runs every minute" + fix})
+		}
+	}
+	return checks
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Exit codes returned by the kairos binary.
+const (
+	exitOK    = 0
+	exitError = 1 // The command ran but failed (bad config, unknown zone, I/O error...).
+	exitUsage = 2 // The command line itself was invalid.
+)
+
+// command is a kairos subcommand, in the style of cobra: a name, a usage line, optional
+// flags, and either a Run function or a list of nested subcommands.
+type command struct {
+	// Name is the word that selects the command, e.g. "add".
+	Name string
+	// Usage lists the positional arguments, e.g. `"Name" "Location"`.
+	Usage string
+	// Short is the one-line description shown in `kairos help`.
+	Short string
+	// MinArgs and MaxArgs bound the number of positional arguments; MaxArgs -1 means unlimited.
+	MinArgs, MaxArgs int
+	// Flags registers the command's own flags; the global flags are always added.
+	Flags func(fs *flag.FlagSet)
+	// Run executes the command with its positional arguments.
+	Run func(args []string) error
+	// Subcommands are dispatched on the first argument when Run is nil.
+	Subcommands []*command
+
+	parent *command
+}
+
+// usageError reports an invalid command line; it makes kairos exit with exitUsage.
+type usageError struct {
+	cmd *command
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+// usageErrorf returns a usageError for cmd with a formatted message.
+func usageErrorf(cmd *command, format string, a ...interface{}) error {
+	return &usageError{cmd: cmd, msg: fmt.Sprintf(format, a...)}
+}
+
+var (
+	// jsonOutput is set by the global --json flag for commands with machine-readable output.
+	jsonOutput bool
+	// configProfile is set by the global --profile flag and selects a named config file.
+	configProfile string
+)
+
+// commands lists every top-level command, in the order shown by `kairos help`.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{Name: "help", Usage: "[command]", Short: "Shows this help menu, or help for one command", MaxArgs: 1, Run: runHelp},
+		{Name: "list", Short: "Lists all saved timezones (--json or --csv for machine-readable output)",
+			Flags: func(fs *flag.FlagSet) {
+				fs.BoolVar(&listCSV, "csv", false, "Print CSV with each zone's local time, UTC offset, and business-hours state")
+			},
+			Run: func(args []string) error { return printList() }},
+		{Name: "add", Usage: `"Name" "Location"`, Short: "Adds a new timezone", MinArgs: 2, MaxArgs: 2,
+			Flags: func(fs *flag.FlagSet) {
+				fs.BoolVar(&addForce, "force", false, "Add the zone even if its location is already configured")
+			},
+			Run: func(args []string) error { return addZone(args[0], args[1]) }},
+		{Name: "remove", Usage: `"Name"`, Short: "Removes a timezone", MinArgs: 1, MaxArgs: 1,
+			Run: func(args []string) error { return removeZone(args[0]) }},
+		{Name: "set-primary", Usage: `"Name"`, Short: "Moves a timezone to the primary (top) view", MinArgs: 1, MaxArgs: 1,
+			Run: func(args []string) error { return setPrimary(args[0]) }},
+		{Name: "swap", Usage: `"Name" "Name"`, Short: "Swaps the positions of two timezones", MinArgs: 2, MaxArgs: 2,
+			Run: func(args []string) error { return swapZones(args[0], args[1]) }},
+		{Name: "hide", Usage: `"Name"`, Short: "Hides a timezone from the dashboard but keeps it for commands", MinArgs: 1, MaxArgs: 1,
+			Run: func(args []string) error { return setZoneHidden(args[0], true) }},
+		{Name: "unhide", Usage: `"Name"`, Short: "Shows a hidden timezone on the dashboard again", MinArgs: 1, MaxArgs: 1,
+			Run: func(args []string) error { return setZoneHidden(args[0], false) }},
+		{Name: "set", Usage: `"Name" option value`, Short: "Sets a per-timezone option", MinArgs: 3, MaxArgs: 3,
+			Run: func(args []string) error {
+				if err := setZoneOption(args[0], args[1], args[2]); err != nil {
+					return err
+				}
+				if err := saveConfig(); err != nil {
+					return err
+				}
+				fmt.Printf("Updated %s successfully!\n", args[0])
+				return nil
+			}},
+		{Name: "explain", Usage: `"Timestamp"`, Short: "Explains a timestamp across timezones", MinArgs: 1, MaxArgs: 1,
+			Run: func(args []string) error { return explainTimestamp(args[0]) }},
+		{Name: "parse", Usage: `"Timestamp"`, Short: "Shows an epoch/ISO/RFC 2822 time in every timezone", MinArgs: 1, MaxArgs: 1,
+			Run: func(args []string) error { return printParse(args[0]) }},
+		{Name: "diff", Usage: `"Name" "Name"`, Short: "Shows the offset between two timezones", MinArgs: 2, MaxArgs: 2,
+			Run: func(args []string) error { return printDiff(args[0], args[1]) }},
+		{Name: "at", Usage: `"Time" "Zone"`, Short: "Shows the offset a zone had at a past or future time", MinArgs: 2, MaxArgs: 2,
+			Run: func(args []string) error { return printAt(args[0], args[1]) }},
+		{Name: "transitions", Usage: `"Zone" [year]`, Short: "Lists a zone's offset changes in a year", MinArgs: 1, MaxArgs: 2,
+			Run: func(args []string) error {
+				year := ""
+				if len(args) == 2 {
+					year = args[1]
+				}
+				return printTransitions(args[0], year)
+			}},
+		dateDiffCommand("until", "Counts the days, weeks, and business days from today to a date in a zone (--zone)", false),
+		dateDiffCommand("since", "Counts the days, weeks, and business days from a date to today in a zone (--zone)", true),
+		sortCommand(),
+		slaCommand(),
+		fairnessCommand(),
+		{Name: "table", Usage: "[hours]", Short: "Prints an hour-by-hour meeting grid", MaxArgs: 1, Run: printTable},
+		shareCommand(),
+		availabilityCommand(),
+		renderCommand(),
+		snapshotCommand(),
+		touchCommand(),
+		eventCommand(),
+		icsCommand(),
+		agendaCommand(),
+		nowCommand(),
+		watchCommand(),
+		trackCommand(),
+		slackCommand(),
+		announceCommand(),
+		greenCommand(),
+		travelCommand(),
+		celebrateCommand(),
+		tileCommand(),
+		gcalCommand(),
+		syncCommand(),
+		ctlCommand(),
+		daemonCommand(),
+		{Name: "widgets", Short: "Runs the widget scripts and plugins once and prints what they show",
+			Run: func(args []string) error { return runWidgetsCommand() }},
+		configCommand(),
+		{Name: "doctor", Short: "Checks tzdata, zones, terminal, and config (--json for machine-readable output)",
+			Run: func(args []string) error { return runDoctor() }},
+		statsCommand(),
+		tzupdateCommand(),
+		manCommand(),
+		{Name: "version", Short: "Shows the version, build, and tz database (--json for machine-readable output)",
+			Run: func(args []string) error { return runVersion() }},
+		{Name: "edit", Short: "Opens the config in $EDITOR and saves it only if it is valid",
+			Run: func(args []string) error { return runEdit() }},
+		{Name: "undo", Short: "Reverts the last config change", Run: func(args []string) error { return runUndo() }},
+		{Name: "restore", Usage: "[backup]", Short: "Lists config backups or restores one", MaxArgs: 1, Run: runRestore},
+	}
+	for _, c := range commands {
+		c.setParents()
+	}
+}
+
+// setParents links nested subcommands to their parent so their full path can be printed.
+func (c *command) setParents() {
+	for _, sub := range c.Subcommands {
+		sub.parent = c
+		sub.setParents()
+	}
+}
+
+// path returns the full command path, e.g. "kairos event add".
+func (c *command) path() string {
+	if c.parent == nil {
+		return "kairos " + c.Name
+	}
+	return c.parent.path() + " " + c.Name
+}
+
+// findCommand looks up a command by name in a list.
+func findCommand(list []*command, name string) *command {
+	for _, c := range list {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+/**
+ * This function runs the kairos command line and returns the process exit code.
+ * Without a command it launches the dashboard; otherwise it dispatches to the named
+ * command, parsing its flags (which may appear anywhere among the arguments).
+ *
+ * @param args - The arguments after the program name.
+ * @returns exitOK, exitError, or exitUsage.
+ */
+func execute(args []string) int {
+	defer closeLogging()
+	err := dispatch(args)
+	flushUsageStats()
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		logger.Error("command failed", "args", args, "err", err)
+	}
+	var uerr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &uerr):
+		fmt.Fprintf(os.Stderr, "\x1b[31m%v\x1b[0m\n", err)
+		if uerr.cmd != nil {
+			fmt.Fprintf(os.Stderr, "Run '%s --help' for usage.\n", uerr.cmd.path())
+		} else {
+			fmt.Fprintln(os.Stderr, "Type 'kairos help' for usage instructions.")
+		}
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "\x1b[31m%v\x1b[0m\n", err)
+		return exitError
+	}
+}
+
+// dispatch selects the command named by the arguments and runs it.
+func dispatch(args []string) error {
+	// Global flags may come before the command name; without a command the dashboard starts.
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		fs := flag.NewFlagSet("kairos", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		registerGlobalFlags(fs)
+		registerDashboardFlags(fs)
+		fs.BoolVar(&showVersion, "version", false, "Print the version and exit")
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				pageHelp(printHelp)
+				return err
+			}
+			return usageErrorf(nil, "%v", err)
+		}
+		args = fs.Args()
+		if showVersion {
+			fmt.Println(versionLine(getBuildInfo()))
+			return nil
+		}
+	}
+	if len(args) == 0 {
+		if err := applyProfile(); err != nil {
+			return err
+		}
+		if err := applyDashboardFlags(); err != nil {
+			return err
+		}
+		loadConfigOrWarn()
+		// The OBS emit mode writes the countdown to a file instead (see countdown.go).
+		if obsPath != "" {
+			return runOBS(obsPath)
+		}
+		// Without a terminal (a container, a service, a pipe) the dashboard cannot start,
+		// so the zones are logged as a plain table instead, as with `kairos watch --plain`.
+		if !stdoutIsTerminal() {
+			logger.Info("stdout is not a terminal; printing the zone table instead of the dashboard")
+			return runWatch(defaultWatchInterval, true)
+		}
+		return runGUI()
+	}
+
+	cmd := findCommand(commands, args[0])
+	if cmd == nil {
+		return usageErrorf(nil, "Unknown command: %s", args[0])
+	}
+	args = args[1:]
+	for cmd.Run == nil {
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+			pageHelp(func(w io.Writer) { printCommandHelp(w, cmd) })
+			if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+				return flag.ErrHelp
+			}
+			return usageErrorf(cmd, "%s needs a subcommand", cmd.path())
+		}
+		sub := findCommand(cmd.Subcommands, args[0])
+		if sub == nil {
+			return usageErrorf(cmd, "Unknown subcommand: %s %s", cmd.path(), args[0])
+		}
+		cmd, args = sub, args[1:]
+	}
+
+	rest, err := parseFlags(cmd, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) < cmd.MinArgs || (cmd.MaxArgs >= 0 && len(rest) > cmd.MaxArgs) {
+		return usageErrorf(cmd, "Usage: %s %s", cmd.path(), cmd.Usage)
+	}
+	loadConfigOrWarn()
+	countCommand(cmd)
+	return runWithConfigRetry(cmd, rest)
+}
+
+/**
+ * This function parses a command's flags together with the global flags.
+ * Unlike flag.Parse, flags may follow positional arguments (e.g. `kairos share T Z --format slack`);
+ * everything after "--" is taken literally.
+ *
+ * @param cmd - The command being run.
+ * @param args - Its arguments.
+ * @returns The positional arguments, flag.ErrHelp after printing help, or a usage error.
+ */
+func parseFlags(cmd *command, args []string) ([]string, error) {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	registerGlobalFlags(fs)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+	}
+
+	// Everything after "--" is positional, even if it looks like a flag.
+	var literal []string
+	for i, a := range args {
+		if a == "--" {
+			literal = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				pageHelp(func(w io.Writer) { printCommandHelp(w, cmd) })
+				return nil, err
+			}
+			return nil, usageErrorf(cmd, "%v", err)
+		}
+		// fs.Parse stops at the first positional argument; keep it and parse the rest.
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	positional = append(positional, literal...)
+	return positional, applyProfile()
+}
+
+// registerGlobalFlags adds the flags accepted by every command.
+func registerGlobalFlags(fs *flag.FlagSet) {
+	fs.StringVar(&configPath, "config", configPath, "Use the config file at this path")
+	fs.StringVar(&configProfile, "profile", configProfile, "Use the named config profile (~/.kairos_config.NAME.json)")
+	fs.BoolVar(&jsonOutput, "json", jsonOutput, "Print machine-readable JSON where supported")
+	fs.BoolVar(&readOnlyFlag, "readonly", readOnlyFlag, "Refuse every change to the config (see the read_only setting)")
+	fs.BoolVar(&debugMode, "debug", debugMode, "Record debug details in the log file (see KAIROS_LOG)")
+	fs.StringVar(&serverAddr, "server", serverAddr, "Attach to (or with ctl, control) the shared kairos server at host:port")
+	fs.StringVar(&serverTokenFlag, "token", serverTokenFlag, "Token for --server (default $KAIROS_TOKEN)")
+}
+
+// applyProfile turns --profile into a config path unless --config was also given.
+func applyProfile() error {
+	if configProfile == "" || configPath != "" {
+		return nil
+	}
+	if strings.ContainsAny(configProfile, `/\`) {
+		return usageErrorf(nil, "invalid profile name '%s'", configProfile)
+	}
+	configPath = filepath.Join(homeDir(), fmt.Sprintf(".kairos_config.%s.json", configProfile))
+	return nil
+}
+
+/**
+ * This function loads the config before a command runs. A config that cannot be parsed
+ * is reported but does not stop read-only commands; the error is kept in configErr so
+ * that saveConfig refuses to overwrite the file.
+ */
+func loadConfigOrWarn() {
+	initLogging()
+	// A tz database installed with kairos tzupdate takes precedence over the system's.
+	useInstalledTZData()
+	// Widget scripts and plugins add footer placeholders, which the footer setting is checked against.
+	loadWidgetScripts()
+	loadWidgetPlugins()
+	if err := loadConfig(); err != nil {
+		configErr = err
+		logger.Error("config load failed", "path", getConfigPath(), "err", err)
+		fmt.Fprintf(os.Stderr, "\x1b[31m%v\x1b[0m\n", err)
+		fmt.Fprintln(os.Stderr, "Fix the file by hand or run 'kairos restore' to pick a backup; changes will not be saved until then.")
+	}
+}
+
+/**
+ * This function prints the help for one command: its usage line, description,
+ * subcommands, and flags.
+ *
+ * @param w - Where to print.
+ * @param cmd - The command.
+ */
+func printCommandHelp(w io.Writer, cmd *command) {
+	usage := cmd.path()
+	if len(cmd.Subcommands) > 0 {
+		usage += " <subcommand>"
+	}
+	if cmd.Usage != "" {
+		usage += " " + cmd.Usage
+	}
+	fmt.Fprintf(w, "\n\x1b[1mUSAGE:\x1b[0m\n  %s [flags]\n\n%s\n", usage, cmd.Short)
+	if len(cmd.Subcommands) > 0 {
+		fmt.Fprintln(w, "\n\x1b[1mSUBCOMMANDS:\x1b[0m")
+		for _, sub := range cmd.Subcommands {
+			fmt.Fprintf(w, "  %-28s \x1b[90m# %s\x1b[0m\n", strings.TrimSpace(sub.Name+" "+sub.Usage), sub.Short)
+		}
+	}
+
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	if cmd.Flags != nil {
+		cmd.Flags(fs)
+		fmt.Fprintln(w, "\n\x1b[1mFLAGS:\x1b[0m")
+		printFlags(w, fs)
+	}
+	fmt.Fprintln(w, "\n\x1b[1mGLOBAL FLAGS:\x1b[0m")
+	global := flag.NewFlagSet("global", flag.ContinueOnError)
+	registerGlobalFlags(global)
+	printFlags(w, global)
+	fmt.Fprintln(w)
+}
+
+// printFlags lists the flags of a flag set, one per line.
+func printFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		name := "--" + f.Name
+		if _, isBool := f.Value.(interface{ IsBoolFlag() bool }); !isBool {
+			name += " value"
+		}
+		fmt.Fprintf(w, "  \x1b[33m%-18s\x1b[0m %s\n", name, f.Usage)
+	})
+}
+
+// runHelp handles `kairos help [command]`.
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		pageHelp(printHelp)
+		return nil
+	}
+	cmd := findCommand(commands, args[0])
+	if cmd == nil {
+		return usageErrorf(nil, "Unknown command: %s", args[0])
+	}
+	pageHelp(func(w io.Writer) { printCommandHelp(w, cmd) })
+	return nil
+}
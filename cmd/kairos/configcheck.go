@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// zoneOptionValues give each validated per-zone option's stored value in the form `kairos set` takes, or "".
+var zoneOptionValues = []struct {
+	option string
+	value  func(tz TimezoneConfig) string
+}{
+	{"calendar", func(tz TimezoneConfig) string { return tz.Calendar }},
+	{"prayer", func(tz TimezoneConfig) string { return tz.Prayer }},
+	{"asr", func(tz TimezoneConfig) string { return tz.Asr }},
+	{"hours", func(tz TimezoneConfig) string { return tz.Hours }},
+	{"focus", func(tz TimezoneConfig) string { return tz.Focus }},
+	{"awake", func(tz TimezoneConfig) string { return tz.Awake }},
+	{"shift", func(tz TimezoneConfig) string { return strings.TrimSpace(tz.Shift + " " + tz.ShiftHours) }},
+	{"states", func(tz TimezoneConfig) string { return formatHoursStates(tz.States) }},
+	{"progress", func(tz TimezoneConfig) string { return tz.Progress }},
+	{"style", func(tz TimezoneConfig) string { return tz.Style }},
+	{"bars", func(tz TimezoneConfig) string { return strings.Join(tz.Bars, ",") }},
+	{"times", func(tz TimezoneConfig) string { return strings.Join(tz.Times, ",") }},
+	{"subtime", func(tz TimezoneConfig) string { return tz.Subtime }},
+	{"health", func(tz TimezoneConfig) string { return tz.Health }},
+	{"carbon", func(tz TimezoneConfig) string { return tz.Carbon }},
+	{"holidays", func(tz TimezoneConfig) string { return strings.Join(tz.Holidays, ",") }},
+	{"coords", func(tz TimezoneConfig) string {
+		if tz.Coordinates == nil {
+			return ""
+		}
+		return fmt.Sprintf("%g,%g", tz.Coordinates.Lat, tz.Coordinates.Lon)
+	}},
+}
+
+/**
+ * This function handles the `kairos config check` command. Values written by kairos are
+ * validated as they are set, but a config edited by hand (or by an older version) can hold
+ * anything; this lints it: zone locations, per-zone options such as business hours and
+ * state colors, global settings, hook commands, and announcements. Each problem comes with a
+ * suggested fix.
+ *
+ * @returns An error if any check failed, so that kairos exits non-zero.
+ */
+func runConfigCheck() error {
+	if configErr != nil {
+		return fmt.Errorf("%v; fix it with 'kairos edit' or run 'kairos restore'", configErr)
+	}
+	var checks []doctorCheck
+	checks = append(checks, checkZoneConfig()...)
+	checks = append(checks, checkSettingValues()...)
+	checks = append(checks, checkCommands()...)
+	checks = append(checks, checkAnnouncementConfig()...)
+	checks = append(checks, checkTileConfig()...)
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == checkFail {
+			failed++
+		}
+	}
+	if jsonOutput {
+		if checks == nil {
+			checks = []doctorCheck{}
+		}
+		out, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	} else if len(checks) == 0 {
+		fmt.Printf("No problems found in %s\n", getConfigPath())
+	} else {
+		printDoctorReport(checks)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d problem(s) found in %s", failed, getConfigPath())
+	}
+	return nil
+}
+
+/**
+ * This function lints the timezones: unique names, locations that load (suggesting the
+ * closest IANA name for a typo), and every per-zone option, re-applied with `kairos set`'s
+ * own validation.
+ *
+ * @returns A check for each problem.
+ */
+func checkZoneConfig() []doctorCheck {
+	section := "zones"
+	var checks []doctorCheck
+	seen := map[string]bool{}
+	for _, tz := range timezones {
+		if seen[tz.Name] {
+			checks = append(checks, doctorCheck{section, tz.Name, checkWarn,
+				"the name is used twice, so commands only find the first; rename one with 'kairos edit'"})
+		}
+		seen[tz.Name] = true
+
+		if _, err := tzutil.LoadLocation(tz.Location); err != nil {
+			fix := fmt.Sprintf("kairos remove \"%s\" && kairos add \"%s\" \"LOCATION\"", tz.Name, tz.Name)
+			if match := closestMatch(tz.Location, allZoneNames()); match != "" {
+				fix = fmt.Sprintf("did you mean %s? kairos remove \"%s\" && kairos add \"%s\" \"%s\"", match, tz.Name, tz.Name, match)
+			}
+			checks = append(checks, doctorCheck{section, tz.Name, checkFail, fmt.Sprintf("unknown location '%s'; %s", tz.Location, fix)})
+		}
+
+		// The options are re-applied to a scratch copy of the zones.
+		saved := timezones
+		for _, o := range zoneOptionValues {
+			value := o.value(tz)
+			if value == "" {
+				continue
+			}
+			timezones = append([]TimezoneConfig(nil), saved...)
+			if err := setZoneOption(tz.Name, o.option, value); err != nil {
+				checks = append(checks, doctorCheck{section, tz.Name + " " + o.option, checkFail,
+					fmt.Sprintf("%v; fix it with kairos set \"%s\" %s VALUE, or clear it with kairos set \"%s\" %s none", err, tz.Name, o.option, tz.Name, o.option)})
+			}
+		}
+		timezones = saved
+	}
+	for _, e := range events {
+		if _, err := eventStart(e); err != nil {
+			checks = append(checks, doctorCheck{section, "event " + e.Title, checkFail, fmt.Sprintf("%v; remove it with kairos event remove \"%s\"", err, e.Title)})
+		}
+	}
+	return checks
+}
+
+// checkSettingValues re-applies every global setting with `kairos config set`'s validation, reporting the ones that fail.
+func checkSettingValues() []doctorCheck {
+	var checks []doctorCheck
+	saved, savedRefs := settings, secretRefs
+	for _, d := range settingDefs {
+		secretRefs = map[string]secretRef{}
+		for k, v := range savedRefs {
+			secretRefs[k] = v
+		}
+		if err := d.Set(d.Get()); err != nil {
+			checks = append(checks, doctorCheck{"settings", d.Key, checkFail,
+				fmt.Sprintf("%v; fix it with kairos config set %s VALUE", err, d.Key)})
+		}
+		settings = saved
+	}
+	secretRefs = savedRefs
+	return checks
+}
+
+/**
+ * This function lints the configured commands: hooks for events that do not exist,
+ * and hook, chime, and metric commands whose program cannot be found.
+ *
+ * @returns A check for each problem.
+ */
+func checkCommands() []doctorCheck {
+	section := "commands"
+	var checks []doctorCheck
+	var events []string
+	for _, d := range settingDefs {
+		if name, ok := strings.CutPrefix(d.Key, "hook_"); ok {
+			events = append(events, name)
+		}
+	}
+	var names []string
+	for event := range settings.Hooks {
+		names = append(names, event)
+	}
+	sort.Strings(names)
+	for _, event := range names {
+		if !containsString(events, event) {
+			checks = append(checks, doctorCheck{section, "hook " + event, checkWarn,
+				fmt.Sprintf("there is no '%s' event, so the hook never runs (events: %s); remove it with 'kairos edit'", event, strings.Join(events, ", "))})
+			continue
+		}
+		if problem := commandProblem(settings.Hooks[event]); problem != "" {
+			checks = append(checks, doctorCheck{section, "hook_" + event, checkWarn, problem + "; fix it with kairos config set hook_" + event + " COMMAND"})
+		}
+	}
+	if problem := commandProblem(settings.ChimeCommand); problem != "" {
+		checks = append(checks, doctorCheck{section, "chime_command", checkWarn, problem + "; fix it with kairos config set chime_command COMMAND"})
+	}
+	for _, tz := range timezones {
+		if tz.Metric == "" || strings.HasPrefix(tz.Metric, promPrefix) {
+			continue
+		}
+		if problem := commandProblem(tz.Metric); problem != "" {
+			checks = append(checks, doctorCheck{section, tz.Name + " metric", checkWarn, fmt.Sprintf("%s; fix it with kairos set \"%s\" metric COMMAND", problem, tz.Name)})
+		}
+	}
+	return checks
+}
+
+// commandProblem describes why a shell command's program cannot be found, or returns "".
+// Commands starting with shell syntax (variables, quotes, subshells) are not checked.
+func commandProblem(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || strings.ContainsAny(fields[0], "$\"'`(=;|&<>") {
+		return ""
+	}
+	program := expandHome(fields[0])
+	if strings.ContainsAny(program, `/\`) {
+		if _, err := os.Stat(program); err != nil {
+			return fmt.Sprintf("'%s' does not exist", fields[0])
+		}
+		return ""
+	}
+	if _, err := exec.LookPath(program); err != nil && !shellBuiltins[program] {
+		return fmt.Sprintf("'%s' is not found on PATH", program)
+	}
+	return ""
+}
+
+// shellBuiltins are commands that the shell runs itself, so they are never on PATH.
+var shellBuiltins = map[string]bool{"echo": true, "printf": true, "cd": true, "exit": true, "true": true, "false": true, "test": true, "[": true, ":": true, "start": true}
+
+/**
+ * This function finds the candidate closest to a misspelled name, comparing whole names
+ * and, for names without a region such as "Tokio", the city part of each candidate.
+ *
+ * @param name - The misspelled name.
+ * @param candidates - The valid names.
+ * @returns The closest candidate, or "" if none is close enough to be a typo.
+ */
+func closestMatch(name string, candidates []string) string {
+	name = strings.ToLower(name)
+	best, bestDist := "", len(name)/4+2
+	for _, c := range candidates {
+		lower := strings.ToLower(c)
+		dist := editDistance(name, lower)
+		if !strings.Contains(name, "/") {
+			dist = min(dist, editDistance(name, lower[strings.LastIndex(lower, "/")+1:]))
+		}
+		if dist < bestDist {
+			best, bestDist = c, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// configLockWait is how long a write waits for another kairos to finish with the config.
+	configLockWait = 3 * time.Second
+	// configLockStale is how old a lock file must be to be taken as left behind by a crash.
+	configLockStale = 30 * time.Second
+	// configConflictRetries is how many times a command is rerun on a config changed under it.
+	configConflictRetries = 5
+)
+
+// configConflictError reports a config file changed by another kairos after it was loaded.
+type configConflictError struct {
+	path string
+}
+
+func (e *configConflictError) Error() string {
+	return fmt.Sprintf("%s was changed by another kairos since it was loaded; nothing was saved, so run the command again (or restart the dashboard) to change the latest version", e.path)
+}
+
+// loadedConfigStamp is the hash of the config file as it was last loaded or written
+// (see configStamp); a different hash on disk means another kairos changed it since.
+var loadedConfigStamp string
+
+// getConfigLockPath returns the lock file taken while the config is written, next to it.
+func getConfigLockPath() string {
+	return getConfigPath() + ".lock"
+}
+
+// configStamp hashes the config file, or returns "" if there is none.
+func configStamp() (string, error) {
+	data, err := os.ReadFile(getConfigPath())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return stampOf(data), nil
+}
+
+// stampOf hashes the contents of a config file.
+func stampOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+/**
+ * This function takes the config lock, so that a dashboard, a daemon, and commands in
+ * other shells never write the config at the same time. The lock is a file created only
+ * if it does not exist yet, which works the same on every platform; a lock older than
+ * configLockStale was left by a kairos that crashed, and is taken over.
+ *
+ * @returns A function that releases the lock, or an error if another kairos held it for configLockWait.
+ */
+func lockConfig() (func(), error) {
+	path := getConfigLockPath()
+	deadline := time.Now().Add(configLockWait)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("cannot lock the config: %v", err)
+		}
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > configLockStale {
+			logger.Warn("stale config lock removed", "path", path, "age", time.Since(fi.ModTime()))
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			holder, _ := os.ReadFile(path)
+			return nil, fmt.Errorf("the config is being written by another kairos (pid %s); try again, or delete %s if none is running",
+				strings.TrimSpace(string(holder)), path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+/**
+ * This function checks, with the lock held, that nobody changed the config file since it
+ * was loaded: saving the zones, events, and settings in memory over such a change would
+ * silently undo it, e.g. a zone added with `kairos add` while a dashboard was open.
+ *
+ * @returns A configConflictError, or nil if the file is as it was loaded.
+ */
+func checkConfigUnchanged() error {
+	stamp, err := configStamp()
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %v", getConfigPath(), err)
+	}
+	if stamp != loadedConfigStamp {
+		logger.Warn("config changed on disk", "path", getConfigPath())
+		return &configConflictError{path: getConfigPath()}
+	}
+	return nil
+}
+
+/**
+ * This function runs a command, and reruns it on the latest config if another kairos
+ * changed the file between loading and saving it, e.g. several `kairos add` run at once
+ * from a script. Nothing was saved by the failed run, so rerunning it is safe. kairos edit
+ * is not rerun, since the edit would have to be typed again.
+ *
+ * @param cmd - The command.
+ * @param args - Its positional arguments.
+ * @returns The command's error.
+ */
+func runWithConfigRetry(cmd *command, args []string) error {
+	err := cmd.Run(args)
+	var conflict *configConflictError
+	for i := 0; i < configConflictRetries && errors.As(err, &conflict) && cmd.Name != "edit"; i++ {
+		logger.Info("config changed while the command ran; running it again", "command", cmd.path())
+		if err := loadConfig(); err != nil {
+			return err
+		}
+		err = cmd.Run(args)
+	}
+	return err
+}
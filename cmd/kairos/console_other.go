@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// enableVirtualTerminal is a no-op outside Windows, where terminals handle ANSI escapes.
+func enableVirtualTerminal() {}
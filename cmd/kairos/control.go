@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// controlMethods are the actions a running dashboard accepts on its control socket.
+var controlMethods = []string{"swap", "notify", "profile", "zen", "celebrate", "status", "state"}
+
+// controlRequest is a JSON-RPC 2.0 request sent to the control socket, one per line.
+type controlRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      int               `json:"id"`
+	Method  string            `json:"method"`
+	Params  map[string]string `json:"params,omitempty"`
+	// Token authenticates requests to a shared server over TCP (see server.go); the local socket ignores it.
+	Token string `json:"token,omitempty"`
+}
+
+// controlResponse is the JSON-RPC 2.0 reply to a controlRequest.
+type controlResponse struct {
+	JSONRPC string                 `json:"jsonrpc"`
+	ID      int                    `json:"id"`
+	Result  map[string]interface{} `json:"result,omitempty"`
+	Error   *controlError          `json:"error,omitempty"`
+}
+
+// controlError is a JSON-RPC error object.
+type controlError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// getControlSocketPath returns the control socket of the running dashboard: $KAIROS_SOCKET, or kairos.sock next to the log.
+func getControlSocketPath() string {
+	if env := os.Getenv("KAIROS_SOCKET"); env != "" {
+		return env
+	}
+	return filepath.Join(filepath.Dir(getLogPath()), "kairos.sock")
+}
+
+// ctlFor and ctlLevel are set by `kairos ctl notify --for` and `--level`.
+var ctlFor, ctlLevel string
+
+// ctlCommand builds the `kairos ctl` command.
+func ctlCommand() *command {
+	return &command{
+		Name:    "ctl",
+		Usage:   "swap|notify|profile|zen|celebrate|status|state [argument]",
+		Short:   "Controls the running dashboard, e.g. from window manager keybindings",
+		MinArgs: 1, MaxArgs: 2,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&ctlFor, "for", "", "How long notify shows the message, e.g. 30s (default 5s)")
+			fs.StringVar(&ctlLevel, "level", "", "The level of notify's message: info (default), warn, or alert")
+		},
+		Run: func(args []string) error {
+			arg := ""
+			if len(args) == 2 {
+				arg = args[1]
+			}
+			return runCtl(args[0], arg)
+		},
+	}
+}
+
+/**
+ * This function handles `kairos ctl`: it sends one request to the running dashboard's
+ * control socket and prints the reply.
+ *
+ * @param method - The action: swap, notify, profile, zen, celebrate, or status.
+ * @param arg - The zone for swap, the message for notify or celebrate, the profile name for profile
+ *              ("default" for the main config), or on/off for zen (toggles when empty).
+ * @returns An error if no dashboard is running or it rejects the request.
+ */
+func runCtl(method, arg string) error {
+	method = strings.ToLower(method)
+	params := map[string]string{}
+	switch method {
+	case "swap":
+		params["zone"] = arg
+	case "notify":
+		params["message"] = arg
+		if ctlFor != "" {
+			params["duration"] = ctlFor
+		}
+		if ctlLevel != "" {
+			params["level"] = ctlLevel
+		}
+	case "celebrate":
+		params["message"] = defaultString(arg, "Hooray!")
+	case "profile":
+		params["name"] = arg
+	case "zen":
+		params["state"] = arg
+	case "status", "state":
+	default:
+		return fmt.Errorf("unknown action '%s' (expected one of: %s)", method, strings.Join(controlMethods, ", "))
+	}
+	if arg == "" && (method == "swap" || method == "notify" || method == "profile") {
+		return fmt.Errorf("kairos ctl %s needs an argument", method)
+	}
+
+	result, err := callControl(method, params)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+	for _, key := range []string{"role", "primary", "profile", "zen"} {
+		if v, ok := result[key]; ok {
+			fmt.Printf("%-8s %v\n", key+":", v)
+		}
+	}
+	return nil
+}
+
+/**
+ * This function sends one request to the control socket of the running dashboard or
+ * daemon and waits for the reply.
+ *
+ * @param method - The method.
+ * @param params - Its parameters, or nil.
+ * @returns The result, or an error if nothing is listening or the request is rejected.
+ */
+func callControl(method string, params map[string]string) (map[string]interface{}, error) {
+	var conn net.Conn
+	var err error
+	if serverAddr != "" {
+		if conn, err = dialServer(serverAddr); err != nil {
+			return nil, fmt.Errorf("cannot reach the kairos server at %s: %v", serverAddr, err)
+		}
+	} else if conn, err = net.DialTimeout("unix", getControlSocketPath(), 2*time.Second); err != nil {
+		return nil, fmt.Errorf("no running dashboard found at %s", getControlSocketPath())
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req := controlRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params, Token: serverToken()}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	var resp controlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, errors.New(resp.Error.Message)
+	}
+	return resp.Result, nil
+}
+
+/**
+ * This function opens the control socket, through which other processes can drive the
+ * dashboard or daemon with JSON-RPC 2.0 requests (one per line). If another instance
+ * already owns the socket, this one goes without.
+ *
+ * @param run - Runs a request's handler where it can safely change the state (on the
+ *              GUI goroutine, or under the daemon's lock) and returns when it is done.
+ * @returns A function that closes and removes the socket.
+ */
+func startControlSocket(run func(func())) func() {
+	path := getControlSocketPath()
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		logger.Warn("control socket in use by another dashboard", "path", path)
+		return func() {}
+	}
+	// A socket left behind by a dashboard that crashed is replaced.
+	os.Remove(path)
+	os.MkdirAll(filepath.Dir(path), 0700)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		logger.Warn("control socket not opened", "path", path, "err", err)
+		return func() {}
+	}
+	os.Chmod(path, 0600)
+	go func() {
+		defer recoverWorker("control socket")
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveControl(run, conn, nil)
+		}
+	}()
+	return func() {
+		listener.Close()
+		os.Remove(path)
+	}
+}
+
+// serveControl answers the requests on one control connection until it is closed; authorize (if set) vets each request first.
+func serveControl(run func(func()), conn net.Conn, authorize func(req controlRequest) *controlError) {
+	defer recoverWorker("control connection")
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req controlRequest
+		resp := controlResponse{JSONRPC: "2.0"}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = &controlError{Code: -32700, Message: "parse error: " + err.Error()}
+		} else {
+			resp.ID = req.ID
+			if authorize != nil {
+				resp.Error = authorize(req)
+			}
+		}
+		if resp.Error == nil {
+			run(func() {
+				result, err := handleControl(req.Method, req.Params)
+				if err != nil {
+					resp.Error = &controlError{Code: -32000, Message: err.Error()}
+					if !containsString(controlMethods, req.Method) {
+						resp.Error.Code = -32601
+					}
+				}
+				resp.Result = result
+			})
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+	}
+}
+
+/**
+ * This function carries out one control request on the GUI goroutine.
+ *
+ * @param method - The action.
+ * @param params - Its parameters.
+ * @returns The dashboard's state after the action, or an error.
+ */
+func handleControl(method string, params map[string]string) (map[string]interface{}, error) {
+	switch method {
+	case "swap":
+		tz, _, err := findZone(params["zone"])
+		if err != nil {
+			return nil, err
+		}
+		if settings.Military {
+			return nil, fmt.Errorf("Zulu is pinned in military mode")
+		}
+		if promoteZone(tz.Name) {
+			showNotification(fmt.Sprintf("Moved %s to the top", tz.Name))
+		}
+	case "notify":
+		if params["message"] == "" {
+			return nil, fmt.Errorf("the notification needs a message")
+		}
+		d := 5 * time.Second
+		if params["duration"] != "" {
+			var err error
+			if d, err = time.ParseDuration(params["duration"]); err != nil || d < time.Second || d > time.Hour {
+				return nil, fmt.Errorf("invalid duration '%s' (expected e.g. 30s, from 1s to 1h)", params["duration"])
+			}
+		}
+		level := levelInfo
+		if params["level"] != "" {
+			var err error
+			if level, err = parseNotifyLevel(params["level"]); err != nil {
+				return nil, err
+			}
+		}
+		notify(level, params["message"], d)
+	case "celebrate":
+		zone := ""
+		if params["zone"] != "" {
+			tz, _, err := findZone(params["zone"])
+			if err != nil {
+				return nil, err
+			}
+			zone = tz.Name
+		}
+		switch params["kind"] {
+		case "", "confetti":
+			params["kind"] = "confetti"
+		case "fireworks":
+		default:
+			return nil, fmt.Errorf("invalid celebration '%s' (expected confetti or fireworks)", params["kind"])
+		}
+		if params["message"] == "" {
+			return nil, fmt.Errorf("the celebration needs a message")
+		}
+		celebrate(params["kind"], params["message"], zone)
+	case "profile":
+		if err := switchProfile(params["name"]); err != nil {
+			return nil, err
+		}
+		showNotification("Switched to profile " + defaultString(configProfile, "default"))
+	case "zen":
+		switch strings.ToLower(params["state"]) {
+		case "":
+			zenMode = !zenMode
+		case "on":
+			zenMode = true
+		case "off":
+			zenMode = false
+		default:
+			return nil, fmt.Errorf("invalid zen state '%s' (expected on or off)", params["state"])
+		}
+	case "status", "state":
+	default:
+		return nil, fmt.Errorf("unknown method '%s' (expected one of: %s)", method, strings.Join(controlMethods, ", "))
+	}
+	role := "dashboard"
+	if daemonMode {
+		role = "daemon"
+	}
+	status := map[string]interface{}{"role": role, "profile": defaultString(configProfile, "default"), "zen": formatSwitch(zenMode)}
+	if zones := displayZones(); len(zones) > 0 {
+		status["primary"] = zones[0].Name
+	}
+	// The full state is for attached dashboards (see daemon.go).
+	if method == "state" {
+		status["zones"] = timezones
+		notification, level, _, _ := appState.NotificationStatus()
+		status["notification"], status["notification_level"] = notification, level.String()
+		status["alarms"] = firedAlarms
+		if celebration != nil {
+			status["celebration"] = celebration
+		}
+	}
+	return status, nil
+}
+
+/**
+ * This function makes the dashboard show another config profile, as if it had been
+ * started with --profile. If the profile cannot be loaded, the current one stays.
+ *
+ * @param name - The profile, or "default" for the main config file.
+ * @returns An error if the name is invalid or the profile cannot be loaded.
+ */
+func switchProfile(name string) error {
+	if name == "default" {
+		name = ""
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name '%s'", name)
+	}
+	oldProfile, oldPath := configProfile, configPath
+	configProfile, configPath = name, ""
+	applyProfile()
+	if _, err := os.Stat(getConfigPath()); err != nil && name != "" {
+		err = fmt.Errorf("no profile '%s' (%s does not exist)", name, getConfigPath())
+		configProfile, configPath = oldProfile, oldPath
+		return err
+	}
+	if err := loadConfig(); err != nil {
+		configProfile, configPath = oldProfile, oldPath
+		return err
+	}
+	configErr = nil
+	focusIndex = -1
+	loadLocations()
+	bus.Publish(topicConfigReloaded, nil)
+	runHook("profile", map[string]string{"PROFILE": defaultString(configProfile, "default")})
+	return nil
+}
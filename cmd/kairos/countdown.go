@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// defaultOBSFormat is the line written by --obs unless obs_format is set.
+const defaultOBSFormat = "{title} {countdown}"
+
+// countdownLinger is how long an event that has started stays the countdown target, counting up.
+const countdownLinger = 5 * time.Minute
+
+var (
+	// countdownMode is toggled with the "c" key, or set by --countdown, and shows the countdown full screen.
+	countdownMode bool
+	// countdownTarget is set by --countdown: a saved event's title, or a time in the primary zone.
+	countdownTarget string
+	// obsPath is set by --obs: instead of the dashboard, the countdown line is written to this file.
+	obsPath string
+	// obsInterval is set by --obs-interval: how often the file is refreshed.
+	obsInterval = 100 * time.Millisecond
+)
+
+// countdown is what the countdown view and the OBS line show: a target and the time to it.
+type countdown struct {
+	Title string
+	At    time.Time
+	// Left is the time until At; it is negative once the target has passed.
+	Left time.Duration
+	// OK is false when there is no target, and only the clock is shown.
+	OK bool
+}
+
+/**
+ * This function finds the countdown target: the event or time given with --countdown, or
+ * else the next saved event. An event that just started stays the target for a few
+ * minutes (see countdownLinger) so that the count up from zero is shown.
+ *
+ * @param now - The current time.
+ * @returns The countdown, with OK false if there is no target.
+ */
+func currentCountdown(now time.Time) countdown {
+	if countdownTarget != "" {
+		for _, e := range events {
+			if strings.EqualFold(e.Title, countdownTarget) {
+				if start, err := eventStart(e); err == nil {
+					return countdown{Title: e.Title, At: start, Left: start.Sub(now), OK: true}
+				}
+			}
+		}
+		loc := time.UTC
+		if zones := displayZones(); len(zones) > 0 && locations[zones[0].Name] != nil {
+			loc = locations[zones[0].Name]
+		}
+		if at, _, err := parseLocalTime(countdownTarget, loc); err == nil {
+			return countdown{Title: "Countdown", At: at.In(loc), Left: at.Sub(now), OK: true}
+		}
+		return countdown{}
+	}
+	var upcoming []countdown
+	for _, e := range events {
+		start, err := eventStart(e)
+		if err != nil || now.Sub(start) > countdownLinger {
+			continue
+		}
+		upcoming = append(upcoming, countdown{Title: e.Title, At: start, Left: start.Sub(now), OK: true})
+	}
+	if len(upcoming) == 0 {
+		return countdown{}
+	}
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].At.Before(upcoming[j].At) })
+	return upcoming[0]
+}
+
+// countdownClock formats the time left as HH:MM:SS, with the days before it once there are any.
+func countdownClock(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	secs := int(d / time.Second)
+	clock := fmt.Sprintf("%02d:%02d:%02d", secs/3600%24, secs/60%60, secs%60)
+	if days := secs / 86400; days > 0 {
+		return fmt.Sprintf("%dd %s", days, clock)
+	}
+	return clock
+}
+
+// countdownSign is "T-" before the target and "T+" after it, as on a launch countdown.
+func countdownSign(d time.Duration) string {
+	if d < 0 {
+		return "T+"
+	}
+	return "T-"
+}
+
+// obsFields are the placeholders of the obs_format template.
+var obsFields = map[string]func(c countdown, now time.Time) string{
+	"title": func(c countdown, _ time.Time) string { return c.Title },
+	"countdown": func(c countdown, _ time.Time) string {
+		if !c.OK {
+			return ""
+		}
+		return countdownSign(c.Left) + countdownClock(c.Left)
+	},
+	"tenths": func(c countdown, _ time.Time) string {
+		if !c.OK {
+			return ""
+		}
+		d := c.Left.Abs()
+		return fmt.Sprintf("%s%s.%d", countdownSign(c.Left), countdownClock(d), int(d%time.Second/(100*time.Millisecond)))
+	},
+	"target": func(c countdown, _ time.Time) string {
+		if !c.OK {
+			return ""
+		}
+		return c.At.Format("Mon 02 Jan 15:04 MST")
+	},
+	"time": func(_ countdown, now time.Time) string { return primaryNow(now).Format("15:04:05") },
+	"zone": func(countdown, time.Time) string {
+		if zones := displayZones(); len(zones) > 0 {
+			return zones[0].Name
+		}
+		return ""
+	},
+	"utc": func(_ countdown, now time.Time) string { return now.UTC().Format("15:04:05Z") },
+}
+
+// obsFieldNames returns the obs_format placeholders, sorted.
+func obsFieldNames() []string {
+	var names []string
+	for name := range obsFields {
+		names = append(names, "{"+name+"}")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// primaryNow returns the time in the primary zone, or local time without zones.
+func primaryNow(now time.Time) time.Time {
+	if zones := displayZones(); len(zones) > 0 && locations[zones[0].Name] != nil {
+		return now.In(locations[zones[0].Name])
+	}
+	return now.In(localZone())
+}
+
+/**
+ * This function returns the {boundary} footer widget, the time left until the next full
+ * or half hour in the primary zone, e.g. "12m to 3:30 PM", since that is when most
+ * meetings start. The boundary is on the zone's own clock, so it holds in zones with a
+ * :30 or :45 offset too.
+ *
+ * @param now - The current time.
+ * @returns The widget's text.
+ */
+func boundaryStatus(now time.Time) string {
+	local := primaryNow(now)
+	left := time.Duration(30-local.Minute()%30)*time.Minute - time.Duration(local.Second())*time.Second - time.Duration(local.Nanosecond())
+	next := local.Add(left)
+	clock := next.Format("3:04 PM")
+	if zones := displayZones(); len(zones) > 0 && uses24Hour(zones[0]) {
+		clock = next.Format("15:04")
+	}
+	if left < time.Minute {
+		return fmt.Sprintf("%ds to %s", int(left.Seconds()), clock)
+	}
+	return fmt.Sprintf("%dm to %s", int(left.Minutes()), clock)
+}
+
+// obsFormat returns the obs_format template.
+func obsFormat() string {
+	return defaultString(settings.OBSFormat, defaultOBSFormat)
+}
+
+// setOBSFormat validates and stores the obs_format template.
+func setOBSFormat(v string) error {
+	if v == "default" || v == "none" || v == "" || v == defaultOBSFormat {
+		settings.OBSFormat = ""
+		return nil
+	}
+	for _, m := range footerPlaceholder.FindAllStringSubmatch(v, -1) {
+		if _, ok := obsFields[m[1]]; !ok {
+			return fmt.Errorf("unknown placeholder {%s} (expected any of: %s)", m[1], strings.Join(obsFieldNames(), ", "))
+		}
+	}
+	settings.OBSFormat = v
+	return nil
+}
+
+// formatOBSLine expands the obs_format template for the current countdown.
+func formatOBSLine(now time.Time) string {
+	c := currentCountdown(now)
+	line := footerPlaceholder.ReplaceAllStringFunc(obsFormat(), func(m string) string {
+		if field, ok := obsFields[m[1:len(m)-1]]; ok {
+			return field(c, now)
+		}
+		return m
+	})
+	return strings.TrimSpace(line)
+}
+
+/**
+ * This function runs the --obs emit mode for livestreams: instead of the dashboard, it
+ * writes the countdown line (see obs_format) to a file many times a second, for an OBS
+ * text source reading from it. The file is replaced rather than rewritten, so OBS never
+ * reads half a line, and only when the line changes. It runs until SIGINT or SIGTERM.
+ *
+ * @param path - The file to write.
+ * @returns An error if the interval is invalid or the file cannot be written.
+ */
+func runOBS(path string) error {
+	if obsInterval < 10*time.Millisecond {
+		return usageErrorf(nil, "invalid --obs-interval %s (expected at least 10ms)", obsInterval)
+	}
+	loadLocations()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	ticker := time.NewTicker(obsInterval)
+	defer ticker.Stop()
+	logger.Info("writing the countdown for OBS", "path", path, "interval", obsInterval)
+	fmt.Printf("Writing the countdown to %s every %s (Ctrl+C stops)\n", path, obsInterval)
+
+	written, wrote := "", false
+	for {
+		if line := formatOBSLine(appClock.Now(time.UTC)); !wrote || line != written {
+			if err := writeOBSLine(path, line); err != nil {
+				return fmt.Errorf("failed to write %s: %v", path, err)
+			}
+			written, wrote = line, true
+		}
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeOBSLine replaces the file with the line through a temporary file in the same directory.
+func writeOBSLine(path, line string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".kairos-obs-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(line + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+/**
+ * This function renders the full-screen countdown view (the "c" key): the target's
+ * title, the time left in digits as large as the screen allows, and the target time.
+ * Past the target it counts up, like a launch clock.
+ *
+ * @param now - The current time.
+ * @param width - The screen width.
+ * @param height - The screen height.
+ * @returns The lines of the view.
+ */
+func renderCountdownLines(now time.Time, width, height int) []string {
+	c := currentCountdown(now)
+	if !c.OK {
+		lines := make([]string, max(height/2-1, 0))
+		lines = append(lines, render.Center("\x1b[1mNo countdown\x1b[0m", width),
+			render.Center("Add an event with kairos event add, or start kairos with --countdown; c closes", width))
+		return lines
+	}
+	secs := int(c.Left.Abs() / time.Second)
+	hms := fmt.Sprintf("%02d:%02d:%02d", secs/3600%24, secs/60%60, secs%60)
+	art := render.PrintTimeASCII(hms)
+	scale := 1
+	for render.TextWidth(art[0])*(scale+1) <= width && 5*(scale+1)+4 <= height {
+		scale++
+	}
+	title := fmt.Sprintf("\x1b[1m%s\x1b[0m", c.Title)
+	status := "starts in"
+	if c.Left < 0 {
+		status = "started"
+	}
+	if days := secs / 86400; days > 0 {
+		status += fmt.Sprintf(" %d day(s) and", days)
+	} else if c.Left < 0 {
+		status += ", counting up"
+	}
+
+	var lines []string
+	for i := 0; i < (height-5*scale-4)/2; i++ {
+		lines = append(lines, "")
+	}
+	lines = append(lines, render.Center(title, width), render.Center(status, width), "")
+	if render.TextWidth(art[0]) > width {
+		lines = append(lines, render.Center("\x1b[1m"+countdownSign(c.Left)+hms+"\x1b[0m", width))
+	} else {
+		for _, line := range scaleASCII(art, scale) {
+			lines = append(lines, render.Center(line, width))
+		}
+	}
+	return append(lines, render.Center(fmt.Sprintf("%s · %s", c.At.Format("Mon 02 Jan 15:04 MST"), c.At.UTC().Format("15:04 UTC")), width))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	// daemonMode is set while `kairos daemon` runs: there is no terminal, and the timers and integrations run here.
+	daemonMode bool
+	// daemonMu serializes the daemon's timers with the requests on its control socket.
+	daemonMu sync.Mutex
+
+	// attached is set when the dashboard follows a running daemon instead of running its own timers.
+	attached bool
+	// daemonZones, daemonNotification, daemonZen, and daemonCelebration are the daemon's state at the last sync, so only changes are applied.
+	daemonZones        string
+	daemonNotification string
+	daemonZen          string
+	daemonCelebration  string
+	// daemonLost is set when the attached daemon stopped answering.
+	daemonLost bool
+)
+
+// daemonCommand builds the `kairos daemon` command.
+func daemonCommand() *command {
+	return &command{
+		Name:  "daemon",
+		Short: "Runs the timers, alarms, and integrations in the background; dashboards attach to it",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&serverListen, "listen", "", "Also serve dashboards on other machines at this address, e.g. :7420, with TLS off loopback (see server_admin_token and server_tls_cert)")
+			fs.StringVar(&textListen, "text", "", "Also serve the kairos now table as plain text over TCP at this address, e.g. :7421")
+			fs.StringVar(&healthListen, "health", "", "Also answer health checks with GET /healthz at this address, e.g. :8080")
+			fs.StringVar(&icsListen, "ics", "", "Also serve an iCalendar feed per zone at /ics/NAME.ics on this address, e.g. :7422, with TLS off loopback (see server_read_token)")
+		},
+		Run: func(args []string) error { return runDaemon() },
+	}
+}
+
+/**
+ * This function handles `kairos daemon`. It keeps the timers, alarms, and integrations
+ * running without a terminal, serves the control socket that dashboards attach to, and
+ * reloads the config when it changes on disk (e.g. after `kairos add`). With --listen it
+ * is also a shared server for dashboards on other machines (see server.go), with --text it
+ * serves the zone table as plain text (see textservice.go), with --health it answers
+ * health checks for containers (see health.go), and with --ics it serves a calendar feed
+ * per zone (see icsfeed.go). Notifications from the timers are also
+ * sent to the desktop, since no dashboard may be open. It runs until SIGINT or SIGTERM;
+ * start it from a systemd unit, launchd agent, container, or similar.
+ *
+ * @returns An error if another dashboard or daemon already owns the control socket.
+ */
+func runDaemon() error {
+	serverAddr = ""
+	if _, err := callControl("status", nil); err == nil {
+		return fmt.Errorf("another kairos instance is already serving %s", getControlSocketPath())
+	}
+	daemonMode = true
+	run := func(f func()) {
+		daemonMu.Lock()
+		defer daemonMu.Unlock()
+		f()
+	}
+	if serverListen != "" {
+		stopServer, err := startServerListener(serverListen, run)
+		if err != nil {
+			return err
+		}
+		defer stopServer()
+	}
+	if textListen != "" {
+		stopText, err := startTextService(textListen, run)
+		if err != nil {
+			return err
+		}
+		defer stopText()
+	}
+	daemonStarted = time.Now()
+	if healthListen != "" {
+		stopHealth, err := startHealthEndpoint(healthListen, run)
+		if err != nil {
+			return err
+		}
+		defer stopHealth()
+	}
+	if icsListen != "" {
+		stopFeeds, err := startICSFeeds(icsListen, run)
+		if err != nil {
+			return err
+		}
+		defer stopFeeds()
+	}
+	loadLocations()
+	defer startWorkers()()
+	startNTPWorker()
+	startOnCallWorker()
+	startSlackWorker()
+	startMQTTWorker()
+	defer startControlSocket(run)()
+	// The timers and what follows their events run on the daemon's loop below (see eventbus.go).
+	defer subscribeComponents()()
+	defer subscribeTimers()()
+	logger.Info("daemon started", "socket", getControlSocketPath(), "config", getConfigPath())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+	modTime := configModTime()
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("daemon stopped", "signal", sig.String())
+			return nil
+		case <-ticker.C:
+		}
+		daemonMu.Lock()
+		if m := configModTime(); !m.Equal(modTime) {
+			modTime = m
+			if err := loadConfig(); err != nil {
+				// The health check reports the broken config until it is fixed.
+				configErr = err
+				logger.Warn("config not reloaded", "err", err)
+			} else {
+				configErr = nil
+				loadLocations()
+				logger.Info("config reloaded", "path", getConfigPath())
+				bus.Publish(topicConfigReloaded, nil)
+			}
+		}
+		appState.ExpireNotification(time.Now())
+		// Notifications reach the desktop as they are raised (see notify_desktop in notify.go).
+		bus.Publish(topicTick, appClock.Now(localZone()))
+		daemonMu.Unlock()
+	}
+}
+
+// configModTime returns when the config file last changed, or the zero time if it cannot be read.
+func configModTime() time.Time {
+	fi, err := os.Stat(getConfigPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
+}
+
+/**
+ * This function attaches the dashboard to a running daemon, if there is one: the
+ * dashboard then takes the daemon's zones and notifications instead of running its own
+ * timers, and forwards swaps and zen mode to it.
+ *
+ * @returns Whether the dashboard attached, and why not if there is something to attach to.
+ */
+func attachToDaemon() (bool, error) {
+	result, err := callControl("status", nil)
+	if err != nil {
+		return false, err
+	}
+	if result["role"] != "daemon" {
+		return false, nil
+	}
+	attached = true
+	logger.Info("attached to daemon", "socket", getControlSocketPath(), "server", serverAddr)
+	applyDaemonState(callControl("state", nil))
+	return true, nil
+}
+
+/**
+ * This function applies the daemon's state that changed since the last sync. It runs
+ * on the GUI goroutine; the state is fetched beforehand, off it, since a shared server
+ * may be slow to answer. A daemon that stopped leaves the dashboard as it is.
+ *
+ * @param result - The daemon's reply to "state".
+ * @param err - The error fetching it, if any.
+ */
+func applyDaemonState(result map[string]interface{}, err error) {
+	if err != nil {
+		if !daemonLost {
+			notify(levelWarn, "The kairos daemon stopped; alarms are paused", 10*time.Second)
+			daemonLost = true
+		}
+		return
+	}
+	daemonLost = false
+	if data, err := json.Marshal(result["zones"]); err == nil && string(data) != daemonZones {
+		var zones []TimezoneConfig
+		if json.Unmarshal(data, &zones) == nil && len(zones) > 0 {
+			daemonZones = string(data)
+			// A metric command is run here, so it comes from this machine's zones, never from a (possibly remote) daemon.
+			timezones = withLocalMetrics(zones, timezones)
+			loadLocations()
+			bus.Publish(topicConfigReloaded, nil)
+		}
+	}
+	if text, _ := result["notification"].(string); text != daemonNotification {
+		daemonNotification = text
+		if text != "" {
+			// The daemon has already sent it to the desktop and the webhook.
+			level, _ := parseNotifyLevel(fmt.Sprint(result["notification_level"]))
+			notifyFooter(level, text, notificationDuration())
+		}
+	}
+	// The daemon's alarms are this dashboard's too, e.g. for its exit summary.
+	if data, err := json.Marshal(result["alarms"]); err == nil {
+		var alarms []alarmFired
+		if json.Unmarshal(data, &alarms) == nil {
+			for _, a := range alarms {
+				recordAlarm(a)
+			}
+		}
+	}
+	if zen, _ := result["zen"].(string); zen != daemonZen {
+		daemonZen = zen
+		zenMode = zen == "on"
+	}
+	// A celebration started through the daemon plays here too (see celebrate.go).
+	if data, err := json.Marshal(result["celebration"]); err == nil && string(data) != daemonCelebration {
+		var c celebrationState
+		if daemonCelebration != "" && json.Unmarshal(data, &c) == nil && c.Kind != "" {
+			celebrate(c.Kind, c.Message, c.Zone)
+		}
+		daemonCelebration = string(data)
+	}
+}
+
+// forwardToDaemon sends a change made in an attached dashboard to the daemon, so the other dashboards follow.
+func forwardToDaemon(method string, params map[string]string) {
+	if !attached {
+		return
+	}
+	go func() {
+		defer recoverWorker("daemon request")
+		// A read-only token keeps the change in this dashboard only.
+		if _, err := callControl(method, params); err != nil {
+			logger.Warn("daemon request failed", "method", method, "err", err)
+			showWarning("Not shared: " + err.Error())
+		}
+	}()
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateDiffZone is set by `kairos until --zone` and `kairos since --zone`.
+var dateDiffZone string
+
+// dateDiffCommand builds the `kairos until` or `kairos since` command.
+func dateDiffCommand(name, short string, since bool) *command {
+	return &command{
+		Name:    name,
+		Usage:   `"Date"`,
+		Short:   short,
+		MinArgs: 1, MaxArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&dateDiffZone, "zone", "", "Count in this zone's calendar, with its holidays (default the primary zone)")
+		},
+		Run: func(args []string) error { return printDateDiff(args[0], dateDiffZone, since) },
+	}
+}
+
+/**
+ * This function resolves the zone of a date difference: a configured zone, whose holidays
+ * are skipped, or any location, or without one the primary zone (local time without zones).
+ *
+ * @param zone - The --zone flag.
+ * @returns The zone, its location, and a label for it.
+ */
+func dateDiffZoneFor(zone string) (TimezoneConfig, *time.Location, string, error) {
+	if zone == "" {
+		if zones := displayZones(); len(zones) > 0 {
+			zone = zones[0].Name
+		} else {
+			return TimezoneConfig{}, localZone(), "local time", nil
+		}
+	}
+	if tz, loc, err := findZone(zone); err == nil {
+		return tz, loc, tz.Name, nil
+	}
+	loc, label, err := resolveZone(zone)
+	if err != nil {
+		return TimezoneConfig{}, nil, "", err
+	}
+	return TimezoneConfig{Name: label, Location: loc.String()}, loc, label, nil
+}
+
+// calendarDaysBetween counts the calendar days from one date to another, ignoring the times and DST.
+func calendarDaysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// workdaysBetween counts the working days in a zone from one date up to, not including, a later one.
+func workdaysBetween(tz TimezoneConfig, from, to time.Time) (workdays, holidays int) {
+	for d := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()); calendarDaysBetween(d, to) > 0; d = d.AddDate(0, 0, 1) {
+		switch {
+		case isWorkday(tz, d):
+			workdays++
+		case isHoliday(tz, d) && d.Weekday() != time.Saturday && d.Weekday() != time.Sunday:
+			holidays++
+		}
+	}
+	return workdays, holidays
+}
+
+/**
+ * This function handles `kairos until` and `kairos since`: it prints how many days and
+ * weeks there are from today to a date (or from a date to today) in a zone's local
+ * calendar, and how many business days: the weekdays that are not the zone's holidays,
+ * counting today but not the date for until, and the date but not today for since. The
+ * date is read in the zone, so "today" is the zone's today, which can differ from yours.
+ *
+ * @param input - The date, e.g. "2026-06-01"; a timestamp counts by its date in the zone.
+ * @param zone - The --zone flag: a configured zone or a location, or "" for the primary zone.
+ * @param since - Whether the date is in the past (since) rather than the future (until).
+ * @returns An error if the zone or date is invalid, or the date is on the wrong side of today.
+ */
+func printDateDiff(input, zone string, since bool) error {
+	tz, loc, label, err := dateDiffZoneFor(zone)
+	if err != nil {
+		return err
+	}
+	var date time.Time
+	if day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(input), loc); err == nil {
+		date = day
+	} else {
+		t, _, err := parseLocalTime(input, loc)
+		if err != nil {
+			return err
+		}
+		date = t.In(loc)
+	}
+	today := appClock.Now(loc)
+
+	name, from, to := "until", today, date
+	if since {
+		name, from, to = "since", date, today
+	}
+	days := calendarDaysBetween(from, to)
+	if days < 0 {
+		if since {
+			return fmt.Errorf("%s is after today in %s; use 'kairos until'", date.Format("2006-01-02"), label)
+		}
+		return fmt.Errorf("%s is before today in %s; use 'kairos since'", date.Format("2006-01-02"), label)
+	}
+	workdays, holidays := workdaysBetween(tz, from, to)
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(map[string]interface{}{
+			"zone":             label,
+			"location":         loc.String(),
+			"today":            today.Format("2006-01-02"),
+			"date":             date.Format("2006-01-02"),
+			"days":             days,
+			"weeks":            days / 7,
+			"business_days":    workdays,
+			"holidays_skipped": holidays,
+			"direction":        name,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Printf("\n\x1b[36m\x1b[1m%s\x1b[0m %s in %s\n", strings.ToUpper(name), date.Format("2006-01-02"), label)
+	fmt.Printf("Today:     %s\n", today.Format("Mon, 02 Jan 2006"))
+	fmt.Printf("Date:      %s\n", date.Format("Mon, 02 Jan 2006"))
+	fmt.Printf("Days:      \x1b[1m%d\x1b[0m (%d week(s) %d day(s))\n", days, days/7, days%7)
+	fmt.Printf("Business:  \x1b[1m%d\x1b[0m business day(s), Monday to Friday\n", workdays)
+	if holidays > 0 {
+		fmt.Printf("Skipped:   %d holiday(s) of %s\n", holidays, label)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+/**
+ * This function handles the `kairos diff` command.
+ * It prints the current offset between two configured timezones and how that offset
+ * changes at the next DST transition of either zone.
+ *
+ * @param nameA - The display name of the reference timezone.
+ * @param nameB - The display name of the timezone to compare against it.
+ * @returns An error if either timezone is unknown.
+ */
+func printDiff(nameA, nameB string) error {
+	tzA, locA, err := findZone(nameA)
+	if err != nil {
+		return err
+	}
+	tzB, locB, err := findZone(nameB)
+	if err != nil {
+		return err
+	}
+
+	now := appClock.Now(time.UTC)
+	nameNowA, offA := now.In(locA).Zone()
+	nameNowB, offB := now.In(locB).Zone()
+
+	fmt.Printf("\n\x1b[36m\x1b[1m%s → %s\x1b[0m\n", tzA.Name, tzB.Name)
+	fmt.Printf("  %-15s %s  UTC%s (%s)\n", tzA.Name, now.In(locA).Format("Mon 15:04"), now.In(locA).Format("-07:00"), nameNowA)
+	fmt.Printf("  %-15s %s  UTC%s (%s)\n", tzB.Name, now.In(locB).Format("Mon 15:04"), now.In(locB).Format("-07:00"), nameNowB)
+	fmt.Printf("\n  %s\n", describeOffset(tzA.Name, tzB.Name, offB-offA))
+
+	// Find the earliest upcoming transition in either zone.
+	const horizon = 366 * 24 * time.Hour
+	trA, okA := tzutil.NextTransition(locA, now, horizon)
+	trB, okB := tzutil.NextTransition(locB, now, horizon)
+	if !okA && !okB {
+		fmt.Println("\n  \x1b[90mNeither zone changes its offset in the next year.\x1b[0m")
+		fmt.Println()
+		return nil
+	}
+
+	zoneName, tr, loc := tzA.Name, trA, locA
+	if !okA || (okB && trB.At.Before(trA.At)) {
+		zoneName, tr, loc = tzB.Name, trB, locB
+	}
+	_, newOffA := tr.At.In(locA).Zone()
+	_, newOffB := tr.At.In(locB).Zone()
+	fmt.Printf("\n  \x1b[33mNext change:\x1b[0m %s in %s (%s → %s, %s)\n",
+		tr.At.In(loc).Format("Mon, 02 Jan 2006 15:04 MST"), zoneName, tr.BeforeName, tr.AfterName, formatRelative(untilNow(tr.At)))
+	fmt.Printf("  After that: %s\n", describeOffset(tzA.Name, tzB.Name, newOffB-newOffA))
+	fmt.Println()
+	return nil
+}
+
+/**
+ * This function describes the offset between two zones in words.
+ *
+ * @param nameA - The reference timezone name.
+ * @param nameB - The compared timezone name.
+ * @param diff - The offset of B relative to A, in seconds.
+ * @returns A sentence such as "Tokyo is 13h ahead of NYC".
+ */
+func describeOffset(nameA, nameB string, diff int) string {
+	switch {
+	case diff > 0:
+		return fmt.Sprintf("%s is %s ahead of %s", nameB, tzutil.FormatOffsetDiff(diff)[1:], nameA)
+	case diff < 0:
+		return fmt.Sprintf("%s is %s behind %s", nameB, tzutil.FormatOffsetDiff(-diff)[1:], nameA)
+	}
+	return fmt.Sprintf("%s and %s are on the same time", nameA, nameB)
+}
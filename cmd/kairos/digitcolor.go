@@ -0,0 +1,51 @@
+package main
+
+import (
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// digitColorModes are the values of the digit_color setting.
+var digitColorModes = []string{"off", "phase", "gradient"}
+
+// skyColors color each hour of the day for digit_color gradient, following the sky: blue at
+// night, magenta and red at dawn and dusk, yellow in the morning and afternoon, cyan at midday.
+var skyColors = [24]string{
+	"\x1b[34m", "\x1b[34m", "\x1b[34m", "\x1b[34m", "\x1b[34m", "\x1b[35m", // 00-05
+	"\x1b[31m", "\x1b[33m", "\x1b[33m", "\x1b[33m", "\x1b[33m", "\x1b[36m", // 06-11
+	"\x1b[36m", "\x1b[36m", "\x1b[36m", "\x1b[33m", "\x1b[33m", "\x1b[33m", // 12-17
+	"\x1b[31m", "\x1b[35m", "\x1b[35m", "\x1b[34m", "\x1b[34m", "\x1b[34m", // 18-23
+}
+
+/**
+ * This function picks the color of a zone's clock digits for the digit_color setting, so
+ * the wall of clocks shows the local part of the day at a glance: "phase" uses the progress
+ * bar's morning, evening, and night colors, and "gradient" steps through skyColors hour by hour.
+ *
+ * @param now - The current time in the zone.
+ * @returns The ANSI color code, or "" when the digits are not colored.
+ */
+func digitColor(now time.Time) string {
+	switch settings.DigitColor {
+	case "phase":
+		return dayPhaseColor(now)
+	case "gradient":
+		return skyColors[now.Hour()]
+	}
+	return ""
+}
+
+// colorArt centers the lines of a clock's art in the view, in the zone's digit color if it has one.
+func colorArt(art []string, now time.Time, width int) []string {
+	color := digitColor(now)
+	lines := make([]string, len(art))
+	for i, line := range art {
+		if color == "" {
+			lines[i] = render.Center(line, width)
+		} else {
+			lines[i] = render.Center(color+line+"\x1b[0m", width)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// Diagnostic check results, in increasing order of severity.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// doctorCheck is the outcome of one `kairos doctor` diagnostic.
+type doctorCheck struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail"`
+}
+
+// zoneinfoDirs are the places the Go runtime looks for the system tz database on Unix.
+var zoneinfoDirs = []string{"/usr/share/zoneinfo/", "/usr/share/lib/zoneinfo/", "/usr/lib/locale/TZ/"}
+
+/**
+ * This function handles the `kairos doctor` command. It checks the tz database, every
+ * configured location, the terminal, and the config file, and prints a pass/fail report
+ * (or JSON with --json).
+ *
+ * @returns An error if any check failed, so that kairos exits non-zero.
+ */
+func runDoctor() error {
+	var checks []doctorCheck
+	checks = append(checks, checkTZData()...)
+	checks = append(checks, checkLocations()...)
+	checks = append(checks, checkTerminal()...)
+	checks = append(checks, checkConfigFile()...)
+	checks = append(checks, checkSecrets()...)
+
+	failed := 0
+	for _, c := range checks {
+		if c.Status == checkFail {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	} else {
+		printDoctorReport(checks)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// printDoctorReport prints the checks grouped by section, with colored status labels.
+func printDoctorReport(checks []doctorCheck) {
+	labels := map[string]string{
+		checkPass: "\x1b[32m PASS \x1b[0m",
+		checkWarn: "\x1b[33m WARN \x1b[0m",
+		checkFail: "\x1b[31m FAIL \x1b[0m",
+	}
+	section := ""
+	for _, c := range checks {
+		if c.Section != section {
+			section = c.Section
+			fmt.Printf("\n\x1b[36m\x1b[1m%s\x1b[0m\n", strings.ToUpper(section))
+		}
+		fmt.Printf("  %s %-22s \x1b[90m%s\x1b[0m\n", labels[c.Status], c.Name, c.Detail)
+	}
+	fmt.Println()
+}
+
+/**
+ * This function checks that the tz database can be loaded and reports where it comes
+ * from, since a missing database (common in minimal containers) makes every zone fail.
+ *
+ * @returns The tz database checks.
+ */
+func checkTZData() []doctorCheck {
+	section := "tz database"
+	var checks []doctorCheck
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		checks = append(checks, doctorCheck{section, "load", checkFail,
+			fmt.Sprintf("%v; install your system's tzdata package or set ZONEINFO", err)})
+	} else {
+		checks = append(checks, doctorCheck{section, "load", checkPass, "America/New_York loads"})
+	}
+
+	source, version := tzdataSource()
+	if version != "" {
+		source += " (version " + version + ")"
+	}
+	checks = append(checks, doctorCheck{section, "source", checkPass, source})
+
+	name, offset := time.Now().Zone()
+	detail := fmt.Sprintf("%s (%s, UTC%s)", detectLocalZone(), name, tzutil.FormatOffsetDiff(offset))
+	if _, err := os.Stat("/etc/localtime"); err != nil && os.Getenv("TZ") == "" && runtime.GOOS != "windows" {
+		checks = append(checks, doctorCheck{section, "local zone", checkWarn, detail + "; /etc/localtime is missing and TZ is not set"})
+	} else {
+		checks = append(checks, doctorCheck{section, "local zone", checkPass, detail})
+	}
+	return checks
+}
+
+// tzdataSource returns where the tz database in use comes from, the way the Go runtime looks for it, and its release if it records one.
+func tzdataSource() (source, version string) {
+	// A database installed with kairos tzupdate comes before all of them.
+	if _, err := os.Stat(getZoneinfoDir()); err == nil {
+		return getZoneinfoDir() + " (kairos tzupdate)", tzdataVersion(getZoneinfoDir())
+	}
+	if env := os.Getenv("ZONEINFO"); env != "" {
+		return "$ZONEINFO (" + env + ")", tzdataVersion(env)
+	}
+	if runtime.GOOS != "windows" {
+		for _, dir := range zoneinfoDirs {
+			if _, err := os.Stat(dir); err == nil {
+				return dir, tzdataVersion(dir)
+			}
+		}
+	}
+	return "embedded in the Go runtime", ""
+}
+
+// tzdataVersion reads the release (e.g. "2024a") from a zoneinfo directory, if it records one.
+func tzdataVersion(dir string) string {
+	// Releases, and the databases kairos tzupdate installs, keep it in a version file.
+	if data, err := os.ReadFile(filepath.Join(dir, "version")); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tzdata.zi"))
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(strings.TrimPrefix(line, "# version"))
+}
+
+/**
+ * This function validates the location of every configured timezone and the zone of
+ * every saved event.
+ *
+ * @returns One check per timezone and event.
+ */
+func checkLocations() []doctorCheck {
+	section := "locations"
+	if len(timezones) == 0 && len(events) == 0 {
+		return []doctorCheck{{section, "timezones", checkWarn, "none configured; add one with 'kairos add'"}}
+	}
+	var checks []doctorCheck
+	for _, tz := range timezones {
+		if loc, err := tzutil.LoadLocation(tz.Location); err != nil {
+			checks = append(checks, doctorCheck{section, tz.Name, checkFail, fmt.Sprintf("invalid location '%s': %v", tz.Location, err)})
+		} else {
+			checks = append(checks, doctorCheck{section, tz.Name, checkPass, loc.String()})
+		}
+	}
+	for _, e := range events {
+		if _, err := eventStart(e); err != nil {
+			checks = append(checks, doctorCheck{section, "event " + e.Title, checkFail, err.Error()})
+		} else {
+			checks = append(checks, doctorCheck{section, "event " + e.Title, checkPass, e.Start + " " + e.Zone})
+		}
+	}
+	return checks
+}
+
+/**
+ * This function checks that the terminal can show the dashboard: that output is a
+ * terminal, what it can draw (colors, UTF-8, and emoji; see termcaps.go), and the expected
+ * character widths for the clock digits and box drawing.
+ *
+ * @returns The terminal checks.
+ */
+func checkTerminal() []doctorCheck {
+	section := "terminal"
+	var checks []doctorCheck
+
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		checks = append(checks, doctorCheck{section, "tty", checkPass, "stdout is a terminal"})
+	} else {
+		checks = append(checks, doctorCheck{section, "tty", checkWarn, "stdout is not a terminal; the dashboard needs one"})
+	}
+
+	detectTermCaps()
+	checks = append(checks, termCapsChecks()...)
+
+	detectBackground()
+	switch {
+	case settings.Background != "":
+		checks = append(checks, doctorCheck{section, "background", checkPass, settings.Background + " (the background setting)"})
+	case detectedBackground != "":
+		checks = append(checks, doctorCheck{section, "background", checkPass, detectedBackground + " (from " + backgroundSource + ")"})
+	default:
+		checks = append(checks, doctorCheck{section, "background", checkWarn,
+			"not detected; if the colors are hard to read, run 'kairos config set background light' (or dark)"})
+	}
+
+	return checks
+}
+
+// firstEnv returns the first non-empty value among the given environment variables.
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+/**
+ * This function checks that the config file parses, is not writable by other users,
+ * and that its directory allows kairos to save changes and backups.
+ *
+ * @returns The config file checks.
+ */
+func checkConfigFile() []doctorCheck {
+	section := "config file"
+	path := getConfigPath()
+	checks := []doctorCheck{{section, "path", checkPass, path}}
+
+	fi, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		checks = append(checks, doctorCheck{section, "exists", checkWarn, "not created yet; it is written on the first change"})
+	case err != nil:
+		checks = append(checks, doctorCheck{section, "exists", checkFail, err.Error()})
+	case configErr != nil:
+		checks = append(checks, doctorCheck{section, "parse", checkFail, configErr.Error()})
+	default:
+		checks = append(checks, doctorCheck{section, "parse", checkPass,
+			fmt.Sprintf("%d timezone(s), %d event(s)", len(timezones), len(events))})
+	}
+
+	if err == nil {
+		perm := fi.Mode().Perm()
+		switch {
+		case runtime.GOOS == "windows":
+		case perm&0200 == 0:
+			checks = append(checks, doctorCheck{section, "permissions", checkFail, fmt.Sprintf("%#o: not writable, changes cannot be saved", perm)})
+		case holdsToken() && perm&0044 != 0:
+			checks = append(checks, doctorCheck{section, "permissions", checkWarn, fmt.Sprintf("%#o: holds an API token but is readable by other users; run chmod 600 %s", perm, path)})
+		case perm&0022 != 0:
+			checks = append(checks, doctorCheck{section, "permissions", checkWarn, fmt.Sprintf("%#o: writable by other users; run chmod 644 %s", perm, path)})
+		default:
+			checks = append(checks, doctorCheck{section, "permissions", checkPass, fmt.Sprintf("%#o", perm)})
+		}
+	}
+
+	logPath := getLogPath()
+	if f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		checks = append(checks, doctorCheck{section, "log file", checkWarn, fmt.Sprintf("cannot write %s: %v", logPath, err)})
+	} else {
+		f.Close()
+		checks = append(checks, doctorCheck{section, "log file", checkPass, logPath})
+	}
+
+	// Saves write a temporary file next to the config and rename it into place.
+	dir := filepath.Dir(path)
+	if tmp, err := os.CreateTemp(dir, ".kairos-doctor-*"); err != nil {
+		checks = append(checks, doctorCheck{section, "directory", checkFail, fmt.Sprintf("cannot write to %s: %v", dir, err)})
+	} else {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		checks = append(checks, doctorCheck{section, "directory", checkPass,
+			fmt.Sprintf("%s is writable (%d backup(s) kept)", dir, len(listBackups()))})
+	}
+	return checks
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/iamstoick/kairos/config"
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// configEditor returns the editor for `kairos edit`: $VISUAL, then $EDITOR, then vi (notepad on Windows).
+func configEditor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(name)); e != "" {
+			return e
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+/**
+ * This function handles the `kairos edit` command, in the manner of `crontab -e`: it
+ * copies the config, pretty-printed, to a temporary file and opens it in the editor.
+ * Once the editor exits the copy is validated, and only a valid config replaces the
+ * file (after a backup, so `kairos undo` reverts the edit); otherwise the problems are
+ * listed with their line numbers and the editor can be opened again. A running daemon
+ * picks up the saved file by itself.
+ *
+ * @returns An error if the editor fails or the edit is discarded.
+ */
+func runEdit() error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	original, err := os.ReadFile(getConfigPath())
+	if os.IsNotExist(err) {
+		original, _ = json.Marshal(Config{Version: config.CurrentVersion, Timezones: []TimezoneConfig{}})
+	} else if err != nil {
+		return fmt.Errorf("cannot read %s: %v", getConfigPath(), err)
+	}
+	// The single-line file kairos writes is spread out for editing; a broken one is left as it is.
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, original, "", "  ") == nil {
+		original = append(pretty.Bytes(), '\n')
+	}
+
+	tmp, err := os.CreateTemp("", "kairos-config-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create a temporary file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(original)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", tmp.Name(), err)
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		cmd := shellCommand(context.Background(), fmt.Sprintf("%s \"%s\"", configEditor(), tmp.Name()))
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("the editor '%s' failed: %v; the config is unchanged", configEditor(), err)
+		}
+		edited, err := os.ReadFile(tmp.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", tmp.Name(), err)
+		}
+		if bytes.Equal(edited, original) {
+			fmt.Println("No changes made.")
+			return nil
+		}
+
+		problems := configProblems(edited)
+		if len(problems) == 0 {
+			return saveEditedConfig(edited)
+		}
+		fmt.Fprintf(os.Stderr, "\x1b[31mThe edited config has %d problem(s):\x1b[0m\n", len(problems))
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, "  "+p)
+		}
+		fmt.Print("Edit again? [Y/n] ")
+		answer, err := in.ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a == "n" || a == "no" || (err != nil && a == "") {
+			return fmt.Errorf("edit discarded; the config is unchanged")
+		}
+	}
+}
+
+/**
+ * This function checks an edited config before it is saved: that it decodes, and that
+ * every timezone and event names a location kairos can load.
+ *
+ * @param data - The edited file.
+ * @returns The problems, each starting with its line number where it is known.
+ */
+func configProblems(data []byte) []string {
+	cfg, _, err := config.Parse(data)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	for _, tz := range cfg.Timezones {
+		if _, err := tzutil.LoadLocation(tz.Location); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: timezone '%s' has an invalid location '%s'", lineOf(data, tz.Location), tz.Name, tz.Location))
+		}
+	}
+	for _, e := range cfg.Events {
+		if _, err := eventStart(e); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: event '%s': %v", lineOf(data, e.Title), e.Title, err))
+		}
+	}
+	return problems
+}
+
+// lineOf returns the line of the first JSON string equal to s in data, or 0 if there is none.
+func lineOf(data []byte, s string) int {
+	quoted, _ := json.Marshal(s)
+	i := bytes.Index(data, quoted)
+	if i < 0 {
+		return 0
+	}
+	return bytes.Count(data[:i], []byte("\n")) + 1
+}
+
+/**
+ * This function replaces the config file with a validated edit, backing up the old one
+ * first. The edit is saved as it was typed, unless it holds a token in plain text; then
+ * the config is written the way kairos writes it, with the token encrypted.
+ *
+ * @param data - The edited file.
+ * @returns An error if the config cannot be saved.
+ */
+func saveEditedConfig(data []byte) error {
+	cfg, _, _ := config.Parse(data)
+	plain := false
+	for _, s := range secretSettings {
+		if v := *s.Field(&cfg.Settings); v != "" && !isSecretRef(v) {
+			plain = true
+		}
+	}
+	if plain {
+		timezones, events, settings, configErr = cfg.Timezones, cfg.Events, cfg.Settings, nil
+		resolveSecrets()
+		if err := saveConfig(); err != nil {
+			return err
+		}
+		fmt.Printf("Saved %s (%d timezone(s), %d event(s)) with its tokens encrypted. 'kairos undo' reverts the edit.\n", getConfigPath(), len(cfg.Timezones), len(cfg.Events))
+		return nil
+	}
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	// The edit started from the file as it was loaded; a change made meanwhile would be lost.
+	if err := checkConfigUnchanged(); err != nil {
+		return err
+	}
+	if err := backupConfig(); err != nil {
+		return fmt.Errorf("failed to back up the config: %v", err)
+	}
+	// The file mode follows the new settings, which may add or remove a token.
+	settings = cfg.Settings
+	resolveSecrets()
+	if err := writeFileAtomic(getConfigPath(), data, configFileMode()); err != nil {
+		return fmt.Errorf("failed to save the config: %v", err)
+	}
+	loadedConfigStamp = stampOf(data)
+	fmt.Printf("Saved %s (%d timezone(s), %d event(s)). 'kairos undo' reverts the edit.\n", getConfigPath(), len(cfg.Timezones), len(cfg.Events))
+	return nil
+}
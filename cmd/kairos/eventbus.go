@@ -0,0 +1,191 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// busTopic names a kind of event on the event bus.
+type busTopic string
+
+const (
+	// topicTick is published every second by the dashboard's clock and the daemon; its data is the time.
+	topicTick busTopic = "tick"
+	// topicZoneChanged is published when a zone is swapped into the primary view; its data is a zoneChange.
+	topicZoneChanged busTopic = "zone-changed"
+	// topicAlarmFired is published when an event's alarm goes off; its data is an alarmFired.
+	topicAlarmFired busTopic = "alarm-fired"
+	// topicConfigReloaded is published after the zones were loaded again: a daemon reload, a profile switch, or the daemon's zones reaching an attached dashboard.
+	topicConfigReloaded busTopic = "config-reloaded"
+	// topicStatsUpdated is published when a CPU or memory reading is taken; its data is a statsUpdate.
+	topicStatsUpdated busTopic = "stats-updated"
+)
+
+// zoneChange is the data of topicZoneChanged: the zone now in the primary view, and the one it replaced.
+type zoneChange struct {
+	Zone, Previous string
+}
+
+// alarmFired is the data of topicAlarmFired: the event, when it starts, and when its alarm went off.
+type alarmFired struct {
+	Title string    `json:"title"`
+	Start time.Time `json:"start"`
+	At    time.Time `json:"at"`
+}
+
+// statsUpdate is the data of topicStatsUpdated: which reading ("cpu" or "mem") and its footer text.
+type statsUpdate struct {
+	Name, Text string
+}
+
+// busEvent is one event as subscribers receive it.
+type busEvent struct {
+	Topic busTopic
+	Data  interface{}
+}
+
+// busSubscriber is a handler subscribed to a topic, with a name for the debug log.
+type busSubscriber struct {
+	id     int
+	name   string
+	handle func(busEvent)
+}
+
+// eventBus passes events from the part of kairos that notices them to the parts that act
+// on them, so that the clock, the stats worker, and the config loader need not know about
+// the hooks, integrations, and views that follow them.
+type eventBus struct {
+	mu     sync.RWMutex
+	nextID int
+	subs   map[busTopic][]busSubscriber
+}
+
+// bus is the process's event bus.
+var bus = &eventBus{subs: map[busTopic][]busSubscriber{}}
+
+/**
+ * This method subscribes a handler to a topic. Handlers run in the order they subscribed,
+ * on the goroutine that publishes the event: the GUI goroutine for ticks in the dashboard,
+ * so they may change what it shows, and a worker's for the stats.
+ *
+ * @param topic - The topic.
+ * @param name - What the handler does, for the log.
+ * @param handle - The handler.
+ * @returns A function that unsubscribes it.
+ */
+func (b *eventBus) Subscribe(topic busTopic, name string, handle func(busEvent)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subs[topic] = append(b.subs[topic], busSubscriber{id: id, name: name, handle: handle})
+	logger.Debug("event subscriber added", "topic", topic, "subscriber", name)
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		list := b.subs[topic]
+		for i, s := range list {
+			if s.id == id {
+				b.subs[topic] = append(list[:i:i], list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish hands an event to the topic's subscribers, in order, and returns once they all have.
+func (b *eventBus) Publish(topic busTopic, data interface{}) {
+	b.mu.RLock()
+	list := b.subs[topic]
+	b.mu.RUnlock()
+	for _, s := range list {
+		s.handle(busEvent{Topic: topic, Data: data})
+	}
+}
+
+// subscribeAll subscribes handlers and returns a function that unsubscribes them all.
+func subscribeAll(subs map[busTopic][]busSubscriber) func() {
+	var unsubscribe []func()
+	for _, topic := range []busTopic{topicTick, topicZoneChanged, topicAlarmFired, topicConfigReloaded, topicStatsUpdated} {
+		for _, s := range subs[topic] {
+			unsubscribe = append(unsubscribe, bus.Subscribe(topic, s.name, s.handle))
+		}
+	}
+	return func() {
+		for _, f := range unsubscribe {
+			f()
+		}
+	}
+}
+
+// onTick adapts a check that runs every second, such as checkChime, to a tick handler.
+func onTick(name string, check func(now time.Time)) busSubscriber {
+	return busSubscriber{name: name, handle: func(e busEvent) { check(e.Data.(time.Time)) }}
+}
+
+/**
+ * This function subscribes what follows the events everywhere kairos runs: the footer's
+ * stats, the hooks, the MQTT publisher, the alarm alerts, and the log of fired alarms. It is called once at startup
+ * by the dashboard and the daemon, and by each command that publishes events.
+ *
+ * @returns A function that unsubscribes them.
+ */
+func subscribeComponents() func() {
+	return subscribeAll(map[busTopic][]busSubscriber{
+		topicStatsUpdated: {
+			{name: "footer stats", handle: func(e busEvent) {
+				u := e.Data.(statsUpdate)
+				if u.Name == "cpu" {
+					appState.SetCPU(u.Text)
+				} else {
+					appState.SetMEM(u.Text)
+				}
+			}},
+		},
+		topicZoneChanged: {
+			{name: "swap hook", handle: func(e busEvent) {
+				c := e.Data.(zoneChange)
+				runHook("swap", map[string]string{"ZONE": c.Zone, "PREVIOUS": c.Previous})
+			}},
+		},
+		topicAlarmFired: {
+			{name: "MQTT alarm", handle: func(e busEvent) {
+				a := e.Data.(alarmFired)
+				publishMQTT(mqttTopic("events"), map[string]interface{}{
+					"type": "alarm", "title": a.Title, "start": a.Start.UTC().Format(time.RFC3339), "at": a.At.UTC().Format(time.RFC3339),
+				}, false)
+			}},
+			{name: "alarm hook", handle: func(e busEvent) {
+				a := e.Data.(alarmFired)
+				runHook("alarm", map[string]string{"TITLE": a.Title, "START": a.Start.Format(time.RFC3339)})
+			}},
+			{name: "alarm alert", handle: func(e busEvent) { showEventAlarm(e.Data.(alarmFired)) }},
+			{name: "alarm log", handle: func(e busEvent) { recordAlarm(e.Data.(alarmFired)) }},
+		},
+		topicConfigReloaded: {
+			{name: "MQTT republish", handle: func(busEvent) { resetMQTTState() }},
+		},
+	})
+}
+
+/**
+ * This function subscribes the checks that fire on time to the tick: notifications,
+ * alarms, the chime, the hour hook, the MQTT publisher, and the announcements. The
+ * dashboard subscribes them unless it is attached to a daemon, which then runs them.
+ *
+ * @returns A function that unsubscribes them.
+ */
+func subscribeTimers() func() {
+	return subscribeAll(map[busTopic][]busSubscriber{
+		topicTick: {
+			onTick("prayer notifications", checkPrayerNotifications),
+			onTick("awake notifications", checkAwakeNotifications),
+			onTick("handoff notifications", checkHandoffNotifications),
+			onTick("event alarms", checkEventAlarms),
+			onTick("chime", checkChime),
+			onTick("hour hook", checkHourHook),
+			onTick("MQTT", checkMQTT),
+			onTick("announcements", checkAnnouncements),
+		},
+	})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/workhours"
+)
+
+// eventTimeLayout is the format used to store event start times.
+const eventTimeLayout = "2006-01-02 15:04"
+
+// lastAlarmCheck remembers the last instant checked for event alarms.
+var lastAlarmCheck time.Time
+
+// maxFiredAlarms is how many fired alarms firedAlarms keeps; older ones are dropped.
+const maxFiredAlarms = 100
+
+// firedAlarms are the event alarms that went off while kairos ran, oldest first: here, or in
+// the daemon an attached dashboard follows, which shares its own (see applyDaemonState).
+var firedAlarms []alarmFired
+
+// recordAlarm adds a fired alarm to firedAlarms, unless it is already there.
+func recordAlarm(a alarmFired) {
+	for _, f := range firedAlarms {
+		if f.Title == a.Title && f.Start.Equal(a.Start) && f.At.Equal(a.At) {
+			return
+		}
+	}
+	firedAlarms = append(firedAlarms, a)
+	if len(firedAlarms) > maxFiredAlarms {
+		firedAlarms = firedAlarms[len(firedAlarms)-maxFiredAlarms:]
+	}
+}
+
+/**
+ * This function resolves an event's start into an instant.
+ *
+ * @param e - The event.
+ * @returns The start instant in the event's location, or an error if the zone or time is invalid.
+ */
+func eventStart(e EventConfig) (time.Time, error) {
+	loc, _, err := resolveZone(e.Zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	wall, err := time.Parse(eventTimeLayout, e.Start)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("event '%s' has an invalid start '%s' (expected YYYY-MM-DD HH:MM)", e.Title, e.Start)
+	}
+	// Repeated wall-clock times resolve to their first occurrence, as when the event was added.
+	t, _ := tzutil.ResolveWallClock(wall, loc)
+	return t, nil
+}
+
+/**
+ * This function returns when an event's alarm fires.
+ *
+ * @param e - The event.
+ * @returns The alarm instant, and false if the event has no (valid) alarm.
+ */
+func eventAlarmTime(e EventConfig) (time.Time, bool) {
+	if e.Alarm == "" {
+		return time.Time{}, false
+	}
+	before, err := time.ParseDuration(e.Alarm)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, err := eventStart(e)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start.Add(-before), true
+}
+
+// eventCommand builds the `kairos event` command group.
+func eventCommand() *command {
+	return &command{
+		Name:  "event",
+		Short: "Manages saved events and alarms",
+		Subcommands: []*command{
+			{Name: "add", Usage: `"Title" "YYYY-MM-DD HH:MM" "Zone" [duration-minutes] [alarm]`, MinArgs: 3, MaxArgs: 5,
+				Short: "Saves an event; an alarm such as 10m notifies in the dashboard before it starts", Run: addEvent},
+			{Name: "list", Short: "Lists saved events", Run: func(args []string) error { return listEvents() }},
+			{Name: "remove", Usage: `"Title"`, MinArgs: 1, MaxArgs: 1, Short: "Removes a saved event",
+				Run: func(args []string) error { return removeEvent(args[0]) }},
+		},
+	}
+}
+
+/**
+ * This function handles `kairos event add`.
+ *
+ * @param args - "Title" "Time" "Zone" [duration-minutes] [alarm].
+ * @returns An error if any argument is invalid or the config cannot be saved.
+ */
+func addEvent(args []string) error {
+	e := EventConfig{Title: args[0], Zone: args[2]}
+	loc, _, err := resolveZone(e.Zone)
+	if err != nil {
+		return err
+	}
+	start, note, err := parseLocalTime(args[1], loc)
+	if err != nil {
+		return err
+	}
+	if note != "" {
+		fmt.Printf("\x1b[33mNote: %s\x1b[0m\n", note)
+	}
+	e.Start = start.In(loc).Format(eventTimeLayout)
+	if len(args) >= 4 {
+		minutes, err := strconv.Atoi(args[3])
+		if err != nil || minutes < 0 {
+			return fmt.Errorf("invalid duration '%s' (expected minutes)", args[3])
+		}
+		e.Duration = minutes
+	}
+	if len(args) == 5 {
+		if _, err := time.ParseDuration(args[4]); err != nil {
+			return fmt.Errorf("invalid alarm '%s' (expected a duration such as 10m)", args[4])
+		}
+		e.Alarm = args[4]
+	}
+	events = append(events, e)
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Added event %s successfully!\n", e.Title)
+	return nil
+}
+
+// listEvents handles `kairos event list`.
+func listEvents() error {
+	if len(events) == 0 {
+		fmt.Println("No events configured.")
+		return nil
+	}
+	fmt.Println("\n\x1b[36m\x1b[1mCONFIGURED EVENTS\x1b[0m")
+	fmt.Printf("%-20s %-18s %-15s %-8s %s\n", "TITLE", "START", "ZONE", "LENGTH", "ALARM")
+	fmt.Println(strings.Repeat("-", 72))
+	for _, e := range events {
+		alarm := "-"
+		if e.Alarm != "" {
+			alarm = e.Alarm + " before"
+		}
+		fmt.Printf("%-20s %-18s %-15s %-8s %s\n", e.Title, e.Start, e.Zone, fmt.Sprintf("%dm", e.Duration), alarm)
+	}
+	fmt.Println()
+	return nil
+}
+
+/**
+ * This function handles `kairos event remove`, deleting every event with the given title.
+ *
+ * @param title - The event title.
+ * @returns An error if no event has that title or the config cannot be saved.
+ */
+func removeEvent(title string) error {
+	var kept []EventConfig
+	for _, e := range events {
+		if e.Title != title {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(events) {
+		return fmt.Errorf("event '%s' not found", title)
+	}
+	events = kept
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed event %s successfully!\n", title)
+	return nil
+}
+
+/**
+ * This function publishes an alarm-fired event for every event alarm that went off since
+ * the previous check, which shows it in the footer, runs the alarm hook, and publishes it
+ * over MQTT (see eventbus.go). It is called on every tick.
+ *
+ * @param now - The current time.
+ */
+func checkEventAlarms(now time.Time) {
+	since := lastAlarmCheck
+	lastAlarmCheck = now
+	if since.IsZero() {
+		return
+	}
+	for _, e := range events {
+		at, ok := eventAlarmTime(e)
+		if !ok || !at.After(since) || at.After(now) {
+			continue
+		}
+		start, _ := eventStart(e)
+		bus.Publish(topicAlarmFired, alarmFired{Title: e.Title, Start: start, At: now})
+	}
+}
+
+// showEventAlarm shows a fired alarm in the footer, with the time until its event starts.
+func showEventAlarm(a alarmFired) {
+	if until := a.Start.Sub(a.At); until > time.Minute {
+		showAlert(fmt.Sprintf("⏰ %s starts in %s", a.Title, workhours.FormatCountdown(until)))
+	} else {
+		showAlert(fmt.Sprintf("⏰ %s is starting now", a.Title))
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/iamstoick/kairos/workhours"
+)
+
+/**
+ * This function prints the recap of a dashboard session when it quits with exit_summary on:
+ * how long it ran, the event alarms that went off meanwhile (here, or in the daemon it was
+ * attached to; see firedAlarms), the work sessions stopped, the stopwatch if it ran, and the
+ * next saved event in each zone. It is plain text, so that it reads the same in a scrollback
+ * or a log, and it is printed once the terminal is restored.
+ *
+ * @param started - When the dashboard was opened.
+ * @param now - When it quit.
+ */
+func printExitSummary(started, now time.Time) {
+	local := now.In(localZone())
+	fmt.Printf("kairos session: %s (%s-%s)\n", workhours.FormatCountdown(now.Sub(started)),
+		started.In(localZone()).Format("15:04"), local.Format("15:04"))
+
+	var alarms []string
+	for _, a := range firedAlarms {
+		if a.At.After(started) && !a.At.After(now) {
+			alarms = append(alarms, a.Title)
+		}
+	}
+	if len(alarms) == 0 {
+		fmt.Println("Alarms fired: none")
+	} else {
+		fmt.Printf("Alarms fired: %d (%s)\n", len(alarms), strings.Join(alarms, ", "))
+	}
+
+	// The sessions are read again, since `kairos track stop` may have stopped one from another shell.
+	list, err := readSessions()
+	if err != nil {
+		list = sessions
+	}
+	completed, tracked := 0, time.Duration(0)
+	for _, s := range list {
+		if s.End != nil && s.End.After(started) && !s.End.After(now) {
+			completed++
+			tracked += sessionDuration(s, now)
+		}
+	}
+	if completed == 0 {
+		fmt.Println("Timers completed: none")
+	} else {
+		fmt.Printf("Timers completed: %d tracked session(s), %s\n", completed, formatElapsed(tracked))
+	}
+	if elapsed := watch.Elapsed(now); elapsed > 0 {
+		fmt.Printf("Stopwatch: %s, %d lap(s)\n", formatStopwatch(elapsed), len(watch.Laps))
+	}
+
+	fmt.Println("Next events:")
+	for _, line := range nextEventLines(now) {
+		fmt.Println("  " + line)
+	}
+}
+
+/**
+ * This function lists the next saved event of every configured zone, in the config's order,
+ * and then of the other zones that events name (such as "UTC"). Each start is given in the
+ * zone's local time and hour format, with the time until it.
+ *
+ * @param now - The current time.
+ * @returns A line per zone, e.g. "PHL   Standup  Mon 19 Oct 9:30 AM (in 17h 16m)".
+ */
+func nextEventLines(now time.Time) []string {
+	next := map[string]EventConfig{}
+	starts := map[string]time.Time{}
+	for _, e := range events {
+		start, err := eventStart(e)
+		if err != nil || !start.After(now) {
+			continue
+		}
+		_, label, err := resolveZone(e.Zone)
+		if err != nil {
+			continue
+		}
+		if prev, ok := starts[label]; !ok || start.Before(prev) {
+			next[label], starts[label] = e, start
+		}
+	}
+	var labels, others []string
+	for _, tz := range timezones {
+		labels = append(labels, tz.Name)
+	}
+	for label := range next {
+		if _, _, err := findZone(label); err != nil {
+			others = append(others, label)
+		}
+	}
+	sort.Strings(others)
+	labels = append(labels, others...)
+	width, titleWidth := 0, 0
+	for _, label := range labels {
+		width = max(width, render.TextWidth(label))
+		titleWidth = max(titleWidth, render.TextWidth(next[label].Title))
+	}
+	var lines []string
+	for _, label := range labels {
+		e, ok := next[label]
+		if !ok {
+			lines = append(lines, padCell(label, width+2)+"no upcoming event")
+			continue
+		}
+		start := starts[label]
+		clock := start.Format("Mon 2 Jan 15:04")
+		if tz, _, err := findZone(label); err == nil && !uses24Hour(tz) {
+			clock = start.Format("Mon 2 Jan 3:04 PM")
+		}
+		lines = append(lines, fmt.Sprintf("%s%s%s (%s)", padCell(label, width+2), padCell(e.Title, titleWidth+2), clock, formatRelative(start.Sub(now))))
+	}
+	return lines
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// parsedTimestamp holds the result of parsing a free-form timestamp.
+type parsedTimestamp struct {
+	// Time is the parsed instant. For naive timestamps it is expressed in UTC
+	// and must be reinterpreted in the zone the reader assumes it came from.
+	Time time.Time
+	// Format is a human-readable description of the detected format.
+	Format string
+	// Naive is true when the input carried no zone or offset information.
+	Naive bool
+	// LeapSecond is true when the input used the :60 leap-second notation.
+	LeapSecond bool
+}
+
+// timestampLayout pairs a Go reference layout with a readable description.
+type timestampLayout struct {
+	layout string
+	name   string
+	naive  bool
+}
+
+var (
+	// Layouts tried against the whole input, most specific first.
+	timestampLayouts = []timestampLayout{
+		{time.RFC3339Nano, "RFC 3339 / ISO 8601", false},
+		{"2006-01-02T15:04:05Z0700", "ISO 8601 (basic offset)", false},
+		{time.RFC1123Z, "RFC 1123 with numeric zone", false},
+		{time.RFC1123, "RFC 1123", false},
+		{time.RFC822Z, "RFC 822 with numeric zone", false},
+		{time.RFC822, "RFC 822", false},
+		{"Mon, 2 Jan 2006 15:04:05 -0700", "RFC 2822", false},
+		{"2 Jan 2006 15:04:05 -0700", "RFC 2822 (no weekday)", false},
+		{time.UnixDate, "Unix date", false},
+		{time.RubyDate, "Ruby date", false},
+		{"2006-01-02 15:04:05 -0700", "date time offset", false},
+		{"2006-01-02 15:04:05 MST", "date time zone", false},
+		{"02/Jan/2006:15:04:05 -0700", "Common Log Format", false},
+		{"2006-01-02T15:04:05.999999999", "ISO 8601 (local)", true},
+		{"2006-01-02 15:04:05.999999999", "date time", true},
+		{"2006-01-02T15:04", "ISO 8601 (local, minutes)", true},
+		{"2006-01-02 15:04", "date time (minutes)", true},
+		{time.ANSIC, "ANSI C", true},
+		{time.Stamp, "syslog stamp", true},
+		{"2006-01-02", "calendar date", true},
+	}
+
+	// Matches the :60 seconds field used to denote a leap second.
+	leapSecondPattern = regexp.MustCompile(`(\d{2}:\d{2}):60`)
+
+	// Matches a date and time embedded in a larger string, such as a filename.
+	// Separators between the fields are optional, so both
+	// "backup_20250701_0930" and "log-2025-07-01T09-30-15" are recognized.
+	embeddedPattern = regexp.MustCompile(`(\d{4})[-_.]?(\d{2})[-_.]?(\d{2})(?:[T_ .-]?(\d{2})[-_:.]?(\d{2})(?:[-_:.]?(\d{2}))?)?`)
+
+	// Matches a bare Unix epoch in seconds, milliseconds, microseconds or nanoseconds.
+	epochPattern = regexp.MustCompile(`^-?\d{9,19}$`)
+)
+
+/**
+ * This function parses a timestamp in any of the common formats found in logs and filenames.
+ * It handles Unix epochs, RFC 3339/ISO 8601, RFC 1123/2822, syslog stamps, leap-second
+ * notation (23:59:60) and dates embedded in filenames such as "backup_20250701_0930".
+ *
+ * @param input - The raw timestamp string.
+ * @returns The parsed timestamp, or an error if no known format matched.
+ */
+func parseTimestamp(input string) (parsedTimestamp, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return parsedTimestamp{}, fmt.Errorf("empty timestamp")
+	}
+
+	// Unix epochs are recognized by their digit count.
+	if epochPattern.MatchString(s) {
+		return parseEpoch(s)
+	}
+
+	// Go's time package rejects a seconds field of 60, so the leap second is
+	// parsed as :59 and the extra second is added back afterwards.
+	leap := false
+	if leapSecondPattern.MatchString(s) {
+		s = leapSecondPattern.ReplaceAllString(s, "$1:59")
+		leap = true
+	}
+
+	for _, l := range timestampLayouts {
+		t, err := time.Parse(l.layout, s)
+		if err != nil {
+			continue
+		}
+		// The syslog stamp has no year, so assume the current one.
+		if l.layout == time.Stamp {
+			t = t.AddDate(appClock.Now(t.Location()).Year(), 0, 0)
+		}
+		if leap {
+			t = t.Add(time.Second)
+		}
+		return parsedTimestamp{Time: t, Format: l.name, Naive: l.naive, LeapSecond: leap}, nil
+	}
+
+	// Fall back to searching for a date embedded in a longer string.
+	if m := embeddedPattern.FindStringSubmatch(s); m != nil {
+		fields := make([]int, 6)
+		for i := range fields {
+			fields[i], _ = strconv.Atoi(m[i+1])
+		}
+		t := time.Date(fields[0], time.Month(fields[1]), fields[2], fields[3], fields[4], fields[5], 0, time.UTC)
+		// time.Date normalizes out-of-range values, so reject anything that moved.
+		if t.Month() != time.Month(fields[1]) || t.Day() != fields[2] || fields[3] > 23 || fields[4] > 59 || fields[5] > 59 {
+			return parsedTimestamp{}, fmt.Errorf("embedded date %q is not a valid calendar time", m[0])
+		}
+		// The leap second counts only if it is the seconds of the date found, not a :60 elsewhere.
+		leap = leap && strings.HasSuffix(m[0], ":59")
+		if leap {
+			t = t.Add(time.Second)
+		}
+		return parsedTimestamp{Time: t, Format: fmt.Sprintf("embedded date (%s)", m[0]), Naive: true, LeapSecond: leap}, nil
+	}
+
+	return parsedTimestamp{}, fmt.Errorf("unrecognized timestamp format: %q", input)
+}
+
+/**
+ * This function parses a time given on the command line relative to a location.
+ * A bare "15:04" means that time today in the location, naive timestamps are read as
+ * wall-clock time in the location, and timestamps with an offset keep their instant.
+ *
+ * @param input - The time string, e.g. "2026-01-15 16:00" or "09:30".
+ * @param loc - The location in which to interpret wall-clock times.
+ * @returns The resolved instant, a note if the wall-clock time is skipped or repeated by a DST change, and any parse error.
+ */
+func parseLocalTime(input string, loc *time.Location) (time.Time, string, error) {
+	if clock, err := time.Parse("15:04", strings.TrimSpace(input)); err == nil {
+		now := appClock.Now(loc)
+		t, note := tzutil.ResolveWallClock(time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC), loc)
+		return t, note, nil
+	}
+	ts, err := parseTimestamp(input)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	if !ts.Naive {
+		return ts.Time, "", nil
+	}
+	t, note := tzutil.ResolveWallClock(ts.Time, loc)
+	return t, note, nil
+}
+
+/**
+ * This function parses a Unix epoch, inferring its unit from the number of digits.
+ *
+ * @param s - The epoch as a string of digits.
+ * @returns The parsed timestamp in UTC.
+ */
+func parseEpoch(s string) (parsedTimestamp, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return parsedTimestamp{}, fmt.Errorf("invalid epoch %q: %v", s, err)
+	}
+	digits := len(strings.TrimPrefix(s, "-"))
+	switch {
+	case digits <= 10:
+		return parsedTimestamp{Time: time.Unix(n, 0).UTC(), Format: "Unix epoch (seconds)"}, nil
+	case digits <= 13:
+		return parsedTimestamp{Time: time.UnixMilli(n).UTC(), Format: "Unix epoch (milliseconds)"}, nil
+	case digits <= 16:
+		return parsedTimestamp{Time: time.UnixMicro(n).UTC(), Format: "Unix epoch (microseconds)"}, nil
+	default:
+		return parsedTimestamp{Time: time.Unix(0, n).UTC(), Format: "Unix epoch (nanoseconds)"}, nil
+	}
+}
+
+/**
+ * This function handles the `kairos explain` command.
+ * It parses the timestamp and prints what it means in every configured timezone.
+ * Timestamps with an explicit offset are converted to each zone; naive timestamps
+ * are reinterpreted as if they had been written in each zone, which is the usual
+ * question when reading logs or filenames produced in another region.
+ *
+ * @param input - The raw timestamp string.
+ * @returns An error if the timestamp cannot be parsed.
+ */
+func explainTimestamp(input string) error {
+	ts, err := parseTimestamp(input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\x1b[36m\x1b[1mEXPLAIN\x1b[0m %s\n", input)
+	fmt.Printf("Detected format: %s\n", ts.Format)
+	if ts.LeapSecond {
+		fmt.Println("\x1b[33mLeap-second notation (:60) normalized to the following second.\x1b[0m")
+	}
+
+	if len(timezones) == 0 {
+		fmt.Printf("UTC: %s\n", ts.Time.UTC().Format(time.RFC3339))
+		fmt.Println("\x1b[90mNo timezones configured; add some to see local interpretations.\x1b[0m")
+		return nil
+	}
+
+	if !ts.Naive {
+		// The input identifies a single instant; show it everywhere.
+		fmt.Printf("Instant (UTC): %s\n\n", ts.Time.UTC().Format(time.RFC3339))
+		printInstantTable(ts.Time)
+		return nil
+	}
+
+	// The input has no zone, so show what it means if each zone wrote it.
+	fmt.Println("No zone information: showing the instant it denotes if written in each zone.")
+	fmt.Println()
+	fmt.Printf("%-15s %-28s %s\n", "IF WRITTEN IN", "UTC INSTANT", "RELATIVE")
+	fmt.Println(strings.Repeat("-", 60))
+	for _, tz := range timezones {
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			fmt.Printf("%-15s \x1b[31minvalid location %q\x1b[0m\n", tz.Name, tz.Location)
+			continue
+		}
+		local, note := tzutil.ResolveWallClock(ts.Time, loc)
+		fmt.Printf("%-15s %-28s %s\n", tz.Name, local.UTC().Format(time.RFC3339), formatRelative(untilNow(local)))
+		if note != "" {
+			fmt.Printf("%-15s \x1b[33m%s\x1b[0m\n", "", note)
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+/**
+ * This function handles the `kairos parse` command.
+ * It accepts an epoch, ISO 8601 string, or RFC 2822 date and prints the instant in every
+ * configured timezone together with how far it is from now. Timestamps without a zone
+ * are read as UTC, which is what most log lines mean.
+ *
+ * @param input - The raw timestamp string.
+ * @returns An error if the timestamp cannot be parsed.
+ */
+func printParse(input string) error {
+	ts, err := parseTimestamp(input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n\x1b[36m\x1b[1mPARSE\x1b[0m %s\n", input)
+	fmt.Printf("Detected format: %s\n", ts.Format)
+	if ts.Naive {
+		fmt.Println("\x1b[33mNo zone information; assuming UTC.\x1b[0m")
+	}
+	fmt.Printf("UTC:      %s\n", ts.Time.UTC().Format(time.RFC3339Nano))
+	fmt.Printf("Epoch:    %d\n", ts.Time.Unix())
+	fmt.Printf("Relative: %s\n\n", formatRelative(untilNow(ts.Time)))
+	printInstantTable(ts.Time)
+	return nil
+}
+
+/**
+ * This function prints a table of an instant's local time and offset in every configured timezone.
+ *
+ * @param t - The instant to show.
+ */
+func printInstantTable(t time.Time) {
+	if len(timezones) == 0 {
+		fmt.Println("\x1b[90mNo timezones configured; add some to see local times.\x1b[0m")
+		return
+	}
+	fmt.Printf("%-15s %-32s %s\n", "NAME", "LOCAL TIME", "OFFSET")
+	fmt.Println(strings.Repeat("-", 60))
+	for _, tz := range timezones {
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			fmt.Printf("%-15s \x1b[31minvalid location %q\x1b[0m\n", tz.Name, tz.Location)
+			continue
+		}
+		local := t.In(loc)
+		fmt.Printf("%-15s %-32s %s\n", tz.Name, local.Format("Mon, 02 Jan 2006 15:04:05 MST"), local.Format("-07:00"))
+	}
+	fmt.Println()
+}
+
+/**
+ * This function formats a duration relative to now as "in 3h 12m" or "3h 12m ago".
+ *
+ * @param d - The duration between now and the instant (positive for future instants).
+ * @returns The relative description.
+ */
+func formatRelative(d time.Duration) string {
+	future := d >= 0
+	if !future {
+		d = -d
+	}
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	var text string
+	switch {
+	case days > 0:
+		text = fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		text = fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		text = fmt.Sprintf("%dm", minutes)
+	}
+	if text == "0m" {
+		return "now"
+	}
+	if future {
+		return "in " + text
+	}
+	return text + " ago"
+}
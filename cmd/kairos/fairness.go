@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/zones"
+)
+
+var (
+	// fairnessCount is set by `kairos fairness --count`.
+	fairnessCount int
+	// fairnessEvery is set by `kairos fairness --every`.
+	fairnessEvery int
+)
+
+// fairnessCommand builds the `kairos fairness` command.
+func fairnessCommand() *command {
+	return &command{
+		Name:    "fairness",
+		Usage:   `"Time" "Zone"`,
+		Short:   "Scores a recurring meeting time for every zone and suggests a fair rotation (--count, --every)",
+		MinArgs: 2, MaxArgs: 2,
+		Flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&fairnessCount, "count", 8, "How many occurrences to plan the rotation for")
+			fs.IntVar(&fairnessEvery, "every", 7, "Days between occurrences")
+		},
+		Run: func(args []string) error { return printFairness(args[0], args[1]) },
+	}
+}
+
+// Meeting pain scores, from a time in the zone's business hours to one in the night.
+const (
+	painNone  = 0
+	painLate  = 1
+	painNight = 3
+)
+
+// meetingPain scores how unfriendly a local meeting time is: none in business hours, late while awake or in a focus block, night outside the humane hours.
+func meetingPain(tz TimezoneConfig, local time.Time) int {
+	switch {
+	case zones.BusinessHours(tz).Contains(local) && !isHoliday(tz, local) && !zones.InFocusBlock(tz, local, local.Add(time.Minute)):
+		return painNone
+	case zones.AwakeHours(tz).CoversClock(local):
+		return painLate
+	}
+	return painNight
+}
+
+// painLabel describes a pain score for the score table.
+func painLabel(pain int) string {
+	switch pain {
+	case painNone:
+		return "\x1b[32mbusiness hours\x1b[0m"
+	case painLate:
+		return "\x1b[33moutside business hours\x1b[0m"
+	}
+	return "\x1b[31mlate night or early morning\x1b[0m"
+}
+
+// fairnessSlot is one occurrence of the rotation: when it is held, and the pain for each zone.
+type fairnessSlot struct {
+	Start time.Time
+	Pain  []int
+}
+
+/**
+ * This function plans a rotation of a recurring meeting. Each occurrence can be held at
+ * the meeting's time or moved by whole hours; it picks, one occurrence after the other,
+ * the hour that keeps the worst total pain of any zone lowest, then the total pain of all
+ * zones, preferring the original time on a tie. Zones thus take turns with the
+ * inconvenient hours instead of one office always taking them.
+ *
+ * @param zones - The zones attending.
+ * @param locs - Their locations.
+ * @param first - The first occurrence at the meeting's time.
+ * @param loc - The meeting's zone, whose wall-clock time it recurs at.
+ * @param count - The number of occurrences.
+ * @param every - The days between occurrences.
+ * @returns The occurrences, and each zone's total pain.
+ */
+func planRotation(zones []TimezoneConfig, locs []*time.Location, first time.Time, loc *time.Location, count, every int) ([]fairnessSlot, []int) {
+	totals := make([]int, len(zones))
+	var slots []fairnessSlot
+	local := first.In(loc)
+	for n := 0; n < count; n++ {
+		day := local.AddDate(0, 0, n*every)
+		var best fairnessSlot
+		bestWorst, bestSum := -1, 0
+		for shift := 0; shift < 24; shift++ {
+			wall := time.Date(day.Year(), day.Month(), day.Day(), (local.Hour()+shift)%24, local.Minute(), 0, 0, time.UTC)
+			start, _ := tzutil.ResolveWallClock(wall, loc)
+			slot := fairnessSlot{Start: start, Pain: make([]int, len(zones))}
+			worst, sum := 0, 0
+			for i, tz := range zones {
+				slot.Pain[i] = meetingPain(tz, start.In(locs[i]))
+				worst = max(worst, totals[i]+slot.Pain[i])
+				sum += slot.Pain[i]
+			}
+			if bestWorst < 0 || worst < bestWorst || (worst == bestWorst && sum < bestSum) {
+				best, bestWorst, bestSum = slot, worst, sum
+			}
+		}
+		for i, pain := range best.Pain {
+			totals[i] += pain
+		}
+		slots = append(slots, best)
+	}
+	return slots, totals
+}
+
+/**
+ * This function handles `kairos fairness`: it scores a recurring meeting time for every
+ * configured zone (a night-time meeting counts more than one after hours), and suggests a
+ * rotation over the next occurrences that spreads the inconvenient hours across the
+ * offices, comparing each zone's total with keeping the time fixed.
+ *
+ * @param when - The meeting time, e.g. "16:00" or "2026-01-15 16:00" for the first occurrence.
+ * @param zone - The zone the meeting recurs in, by name or location.
+ * @returns An error if the time, zone, or flags are invalid, or no zones are configured.
+ */
+func printFairness(when, zone string) error {
+	if fairnessCount < 1 || fairnessCount > 52 {
+		return fmt.Errorf("invalid --count %d (expected 1-52)", fairnessCount)
+	}
+	if fairnessEvery < 1 {
+		return fmt.Errorf("invalid --every %d (expected a number of days)", fairnessEvery)
+	}
+	loc, label, err := resolveZone(zone)
+	if err != nil {
+		return err
+	}
+	first, _, err := parseLocalTime(when, loc)
+	if err != nil {
+		return err
+	}
+	var attending []TimezoneConfig
+	var locs []*time.Location
+	for _, tz := range timezones {
+		if l, err := tzutil.LoadLocation(tz.Location); err == nil {
+			attending = append(attending, tz)
+			locs = append(locs, l)
+		}
+	}
+	if len(attending) == 0 {
+		return fmt.Errorf("No timezones configured. Use 'kairos help' to see how to add some.")
+	}
+
+	// Keeping the meeting at its time is the baseline the rotation is compared with.
+	fixed := make([]int, len(attending))
+	for n := 0; n < fairnessCount; n++ {
+		local := first.In(loc)
+		start, _ := tzutil.ResolveWallClock(time.Date(local.Year(), local.Month(), local.Day()+n*fairnessEvery, local.Hour(), local.Minute(), 0, 0, time.UTC), loc)
+		for i, tz := range attending {
+			fixed[i] += meetingPain(tz, start.In(locs[i]))
+		}
+	}
+	slots, totals := planRotation(attending, locs, first, loc, fairnessCount, fairnessEvery)
+
+	if jsonOutput {
+		type zonePain struct {
+			Zone     string `json:"zone"`
+			Local    string `json:"local"`
+			Pain     int    `json:"pain"`
+			Fixed    int    `json:"fixed_total"`
+			Rotation int    `json:"rotation_total"`
+		}
+		type occurrence struct {
+			Start string         `json:"start"`
+			Pain  map[string]int `json:"pain"`
+		}
+		out := struct {
+			Time     string       `json:"time"`
+			Zone     string       `json:"zone"`
+			Zones    []zonePain   `json:"zones"`
+			Rotation []occurrence `json:"rotation"`
+		}{Time: first.Format(time.RFC3339), Zone: label}
+		for i, tz := range attending {
+			out.Zones = append(out.Zones, zonePain{tz.Name, first.In(locs[i]).Format(time.RFC3339), meetingPain(tz, first.In(locs[i])), fixed[i], totals[i]})
+		}
+		for _, s := range slots {
+			o := occurrence{Start: s.Start.Format(time.RFC3339), Pain: map[string]int{}}
+			for i, tz := range attending {
+				o.Pain[tz.Name] = s.Pain[i]
+			}
+			out.Rotation = append(out.Rotation, o)
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("\n\x1b[36m\x1b[1mMEETING FAIRNESS\x1b[0m %s %s, every %d days\n", first.In(loc).Format("15:04"), label, fairnessEvery)
+	for i, tz := range attending {
+		local := first.In(locs[i])
+		label := painLabel(meetingPain(tz, local))
+		if zones.InFocusBlock(tz, local, local.Add(time.Minute)) {
+			label = "\x1b[33min a focus block\x1b[0m"
+		}
+		fmt.Printf("  %s%s  %d  %s\n", padCell(tz.Name, 14), local.Format("Mon 15:04"), meetingPain(tz, local), label)
+	}
+
+	const cellWidth = 12
+	fmt.Printf("\n\x1b[1mSUGGESTED ROTATION\x1b[0m over %d occurrences\n", fairnessCount)
+	// The meeting's zone gets a column of its own unless it is one of the configured zones.
+	anchor := true
+	for _, tz := range attending {
+		anchor = anchor && tz.Name != label
+	}
+	columns := len(attending) + 1
+	fmt.Print(padCell("DATE", cellWidth))
+	if anchor {
+		fmt.Print(padCell(label, cellWidth))
+		columns++
+	}
+	for _, tz := range attending {
+		fmt.Print(padCell(tz.Name, cellWidth))
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", cellWidth*columns))
+	for _, s := range slots {
+		fmt.Print(padCell(s.Start.In(loc).Format("Mon Jan 2"), cellWidth))
+		if anchor {
+			fmt.Print(padCell(s.Start.In(loc).Format("15:04"), cellWidth))
+		}
+		for i, pain := range s.Pain {
+			cell := padCell(s.Start.In(locs[i]).Format("15:04"), cellWidth)
+			switch pain {
+			case painLate:
+				cell = "\x1b[33m" + cell + "\x1b[0m"
+			case painNight:
+				cell = "\x1b[31m" + cell + "\x1b[0m"
+			}
+			fmt.Print(cell)
+		}
+		fmt.Println()
+	}
+	fmt.Println("\x1b[90m(yellow = outside business hours, red = late night or early morning)\x1b[0m")
+
+	fmt.Println("\n\x1b[1mTOTAL PAIN\x1b[0m rotating vs. always at the same time")
+	for i, tz := range attending {
+		fmt.Printf("  %s%3d  vs. %d\n", padCell(tz.Name, 14), totals[i], fixed[i])
+	}
+	fmt.Println()
+	return nil
+}
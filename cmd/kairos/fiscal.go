@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fiscalStartMonth returns the first month of the fiscal year from the fiscal_start setting (January by default).
+func fiscalStartMonth() time.Month {
+	if m, err := parseMonth(settings.FiscalStart); err == nil {
+		return m
+	}
+	return time.January
+}
+
+// parseMonth reads a month as a number (1-12) or an English name, e.g. "10", "oct", or "October".
+func parseMonth(v string) (time.Month, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= 12 {
+		return time.Month(n), nil
+	}
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if len(v) >= 3 && strings.HasPrefix(name, v) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid month '%s' (expected 1-12 or a name such as October)", v)
+}
+
+// setFiscalStart validates and stores the first month of the fiscal year ("default" restores January).
+func setFiscalStart(v string) error {
+	if v == "default" || v == "none" || v == "" {
+		settings.FiscalStart = ""
+		return nil
+	}
+	m, err := parseMonth(v)
+	if err != nil {
+		return err
+	}
+	settings.FiscalStart = strings.ToLower(m.String())
+	if m == time.January {
+		settings.FiscalStart = ""
+	}
+	return nil
+}
+
+// fiscalPeriod is where a date falls in the fiscal calendar.
+type fiscalPeriod struct {
+	// Year is the fiscal year, named after the calendar year it ends in (e.g. 2027 for October 2026 to September 2027).
+	Year    int
+	Quarter int
+	// QuarterEnd and YearEnd are the first days after the quarter and the year.
+	QuarterEnd, YearEnd time.Time
+}
+
+/**
+ * This function places a date in the fiscal calendar that starts in the given month. A
+ * fiscal year is named after the calendar year it ends in, as most companies do, so with
+ * a January start it is simply the calendar year.
+ *
+ * @param day - The date, in the zone whose calendar counts.
+ * @param start - The first month of the fiscal year.
+ * @returns The fiscal year and quarter, and when they end.
+ */
+func fiscalPeriodOf(day time.Time, start time.Month) fiscalPeriod {
+	// Months since the start of the fiscal year, 0 to 11.
+	into := (int(day.Month()) - int(start) + 12) % 12
+	startYear := day.Year()
+	if day.Month() < start {
+		startYear--
+	}
+	yearStart := time.Date(startYear, start, 1, 0, 0, 0, 0, time.UTC)
+	p := fiscalPeriod{Year: yearStart.AddDate(0, 11, 0).Year(), Quarter: into/3 + 1, YearEnd: yearStart.AddDate(1, 0, 0)}
+	p.QuarterEnd = yearStart.AddDate(0, 3*p.Quarter, 0)
+	return p
+}
+
+// daysUntil counts the calendar days from a date to a later one, ignoring DST.
+func daysUntil(day, end time.Time) int {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(from).Hours() / 24)
+}
+
+// fiscalStatus returns the {fiscal} footer widget, e.g. "Q3 FY27 · 45d left in quarter · 228d in year", counted in the primary zone.
+func fiscalStatus(now time.Time) string {
+	day := primaryNow(now)
+	p := fiscalPeriodOf(day, fiscalStartMonth())
+	return fmt.Sprintf("Q%d FY%02d · %dd left in quarter · %dd in year", p.Quarter, p.Year%100, daysUntil(day, p.QuarterEnd), daysUntil(day, p.YearEnd))
+}
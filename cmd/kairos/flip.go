@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// flipDuration is how long the primary clock's digits take to flip over after each minute change.
+const flipDuration = 600 * time.Millisecond
+
+// flipFrames is the number of frames of the flip, shown for equal parts of flipDuration.
+const flipFrames = 3
+
+// flipEnabled reports whether the primary clock flips its digits; reduced_motion turns it off.
+func flipEnabled() bool {
+	return settings.FlipClock && !settings.ReducedMotion
+}
+
+/**
+ * This function animates the primary clock like a flip clock: for flipDuration after
+ * each minute change, the digits that changed flip over from the old minute's art. The
+ * top half of the old flap falls over the hinge, then the new flap settles onto the
+ * old bottom half. Digits that did not change stay as they are.
+ *
+ * @param now - The current time in the view's timezone.
+ * @param art - The new minute's art.
+ * @param old - The same art for the previous minute.
+ * @returns The art of the current frame, or art itself once the flip is over.
+ */
+func flipArt(now time.Time, art, old []string) []string {
+	elapsed := now.Sub(now.Truncate(time.Minute))
+	// Braille digits are too short to flip.
+	if !flipEnabled() || elapsed >= flipDuration || len(art) < 5 || len(old) != len(art) {
+		return art
+	}
+	next := make([][]rune, len(art))
+	prev := make([][]rune, len(art))
+	for i := range art {
+		next[i], prev[i] = []rune(art[i]), []rune(old[i])
+		// Art of another width is not flipped.
+		if len(next[i]) != len(prev[i]) {
+			return art
+		}
+	}
+	// A glyph flips as a whole when any of its columns changed; glyphs are separated by blank columns.
+	changed := make([]bool, len(next[0]))
+	blank := make([]bool, len(next[0]))
+	for x := range changed {
+		blank[x] = true
+		for i := range next {
+			if x >= len(next[i]) {
+				continue
+			}
+			changed[x] = changed[x] || next[i][x] != prev[i][x]
+			blank[x] = blank[x] && next[i][x] == ' ' && prev[i][x] == ' '
+		}
+	}
+	for start := 0; start < len(changed); {
+		end := start
+		flip := false
+		for end < len(changed) && !blank[end] {
+			flip = flip || changed[end]
+			end++
+		}
+		for x := start; x < end; x++ {
+			changed[x] = flip
+		}
+		start = end + 1
+	}
+
+	hinge := len(art) / 2
+	frame := int(elapsed * flipFrames / flipDuration)
+	lines := make([]string, len(art))
+	for y := range art {
+		row := make([]rune, len(next[y]))
+		for x := range row {
+			if x >= len(changed) || !changed[x] {
+				row[x] = next[y][x]
+				continue
+			}
+			switch {
+			// Frame 0: the new top shows above the old top, which falls towards the hinge.
+			case frame == 0 && y == 0:
+				row[x] = next[y][x]
+			case frame == 0 && y < hinge:
+				row[x] = prev[y-1][x]
+			// Frame 1: the flap lies flat along the hinge over the old bottom.
+			case frame == 1 && y == hinge:
+				row[x] = '─'
+			case frame == 1 && y < hinge:
+				row[x] = next[y][x]
+			// Frame 2: the new flap has fallen all but its last row.
+			case frame == 2 && y < len(art)-1:
+				row[x] = next[y][x]
+			default:
+				row[x] = prev[y][x]
+			}
+		}
+		lines[y] = string(row)
+	}
+	return lines
+}
+
+/**
+ * This function starts the flip worker, which redraws the dashboard every frame while the
+ * primary clock flips at the start of each minute; the clock ticker alone redraws only once
+ * a second, which would skip most of the frames.
+ *
+ * @param g - The GUI to redraw.
+ */
+func startFlipWorker(g *gocui.Gui) {
+	if !settings.FlipClock {
+		return
+	}
+	goWorker("flip worker", func(ctx context.Context) {
+		for {
+			now := appClock.Now(time.UTC)
+			if !sleepCtx(ctx, now.Truncate(time.Minute).Add(time.Minute).Sub(now)) {
+				return
+			}
+			if !flipEnabled() {
+				continue
+			}
+			for i := 0; i <= flipFrames; i++ {
+				g.Update(func(*gocui.Gui) error {
+					takeSnapshot()
+					return nil
+				})
+				if !sleepCtx(ctx, flipDuration/flipFrames) {
+					return
+				}
+			}
+		}
+	})
+}
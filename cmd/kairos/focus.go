@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/zonemeta"
+	"github.com/iamstoick/kairos/zones"
+	"github.com/jroimartin/gocui"
+)
+
+// focusIndex is the zone whose view is focused with Tab or the arrow keys (-1 for none).
+var focusIndex = -1
+
+// maxInfoPanelWidth is the width of the info panel on wide screens.
+const maxInfoPanelWidth = 44
+
+// focusableViews returns how many zone views the dashboard shows, primary included.
+func focusableViews() int {
+	return min(len(displayZones()), visibleViews)
+}
+
+// moveFocus moves the focus by delta views, wrapping around; the first move focuses the primary view.
+func moveFocus(delta int) {
+	n := focusableViews()
+	if n == 0 {
+		return
+	}
+	if focusIndex < 0 {
+		focusIndex = 0
+		return
+	}
+	focusIndex = ((focusIndex+delta)%n + n) % n
+}
+
+// focusedZone returns the focused zone, if any.
+func focusedZone() (TimezoneConfig, bool) {
+	zones := displayZones()
+	if focusIndex < 0 || focusIndex >= min(len(zones), visibleViews) {
+		return TimezoneConfig{}, false
+	}
+	return zones[focusIndex], true
+}
+
+// infoPanelWidth returns the width of the info panel for a screen width, or 0 when it is hidden.
+func infoPanelWidth(maxX int) int {
+	if _, ok := focusedZone(); !ok || maxX < 80 {
+		return 0
+	}
+	return min(maxInfoPanelWidth, maxX/3)
+}
+
+/**
+ * This function lists the extended details of a zone for the info panel: its full
+ * location, UTC offset and DST state, the next offset change, the offset from the local
+ * zone, the distance from the primary zone, business hours, sunrise and sunset (for zones
+ * with a known position), and its people.
+ *
+ * @param tz - The zone.
+ * @param now - The current time.
+ * @returns The panel's lines.
+ */
+func infoPanelLines(tz TimezoneConfig, now time.Time) []string {
+	loc, ok := locations[tz.Name]
+	if !ok {
+		return []string{" Unknown location " + tz.Location}
+	}
+	local := now.In(loc)
+	name, offset := local.Zone()
+	_, localOffset := now.In(localZone()).Zone()
+	dst := "not in effect"
+	if local.IsDST() {
+		dst = "in effect"
+	}
+
+	label := func(key, value string) string { return fmt.Sprintf(" \x1b[1m%-10s\x1b[0m %s", key, value) }
+	lines := []string{
+		label("Location", tz.Location),
+	}
+	if info, ok := zonemeta.Lookup(tz.Location); ok {
+		lines = append(lines, label("Country", info.Flag()+" "+info.Country))
+	}
+	if coords, ok := zoneCoordinates(tz); ok {
+		lines = append(lines, label("Coords", formatCoordinates(coords)))
+	}
+	if distance, flight, ok := distanceFromPrimary(tz); ok {
+		lines = append(lines, label("Distance", distance), label("Flight", flight))
+	}
+	lines = append(lines,
+		label("Time", local.Format("Mon 2 Jan, 15:04:05")),
+		label("Offset", fmt.Sprintf("UTC%s (%s)", local.Format("-07:00"), name)),
+		label("vs. local", tzutil.FormatOffsetDiff(offset-localOffset)),
+		label("DST", dst),
+	)
+	if tr, ok := tzutil.NextTransition(loc, now, transitionSearchWindow); ok {
+		lines = append(lines, label("Next", fmt.Sprintf("%s → %s", tr.At.In(loc).Format("2 Jan 2006 15:04"), tr.AfterName)))
+	} else {
+		lines = append(lines, label("Next", "no offset change in two years"))
+	}
+
+	lines = append(lines, "", label("Hours", fmt.Sprintf("%s %s", zones.BusinessHours(tz), getBusinessHoursIndicator(local, tz))),
+		label("", zones.Countdown(local, tz)), label("Awake", awakeStatus(tz, local)))
+	if state := stateLabel(local, tz); state != "" {
+		lines = append(lines, label("State", state))
+	}
+	if blocks, ok := zones.FocusBlocks(tz); ok {
+		focus := blocks.String()
+		if blocks.Contains(local) {
+			focus += " (focusing now)"
+		}
+		lines = append(lines, label("Focus", focus))
+	}
+	if names := onCallStatus(tz); names != "" {
+		lines = append(lines, label("On call", names))
+	}
+	if line := interactionLine(tz, now); line != "" {
+		lines = append(lines, label("Contact", line))
+	}
+	lines = append(append(lines, ""), miniCalendarLines(tz, local)...)
+	lines = append(lines, "")
+	if coords, ok := zoneCoordinates(tz); ok {
+		if rise, set, ok := sunriseSunset(local, coords); ok {
+			lines = append(lines, label("Sunrise", rise.In(loc).Format("15:04")), label("Sunset", set.In(loc).Format("15:04")))
+		} else {
+			lines = append(lines, label("Sun", "no sunrise or sunset today"))
+		}
+		lines = append(lines, label("Daylight", formatDaylight(daylightDuration(local, coords))))
+		lines = append(lines, label("Sun now", formatSunPosition(now, coords)))
+		if reading, ok := zoneAirReading(tz); ok {
+			if reading.AQI >= 0 {
+				level, color := aqiLevel(reading.AQI)
+				lines = append(lines, label("Air", fmt.Sprintf("%sAQI %.0f[0m (%s)", color, reading.AQI, level)))
+			}
+			if reading.UV >= 0 {
+				level, color := uvLevel(reading.UV)
+				lines = append(lines, label("UV", fmt.Sprintf("%s%.1f[0m (%s)", color, reading.UV, level)))
+			}
+		}
+		if golden, ok := formatGoldenHours(local, coords); ok {
+			lines = append(lines, label("Golden", golden))
+		}
+	}
+
+	if reading, ok := zoneCarbonReading(tz); ok && tz.Carbon != "" {
+		grid := fmt.Sprintf("%s %.0f g/kWh", tz.Carbon, reading.Intensity)
+		if text := greenHoursText(reading, loc, now); text != "" {
+			grid += ", " + text
+		}
+		lines = append(lines, label("Grid", grid))
+	}
+
+	if tz.Note != "" {
+		lines = append(lines, "", " \x1b[1mNote\x1b[0m")
+		for _, line := range wrapText(tz.Note, maxInfoPanelWidth-4) {
+			lines = append(lines, "  "+line)
+		}
+	}
+	if len(tz.People) > 0 {
+		lines = append(lines, "", " \x1b[1mPeople\x1b[0m")
+		for _, p := range tz.People {
+			lines = append(lines, "  • "+p)
+		}
+	}
+	if mates := zoneSlackMates(tz); len(mates) > 0 {
+		lines = append(lines, "", " \x1b[1mOn Slack\x1b[0m")
+		for _, m := range mates {
+			dot := "\x1b[90m○\x1b[0m"
+			if m.Online {
+				dot = "\x1b[32m●\x1b[0m"
+			}
+			lines = append(lines, strings.TrimRight(fmt.Sprintf("  %s %s %s", dot, m.Name, m.Status), " "))
+		}
+	}
+	// Long values are clipped at the panel's edge, like in every other view.
+	return append(lines, "", " \x1b[90mTab/arrows move, Esc closes\x1b[0m")
+}
+
+// focusBindings sets up Tab, the arrow keys, and Esc for moving the focus between views.
+func focusBindings(g *gocui.Gui) error {
+	keys := []struct {
+		key   gocui.Key
+		delta int
+	}{
+		{gocui.KeyTab, 1}, {gocui.KeyArrowRight, 1}, {gocui.KeyArrowDown, 1},
+		{gocui.KeyArrowLeft, -1}, {gocui.KeyArrowUp, -1},
+	}
+	for _, k := range keys {
+		k := k
+		if err := g.SetKeybinding("", k.key, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+			// In a prompt the arrow keys move the cursor (or the palette's selection) instead,
+			// and Tab peeks at the selected zone (see peekBindings).
+			if promptActive(v) {
+				if k.key != gocui.KeyArrowUp && k.key != gocui.KeyArrowDown && k.key != gocui.KeyTab {
+					v.Editor.Edit(v, k.key, 0, gocui.ModNone)
+				}
+				return nil
+			}
+			moveFocus(k.delta)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return g.SetKeybinding("", gocui.KeyEsc, gocui.ModNone, onEscape(func(g *gocui.Gui, v *gocui.View) error {
+		// Esc in a prompt closes the prompt (see trackPromptBindings and paletteBindings),
+		// in timer mode hides the stopwatch, and otherwise closes the peek card first.
+		if currentMode() == modeTimer {
+			stopwatchMode = false
+		} else if peekLocation != "" && !promptActive(v) {
+			closePeek(g)
+		} else if !promptActive(v) {
+			focusIndex = -1
+			endPreview()
+		}
+		return nil
+	}))
+}
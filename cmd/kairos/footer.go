@@ -0,0 +1,358 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/iamstoick/kairos/workhours"
+)
+
+// defaultFooter is the footer template used unless the footer setting overrides it.
+const defaultFooter = "{mode} {keys} | {tracker} | {handoff} | {status} {heartbeat}"
+
+// footerPlaceholder matches a {name} placeholder in the footer template.
+var footerPlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// footerFields are the footer placeholders and what they expand to.
+var footerFields = map[string]func(now time.Time) string{
+	"keys": func(time.Time) string {
+		// Kiosk mode leaves out the key hints, since the keys are disabled.
+		if kioskMode {
+			return ""
+		}
+		// In timer mode the hints are the stopwatch's keys.
+		switch currentMode() {
+		case modeTimer:
+			return "Space start/stop, l lap, r reset, y copy | w or Esc to leave"
+		case modeIncident:
+			return "x export the timeline | ! resolve | Ctrl+C to quit"
+		}
+		return "Keys [1-6] to swap timezones | Ctrl+C to quit"
+	},
+	"mode":         func(time.Time) string { return modeStatus() },
+	"cpu":          func(time.Time) string { cpu, _ := appState.Stats(); return cpu },
+	"mem":          func(time.Time) string { _, mem := appState.Stats(); return mem },
+	"heartbeat":    heartbeatStatus,
+	"notification": func(time.Time) string { return formatNotification() },
+	// status is the notification while one is shown, and the CPU and memory usage otherwise.
+	"status": func(time.Time) string {
+		if appState.Notification() != "" {
+			return formatNotification()
+		}
+		cpu, mem := appState.Stats()
+		return fmt.Sprintf("%s | %s", cpu, mem)
+	},
+	"tracker": trackerStatus,
+	"alarm":   nextAlarmStatus,
+	"ntp":     func(time.Time) string { return ntpStatus },
+	"handoff": handoffStatus,
+	"ticker":  tickerStatus,
+	"fiscal":  fiscalStatus,
+	// boundary counts down to the next full or half hour in the primary zone (see countdown.go).
+	"boundary": boundaryStatus,
+}
+
+// notificationScrollStep is how many columns a long notification scrolls each second (see long_notifications).
+const notificationScrollStep = 3
+
+// longNotificationModes are the values of the long_notifications setting.
+var longNotificationModes = []string{"wrap", "scroll"}
+
+// notificationText returns the current notification, with how many more are waiting, or "", and its level.
+func notificationText() (string, notifyLevel) {
+	notification, level, _, waiting := appState.NotificationStatus()
+	if notification != "" && waiting > 0 {
+		notification += fmt.Sprintf(" (+%d)", waiting)
+	}
+	return notification, level
+}
+
+// formatNotification highlights the current notification in bold and its level's color, or returns "".
+func formatNotification() string {
+	return highlightNotification(notificationText())
+}
+
+// highlightNotification wraps a notification in bold and its level's color (see notify.go), or returns "" for none.
+func highlightNotification(text string, level notifyLevel) string {
+	if text == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s\x1b[1m %s \x1b[0m", notifyLevelColors[level], text)
+}
+
+/**
+ * This function lays out the footer for a screen width. A notification too long for the
+ * footer's line either gets a line of its own above the rest of the footer while it is
+ * shown, or scrolls through its place in the line, from its start, as long_notifications
+ * says. On a line of its own it still scrolls if it is wider than the screen.
+ *
+ * @param now - The current time.
+ * @param width - The screen width.
+ * @returns The footer's lines, centered: one, or two while a long notification is shown.
+ */
+func footerLines(now time.Time, width int) []string {
+	line := renderFooter(now)
+	text, level := notificationText()
+	full := highlightNotification(text, level)
+	if text == "" || !strings.Contains(line, full) || render.TextWidth(line) <= width {
+		return []string{render.Center(line, width)}
+	}
+	_, _, shown, _ := appState.NotificationStatus()
+	offset := int(time.Since(shown)/time.Second) * notificationScrollStep
+	if settings.LongNotifications == "scroll" {
+		// The notification's room is what the rest of the line leaves, and its padding.
+		room := width - render.TextWidth(strings.Replace(line, full, "", 1)) - 2
+		return []string{render.Center(strings.Replace(line, full, highlightNotification(marqueeAt(text, max(room, 10), offset), level), 1), width)}
+	}
+	var rest []string
+	for _, part := range strings.Split(strings.Replace(line, full, "", 1), " | ") {
+		if part = strings.TrimSpace(part); part != "" {
+			rest = append(rest, part)
+		}
+	}
+	return []string{render.Center(highlightNotification(marqueeAt(text, width-2, offset), level), width), render.Center(strings.Join(rest, " | "), width)}
+}
+
+// setNotificationDuration validates and stores how long notifications are shown ("default" restores 3s).
+func setNotificationDuration(v string) error {
+	if v == "default" || v == "none" || v == "" {
+		settings.NotificationDuration = ""
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < time.Second || d > 5*time.Minute {
+		return fmt.Errorf("invalid notification duration '%s' (expected e.g. 3s or 10s, from 1s to 5m)", v)
+	}
+	settings.NotificationDuration = v
+	return nil
+}
+
+// notificationDuration returns how long a notification is shown unless it says otherwise.
+func notificationDuration() time.Duration {
+	if d, err := time.ParseDuration(settings.NotificationDuration); err == nil {
+		return d
+	}
+	return 3 * time.Second
+}
+
+// footerTemplate returns the configured footer template, or the default one.
+func footerTemplate() string {
+	return defaultString(settings.Footer, defaultFooter)
+}
+
+/**
+ * This function expands the footer template. The template is split into sections at
+ * "|", and sections whose placeholders are all empty (such as {tracker} when no session
+ * is running) are dropped together with their separator.
+ *
+ * @param now - The current time.
+ * @returns The footer text.
+ */
+func renderFooter(now time.Time) string {
+	var parts []string
+	for _, section := range strings.Split(footerTemplate(), "|") {
+		text := footerPlaceholder.ReplaceAllStringFunc(section, func(m string) string {
+			name := m[1 : len(m)-1]
+			if field, ok := footerFields[name]; ok && !containsString(settings.FooterHide, name) {
+				return field(now)
+			} else if ok {
+				return ""
+			}
+			return m
+		})
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// setFooter validates and stores the footer template ("default" restores the default).
+func setFooter(v string) error {
+	if v == "default" || v == "none" || v == "" || v == defaultFooter {
+		settings.Footer = ""
+		return nil
+	}
+	for _, m := range footerPlaceholder.FindAllStringSubmatch(v, -1) {
+		if _, ok := footerFields[m[1]]; !ok {
+			return fmt.Errorf("unknown footer placeholder {%s} (expected any of: %s)", m[1], strings.Join(footerFieldNames(), ", "))
+		}
+	}
+	settings.Footer = v
+	return nil
+}
+
+// footerWidgetKeys are the Shift+letter keys that show or hide each footer widget.
+var footerWidgetKeys = map[rune]string{
+	'K': "keys", 'C': "cpu", 'M': "mem", 'S': "status", 'H': "heartbeat", 'T': "tracker", 'A': "alarm", 'N': "ntp", 'O': "handoff", 'P': "ticker",
+	'Y': "fiscal", 'B': "boundary",
+}
+
+// setFooterHide validates and stores the comma-separated footer widgets to leave out.
+func setFooterHide(v string) error {
+	if v == "none" || v == "" {
+		settings.FooterHide = nil
+		return nil
+	}
+	var hide []string
+	for _, name := range strings.Split(strings.ToLower(v), ",") {
+		name = strings.Trim(strings.TrimSpace(name), "{}")
+		if _, ok := footerFields[name]; !ok {
+			return fmt.Errorf("unknown footer widget '%s' (expected any of: %s)", name, strings.Join(footerFieldNames(), ", "))
+		}
+		hide = append(hide, name)
+	}
+	settings.FooterHide = hide
+	return nil
+}
+
+// toggleFooter hides or shows the whole footer (the "f" key) and saves the choice.
+func toggleFooter() {
+	hidden := !settings.FooterHidden
+	if err := persistSettings(func(s *Settings) { s.FooterHidden = hidden }); err != nil {
+		showWarning("Footer setting not saved: " + err.Error())
+	}
+}
+
+/**
+ * This function hides or shows one footer widget (a Shift+letter key) and saves the choice.
+ *
+ * @param name - The widget's placeholder name, e.g. "cpu".
+ */
+func toggleFooterWidget(name string) {
+	if !strings.Contains(footerTemplate(), "{"+name+"}") {
+		showNotification(fmt.Sprintf("{%s} is not in the footer template", name))
+		return
+	}
+	hidden := containsString(settings.FooterHide, name)
+	var hide []string
+	for _, h := range settings.FooterHide {
+		if h != name {
+			hide = append(hide, h)
+		}
+	}
+	if !hidden {
+		hide = append(hide, name)
+	}
+	if err := persistSettings(func(s *Settings) { s.FooterHide = hide }); err != nil {
+		showWarning("Footer setting not saved: " + err.Error())
+		return
+	}
+	if hidden {
+		showNotification("Showing " + name + " in the footer")
+	} else {
+		showNotification("Hiding " + name + " in the footer")
+	}
+}
+
+// footerFieldNames returns the footer placeholders, sorted, for help and errors.
+func footerFieldNames() []string {
+	var names []string
+	for name := range footerFields {
+		names = append(names, "{"+name+"}")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nextAlarmStatus returns the next event alarm for the {alarm} placeholder, or "".
+func nextAlarmStatus(now time.Time) string {
+	var next time.Time
+	title := ""
+	for _, e := range events {
+		at, ok := eventAlarmTime(e)
+		if ok && at.After(now) && (next.IsZero() || at.Before(next)) {
+			next, title = at, e.Title
+		}
+	}
+	if next.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("⏰ %s in %s", title, workhours.FormatCountdown(next.Sub(now)))
+}
+
+// ntpServer is queried for the {ntp} placeholder.
+const ntpServer = "pool.ntp.org:123"
+
+// ntpStatus is the clock offset shown by {ntp}; see startNTPWorker.
+var ntpStatus = "NTP: checking..."
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the Unix epoch.
+const ntpEpochOffset = 2208988800
+
+/**
+ * This function starts a worker that measures the local clock's offset from an NTP
+ * server every 10 minutes, but only if the footer template shows it with {ntp}.
+ */
+func startNTPWorker() {
+	if !strings.Contains(footerTemplate(), "{ntp}") {
+		return
+	}
+	goWorker("ntp worker", func(ctx context.Context) {
+		lastErr := ""
+		for {
+			offset, err := queryNTP(ntpServer)
+			if err != nil {
+				ntpStatus = "NTP: \x1b[33munavailable\x1b[0m"
+				if err.Error() != lastErr {
+					logger.Warn("NTP query failed", "server", ntpServer, "err", err)
+				}
+				lastErr = err.Error()
+			} else {
+				color := "\x1b[32m"
+				if offset > time.Second || offset < -time.Second {
+					color = "\x1b[31m"
+				}
+				ntpStatus = fmt.Sprintf("NTP: %s%+.3fs\x1b[0m", color, offset.Seconds())
+				lastErr = ""
+			}
+			if !sleepCtx(ctx, 10*time.Minute) {
+				return
+			}
+		}
+	})
+}
+
+/**
+ * This function asks an NTP server for the time with a single SNTP request.
+ *
+ * @param server - The server's host:port.
+ * @returns How far the server's clock is ahead of the local clock (negative if behind).
+ */
+func queryNTP(server string) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", server, 5*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// LI = 0, version 3, mode 3 (client).
+	req := make([]byte, 48)
+	req[0] = 0x1b
+	sent := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, err
+	}
+	resp := make([]byte, 48)
+	if _, err := conn.Read(resp); err != nil {
+		return 0, err
+	}
+	received := time.Now()
+
+	ntpTime := func(b []byte) time.Time {
+		secs := int64(binary.BigEndian.Uint32(b[:4])) - ntpEpochOffset
+		frac := int64(binary.BigEndian.Uint32(b[4:8])) * 1e9 >> 32
+		return time.Unix(secs, frac)
+	}
+	// The offset averages the two legs of the round trip: ((t1 - t0) + (t2 - t3)) / 2.
+	serverReceive, serverTransmit := ntpTime(resp[32:40]), ntpTime(resp[40:48])
+	return (serverReceive.Sub(sent) + serverTransmit.Sub(received)) / 2, nil
+}
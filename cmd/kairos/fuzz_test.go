@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// The fuzz targets below run their seeds with go test, and search further with e.g.
+// go test -run '^$' -fuzz FuzzParseTimestamp -fuzztime 1m; inputs that fail are saved to
+// testdata/fuzz and become seeds too.
+
+// FuzzParseTimestamp checks the parsers behind explain, parse, until, and since: any
+// input is parsed or refused without a panic, and a parsed leap second is a second later
+// than the :59 before it.
+func FuzzParseTimestamp(f *testing.F) {
+	setupGolden(f)
+	for _, seed := range []string{
+		"1718000000", "1718000000123", "1718000000123456", "1718000000123456789", "-1",
+		"99999999999999999999", "2024-06-10T09:30:00Z", "2024-06-10T09:30:00.123+05:30",
+		"2024-06-10T093000+0530", "Mon, 10 Jun 2024 09:30:00 -0700", "10/Jun/2024:09:30:00 +0000",
+		"2016-12-31T23:59:60Z", "2016-12-31 23:59:60", "Jun 10 09:30:00", "2024-06-10",
+		"backup_20250701_0930", "log-2025-07-01T09-30-15", "20251341_2599", "09:30", "25:00", "",
+	} {
+		f.Add(seed)
+	}
+	locs := []*time.Location{time.UTC, locations["Manila"], locations["New York"], time.FixedZone("UTC-09:30", -9*3600-30*60)}
+	f.Fuzz(func(t *testing.T, input string) {
+		ts, err := parseTimestamp(input)
+		if err == nil {
+			if ts.Format == "" {
+				t.Fatalf("parseTimestamp(%q) gave no format", input)
+			}
+			if ts.LeapSecond && ts.Time.Second() != 0 {
+				t.Fatalf("parseTimestamp(%q) = %v, want the leap second to carry into the next minute", input, ts.Time)
+			}
+		}
+		for _, loc := range locs {
+			if _, _, err := parseLocalTime(input, loc); err != nil && err.Error() == "" {
+				t.Fatalf("parseLocalTime(%q, %s) failed with an empty message", input, loc)
+			}
+		}
+	})
+}
+
+// FuzzResolveZone checks that a zone argument resolves to a location or is refused.
+func FuzzResolveZone(f *testing.F) {
+	setupGolden(f)
+	for _, seed := range []string{"Manila", "new york", "UTC", "Local", "Europe/Berlin", "+08:30", "UTC-3", "+15", "Mars/Base", "../../etc/passwd", "Asia/", ""} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		loc, label, err := resolveZone(name)
+		if err != nil {
+			return
+		}
+		if loc == nil || label == "" && name != "" {
+			t.Fatalf("resolveZone(%q) = %v, %q", name, loc, label)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// gcalAuthURL and gcalTokenURL are Google's OAuth 2.0 endpoints for installed applications.
+	gcalAuthURL  = "https://accounts.google.com/o/oauth2/v2/auth"
+	gcalTokenURL = "https://oauth2.googleapis.com/token"
+	// gcalFreeBusyURL is the Calendar API's free/busy query.
+	gcalFreeBusyURL = "https://www.googleapis.com/calendar/v3/freeBusy"
+	// gcalScope only allows reading free/busy blocks, not event details.
+	gcalScope = "https://www.googleapis.com/auth/calendar.freebusy"
+	// gcalLoginTimeout is how long `kairos gcal login` waits for the browser to come back.
+	gcalLoginTimeout = 5 * time.Minute
+)
+
+// busyBlock is a busy period in one Google calendar ("primary" is the user's own).
+type busyBlock struct {
+	Calendar   string
+	Start, End time.Time
+}
+
+// gcalCommand builds the `kairos gcal` command.
+func gcalCommand() *command {
+	return &command{
+		Name:  "gcal",
+		Short: "Connects Google Calendar so the meeting grid avoids busy times (see kairos config)",
+		Subcommands: []*command{
+			{Name: "login", Short: "Authorizes kairos to read your free/busy times in a browser",
+				Run: func(args []string) error { return runGcalLogin() }},
+			{Name: "logout", Short: "Forgets the Google Calendar authorization",
+				Run: func(args []string) error {
+					settings.GCalRefreshToken = ""
+					if err := saveConfig(); err != nil {
+						return err
+					}
+					fmt.Println("Google Calendar disconnected")
+					return nil
+				}},
+		},
+	}
+}
+
+// randomToken returns a random URL-safe string, for the OAuth state and PKCE verifier.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+/**
+ * This function handles `kairos gcal login`. It runs the OAuth flow for installed
+ * applications: the user opens the printed URL, Google redirects back to a one-off
+ * server on the loopback interface with a code, and the code is exchanged (with PKCE)
+ * for a refresh token, which is saved to the config.
+ *
+ * @returns An error if the client is not configured, or the authorization fails or times out.
+ */
+func runGcalLogin() error {
+	if settings.GCalClientID == "" || settings.GCalClientSecret == "" {
+		return fmt.Errorf("set gcal_client_id and gcal_client_secret to an OAuth client (Desktop app) from the Google Cloud console")
+	}
+	state, err := randomToken()
+	if err != nil {
+		return err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	redirect := "http://" + listener.Addr().String()
+	codes := make(chan string, 1)
+	failures := make(chan error, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "unexpected state", http.StatusBadRequest)
+			return
+		case q.Get("error") != "":
+			// Only the first callback is read; a repeated one (reload, second tab) must not block its handler.
+			select {
+			case failures <- fmt.Errorf("authorization refused: %s", q.Get("error")):
+			default:
+			}
+		default:
+			select {
+			case codes <- q.Get("code"):
+			default:
+			}
+		}
+		fmt.Fprintln(w, "kairos: you can close this tab and return to the terminal.")
+	})}
+	go server.Serve(listener)
+	defer server.Shutdown(context.Background())
+
+	auth := gcalAuthURL + "?" + url.Values{
+		"client_id": {settings.GCalClientID}, "redirect_uri": {redirect}, "response_type": {"code"},
+		"scope": {gcalScope}, "access_type": {"offline"}, "prompt": {"consent"}, "state": {state},
+		"code_challenge": {base64.RawURLEncoding.EncodeToString(challenge[:])}, "code_challenge_method": {"S256"},
+	}.Encode()
+	fmt.Printf("Open this URL in your browser to connect Google Calendar:\n\n  %s\n\nWaiting for the authorization...\n", auth)
+
+	var code string
+	select {
+	case code = <-codes:
+	case err := <-failures:
+		return err
+	case <-time.After(gcalLoginTimeout):
+		return fmt.Errorf("gave up waiting for the authorization after %s", gcalLoginTimeout)
+	}
+
+	var token struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := gcalTokenRequest(url.Values{
+		"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {redirect}, "code_verifier": {verifier},
+	}, &token); err != nil {
+		return err
+	}
+	if token.RefreshToken == "" {
+		return fmt.Errorf("google did not return a refresh token; remove kairos from your account's third-party access and try again")
+	}
+	settings.GCalRefreshToken = token.RefreshToken
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Println("Google Calendar connected; kairos table now avoids your busy times")
+	return nil
+}
+
+// gcalTokenRequest posts a grant to Google's token endpoint with the client credentials and decodes the response.
+func gcalTokenRequest(form url.Values, out interface{}) error {
+	form.Set("client_id", settings.GCalClientID)
+	form.Set("client_secret", settings.GCalClientSecret)
+	resp, err := httpClient.PostForm(gcalTokenURL, form)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("google rejected the authorization: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+/**
+ * This function asks Google Calendar for the busy blocks between two instants, in the
+ * user's own calendar and the teammates' calendars listed in gcal_calendars.
+ *
+ * @param from - The start of the period.
+ * @param to - The end of the period.
+ * @returns The busy blocks, or an error.
+ */
+func fetchFreeBusy(from, to time.Time) ([]busyBlock, error) {
+	if settings.GCalRefreshToken == "" {
+		return nil, fmt.Errorf("run kairos gcal login first")
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := gcalTokenRequest(url.Values{"grant_type": {"refresh_token"}, "refresh_token": {settings.GCalRefreshToken}}, &token); err != nil {
+		return nil, err
+	}
+
+	items := []map[string]string{{"id": "primary"}}
+	for _, c := range settings.GCalCalendars {
+		items = append(items, map[string]string{"id": c})
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"timeMin": from.UTC().Format(time.RFC3339), "timeMax": to.UTC().Format(time.RFC3339), "items": items,
+	})
+	req, err := http.NewRequest(http.MethodPost, gcalFreeBusyURL, strings.NewReader(string(data)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("google calendar rejected the query: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var body struct {
+		Calendars map[string]struct {
+			Busy []struct {
+				Start time.Time `json:"start"`
+				End   time.Time `json:"end"`
+			} `json:"busy"`
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"calendars"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	var blocks []busyBlock
+	for id, c := range body.Calendars {
+		// A teammate's calendar that is not shared with the user reports an error instead of blocks.
+		for _, e := range c.Errors {
+			logger.Warn("free/busy unavailable", "calendar", id, "reason", e.Reason)
+		}
+		for _, b := range c.Busy {
+			blocks = append(blocks, busyBlock{Calendar: id, Start: b.Start, End: b.End})
+		}
+	}
+	return blocks, nil
+}
+
+// busyDuring returns the calendars with a busy block overlapping [start, end), without duplicates.
+func busyDuring(blocks []busyBlock, start, end time.Time) []string {
+	var calendars []string
+	for _, b := range blocks {
+		if b.Start.Before(end) && b.End.After(start) && !containsString(calendars, b.Calendar) {
+			calendars = append(calendars, b.Calendar)
+		}
+	}
+	return calendars
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// earthRadiusKm is the Earth's mean radius.
+	earthRadiusKm = 6371.0
+	// cruiseSpeedKmh and flightOverhead give a rough airliner flight time: cruising plus taxi, climb, and descent.
+	cruiseSpeedKmh = 850.0
+	flightOverhead = 30 * time.Minute
+)
+
+// greatCircleKm returns the great-circle distance between two positions (the haversine formula).
+func greatCircleKm(a, b Coordinates) float64 {
+	dLat := (b.Lat - a.Lat) * degToRad
+	dLon := (b.Lon - a.Lon) * degToRad
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(a.Lat*degToRad)*math.Cos(b.Lat*degToRad)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// flightTime estimates a direct flight's duration over a distance, rounded to 5 minutes.
+func flightTime(km float64) time.Duration {
+	d := time.Duration(km/cruiseSpeedKmh*float64(time.Hour)) + flightOverhead
+	return d.Round(5 * time.Minute)
+}
+
+/**
+ * This function describes how far a zone is from the primary zone, for the info panel:
+ * the great-circle distance and a rough direct flight time.
+ *
+ * @param tz - The zone.
+ * @returns The distance and flight lines, or false if either zone has no position or they are the same zone.
+ */
+func distanceFromPrimary(tz TimezoneConfig) (distance, flight string, ok bool) {
+	zones := displayZones()
+	if len(zones) == 0 || zones[0].Name == tz.Name {
+		return "", "", false
+	}
+	from, ok1 := zoneCoordinates(zones[0])
+	to, ok2 := zoneCoordinates(tz)
+	if !ok1 || !ok2 {
+		return "", "", false
+	}
+	km := greatCircleKm(from, to)
+	distance = fmt.Sprintf("%.0f km (%.0f mi) from %s", km, km*0.621371, zones[0].Name)
+	if km < 100 {
+		return distance, "no flight needed", true
+	}
+	h := flightTime(km)
+	return distance, fmt.Sprintf("~%dh %02dm direct flight", int(h.Hours()), int(h.Minutes())%60), true
+}
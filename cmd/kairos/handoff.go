@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/workhours"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// handoffWarning is how long before a handoff the dashboard shows a notification.
+const handoffWarning = 15 * time.Minute
+
+// lastHandoffCheck is when checkHandoffNotifications last ran.
+var lastHandoffCheck time.Time
+
+/**
+ * This function parses a shift option such as "EMEA" or "EMEA 07:00-15:00": the team
+ * that covers the zone, optionally followed by its shift hours.
+ *
+ * @param value - The option value.
+ * @returns The team and the hours ("" for the zone's business hours), or an error.
+ */
+func parseShift(value string) (team, hours string, err error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", "", fmt.Errorf("the shift needs a team name, e.g. \"EMEA\" or \"EMEA 07:00-15:00\"")
+	}
+	if len(fields) > 1 && strings.Contains(fields[len(fields)-1], ":") {
+		b, err := workhours.ParseSchedule(fields[len(fields)-1])
+		if err != nil {
+			return "", "", err
+		}
+		return strings.Join(fields[:len(fields)-1], " "), b.String(), nil
+	}
+	return strings.Join(fields, " "), "", nil
+}
+
+// zoneShiftHours returns the hours of a zone's shift, which default to its business hours (Monday to Friday).
+func zoneShiftHours(tz TimezoneConfig) workhours.Schedule {
+	if tz.ShiftHours == "" {
+		return zones.BusinessHours(tz)
+	}
+	b, err := workhours.ParseSchedule(tz.ShiftHours)
+	if err != nil {
+		return zones.BusinessHours(tz)
+	}
+	return b
+}
+
+/**
+ * This function finds the next shift handoff in the roster, which is the set of zones
+ * with a "shift" option: the earliest upcoming start of any team's shift.
+ *
+ * @param now - The current time.
+ * @returns The team taking over, when, and false if no zone has a shift.
+ */
+func nextHandoff(now time.Time) (team string, at time.Time, ok bool) {
+	for _, tz := range timezones {
+		loc, found := locations[tz.Name]
+		if tz.Shift == "" || !found {
+			continue
+		}
+		start := zoneShiftHours(tz).NextOpen(now.In(loc))
+		if !ok || start.Before(at) {
+			team, at, ok = tz.Shift, start, true
+		}
+	}
+	return team, at, ok
+}
+
+// handoffStatus returns the next handoff for the {handoff} placeholder, or "".
+func handoffStatus(now time.Time) string {
+	team, at, ok := nextHandoff(now)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("handoff to %s in %s", team, workhours.FormatCountdown(at.Sub(now)))
+}
+
+/**
+ * This function shows a notification handoffWarning before each shift handoff. It is
+ * called on every UI tick and checks whether the warning time of the handoff that was
+ * next at the previous check has passed since.
+ *
+ * @param now - The current time.
+ */
+func checkHandoffNotifications(now time.Time) {
+	since := lastHandoffCheck
+	lastHandoffCheck = now
+	if since.IsZero() {
+		return
+	}
+	team, at, ok := nextHandoff(since)
+	if !ok {
+		return
+	}
+	if warn := at.Add(-handoffWarning); warn.After(since) && !warn.After(now) {
+		showNotification(fmt.Sprintf("Handoff to %s in %d minutes", team, int(handoffWarning.Minutes())))
+	}
+}
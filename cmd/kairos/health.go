@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+)
+
+// healthListen is set by `kairos daemon --health` and serves the health check endpoint.
+var healthListen string
+
+// daemonStarted is when the daemon started, reported by the health check.
+var daemonStarted time.Time
+
+// healthStatus is the body of a health check response.
+type healthStatus struct {
+	Status string `json:"status"`
+	Zones  int    `json:"zones"`
+	// Uptime is how long the daemon has run, e.g. "3h12m5s".
+	Uptime string `json:"uptime"`
+	Error  string `json:"error,omitempty"`
+}
+
+/**
+ * This function starts the health check endpoint for container and service managers:
+ * GET /healthz answers 200 with the daemon's status while it runs with a valid config,
+ * and 503 when the config could not be loaded, so a broken config marks the container
+ * unhealthy instead of serving an empty clock.
+ *
+ * @param addr - The address to listen on, e.g. ":8080".
+ * @param run - Runs a request's handler under the daemon's lock.
+ * @returns A function that stops listening, or an error.
+ */
+func startHealthEndpoint(addr string, run func(func())) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("health check listening", "addr", listener.Addr().String())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		var status healthStatus
+		run(func() {
+			status = healthStatus{Status: "ok", Zones: len(timezones), Uptime: time.Since(daemonStarted).Round(time.Second).String()}
+			if configErr != nil {
+				status.Status, status.Error = "error", configErr.Error()
+			}
+		})
+		w.Header().Set("Content-Type", "application/json")
+		if status.Error != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		defer recoverWorker("health check")
+		server.Serve(listener)
+	}()
+	return func() { server.Close() }, nil
+}
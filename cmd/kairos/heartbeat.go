@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/jroimartin/gocui"
+	"github.com/nsf/termbox-go"
+)
+
+const (
+	// heartbeatLagWarn is how late a clock tick can run before {heartbeat} says the dashboard is behind.
+	heartbeatLagWarn = 2 * time.Second
+	// heartbeatStaleAfter is how long the dashboard can go without a redraw before the watchdog marks it stale.
+	heartbeatStaleAfter = 5 * time.Second
+)
+
+// heartbeat is what the watchdog knows of the dashboard's redraws.
+var heartbeat struct {
+	mu sync.Mutex
+	// at is when the dashboard last redrew, at the width and height it had.
+	at            time.Time
+	width, height int
+	// stale is set while the watchdog shows the stale badge.
+	stale bool
+}
+
+// recordRedraw notes a redraw of the dashboard for the watchdog; layout calls it.
+func recordRedraw(width, height int) {
+	heartbeat.mu.Lock()
+	defer heartbeat.mu.Unlock()
+	heartbeat.at, heartbeat.width, heartbeat.height = time.Now(), width, height
+}
+
+// lastTickLag returns how late the last clock tick ran on the GUI goroutine (see perfhud.go).
+func lastTickLag() time.Duration {
+	perfStats.mu.Lock()
+	defer perfStats.mu.Unlock()
+	if perfStats.lagN == 0 {
+		return 0
+	}
+	return perfStats.lags[(perfStats.lagN-1)%perfWindow]
+}
+
+/**
+ * This function renders the {heartbeat} footer widget: a green dot that pulses every
+ * second while the dashboard keeps up, or a yellow one saying how far behind it is when
+ * clock ticks run late. If redraws stop altogether, the footer cannot change, so the
+ * watchdog marks the screen stale instead (see startHeartbeatWatchdog).
+ *
+ * @param now - The current time.
+ * @returns The widget.
+ */
+func heartbeatStatus(now time.Time) string {
+	if lag := lastTickLag(); lag > heartbeatLagWarn {
+		return fmt.Sprintf("\x1b[33m●\x1b[0m %s behind", lag.Round(time.Second))
+	}
+	dot := "●"
+	if !settings.ReducedMotion && now.Second()%2 != 0 {
+		dot = "○"
+	}
+	return "\x1b[32m" + dot + "\x1b[0m live"
+}
+
+/**
+ * This function starts the heartbeat watchdog. A frozen dashboard (a stuck update, or a
+ * terminal that stopped taking output) cannot draw anything about itself, so the watchdog
+ * writes a red STALE badge straight to the terminal, at the right of the footer line, once
+ * there has been no redraw for heartbeatStaleAfter, and keeps its age up to date. When
+ * redraws come back, the whole screen is redrawn to clear it.
+ *
+ * @param g - The dashboard.
+ */
+func startHeartbeatWatchdog(g *gocui.Gui) {
+	goWorker("heartbeat watchdog", func(ctx context.Context) {
+		for sleepCtx(ctx, time.Second) {
+			heartbeat.mu.Lock()
+			at, width, height, wasStale := heartbeat.at, heartbeat.width, heartbeat.height, heartbeat.stale
+			age := time.Since(at)
+			stale := !at.IsZero() && age > heartbeatStaleAfter
+			heartbeat.stale = stale
+			heartbeat.mu.Unlock()
+
+			switch {
+			case stale:
+				if !wasStale {
+					logger.Warn("dashboard stopped redrawing", "last", at)
+				}
+				badge := fmt.Sprintf(" ● STALE %s ", age.Round(time.Second))
+				// Save the cursor, write the badge in red at the right of the footer line, and restore it.
+				os.Stdout.WriteString(fmt.Sprintf("\x1b7\x1b[%d;%dH\x1b[41m\x1b[97m\x1b[1m%s\x1b[0m\x1b8",
+					max(height-1, 1), max(width-render.TextWidth(badge)+1, 1), badge))
+			case wasStale:
+				logger.Info("dashboard redrawing again", "stale", age)
+				// termbox only writes the cells it changed, so the badge is cleared by a full redraw.
+				g.Update(func(*gocui.Gui) error {
+					return termbox.Sync()
+				})
+			}
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// heatmapMode is toggled with the "g" key (or --heatmap) and shows the workweek heat map instead of the clocks.
+var heatmapMode bool
+
+// heatmapSteps are the hours a heat map cell can span, the smallest that fits the width is used.
+var heatmapSteps = []int{1, 2, 3, 4, 6}
+
+// heatmapOpen reports whether a zone is within its business hours at an instant, holidays excepted.
+func heatmapOpen(tz TimezoneConfig, loc *time.Location, t time.Time) bool {
+	local := t.In(loc)
+	return zones.BusinessHours(tz).Contains(local) && !isHoliday(tz, local)
+}
+
+// heatmapDays returns midnight of each weekday in the week that local is in, starting on the week_start day.
+func heatmapDays(local time.Time) []time.Time {
+	start := startOfWeek(local)
+	var days []time.Time
+	for i := 0; i < 7; i++ {
+		day := time.Date(start.Year(), start.Month(), start.Day()+i, 0, 0, 0, 0, start.Location())
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// heatmapCountCell draws an overlap cell: how many of the n zones are open, shaded from none to all.
+func heatmapCountCell(count, n int) string {
+	digit := "+"
+	if count < 10 {
+		digit = fmt.Sprint(count)
+	}
+	switch {
+	case count == 0:
+		return "\x1b[90m·\x1b[0m"
+	case count == n:
+		return "\x1b[1;30;102m" + digit + "\x1b[0m"
+	case count*2 >= n:
+		return "\x1b[30;43m" + digit + "\x1b[0m"
+	}
+	return "\x1b[37;44m" + digit + "\x1b[0m"
+}
+
+/**
+ * This function draws the workweek heat map: a row per zone and a column per hour of my
+ * week (the primary zone's Monday to Friday, in the week_start order), each cell marking
+ * whether the zone is within its business hours then, and an overlap row counting the
+ * zones that are, so that recurring meeting slots which suit everyone stand out. A cell
+ * spans several hours when the width is too small for one per hour; the overlap then
+ * counts the zones open for all of them. The best slots are listed above the map.
+ *
+ * @param now - The current time.
+ * @param width - The width of the panel.
+ * @returns The lines of the heat map.
+ */
+func heatmapLines(now time.Time, width int) []string {
+	zones := displayZones()
+	home, ok := locations[zones[0].Name]
+	if !ok {
+		return []string{" The primary zone's location is unknown"}
+	}
+	var shown []TimezoneConfig
+	labelWidth := len("Overlap")
+	for _, tz := range zones {
+		if _, ok := locations[tz.Name]; ok {
+			shown = append(shown, tz)
+			labelWidth = max(labelWidth, min(render.TextWidth(tz.Name), 14))
+		}
+	}
+	days := heatmapDays(now.In(home))
+	step := heatmapSteps[len(heatmapSteps)-1]
+	for _, s := range heatmapSteps {
+		if 2+labelWidth+len(days)*(24/s+1) <= width {
+			step = s
+			break
+		}
+	}
+	cells := 24 / step
+
+	// open[z][d][c] is how many of the cell's hours zone z is open; counts[d][c] is how many zones are open throughout it.
+	open := make([][][]int, len(shown))
+	counts := make([][]int, len(days))
+	for d := range days {
+		counts[d] = make([]int, cells)
+	}
+	for z, tz := range shown {
+		open[z] = make([][]int, len(days))
+		for d, day := range days {
+			open[z][d] = make([]int, cells)
+			for c := 0; c < cells; c++ {
+				for h := c * step; h < (c+1)*step; h++ {
+					hour := time.Date(day.Year(), day.Month(), day.Day(), h, 0, 0, 0, home)
+					if heatmapOpen(tz, locations[tz.Name], hour) {
+						open[z][d][c]++
+					}
+				}
+				if open[z][d][c] == step {
+					counts[d][c]++
+				}
+			}
+		}
+	}
+
+	label := func(s string) string { return " " + padCell(s, labelWidth+1) }
+	var dayRow, hourRow, nowRow, overlapRow strings.Builder
+	dayRow.WriteString(label(""))
+	hourRow.WriteString(label(fmt.Sprintf("%dh/cell", step)))
+	nowRow.WriteString(label(""))
+	overlapRow.WriteString(label("Overlap"))
+	local := now.In(home)
+	for d, day := range days {
+		dayRow.WriteString(padCell(day.Format("Mon 2"), cells+1))
+		var hours string
+		for c := 0; c < cells; c++ {
+			if h := c * step; h%6 == 0 && (c == 0 || len(hours) < c) {
+				hours += strings.Repeat(" ", c-len(hours)) + fmt.Sprint(h)
+			}
+		}
+		hourRow.WriteString("\x1b[90m" + padCell(hours, cells+1) + "\x1b[0m")
+		for c := 0; c < cells; c++ {
+			if local.YearDay() == day.YearDay() && local.Year() == day.Year() && local.Hour()/step == c {
+				nowRow.WriteString("\x1b[1m▼\x1b[0m")
+			} else {
+				nowRow.WriteString(" ")
+			}
+			overlapRow.WriteString(heatmapCountCell(counts[d][c], len(shown)))
+		}
+		nowRow.WriteString(" ")
+		overlapRow.WriteString(" ")
+	}
+
+	lines := []string{" " + heatmapBest(days, counts, step, len(shown)), "",
+		dayRow.String(), hourRow.String(), nowRow.String(), overlapRow.String()}
+	for z, tz := range shown {
+		var row strings.Builder
+		row.WriteString(label(tz.Name))
+		for d := range days {
+			for c := 0; c < cells; c++ {
+				switch open[z][d][c] {
+				case step:
+					row.WriteString("\x1b[32m█\x1b[0m")
+				case 0:
+					row.WriteString("\x1b[90m·\x1b[0m")
+				default:
+					row.WriteString("\x1b[32m▒\x1b[0m")
+				}
+			}
+			row.WriteString(" ")
+		}
+		lines = append(lines, row.String())
+	}
+	return append(lines, "", " \x1b[90mHours are the primary zone's; █ open, ▒ open part of the cell, · closed. Save with s; g closes\x1b[0m")
+}
+
+/**
+ * This function describes the best slots of the heat map, those with the most zones open,
+ * e.g. "Best: 3 of 4 zones open Mon/Tue/Wed 16:00-18:00". Slots at the same hours on
+ * several days are listed once, with the days.
+ *
+ * @param days - The days of the heat map.
+ * @param counts - How many zones are open in each cell of each day.
+ * @param step - How many hours a cell spans.
+ * @param n - How many zones there are.
+ * @returns The description.
+ */
+func heatmapBest(days []time.Time, counts [][]int, step, n int) string {
+	best := 0
+	for _, row := range counts {
+		for _, count := range row {
+			best = max(best, count)
+		}
+	}
+	if best == 0 {
+		return "No hour of the week has any zone within business hours"
+	}
+	var spans []string
+	spanDays := map[string][]string{}
+	for d, row := range counts {
+		for c := 0; c < len(row); c++ {
+			if row[c] != best {
+				continue
+			}
+			end := c
+			for end+1 < len(row) && row[end+1] == best {
+				end++
+			}
+			span := fmt.Sprintf("%02d:00-%02d:00", c*step, (end+1)*step%24)
+			if _, ok := spanDays[span]; !ok {
+				spans = append(spans, span)
+			}
+			spanDays[span] = append(spanDays[span], days[d].Format("Mon"))
+			c = end
+		}
+	}
+	var parts []string
+	for _, span := range spans {
+		parts = append(parts, strings.Join(spanDays[span], "/")+" "+span)
+	}
+	return fmt.Sprintf("\x1b[1mBest:\x1b[0m %d of %d zone(s) open %s", best, n, strings.Join(parts, ", "))
+}
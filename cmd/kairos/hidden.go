@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// showHidden is toggled with the "h" key and reveals hidden zones until the dashboard exits.
+var showHidden bool
+
+/**
+ * This function handles `kairos hide` and `kairos unhide`. A hidden zone stays in the
+ * config and works with every CLI command, but gets no view on the dashboard.
+ *
+ * @param name - The display name of the timezone.
+ * @param hidden - Whether to hide or show it.
+ * @returns An error if no timezone has that name, it is the last visible one, or the config cannot be saved.
+ */
+func setZoneHidden(name string, hidden bool) error {
+	idx, visible := -1, 0
+	for i, tz := range timezones {
+		if strings.EqualFold(tz.Name, name) {
+			idx = i
+		}
+		if !tz.Hidden {
+			visible++
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("timezone '%s' not found", name)
+	}
+	if hidden && !timezones[idx].Hidden && visible == 1 {
+		return fmt.Errorf("'%s' is the only visible timezone", timezones[idx].Name)
+	}
+	timezones[idx].Hidden = hidden
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	if hidden {
+		fmt.Printf("Hid %s; it stays available to commands ('kairos unhide' shows it again)\n", timezones[idx].Name)
+	} else {
+		fmt.Printf("%s is shown again\n", timezones[idx].Name)
+	}
+	return nil
+}
+
+// toggleHidden reveals or hides the hidden zones on the dashboard (the "h" key).
+func toggleHidden() {
+	n := 0
+	for _, tz := range timezones {
+		if tz.Hidden {
+			n++
+		}
+	}
+	if n == 0 {
+		showNotification("No hidden zones; hide one with 'kairos hide \"Name\"'")
+		return
+	}
+	showHidden = !showHidden
+	if showHidden {
+		showNotification(fmt.Sprintf("Showing %d hidden zone(s)", n))
+	} else {
+		showNotification(fmt.Sprintf("Hiding %d zone(s) again", n))
+	}
+}
+
+/**
+ * This function moves a zone to the primary view by swapping it with the zone shown
+ * there. The swap is made in the config order, since hidden zones may sit between them.
+ * It only changes the dashboard and is never saved; the zone-changed event runs the swap hook.
+ *
+ * @param name - The display name of the zone.
+ * @returns Whether the zone was promoted (false if it already is the primary or is unknown).
+ */
+func promoteZone(name string) bool {
+	previous, ok := moveToTop(name)
+	if ok {
+		bus.Publish(topicZoneChanged, zoneChange{Zone: name, Previous: previous})
+	}
+	return ok
+}
+
+// moveToTop swaps a zone into the primary view without running the swap hook, and returns the zone it replaced.
+func moveToTop(name string) (string, bool) {
+	zones := displayZones()
+	if len(zones) == 0 || zones[0].Name == name {
+		return "", false
+	}
+	top, idx := -1, -1
+	for i, tz := range timezones {
+		switch tz.Name {
+		case zones[0].Name:
+			top = i
+		case name:
+			idx = i
+		}
+	}
+	if top < 0 || idx < 0 {
+		return "", false
+	}
+	timezones[top], timezones[idx] = timezones[idx], timezones[top]
+	// Promoting the pinned zone swaps the two sides of the split view.
+	if strings.EqualFold(name, pinnedZone) {
+		pinnedZone = zones[0].Name
+	}
+	return zones[0].Name, true
+}
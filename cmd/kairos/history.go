@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// How far around an instant `kairos at` looks for the surrounding offset changes.
+const transitionSearchWindow = 2 * 366 * 24 * time.Hour
+
+/**
+ * This function handles the `kairos at` command.
+ * It resolves a past or future wall-clock time in a zone and prints the offset that zone
+ * had at that instant, the offset changes on either side of it, and the same instant in
+ * every configured timezone. The tz database carries historical rules, so this answers
+ * questions about old log data as well as future schedules.
+ *
+ * @param input - The time, e.g. "1999-04-04 12:00" or an RFC 3339 timestamp.
+ * @param zone - A configured display name or a location such as "Europe/Dublin".
+ * @returns An error if the zone or time cannot be resolved.
+ */
+func printAt(input, zone string) error {
+	loc, label, err := resolveZone(zone)
+	if err != nil {
+		return err
+	}
+	instant, note, err := parseLocalTime(input, loc)
+	if err != nil {
+		return err
+	}
+
+	local := instant.In(loc)
+	fmt.Printf("\n\x1b[36m\x1b[1mAT\x1b[0m %s in %s\n", input, label)
+	if note != "" {
+		fmt.Printf("\x1b[33mNote: %s\x1b[0m\n", note)
+	}
+	fmt.Printf("Local:    %s (UTC%s)\n", local.Format("Mon, 02 Jan 2006 15:04:05 MST"), local.Format("-07:00"))
+	fmt.Printf("UTC:      %s\n", instant.UTC().Format(time.RFC3339))
+	fmt.Printf("Relative: %s\n", formatRelative(untilNow(instant)))
+
+	// The previous change is the last one in the window leading up to the instant.
+	if list := tzutil.TransitionsBetween(loc, instant.Add(-transitionSearchWindow), instant); len(list) > 0 {
+		fmt.Printf("Previous: %s\n", describeTransition(list[len(list)-1], loc))
+	} else {
+		fmt.Println("Previous: \x1b[90mno offset change in the preceding two years\x1b[0m")
+	}
+	if tr, ok := tzutil.NextTransition(loc, instant, transitionSearchWindow); ok {
+		fmt.Printf("Next:     %s\n", describeTransition(tr, loc))
+	} else {
+		fmt.Println("Next:     \x1b[90mno offset change in the following two years\x1b[0m")
+	}
+	fmt.Println()
+	printInstantTable(instant)
+	return nil
+}
+
+/**
+ * This function handles the `kairos transitions` command, a zdump-style listing of every
+ * UTC offset change in a zone during one calendar year.
+ *
+ * @param zone - A configured display name or a location such as "Europe/Dublin".
+ * @param year - The year to list, e.g. "2024"; empty means the current year.
+ * @returns An error if the zone or year is invalid.
+ */
+func printTransitions(zone, year string) error {
+	loc, label, err := resolveZone(zone)
+	if err != nil {
+		return err
+	}
+	y := appClock.Now(loc).Year()
+	if year != "" {
+		if y, err = strconv.Atoi(year); err != nil || y < 1 || y > 9999 {
+			return fmt.Errorf("invalid year '%s'", year)
+		}
+	}
+
+	from := time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	list := tzutil.TransitionsBetween(loc, from, from.AddDate(1, 0, 0))
+	fmt.Printf("\n\x1b[36m\x1b[1mTRANSITIONS\x1b[0m %s %d\n", label, y)
+	if len(list) == 0 {
+		name, offset := from.Zone()
+		fmt.Printf("\x1b[90mNo offset changes; %s (UTC%s) all year.\x1b[0m\n\n", name, tzutil.FormatOffsetDiff(offset))
+		return nil
+	}
+	for _, tr := range list {
+		fmt.Printf("  %s\n", describeTransition(tr, loc))
+	}
+	fmt.Println()
+	return nil
+}
+
+/**
+ * This function describes an offset change as its UTC and local instant plus the offsets around it.
+ *
+ * @param tr - The transition.
+ * @param loc - The location it belongs to.
+ * @returns A line such as "2024-03-31 01:00:00 UTC = 2024-03-31 02:00:00 IST (GMT +0h → IST +1h)".
+ */
+func describeTransition(tr tzutil.Transition, loc *time.Location) string {
+	return fmt.Sprintf("%s UTC = %s (%s %s → %s %s)",
+		tr.At.UTC().Format("2006-01-02 15:04:05"), tr.At.In(loc).Format("2006-01-02 15:04:05 MST"),
+		tr.BeforeName, tzutil.FormatOffsetDiff(tr.Before), tr.AfterName, tzutil.FormatOffsetDiff(tr.After))
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamstoick/kairos/zonemeta"
+)
+
+// holidaysMode is toggled with the "o" key and shows today's and tomorrow's public holidays instead of the clocks.
+var holidaysMode bool
+
+// publicHoliday is one entry of the Nager.Date public holiday API.
+type publicHoliday struct {
+	Date      string `json:"date"`
+	LocalName string `json:"localName"`
+	Name      string `json:"name"`
+	// Global is false for holidays kept only in some regions of the country.
+	Global bool `json:"global"`
+}
+
+var (
+	// holidayMu guards holidayCache and holidayErrors, which fetches fill in the background.
+	holidayMu sync.Mutex
+	// holidayCache holds each country's public holidays by "CC/year", e.g. "JP/2026".
+	holidayCache = map[string][]publicHoliday{}
+	// holidayErrors holds why a country's holidays could not be fetched, by "CC/year".
+	holidayErrors = map[string]string{}
+	// holidayFetching marks the "CC/year" keys being fetched, so each is fetched once.
+	holidayFetching = map[string]bool{}
+)
+
+// toggleHolidays shows or hides the holidays panel, fetching the holidays it needs.
+func toggleHolidays() {
+	holidaysMode = !holidaysMode
+	if holidaysMode {
+		fetchMissingHolidays(appClock.Now(time.UTC))
+	}
+}
+
+/**
+ * This function starts fetching, in the background, the public holidays of every zone's
+ * country for the years of today and tomorrow, unless they are cached or being fetched.
+ *
+ * @param now - The current time.
+ */
+func fetchMissingHolidays(now time.Time) {
+	holidayMu.Lock()
+	defer holidayMu.Unlock()
+	for _, tz := range timezones {
+		info, ok := zonemeta.Lookup(tz.Location)
+		if !ok || info.CountryCode == "" {
+			continue
+		}
+		for _, year := range []int{now.Year(), now.Add(48 * time.Hour).Year()} {
+			key := fmt.Sprintf("%s/%d", info.CountryCode, year)
+			if _, cached := holidayCache[key]; cached || holidayFetching[key] {
+				continue
+			}
+			holidayFetching[key] = true
+			delete(holidayErrors, key)
+			go func(country string, year int, key string) {
+				defer recoverWorker("holiday fetch")
+				holidays, err := fetchPublicHolidays(country, year)
+				holidayMu.Lock()
+				defer holidayMu.Unlock()
+				delete(holidayFetching, key)
+				if err != nil {
+					logger.Warn("public holidays not fetched", "country", country, "year", year, "err", err)
+					holidayErrors[key] = err.Error()
+					return
+				}
+				holidayCache[key] = holidays
+			}(info.CountryCode, year, key)
+		}
+	}
+}
+
+/**
+ * This function fetches a country's public holidays for a year from Nager.Date, an open
+ * API that needs no key.
+ *
+ * @param country - The ISO 3166 alpha-2 country code, e.g. "JP".
+ * @param year - The year.
+ * @returns The holidays, or an error.
+ */
+func fetchPublicHolidays(country string, year int) ([]publicHoliday, error) {
+	resp, err := httpClient.Get(fmt.Sprintf("https://date.nager.at/api/v3/PublicHolidays/%d/%s", year, country))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	// Countries the API does not cover answer 404 (or 204 with no body).
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNoContent {
+		return []publicHoliday{}, nil
+	}
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("holiday API rejected the request: %s %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var holidays []publicHoliday
+	if err := json.NewDecoder(resp.Body).Decode(&holidays); err != nil {
+		return nil, fmt.Errorf("unexpected holiday API response: %v", err)
+	}
+	return holidays, nil
+}
+
+/**
+ * This function lists the holidays panel: for today and tomorrow, each in the zone's own
+ * date, the public holidays of every zone's country and the zones' own holidays (the
+ * holidays option). Countries are listed once, with the zones in them.
+ *
+ * @param now - The current time.
+ * @returns The panel's lines.
+ */
+func holidayPanelLines(now time.Time) []string {
+	holidayMu.Lock()
+	defer holidayMu.Unlock()
+	var lines []string
+	pending, failed := 0, map[string]bool{}
+	for i, title := range []string{"Today", "Tomorrow"} {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, " \x1b[1m"+title+"\x1b[0m")
+		// Each entry is keyed by where it applies and what it is, so zones in the same country share a line.
+		var order []string
+		entries := map[string][]string{}
+		for _, tz := range timezones {
+			loc, ok := locations[tz.Name]
+			if !ok {
+				continue
+			}
+			day := now.In(loc).AddDate(0, 0, i)
+			date := day.Format("2006-01-02")
+			place, names := tz.Name, []string(nil)
+			if info, ok := zonemeta.Lookup(tz.Location); ok && info.CountryCode != "" {
+				place = strings.TrimSpace(info.Flag() + " " + info.Country)
+				key := fmt.Sprintf("%s/%d", info.CountryCode, day.Year())
+				if holidays, cached := holidayCache[key]; cached {
+					for _, h := range holidays {
+						if h.Date != date {
+							continue
+						}
+						name := defaultString(h.Name, h.LocalName)
+						if !h.Global {
+							name += " \x1b[90m(some regions)\x1b[0m"
+						}
+						names = append(names, name)
+					}
+				} else if holidayFetching[key] {
+					pending++
+				} else if _, bad := holidayErrors[key]; bad {
+					failed[info.Country] = true
+				}
+			}
+			if isHoliday(tz, day) {
+				names = append(names, "Holiday \x1b[90m(your config)\x1b[0m")
+			}
+			for _, name := range names {
+				entry := place + "\x00" + name
+				if _, seen := entries[entry]; !seen {
+					order = append(order, entry)
+				}
+				if !containsString(entries[entry], tz.Name) {
+					entries[entry] = append(entries[entry], tz.Name)
+				}
+			}
+		}
+		if len(order) == 0 {
+			lines = append(lines, "  No holidays")
+		}
+		for _, entry := range order {
+			place, name, _ := strings.Cut(entry, "\x00")
+			// Zones without a known country are listed by their own name.
+			if zones := strings.Join(entries[entry], ", "); zones != place {
+				place += " \x1b[90m(" + zones + ")\x1b[0m"
+			}
+			lines = append(lines, fmt.Sprintf("  %s: %s", place, name))
+		}
+	}
+
+	if pending > 0 {
+		lines = append(lines, "", " \x1b[33mLoading holidays…\x1b[0m")
+	}
+	if len(failed) > 0 {
+		var countries []string
+		for c := range failed {
+			countries = append(countries, c)
+		}
+		sort.Strings(countries)
+		lines = append(lines, "", " \x1b[33mHolidays unavailable for "+strings.Join(countries, ", ")+" (see the log)\x1b[0m")
+	}
+	return append(lines, "", " \x1b[90mPublic holidays from date.nager.at; o closes\x1b[0m")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// lastHourCheck is when checkHourHook last ran.
+var lastHourCheck time.Time
+
+// hookSetting builds the hook_<event> setting that holds the hook command for an event.
+func hookSetting(event, help string) settingDef {
+	return settingDef{
+		Key:  "hook_" + event,
+		Help: help,
+		Get:  func() string { return defaultString(settings.Hooks[event], "none") },
+		Set: func(v string) error {
+			if v == "none" || v == "" {
+				delete(settings.Hooks, event)
+				return nil
+			}
+			if settings.Hooks == nil {
+				settings.Hooks = map[string]string{}
+			}
+			settings.Hooks[event] = v
+			return nil
+		},
+	}
+}
+
+/**
+ * This function runs the hook command configured for an event, in the background
+ * through the shell, like the chime command. The command gets KAIROS_EVENT and the
+ * event's details as KAIROS_* environment variables. An attached dashboard leaves the
+ * hooks to the daemon, so they run once.
+ *
+ * @param event - The event: swap, hour, alarm, or profile.
+ * @param env - The details, e.g. {"ZONE": "Tokyo"} for KAIROS_ZONE=Tokyo.
+ */
+func runHook(event string, env map[string]string) {
+	command := settings.Hooks[event]
+	if command == "" || attached {
+		return
+	}
+	cmd := shellCommand(context.Background(), command)
+	cmd.Env = append(os.Environ(), "KAIROS_EVENT="+event)
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, "KAIROS_"+k+"="+v)
+	}
+	if err := cmd.Start(); err != nil {
+		logger.Warn("hook failed", "event", event, "err", err)
+		showWarning("Hook " + event + " failed: " + err.Error())
+		return
+	}
+	go func() {
+		defer recoverWorker("hook command")
+		if err := cmd.Wait(); err != nil {
+			logger.Warn("hook command failed", "event", event, "command", command, "err", err)
+		}
+	}()
+	logger.Debug("hook started", "event", event, "command", command)
+}
+
+/**
+ * This function runs the "hour" hook when the hour changes on the primary zone's wall
+ * clock, so half-hour zones get it on their own hour. It is called on every tick.
+ *
+ * @param now - The current time.
+ */
+func checkHourHook(now time.Time) {
+	since := lastHourCheck
+	lastHourCheck = now
+	if since.IsZero() || settings.Hooks["hour"] == "" || len(timezones) == 0 {
+		return
+	}
+	primary := displayZones()[0]
+	loc, ok := locations[primary.Name]
+	if !ok {
+		return
+	}
+	local := now.In(loc)
+	if local.Format("2006010215") == since.In(loc).Format("2006010215") {
+		return
+	}
+	runHook("hour", map[string]string{"ZONE": primary.Name, "TIME": local.Format("15:04"), "HOUR": local.Format("15")})
+}
+
+// shellCommand prepares a user-configured command line for the platform's shell: sh -c, or cmd /C on Windows.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/workhours"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// stateColors maps the color names a state can use to their ANSI codes.
+var stateColors = map[string]string{
+	"red": "\x1b[31m", "green": "\x1b[32m", "yellow": "\x1b[33m", "blue": "\x1b[34m", "magenta": "\x1b[35m", "cyan": "\x1b[36m",
+}
+
+/**
+ * This function parses the "states" option: states separated by ";", each a label, an
+ * HH:MM-HH:MM window, a glyph, and optionally a color and "daily" (every day rather than
+ * Monday to Friday), e.g. "core hours 10:00-15:00 🟢 green; on-call only 17:00-22:00 📟 magenta daily".
+ *
+ * @param value - The option's value.
+ * @returns The states in order, or an error naming the state that is malformed.
+ */
+func parseHoursStates(value string) ([]HoursState, error) {
+	var states []HoursState
+	for _, part := range strings.Split(value, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		at := -1
+		for i, f := range fields {
+			if _, err := workhours.Parse(f); err == nil {
+				at = i
+				break
+			}
+		}
+		if at < 1 || at+1 >= len(fields) {
+			return nil, fmt.Errorf("invalid state '%s' (expected a label, HH:MM-HH:MM, a glyph, and optionally a color and daily, e.g. \"core hours 10:00-15:00 🟢 green\")", strings.TrimSpace(part))
+		}
+		h, _ := workhours.Parse(fields[at])
+		s := HoursState{Label: strings.Join(fields[:at], " "), Hours: h.String(), Glyph: fields[at+1]}
+		for _, f := range fields[at+2:] {
+			f = strings.ToLower(f)
+			if _, ok := stateColors[f]; ok {
+				s.Color = f
+			} else if f == "daily" {
+				s.Daily = true
+			} else {
+				return nil, fmt.Errorf("unknown color '%s' in state '%s' (expected any of: red, green, yellow, blue, magenta, cyan, or daily)", f, s.Label)
+			}
+		}
+		states = append(states, s)
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("states needs at least one state, e.g. \"core hours 10:00-15:00 🟢 green\"")
+	}
+	return states, nil
+}
+
+// formatHoursStates formats states back into the "states" option's form.
+func formatHoursStates(states []HoursState) string {
+	var parts []string
+	for _, s := range states {
+		part := strings.Join([]string{s.Label, s.Hours, s.Glyph, s.Color}, " ")
+		if s.Daily {
+			part += " daily"
+		}
+		parts = append(parts, strings.Join(strings.Fields(part), " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+/**
+ * This function works out a zone's business-hours state: the first of its custom states
+ * whose window contains now, or else plain "open" (🟢) or "closed" (⚫) from its business
+ * hours. Listing states from the most to the least specific lets them overlap.
+ *
+ * @param now - The current time in the zone.
+ * @param tz - The zone.
+ * @returns The state.
+ */
+func zoneHoursState(now time.Time, tz TimezoneConfig) HoursState {
+	for _, s := range tz.States {
+		if s.Covers(now) {
+			return s
+		}
+	}
+	// The business hours are checked with the zone's own window; hours past the
+	// end (e.g. 5:00 PM for 09:00-17:00) already count as "closed".
+	if zones.BusinessHours(tz).Contains(now) {
+		return HoursState{Label: "open", Glyph: "🟢"}
+	}
+	return HoursState{Label: "closed", Glyph: "⚫"}
+}
+
+// stateLabel returns a custom state's label in its color for the zone's view, or "" for plain open and closed.
+func stateLabel(now time.Time, tz TimezoneConfig) string {
+	if len(tz.States) == 0 {
+		return ""
+	}
+	s := zoneHoursState(now, tz)
+	if s.Color == "" {
+		return s.Label
+	}
+	return stateColors[s.Color] + s.Label + "\x1b[0m"
+}
@@ -0,0 +1,9 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+// httpClient is shared by the integrations that call third-party HTTP APIs (calendars, chat, tickers, metrics, widgets, ...).
+var httpClient = &http.Client{Timeout: 15 * time.Second}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"crypto/sha1"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// icsEvent is a single calendar entry ready to be written as a VEVENT.
+type icsEvent struct {
+	Title    string
+	Start    time.Time
+	Duration time.Duration
+	Alarm    *time.Duration // How long before the start to alert, if any.
+	// AllDay events are written as dates, from the start's date for Duration's whole days.
+	AllDay bool
+}
+
+// icsOut is set by the --out flag of `kairos ics`.
+var icsOut string
+
+// icsCommand builds the `kairos ics` command.
+func icsCommand() *command {
+	return &command{
+		Name: "ics", Usage: `["Title" "Time" "Zone" [duration-minutes]]`, MaxArgs: 4,
+		Short: "Exports events (or one meeting) as iCalendar (--out file.ics)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&icsOut, "out", "", "Write the calendar to this file instead of stdout")
+		},
+		Run: func(args []string) error { return exportICS(args, icsOut) },
+	}
+}
+
+/**
+ * This function handles the `kairos ics` command.
+ * Without arguments it exports all configured events; with a title, time and zone it
+ * exports a single proposed meeting. The calendar is written to stdout, or to the file
+ * given with --out.
+ *
+ * @param positional - The positional arguments: ["Title" "Time" "Zone" [duration-minutes]].
+ * @param out - The file to write, or "" for stdout.
+ * @returns An error if the meeting is invalid, there is nothing to export, or the file cannot be written.
+ */
+func exportICS(positional []string, out string) error {
+	var list []icsEvent
+	switch len(positional) {
+	case 0:
+		for _, e := range events {
+			start, err := eventStart(e)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "\x1b[33mSkipping: %v\x1b[0m\n", err)
+				continue
+			}
+			ev := icsEvent{Title: e.Title, Start: start, Duration: time.Duration(e.Duration) * time.Minute}
+			if before, err := time.ParseDuration(e.Alarm); err == nil && e.Alarm != "" {
+				ev.Alarm = &before
+			}
+			list = append(list, ev)
+		}
+		if len(list) == 0 {
+			return fmt.Errorf("No events configured. Use 'kairos event add' or pass a one-off meeting.")
+		}
+	case 3, 4:
+		loc, _, err := resolveZone(positional[2])
+		if err != nil {
+			return err
+		}
+		start, note, err := parseLocalTime(positional[1], loc)
+		if err != nil {
+			return err
+		}
+		// The note goes to stderr so that the calendar on stdout stays valid.
+		if note != "" {
+			fmt.Fprintf(os.Stderr, "\x1b[33mNote: %s\x1b[0m\n", note)
+		}
+		minutes := 30
+		if len(positional) == 4 {
+			if minutes, err = strconv.Atoi(positional[3]); err != nil || minutes < 0 {
+				return fmt.Errorf("invalid duration '%s' (expected minutes)", positional[3])
+			}
+		}
+		list = append(list, icsEvent{Title: positional[0], Start: start.In(loc), Duration: time.Duration(minutes) * time.Minute})
+	default:
+		return usageErrorf(nil, "Usage: kairos ics [\"Title\" \"Time\" \"Zone\" [duration-minutes]] [--out file.ics]")
+	}
+
+	data := buildICS(list, appClock.Now(time.UTC), "")
+	if out == "" {
+		fmt.Print(data)
+		return nil
+	}
+	if err := os.WriteFile(out, []byte(data), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", out, err)
+	}
+	fmt.Printf("Wrote %d event(s) to %s\n", len(list), out)
+	return nil
+}
+
+/**
+ * This function renders events as an iCalendar (RFC 5545) document.
+ * Times in named locations are written with a TZID and a matching VTIMEZONE built from
+ * the location's actual offset transitions; times in UTC are written in UTC form.
+ *
+ * @param list - The events to export.
+ * @param stamp - The creation timestamp (DTSTAMP).
+ * @param calendarName - The calendar's display name for subscribers (X-WR-CALNAME), or "".
+ * @returns The calendar text with CRLF line endings.
+ */
+func buildICS(list []icsEvent, stamp time.Time, calendarName string) string {
+	var lines []string
+	add := func(format string, a ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, a...))
+	}
+
+	add("BEGIN:VCALENDAR")
+	add("VERSION:2.0")
+	add("PRODID:-//kairos//World Clock Dashboard//EN")
+	add("CALSCALE:GREGORIAN")
+	if calendarName != "" {
+		add("X-WR-CALNAME:%s", icsEscape(calendarName))
+	}
+
+	// Emit one VTIMEZONE per location, covering a year either side of its events.
+	ranges := map[string][2]time.Time{}
+	locs := map[string]*time.Location{}
+	for _, e := range list {
+		if e.AllDay || tzidFor(e.Start.Location()) == "" {
+			continue
+		}
+		name := e.Start.Location().String()
+		r, ok := ranges[name]
+		if !ok || e.Start.Before(r[0]) {
+			r[0] = e.Start
+		}
+		if !ok || e.Start.After(r[1]) {
+			r[1] = e.Start
+		}
+		ranges[name] = r
+		locs[name] = e.Start.Location()
+	}
+	names := make([]string, 0, len(ranges))
+	for name := range ranges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := ranges[name]
+		lines = append(lines, vtimezone(locs[name], r[0].AddDate(-1, 0, 0), r[1].AddDate(1, 0, 0))...)
+	}
+
+	for _, e := range list {
+		add("BEGIN:VEVENT")
+		add("UID:%x@kairos", sha1.Sum([]byte(e.Title+e.Start.UTC().Format(time.RFC3339))))
+		add("DTSTAMP:%s", stamp.UTC().Format("20060102T150405Z"))
+		add("SUMMARY:%s", icsEscape(e.Title))
+		if e.AllDay {
+			add("DTSTART;VALUE=DATE:%s", e.Start.Format("20060102"))
+			add("DTEND;VALUE=DATE:%s", e.Start.Add(e.Duration).Format("20060102"))
+		} else {
+			add("%s", icsDateTime("DTSTART", e.Start))
+		}
+		if e.Duration > 0 && !e.AllDay {
+			add("%s", icsDateTime("DTEND", e.Start.Add(e.Duration)))
+		}
+		if e.Alarm != nil {
+			add("BEGIN:VALARM")
+			add("ACTION:DISPLAY")
+			add("DESCRIPTION:%s", icsEscape(e.Title))
+			add("TRIGGER:-PT%dM", int(e.Alarm.Minutes()))
+			add("END:VALARM")
+		}
+		add("END:VEVENT")
+	}
+	add("END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icsFold(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// tzidFor returns the TZID to use for a location, or "" if times should be written in UTC.
+func tzidFor(loc *time.Location) string {
+	name := loc.String()
+	if name == "UTC" || name == "Local" || name == "" || !strings.Contains(name, "/") {
+		return ""
+	}
+	return name
+}
+
+// icsDateTime formats a DTSTART/DTEND property, using a TZID when the location has one.
+func icsDateTime(prop string, t time.Time) string {
+	if tzid := tzidFor(t.Location()); tzid != "" {
+		return fmt.Sprintf("%s;TZID=%s:%s", prop, tzid, t.Format("20060102T150405"))
+	}
+	return fmt.Sprintf("%s:%s", prop, t.UTC().Format("20060102T150405Z"))
+}
+
+/**
+ * This function builds a VTIMEZONE component listing every offset change in a range.
+ * Each observance is written with an explicit DTSTART rather than an RRULE, which is
+ * always correct even for zones whose rules changed over time.
+ *
+ * @param loc - The location.
+ * @param from - The start of the range to cover.
+ * @param to - The end of the range to cover.
+ * @returns The component's lines.
+ */
+func vtimezone(loc *time.Location, from, to time.Time) []string {
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+	transitions := tzutil.TransitionsBetween(loc, from, to)
+	if len(transitions) == 0 {
+		name, offset := from.In(loc).Zone()
+		lines = append(lines,
+			"BEGIN:STANDARD",
+			"DTSTART:19700101T000000",
+			"TZOFFSETFROM:"+icsOffset(offset),
+			"TZOFFSETTO:"+icsOffset(offset),
+			"TZNAME:"+name,
+			"END:STANDARD")
+	}
+	for _, tr := range transitions {
+		kind := "STANDARD"
+		if tr.At.In(loc).IsDST() {
+			kind = "DAYLIGHT"
+		}
+		// DTSTART is the local time of the change, expressed in the offset that was in effect before it.
+		local := tr.At.In(time.FixedZone("", tr.Before))
+		lines = append(lines,
+			"BEGIN:"+kind,
+			"DTSTART:"+local.Format("20060102T150405"),
+			"TZOFFSETFROM:"+icsOffset(tr.Before),
+			"TZOFFSETTO:"+icsOffset(tr.After),
+			"TZNAME:"+tr.AfterName,
+			"END:"+kind)
+	}
+	return append(lines, "END:VTIMEZONE")
+}
+
+// icsOffset formats an offset in seconds as +HHMM.
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// icsEscape escapes text values as required by RFC 5545.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// icsFold splits lines longer than 75 octets, continuing them with a leading space.
+func icsFold(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		n := len(string(r))
+		if width+n > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	return b.String()
+}
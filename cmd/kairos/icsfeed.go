@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/zones"
+)
+
+// icsListen is set by `kairos daemon --ics` and serves an iCalendar feed per zone.
+var icsListen string
+
+// The feeds list business hours from a week ago to this many days ahead; calendar apps
+// refetch subscriptions regularly, so the window rolls forward.
+const (
+	icsFeedPastDays = 7
+	icsFeedDays     = 90
+)
+
+/**
+ * This function starts the iCalendar feeds: GET /ics/NAME.ics answers with a zone's
+ * business hours, holidays, and saved events, for teammates to subscribe to ("Manila office
+ * hours") in their calendar apps; GET /ics/ lists the feeds. Calendar apps cannot send
+ * headers, so the token goes in the feed URL as ?token=, where calendar services store and
+ * log it: only server_read_token is accepted, never the admin token, and the feeds refuse
+ * to start without it. Like the shared server, they need TLS off loopback.
+ *
+ * @param addr - The address to listen on, e.g. ":7422".
+ * @param run - Runs a request's handler under the daemon's lock.
+ * @returns A function that stops listening, or an error.
+ */
+func startICSFeeds(addr string, run func(func())) (func(), error) {
+	if settings.ServerReadToken == "" {
+		return nil, fmt.Errorf("set server_read_token before serving calendar feeds on %s; they do not accept the admin token", addr)
+	}
+	listener, err := listenShared(addr, "--ics")
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("calendar feeds listening", "addr", listener.Addr().String(), "tls", !isLoopbackAddr(addr))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ics/", func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(settings.ServerReadToken)) != 1 {
+			http.Error(w, "invalid or missing token (use server_read_token)", http.StatusUnauthorized)
+			return
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/ics/"), ".ics")
+		logger.Debug("calendar feed requested", "remote", r.RemoteAddr, "zone", name)
+		var body string
+		var err error
+		run(func() {
+			if name == "" {
+				body = icsFeedIndex(token)
+				return
+			}
+			tz, loc, e := findZone(name)
+			if err = e; err == nil {
+				now := appClock.Now(time.UTC)
+				body = buildICS(zoneFeedEvents(tz, loc, now), now, tz.Name+" office hours")
+			}
+		})
+		switch {
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case name == "":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprint(w, body)
+		default:
+			w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+			fmt.Fprint(w, body)
+		}
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		defer recoverWorker("calendar feeds")
+		server.Serve(listener)
+	}()
+	return func() { server.Close() }, nil
+}
+
+// icsFeedIndex lists the path of every zone's feed, with the read token the index was requested with.
+func icsFeedIndex(token string) string {
+	var b strings.Builder
+	for _, tz := range timezones {
+		fmt.Fprintf(&b, "%s\t/ics/%s.ics?token=%s\n", tz.Name, url.PathEscape(tz.Name), url.QueryEscape(token))
+	}
+	return b.String()
+}
+
+/**
+ * This function gathers a zone's feed: a business hours event for every working window
+ * on a weekday that is not one of the zone's holidays, the holidays as all-day events,
+ * and the saved events held in the zone (by name or location).
+ *
+ * @param tz - The zone.
+ * @param loc - Its location.
+ * @param now - The current time.
+ * @returns The events.
+ */
+func zoneFeedEvents(tz TimezoneConfig, loc *time.Location, now time.Time) []icsEvent {
+	local := now.In(loc)
+	from := time.Date(local.Year(), local.Month(), local.Day()-icsFeedPastDays, 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, icsFeedPastDays+icsFeedDays)
+	var list []icsEvent
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !isWorkday(tz, day) {
+			continue
+		}
+		for _, b := range zones.BusinessHours(tz) {
+			list = append(list, icsEvent{Title: tz.Name + " office hours", Start: b.StartOn(day), Duration: b.Length()})
+		}
+	}
+	for _, date := range tz.Holidays {
+		day, err := time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil || day.Before(from) || !day.Before(to) {
+			continue
+		}
+		list = append(list, icsEvent{Title: tz.Name + " holiday", Start: day, Duration: 24 * time.Hour, AllDay: true})
+	}
+	for _, e := range events {
+		if !strings.EqualFold(e.Zone, tz.Name) && e.Zone != tz.Location {
+			continue
+		}
+		start, err := eventStart(e)
+		if err != nil || start.Before(from) {
+			continue
+		}
+		ev := icsEvent{Title: e.Title, Start: start.In(loc), Duration: time.Duration(e.Duration) * time.Minute}
+		if before, err := time.ParseDuration(e.Alarm); err == nil && e.Alarm != "" {
+			ev.Alarm = &before
+		}
+		list = append(list, ev)
+	}
+	return list
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// incident is the incident being handled, declared with the "!" key.
+type incident struct {
+	// Start is when it was declared, in UTC.
+	Start time.Time `json:"start"`
+}
+
+// currentIncident is the incident being handled, or nil; it is kept in the runtime state (see runstate.go).
+var currentIncident *incident
+
+// declareIncident starts incident mode, with the incident starting now.
+func declareIncident(now time.Time) {
+	if currentIncident != nil {
+		showNotification("An incident is already being handled; ! resolves it")
+		return
+	}
+	currentIncident = &incident{Start: now.UTC().Truncate(time.Second)}
+	logger.Info("incident declared", "start", currentIncident.Start)
+	showAlert(fmt.Sprintf("Incident declared at %s UTC: x exports the timeline, ! resolves it", currentIncident.Start.Format("15:04:05")))
+}
+
+/**
+ * This function resolves the incident and leaves incident mode. The timeline stub is
+ * exported a last time, now with the resolution, so that it is never lost.
+ *
+ * @param now - The current time.
+ */
+func resolveIncident(now time.Time) {
+	if currentIncident == nil {
+		return
+	}
+	path, err := exportIncidentTimeline(now, true)
+	elapsed := now.Sub(currentIncident.Start)
+	logger.Info("incident resolved", "start", currentIncident.Start, "elapsed", elapsed)
+	currentIncident = nil
+	if err != nil {
+		showWarning("Incident resolved, but the timeline was not saved: " + err.Error())
+		return
+	}
+	showNotification(fmt.Sprintf("Incident resolved after %s; the timeline is in %s", formatIncidentElapsed(elapsed), path))
+}
+
+// formatIncidentElapsed formats the time since an incident started as HH:MM:SS (hours keep counting past 23).
+func formatIncidentElapsed(d time.Duration) string {
+	s := int(max(d, 0) / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// incidentClock formats a zone's local time of the incident start, in the zone's hour format, e.g. "3:44 PM Sat".
+func incidentClock(tz TimezoneConfig, local time.Time) string {
+	if uses24Hour(tz) {
+		return local.Format("15:04 Mon")
+	}
+	return local.Format("3:04 PM Mon")
+}
+
+// incidentLine returns the zone's incident annotation, the local time the incident started there, or "" without one.
+func incidentLine(tz TimezoneConfig, local time.Time) string {
+	if currentIncident == nil {
+		return ""
+	}
+	return "\x1b[31mincident began " + incidentClock(tz, currentIncident.Start.In(local.Location())) + "\x1b[0m"
+}
+
+/**
+ * This function renders the incident timer in the primary view while an incident is
+ * handled: the elapsed time in big red digits (MM:SS for the first hour, then HH:MM),
+ * the start in UTC, and as many zones' local start times as fit, so that everyone on a
+ * bridge across regions reads the same timeline.
+ *
+ * @param now - The current time.
+ * @param width - The inner width of the view.
+ * @param height - The inner height of the view.
+ * @returns The lines of the view.
+ */
+func renderIncidentLines(now time.Time, width, height int) []string {
+	elapsed := now.Sub(currentIncident.Start)
+	summary := fmt.Sprintf("\x1b[1;31m%s\x1b[0m elapsed since \x1b[1m%s UTC\x1b[0m",
+		formatIncidentElapsed(elapsed), currentIncident.Start.Format("2006-01-02 15:04:05"))
+	var lines []string
+	if height < 8 {
+		lines = []string{render.Center(summary, width)}
+	} else {
+		big := formatIncidentElapsed(elapsed)
+		if elapsed < time.Hour {
+			big = big[3:]
+		} else {
+			big = big[:5]
+		}
+		lines = []string{""}
+		for _, line := range render.PrintTimeASCII(big) {
+			lines = append(lines, render.Center("\x1b[31m"+line+"\x1b[0m", width))
+		}
+		lines = append(lines, render.Center(summary, width))
+	}
+	var starts []string
+	for _, tz := range displayZones() {
+		if loc, ok := locations[tz.Name]; ok {
+			starts = append(starts, tz.Name+" "+incidentClock(tz, currentIncident.Start.In(loc)))
+		}
+	}
+	line := ""
+	for _, start := range starts {
+		if len(lines) >= height {
+			break
+		}
+		if line != "" && render.TextWidth(line)+3+render.TextWidth(start) > width-2 {
+			lines = append(lines, render.Center("\x1b[90m"+line+"\x1b[0m", width))
+			line = ""
+		}
+		if line != "" {
+			line += " · "
+		}
+		line += start
+	}
+	if line != "" && len(lines) < height {
+		lines = append(lines, render.Center("\x1b[90m"+line+"\x1b[0m", width))
+	}
+	return lines
+}
+
+// getIncidentPath returns the timeline stub's file in the home directory, named after the incident's start.
+func getIncidentPath(start time.Time) string {
+	return filepath.Join(homeDir(), "kairos-incident-"+start.UTC().Format("20060102-150405")+".md")
+}
+
+/**
+ * This function writes the incident's timeline stub, a Markdown skeleton for the
+ * post-incident review: the start (and resolution) in UTC, the start in every zone's
+ * local time, and a UTC timeline table to fill in. It is written again on every export,
+ * so copy it elsewhere before editing it.
+ *
+ * @param now - The current time.
+ * @param resolved - Whether the incident is resolved now.
+ * @returns The file written, or an error.
+ */
+func exportIncidentTimeline(now time.Time, resolved bool) (string, error) {
+	start := currentIncident.Start
+	end := "ongoing"
+	if resolved {
+		end = now.UTC().Format("2006-01-02 15:04:05") + " UTC"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Incident %s\n\n", start.Format("2006-01-02 15:04 UTC"))
+	fmt.Fprintf(&b, "- **Started:** %s UTC\n", start.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- **Resolved:** %s\n", end)
+	fmt.Fprintf(&b, "- **Duration:** %s\n\n", formatIncidentElapsed(now.Sub(start)))
+	b.WriteString("## Start in each zone\n\n| Zone | Local time |\n| --- | --- |\n")
+	for _, tz := range displayZones() {
+		if loc, ok := locations[tz.Name]; ok {
+			local := start.In(loc)
+			fmt.Fprintf(&b, "| %s | %s %s (%s) |\n", tz.Name, local.Format("2006-01-02"), incidentClock(tz, local), local.Format("MST"))
+		}
+	}
+	b.WriteString("\n## Timeline (UTC)\n\n| Time | Event |\n| --- | --- |\n")
+	fmt.Fprintf(&b, "| %s | Incident declared |\n", start.Format("15:04"))
+	b.WriteString("|  |  |\n")
+	if resolved {
+		fmt.Fprintf(&b, "| %s | Resolved |\n", now.UTC().Format("15:04"))
+	}
+	path := getIncidentPath(start)
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", err
+	}
+	logger.Info("incident timeline exported", "path", path)
+	return path, nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// interactionStale is how long after the last interaction a zone's line turns yellow.
+const interactionStale = 7 * 24 * time.Hour
+
+var (
+	// interactions maps zone names to when I last interacted with their team, as in the interactions file.
+	interactions = map[string]time.Time{}
+	// interactionsModTime is the interactions file's modification time when it was last read.
+	interactionsModTime time.Time
+)
+
+// touchCommand builds the `kairos touch` command.
+func touchCommand() *command {
+	return &command{
+		Name:    "touch",
+		Usage:   `"Name"`,
+		Short:   "Records that you were just in touch with a zone's team, for track_interactions (e.g. from a hook)",
+		MinArgs: 1,
+		MaxArgs: 1,
+		Run: func(args []string) error {
+			tz, _, err := findZone(args[0])
+			if err != nil {
+				return err
+			}
+			if err := recordInteraction(tz.Name, time.Now()); err != nil {
+				return err
+			}
+			fmt.Printf("Recorded an interaction with %s\n", tz.Name)
+			return nil
+		},
+	}
+}
+
+// getInteractionsPath returns the interactions file, next to (and named after) the config file.
+func getInteractionsPath() string {
+	return strings.TrimSuffix(getConfigPath(), filepath.Ext(getConfigPath())) + "_interactions.json"
+}
+
+/**
+ * This function reads the interactions file if it changed since it was last read, so
+ * that the dashboard picks up `kairos touch` run from a hook or another shell. It is
+ * called on every tick while track_interactions is on, and costs a stat otherwise.
+ */
+func loadInteractions() {
+	fi, err := os.Stat(getInteractionsPath())
+	if err != nil || fi.ModTime().Equal(interactionsModTime) {
+		return
+	}
+	data, err := os.ReadFile(getInteractionsPath())
+	if err != nil {
+		return
+	}
+	loaded := map[string]time.Time{}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		logger.Warn("interactions not loaded", "path", getInteractionsPath(), "err", err)
+		return
+	}
+	interactions, interactionsModTime = loaded, fi.ModTime()
+}
+
+/**
+ * This function records an interaction with a zone's team in the interactions file. The
+ * file is read again first, so that interactions recorded meanwhile by another kairos
+ * are kept. It is a file of its own, since it changes far more often than the config.
+ *
+ * @param zone - The zone's name.
+ * @param at - When the interaction happened.
+ * @returns An error if the file cannot be written.
+ */
+func recordInteraction(zone string, at time.Time) error {
+	loadInteractions()
+	interactions[zone] = at.UTC()
+	data, err := json.MarshalIndent(interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(getInteractionsPath(), data, 0644); err != nil {
+		return err
+	}
+	if fi, err := os.Stat(getInteractionsPath()); err == nil {
+		interactionsModTime = fi.ModTime()
+	}
+	logger.Debug("interaction recorded", "zone", zone)
+	return nil
+}
+
+// touchZone records an interaction from the dashboard, e.g. a zone's number key, when track_interactions is on.
+func touchZone(zone string) {
+	if !settings.TrackInteractions {
+		return
+	}
+	if err := recordInteraction(zone, time.Now()); err != nil {
+		logger.Warn("interaction not recorded", "zone", zone, "err", err)
+	}
+}
+
+// formatSince formats how long ago an interaction was, coarsely: "just now", "12m ago", "5h ago", or "3d ago".
+func formatSince(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
+/**
+ * This function returns the zone's interaction line, "last synced 3d ago", a reminder
+ * of which distributed reports have not heard from me lately. It turns yellow after
+ * interactionStale.
+ *
+ * @param tz - The zone.
+ * @param now - The current time.
+ * @returns The line, or "" with track_interactions off.
+ */
+func interactionLine(tz TimezoneConfig, now time.Time) string {
+	if !settings.TrackInteractions {
+		return ""
+	}
+	at, ok := interactions[tz.Name]
+	if !ok {
+		return "\x1b[90mlast synced: never\x1b[0m"
+	}
+	since := now.Sub(at)
+	if since >= interactionStale {
+		return "\x1b[33mlast synced " + formatSince(since) + "\x1b[0m"
+	}
+	return "\x1b[90mlast synced " + formatSince(since) + "\x1b[0m"
+}
+
+// checkInteractions keeps the interactions up to date with the file on every tick.
+func checkInteractions(now time.Time) {
+	if settings.TrackInteractions {
+		loadInteractions()
+	}
+}
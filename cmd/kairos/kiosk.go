@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"strings"
+)
+
+var (
+	// kioskMode is set by --kiosk: a read-only dashboard for wall-mounted displays.
+	kioskMode bool
+	// kioskLock is set by --kiosk-lock: kiosk mode without even a key to quit.
+	kioskLock bool
+	// clockScale is set by --scale and enlarges the clock digits when the view has room.
+	clockScale = 1
+	// zenMode is toggled with the "z" key and hides everything but the primary clock.
+	zenMode bool
+)
+
+// registerDashboardFlags adds the flags that change how the dashboard is drawn.
+func registerDashboardFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&kioskMode, "kiosk", kioskMode, "Read-only dashboard: only Ctrl+C works and the footer hints are hidden")
+	fs.BoolVar(&kioskLock, "kiosk-lock", kioskLock, "Like --kiosk, but Ctrl+C is disabled too (stop it with SIGTERM)")
+	fs.IntVar(&clockScale, "scale", clockScale, "Enlarge the clock digits by this factor when they fit (1-4)")
+	fs.IntVar(&cycleSeconds, "cycle", cycleSeconds, "Rotate the primary view through the zones every N seconds (p pauses)")
+	fs.StringVar(&countdownTarget, "countdown", countdownTarget, "Open the full-screen countdown to this event (by title) or time (c closes)")
+	fs.StringVar(&pinnedZone, "split", pinnedZone, "Pin this zone beside the primary one in a split top view (Ctrl+S in the palette)")
+	fs.BoolVar(&heatmapMode, "heatmap", heatmapMode, "Open the workweek heat map of business hours across the zones (g closes)")
+	fs.StringVar(&obsPath, "obs", obsPath, "Instead of the dashboard, write the countdown line (see obs_format) to this file for OBS")
+	fs.DurationVar(&obsInterval, "obs-interval", obsInterval, "How often --obs refreshes the file")
+}
+
+/**
+ * This function checks the dashboard flags after parsing; --kiosk-lock implies --kiosk,
+ * and --countdown opens the countdown view.
+ *
+ * @returns A usage error if the scale or the carousel interval is out of range.
+ */
+func applyDashboardFlags() error {
+	if kioskLock {
+		kioskMode = true
+	}
+	if clockScale < 1 || clockScale > 4 {
+		return usageErrorf(nil, "invalid --scale %d (expected 1 to 4)", clockScale)
+	}
+	if cycleSeconds < 0 {
+		return usageErrorf(nil, "invalid --cycle %d (expected seconds, or 0 to disable)", cycleSeconds)
+	}
+	if countdownTarget != "" {
+		countdownMode = true
+	}
+	return nil
+}
+
+/**
+ * This function enlarges block-character art by repeating every character horizontally
+ * and every line vertically.
+ *
+ * @param art - The lines of the art, e.g. from PrintTimeASCII.
+ * @param scale - The factor; 1 returns the art unchanged.
+ * @returns The enlarged lines.
+ */
+func scaleASCII(art []string, scale int) []string {
+	if scale <= 1 {
+		return art
+	}
+	var lines []string
+	for _, line := range art {
+		var b strings.Builder
+		for _, r := range line {
+			b.WriteString(strings.Repeat(string(r), scale))
+		}
+		for i := 0; i < scale; i++ {
+			lines = append(lines, b.String())
+		}
+	}
+	return lines
+}
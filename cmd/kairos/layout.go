@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/jroimartin/gocui"
+)
+
+const (
+	// maxGridColumns is the most columns the automatic layout uses, on ultrawide terminals.
+	maxGridColumns = 4
+	// minTileWidth is the narrowest tile the automatic layout makes, enough for the text clock and date.
+	minTileWidth = 24
+
+	// minDashboardWidth and minDashboardHeight are the smallest terminal the dashboard is drawn in.
+	minDashboardWidth  = 40
+	minDashboardHeight = 12
+	// resizeDebounce is how long the terminal size must hold still before the views are laid out again.
+	resizeDebounce = 150 * time.Millisecond
+)
+
+var (
+	// visibleViews is how many zone views the last rendered dashboard showed, primary included.
+	visibleViews = 7
+
+	// lastWidth and lastHeight are the terminal size the views were last laid out for.
+	lastWidth, lastHeight int
+	// resizedAt is when the terminal size last changed.
+	resizedAt time.Time
+)
+
+// gridLayout is the arrangement of the secondary zones below the primary view.
+type gridLayout struct {
+	Cols, Rows int
+}
+
+/**
+ * This function parses a layout override such as "2x4" (two columns, four rows).
+ * "auto" (or "") selects the automatic layout and returns a zero gridLayout.
+ *
+ * @param s - The layout setting.
+ * @returns The layout, or an error if it is not COLSxROWS with both between 1 and 8.
+ */
+func parseGridLayout(s string) (gridLayout, error) {
+	if s == "" || s == "auto" {
+		return gridLayout{}, nil
+	}
+	c, r, ok := strings.Cut(strings.ToLower(s), "x")
+	cols, err1 := strconv.Atoi(c)
+	rows, err2 := strconv.Atoi(r)
+	if !ok || err1 != nil || err2 != nil || cols < 1 || rows < 1 || cols > 8 || rows > 8 {
+		return gridLayout{}, fmt.Errorf("invalid layout '%s' (expected auto or COLSxROWS, e.g. 3x2)", s)
+	}
+	return gridLayout{Cols: cols, Rows: rows}, nil
+}
+
+// setLayout validates and stores the layout setting.
+func setLayout(v string) error {
+	l, err := parseGridLayout(strings.ToLower(v))
+	if err != nil {
+		return err
+	}
+	settings.Layout = ""
+	if l.Cols > 0 {
+		settings.Layout = fmt.Sprintf("%dx%d", l.Cols, l.Rows)
+	}
+	return nil
+}
+
+/**
+ * This function picks the grid for the secondary zones. A layout override is used as
+ * is; otherwise every column count up to maxGridColumns is tried, and the one whose
+ * tiles are largest is chosen. A terminal cell is about twice as tall as it is wide,
+ * so a tile's size is taken as the smaller of half its width and its height. Ties go to
+ * the grid with fewer empty cells, then to fewer columns. Columns narrower than
+ * minTileWidth are only used when there is no other choice.
+ *
+ * @param n - The number of secondary zones.
+ * @param width - The width of the grid area.
+ * @param height - The height of the grid area.
+ * @returns The grid; it has no cells when n is 0.
+ */
+func chooseGridLayout(n, width, height int) gridLayout {
+	if l, err := parseGridLayout(settings.Layout); err == nil && l.Cols > 0 {
+		return l
+	}
+	if n == 0 {
+		return gridLayout{}
+	}
+	best, bestSize, bestEmpty := gridLayout{Cols: 1, Rows: n}, -1, 0
+	for cols := 1; cols <= min(n, maxGridColumns); cols++ {
+		if cols > 1 && width/cols < minTileWidth {
+			break
+		}
+		rows := (n + cols - 1) / cols
+		size := min(width/cols/2, height/rows)
+		empty := cols*rows - n
+		if size > bestSize || (size == bestSize && empty < bestEmpty) {
+			best, bestSize, bestEmpty = gridLayout{Cols: cols, Rows: rows}, size, empty
+		}
+	}
+	return best
+}
+
+// tooSmall reports whether a terminal is below the dashboard's minimum size.
+func tooSmall(maxX, maxY int) bool {
+	return maxX < minDashboardWidth || maxY < minDashboardHeight
+}
+
+// renderTooSmall renders the message shown instead of the dashboard on a terminal that is too small.
+func renderTooSmall(maxX, maxY int) viewFrame {
+	lines := make([]string, max(0, maxY/2-1))
+	lines = append(lines,
+		render.Center("\x1b[1mTerminal too small\x1b[0m", maxX),
+		render.Center(fmt.Sprintf("need %dx%d, have %dx%d", minDashboardWidth, minDashboardHeight, maxX, maxY), maxX))
+	return viewFrame{Name: "toosmall", X0: -1, Y0: -1, X1: maxX, Y1: maxY, Lines: lines}
+}
+
+/**
+ * This function debounces resizes. Dragging a window edge resizes the terminal many
+ * times a second, so after a size change the views keep their old layout until the
+ * size has held still for resizeDebounce, and a redraw is scheduled for then.
+ *
+ * @param g - The dashboard's gocui.Gui.
+ * @param maxX - The terminal width.
+ * @param maxY - The terminal height.
+ * @returns Whether the views should be laid out now.
+ */
+func resizeSettled(g *gocui.Gui, maxX, maxY int) bool {
+	first := lastWidth == 0 && lastHeight == 0
+	if maxX != lastWidth || maxY != lastHeight {
+		lastWidth, lastHeight, resizedAt = maxX, maxY, time.Now()
+		if first {
+			return true
+		}
+		time.AfterFunc(resizeDebounce, func() {
+			// Once the dashboard is exiting, nothing reads the update any more.
+			if workerCtx.Err() == nil {
+				g.Update(func(*gocui.Gui) error { return nil })
+			}
+		})
+		return false
+	}
+	return time.Since(resizedAt) >= resizeDebounce
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gpsBehindTAI is how far GPS time runs behind TAI: it matched UTC at its 1980 epoch, when
+// TAI−UTC was 19 s, and has had no leap seconds since.
+const gpsBehindTAI = 19
+
+// leapSecond is a step of TAI−UTC in the leap second table.
+type leapSecond struct {
+	// At is the first instant with the new offset, i.e. just after 23:59:60 UTC.
+	At time.Time
+	// Offset is TAI−UTC from then on, in seconds.
+	Offset int
+}
+
+// leapTable is the leap second table. The IERS announces leap seconds about six months
+// ahead, so the table is only complete until it expires.
+type leapTable struct {
+	Steps   []leapSecond
+	Expires time.Time
+}
+
+// builtinLeapSeconds is leap-seconds.list as of IERS Bulletin C 70, for systems without the file.
+const builtinLeapSeconds = `#@	3991593600
+2272060800	10
+2287785600	11
+2303683200	12
+2335219200	13
+2366755200	14
+2398291200	15
+2429913600	16
+2461449600	17
+2492985600	18
+2524521600	19
+2571782400	20
+2603318400	21
+2634854400	22
+2698012800	23
+2776982400	24
+2840140800	25
+2871676800	26
+2918937600	27
+2950473600	28
+2982009600	29
+3029443200	30
+3076704000	31
+3124137600	32
+3345062400	33
+3439756800	34
+3550089600	35
+3644697600	36
+3692217600	37
+`
+
+var (
+	leapOnce        sync.Once
+	cachedLeapTable leapTable
+)
+
+/**
+ * This function returns the leap second table: the leap-seconds.list installed by
+ * `kairos tzupdate` if there is one, then the system's, then the built-in copy, whichever
+ * of them expires last. It is read once per run.
+ *
+ * @returns The table.
+ */
+func getLeapTable() leapTable {
+	leapOnce.Do(func() {
+		cachedLeapTable, _ = parseLeapSeconds(builtinLeapSeconds)
+		paths := []string{filepath.Join(getZoneinfoDir(), "leap-seconds.list")}
+		for _, dir := range zoneinfoDirs {
+			paths = append(paths, filepath.Join(dir, "leap-seconds.list"))
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			table, err := parseLeapSeconds(string(data))
+			if err != nil {
+				logger.Warn("leap second table not read", "path", path, "err", err)
+				continue
+			}
+			if table.Expires.After(cachedLeapTable.Expires) {
+				cachedLeapTable = table
+			}
+		}
+	})
+	return cachedLeapTable
+}
+
+/**
+ * This function parses the IERS leap-seconds.list format: one "NTP-timestamp offset" line
+ * per step, and the expiry as a "#@ NTP-timestamp" line; other comments are ignored.
+ *
+ * @param data - The file.
+ * @returns The table, or an error if a line cannot be read or there are no steps.
+ */
+func parseLeapSeconds(data string) (leapTable, error) {
+	var table leapTable
+	ntpTime := func(field string) (time.Time, error) {
+		secs, err := strconv.ParseInt(field, 10, 64)
+		return time.Unix(secs-ntpEpochOffset, 0).UTC(), err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "#@"); ok {
+			expires, err := ntpTime(strings.TrimSpace(rest))
+			if err != nil {
+				return table, fmt.Errorf("invalid expiry line '%s'", line)
+			}
+			table.Expires = expires
+			continue
+		}
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return table, fmt.Errorf("invalid line '%s'", line)
+		}
+		at, err := ntpTime(fields[0])
+		offset, err2 := strconv.Atoi(fields[1])
+		if err != nil || err2 != nil {
+			return table, fmt.Errorf("invalid line '%s'", line)
+		}
+		table.Steps = append(table.Steps, leapSecond{At: at, Offset: offset})
+	}
+	if len(table.Steps) == 0 {
+		return table, fmt.Errorf("no leap seconds listed")
+	}
+	return table, scanner.Err()
+}
+
+// taiOffset returns TAI−UTC in seconds at an instant (10 s before the table starts in 1972).
+func (t leapTable) taiOffset(now time.Time) int {
+	offset := t.Steps[0].Offset
+	for _, step := range t.Steps {
+		if now.Before(step.At) {
+			break
+		}
+		offset = step.Offset
+	}
+	return offset
+}
+
+// nextLeapSecond returns the first announced leap second after an instant, if there is one.
+func (t leapTable) nextLeapSecond(now time.Time) (leapSecond, bool) {
+	for _, step := range t.Steps {
+		if step.At.After(now) {
+			return step, true
+		}
+	}
+	return leapSecond{}, false
+}
+
+/**
+ * This function formats the precision strip: TAI and GPS time with their offsets from
+ * UTC, then the next announced leap second, how long none is due, or that the table has
+ * expired and may miss one.
+ *
+ * @param now - The current time.
+ * @returns The strip, e.g. "TAI 12:35:14 (+37s) · GPS 12:34:55 (+18s) · no leap second until 28 Jun 2026".
+ */
+func formatPrecisionStrip(now time.Time) string {
+	table := getLeapTable()
+	offset := table.taiOffset(now)
+	tai := now.UTC().Add(time.Duration(offset) * time.Second)
+	gps := tai.Add(-gpsBehindTAI * time.Second)
+	line := fmt.Sprintf("TAI \x1b[36m%s\x1b[0m (+%ds) · GPS \x1b[36m%s\x1b[0m (+%ds)",
+		tai.Format("15:04:05"), offset, gps.Format("15:04:05"), offset-gpsBehindTAI)
+	if next, ok := table.nextLeapSecond(now); ok {
+		// The leap second ends the day before the step; a negative one skips 23:59:59 instead.
+		day := next.At.Add(-time.Second).Format("02 Jan 2006")
+		if next.Offset < offset {
+			return line + fmt.Sprintf(" · \x1b[33mnegative leap second: %s 23:59:59 UTC is skipped\x1b[0m", day)
+		}
+		return line + fmt.Sprintf(" · \x1b[33mleap second %s 23:59:60 UTC\x1b[0m", day)
+	}
+	if now.After(table.Expires) {
+		return line + fmt.Sprintf(" · \x1b[33mleap table expired %s\x1b[0m", table.Expires.Format("02 Jan 2006"))
+	}
+	return line + " · no leap second until " + table.Expires.Format("02 Jan 2006")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// workerStopTimeout is how long exiting waits for the workers to stop; one blocked on a
+// slow request is left behind rather than holding up the exit.
+const workerStopTimeout = 2 * time.Second
+
+var (
+	// workerCtx is canceled when the dashboard or daemon exits; every worker started with
+	// goWorker stops with it.
+	workerCtx    = context.Background()
+	stopWorkerFn context.CancelFunc
+	// workerGroup tracks the running workers, so stopWorkers can wait for them.
+	workerGroup sync.WaitGroup
+)
+
+/**
+ * This function creates the root context for the workers of a dashboard or daemon run.
+ * The caller defers the returned function, which cancels the context and waits (up to
+ * workerStopTimeout) for the workers to return, so their goroutines and timers do not
+ * outlive the GUI.
+ *
+ * @returns A function that stops the workers.
+ */
+func startWorkers() func() {
+	workerCtx, stopWorkerFn = context.WithCancel(context.Background())
+	return stopWorkers
+}
+
+// stopWorkers cancels the workers' context and waits for them to return.
+func stopWorkers() {
+	if stopWorkerFn == nil {
+		return
+	}
+	stopWorkerFn()
+	done := make(chan struct{})
+	go func() {
+		workerGroup.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		logger.Debug("workers stopped")
+	case <-time.After(workerStopTimeout):
+		logger.Warn("workers still running at exit", "waited", workerStopTimeout)
+	}
+}
+
+// goWorker runs f in a worker goroutine that is tracked by stopWorkers; f returns when ctx is done.
+func goWorker(name string, f func(ctx context.Context)) {
+	ctx := workerCtx
+	workerGroup.Add(1)
+	go func() {
+		defer workerGroup.Done()
+		defer recoverWorker(name)
+		f(ctx)
+	}()
+}
+
+// sleepCtx waits for d, or until ctx is done; it reports whether the full time passed.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
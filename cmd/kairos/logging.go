@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// maxLogSize is the size at which the log file is rotated on startup.
+const maxLogSize = 1 << 20
+
+// maxLogFiles is how many rotated log files (kairos.log.1, kairos.log.2, ...) are kept.
+const maxLogFiles = 3
+
+var (
+	// logger records diagnostics to the log file, never to the screen, since the dashboard
+	// owns the terminal. It discards everything until initLogging runs.
+	logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	// debugMode is set by the global --debug flag and lowers the log level to debug.
+	debugMode bool
+	// logFile is the open log file, if any.
+	logFile *os.File
+)
+
+/**
+ * This function returns the path of the log file: $KAIROS_LOG if set, and otherwise
+ * kairos.log in the user's cache directory (e.g. ~/.cache/kairos on Linux).
+ *
+ * @returns The full path to the log file.
+ */
+func getLogPath() string {
+	if env := os.Getenv("KAIROS_LOG"); env != "" {
+		return env
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "kairos", "kairos.log")
+}
+
+/**
+ * This function opens the log file and points logger at it. Upgrades, warnings, and
+ * errors are always recorded; --debug also records config loads and saves, commands,
+ * and other routine events. A log that cannot be opened is never fatal.
+ */
+func initLogging() {
+	if logFile != nil {
+		return
+	}
+	path := getLogPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		warnLogging(err)
+		return
+	}
+	rotateLog(path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		warnLogging(err)
+		return
+	}
+	logFile = f
+	level := slog.LevelInfo
+	if debugMode {
+		level = slog.LevelDebug
+	}
+	logger = slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: level}))
+	logger.Debug("kairos started", "args", os.Args[1:], "config", getConfigPath())
+}
+
+// warnLogging reports a log file that cannot be opened, but only when it was asked for with --debug.
+func warnLogging(err error) {
+	if debugMode {
+		fmt.Fprintf(os.Stderr, "\x1b[33mCannot open the log file: %v\x1b[0m\n", err)
+	}
+}
+
+/**
+ * This function rotates the log once it reaches maxLogSize: kairos.log becomes
+ * kairos.log.1, kairos.log.1 becomes kairos.log.2, and so on, dropping the oldest.
+ *
+ * @param path - The log file path.
+ */
+func rotateLog(path string) {
+	fi, err := os.Stat(path)
+	if err != nil || fi.Size() < maxLogSize {
+		return
+	}
+	os.Remove(fmt.Sprintf("%s.%d", path, maxLogFiles))
+	for i := maxLogFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	os.Rename(path, path+".1")
+}
+
+// closeLogging flushes and closes the log file.
+func closeLogging() {
+	if logFile != nil {
+		logFile.Close()
+		logFile = nil
+	}
+}
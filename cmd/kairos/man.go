@@ -0,0 +1,194 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// manOut is set by `kairos man --out`.
+var manOut string
+
+// manEnvironment documents the environment variables kairos reads, for the ENVIRONMENT section.
+var manEnvironment = [][2]string{
+	{"KAIROS_CONFIG", "The config file, instead of ~/.kairos_config.json (--config takes precedence)."},
+	{"KAIROS_LOG", "The log file, instead of kairos.log in the user cache directory."},
+	{"KAIROS_SOCKET", "The control socket, instead of kairos.sock next to the log file."},
+	{"KAIROS_TOKEN", "The token for --server, when --token is not given."},
+	{"KAIROS_SECRET_KEY", "The passphrase of the encrypted tokens in the config, instead of the key file."},
+	{"PAGER", "The pager for long help output (default \"less -R\"); \"cat\" turns paging off."},
+	{"TZ", "The local timezone, as for every program."},
+}
+
+// manCommand builds the `kairos man` command.
+func manCommand() *command {
+	return &command{
+		Name: "man", Usage: "[command [subcommand]]", MaxArgs: 2,
+		Short: "Prints the man page of kairos or a command (--out DIR writes them all)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&manOut, "out", "", "Write kairos.1 and a page per command to this directory, e.g. /usr/local/share/man/man1")
+		},
+		Run: runMan,
+	}
+}
+
+/**
+ * This function handles `kairos man`: it prints the man page of kairos, or of a command,
+ * as roff for `man -l -`; with --out it writes every page to a directory instead. The
+ * pages are generated from the command table and flag sets, like `kairos help`, so they
+ * always match the commands kairos accepts.
+ *
+ * @param args - The command to print the page of, with its subcommand, or none for kairos(1).
+ * @returns An error if the command is unknown or the pages cannot be written.
+ */
+func runMan(args []string) error {
+	// Writing a command's flags registers them again, resetting manOut to its default.
+	if dir := manOut; dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		pages := map[string]func(w io.Writer){"kairos.1": writeMainManPage}
+		var add func(list []*command)
+		add = func(list []*command) {
+			for _, c := range list {
+				c := c
+				pages[manPageName(c)+".1"] = func(w io.Writer) { writeCommandManPage(w, c) }
+				add(c.Subcommands)
+			}
+		}
+		add(commands)
+		for name, write := range pages {
+			f, err := os.Create(filepath.Join(dir, name))
+			if err != nil {
+				return err
+			}
+			write(f)
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("Wrote %d man pages to %s\n", len(pages), dir)
+		return nil
+	}
+	if len(args) == 0 {
+		writeMainManPage(os.Stdout)
+		return nil
+	}
+	list, cmd := commands, (*command)(nil)
+	for _, name := range args {
+		if cmd = findCommand(list, name); cmd == nil {
+			return usageErrorf(nil, "Unknown command: %s", strings.Join(args, " "))
+		}
+		list = cmd.Subcommands
+	}
+	writeCommandManPage(os.Stdout, cmd)
+	return nil
+}
+
+// manPageName is a command's page name, e.g. "kairos-event-add".
+func manPageName(c *command) string {
+	return strings.ReplaceAll(c.path(), " ", "-")
+}
+
+// roffEscape escapes text for roff: backslashes, dashes, double quotes (which would group macro
+// arguments), and dots or quotes that would start a request.
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`, `"`, `\(dq`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// writeManHeader writes the title line and the NAME section.
+func writeManHeader(w io.Writer, name, summary string) {
+	fmt.Fprintf(w, ".TH %s 1 \"\" \"kairos %s\" \"User Commands\"\n", strings.ToUpper(name), roffEscape(getBuildInfo().Version))
+	fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", roffEscape(name), roffEscape(summary))
+}
+
+// writeManFlags writes a flag set as tagged paragraphs.
+func writeManFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(w, ".TP\n.B \\-\\-%s", roffEscape(f.Name))
+		if _, isBool := f.Value.(interface{ IsBoolFlag() bool }); !isBool {
+			fmt.Fprint(w, " \\fIvalue\\fR")
+		}
+		fmt.Fprintf(w, "\n%s\n", roffEscape(f.Usage))
+	})
+}
+
+/**
+ * This function writes kairos(1): the synopsis, every command with its description, the
+ * global and dashboard flags, the environment, the files, and the command pages.
+ *
+ * @param w - Where to write the page.
+ */
+func writeMainManPage(w io.Writer) {
+	writeManHeader(w, "kairos", "world clock dashboard for the terminal")
+	fmt.Fprint(w, ".SH SYNOPSIS\n.B kairos\n[\\fIflags\\fR]\n.br\n.B kairos\n\\fIcommand\\fR [\\fIarguments\\fR] [\\fIflags\\fR]\n")
+	fmt.Fprint(w, ".SH DESCRIPTION\nWithout a command, kairos launches the dashboard: the primary zone's clock on top and the other zones in a grid below, "+
+		"with business hours, day progress, and a footer of widgets. The commands manage the zones and settings, and answer time questions from the shell.\n")
+	fmt.Fprint(w, ".SH COMMANDS\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, ".TP\n.B kairos %s\n%s (see \\fB%s\\fR(1))\n", roffEscape(strings.TrimSpace(c.Name+" "+c.Usage)), roffEscape(c.Short), roffEscape(manPageName(c)))
+	}
+	fmt.Fprint(w, ".SH OPTIONS\nThese flags are accepted by every command.\n")
+	global := flag.NewFlagSet("global", flag.ContinueOnError)
+	registerGlobalFlags(global)
+	writeManFlags(w, global)
+	fmt.Fprint(w, ".SS Dashboard options\nThese flags change the dashboard, and are also accepted by \\fBkairos render\\fR.\n")
+	dashboard := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+	registerDashboardFlags(dashboard)
+	writeManFlags(w, dashboard)
+	fmt.Fprint(w, ".SH ENVIRONMENT\n")
+	for _, env := range manEnvironment {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", env[0], roffEscape(env[1]))
+	}
+	fmt.Fprint(w, ".SH FILES\n.TP\n.I ~/.kairos_config.json\nThe config: zones, events, and settings (\\fBkairos config\\fR).\n"+
+		".TP\n.I ~/.kairos_config.NAME.json\nThe config of profile NAME (\\-\\-profile).\n"+
+		".TP\n.I ~/.cache/kairos/\nThe log, the control socket, and the tz database installed by \\fBkairos tzupdate\\fR (the user cache directory on other systems).\n")
+	var pages []string
+	for _, c := range commands {
+		pages = append(pages, fmt.Sprintf("\\fB%s\\fR(1)", roffEscape(manPageName(c))))
+	}
+	fmt.Fprintf(w, ".SH SEE ALSO\n%s\n", strings.Join(pages, ", "))
+}
+
+/**
+ * This function writes the page of a command: its synopsis and description, its
+ * subcommands, and its own flags; the global flags are in kairos(1).
+ *
+ * @param w - Where to write the page.
+ * @param c - The command.
+ */
+func writeCommandManPage(w io.Writer, c *command) {
+	writeManHeader(w, manPageName(c), c.Short)
+	usage := c.Usage
+	if len(c.Subcommands) > 0 {
+		usage = strings.TrimSpace("\\fIsubcommand\\fR " + roffEscape(usage))
+	} else {
+		usage = roffEscape(usage)
+	}
+	fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n%s [\\fIflags\\fR]\n", roffEscape(c.path()), usage)
+	fmt.Fprintf(w, ".SH DESCRIPTION\n%s.\n", roffEscape(strings.TrimSuffix(c.Short, ".")))
+	if len(c.Subcommands) > 0 {
+		fmt.Fprint(w, ".SH SUBCOMMANDS\n")
+		for _, sub := range c.Subcommands {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s (see \\fB%s\\fR(1))\n", roffEscape(strings.TrimSpace(sub.Name+" "+sub.Usage)), roffEscape(sub.Short), roffEscape(manPageName(sub)))
+		}
+	}
+	if c.Flags != nil {
+		fmt.Fprint(w, ".SH OPTIONS\n")
+		fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+		c.Flags(fs)
+		writeManFlags(w, fs)
+	}
+	fmt.Fprint(w, ".SH SEE ALSO\n\\fBkairos\\fR(1)")
+	if c.parent != nil {
+		fmt.Fprintf(w, ", \\fB%s\\fR(1)", roffEscape(manPageName(c.parent)))
+	}
+	fmt.Fprintln(w)
+}
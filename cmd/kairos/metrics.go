@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMetricInterval is how often zone metrics are refreshed unless metric_interval says otherwise.
+	defaultMetricInterval = time.Minute
+	// sparklineWidth is how many samples a zone's sparkline shows.
+	sparklineWidth = 20
+	// promPrefix marks a zone metric as a Prometheus query rather than a shell command.
+	promPrefix = "prom:"
+)
+
+// sparkTicks are the bars of a sparkline, from the lowest value to the highest.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+var (
+	// metricMu guards metricSeries, which the metric worker writes and the dashboard reads.
+	metricMu sync.Mutex
+	// metricSeries holds each zone's recent metric samples, oldest first, by zone name.
+	metricSeries = map[string][]float64{}
+)
+
+// metricInterval returns the metric refresh interval from the metric_interval setting.
+func metricInterval() time.Duration {
+	if d, err := time.ParseDuration(settings.MetricInterval); err == nil {
+		return d
+	}
+	return defaultMetricInterval
+}
+
+// setMetricInterval validates and stores the metric refresh interval ("default" restores a minute).
+func setMetricInterval(v string) error {
+	if v == "default" || v == "none" || v == "" {
+		settings.MetricInterval = ""
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 5*time.Second {
+		return fmt.Errorf("invalid metric interval '%s' (expected e.g. 30s or 5m, at least 5s)", v)
+	}
+	settings.MetricInterval = v
+	return nil
+}
+
+/**
+ * This function starts the metric worker if any zone has a metric. Every interval it runs
+ * each zone's command or Prometheus query off the GUI goroutine and keeps the last
+ * sparklineWidth samples for the zone's sparkline.
+ */
+func startMetricWorker() {
+	var zones []TimezoneConfig
+	for _, tz := range timezones {
+		if tz.Metric != "" {
+			zones = append(zones, tz)
+		}
+	}
+	if len(zones) == 0 {
+		return
+	}
+	interval := metricInterval()
+	goWorker("metric worker", func(ctx context.Context) {
+		lastErr := map[string]string{}
+		for {
+			for _, tz := range zones {
+				values, series, err := fetchMetric(ctx, tz, interval)
+				if err != nil {
+					if err.Error() != lastErr[tz.Name] {
+						logger.Warn("zone metric failed", "zone", tz.Name, "metric", tz.Metric, "err", err)
+					}
+					lastErr[tz.Name] = err.Error()
+					continue
+				}
+				delete(lastErr, tz.Name)
+				metricMu.Lock()
+				if !series {
+					values = append(metricSeries[tz.Name], values...)
+				}
+				metricSeries[tz.Name] = values[max(0, len(values)-sparklineWidth):]
+				metricMu.Unlock()
+			}
+			if !sleepCtx(ctx, interval) {
+				return
+			}
+		}
+	})
+}
+
+/**
+ * This function reads a zone's metric. A Prometheus query ("prom:" followed by PromQL)
+ * returns its whole recent range, one sample per interval. A shell command gets the zone
+ * as KAIROS_ZONE and KAIROS_LOCATION and prints either one number, the newest sample, or
+ * several, the whole series.
+ *
+ * @param ctx - Stops the command when the dashboard exits.
+ * @param tz - The zone.
+ * @param interval - The refresh interval, used as the Prometheus step.
+ * @returns The samples, whether they are the whole series, or an error.
+ */
+func fetchMetric(ctx context.Context, tz TimezoneConfig, interval time.Duration) ([]float64, bool, error) {
+	if query, ok := strings.CutPrefix(tz.Metric, promPrefix); ok {
+		values, err := queryPrometheus(strings.TrimSpace(query), interval)
+		return values, true, err
+	}
+	// A hung command is stopped rather than stalling every zone's sparkline.
+	ctx, cancel := context.WithTimeout(ctx, widgetTimeout)
+	defer cancel()
+	cmd := shellCommand(ctx, tz.Metric)
+	cmd.Env = append(os.Environ(), "KAIROS_ZONE="+tz.Name, "KAIROS_LOCATION="+tz.Location)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, false, err
+	}
+	var values []float64
+	for _, field := range strings.Fields(string(out)) {
+		v, err := strconv.ParseFloat(strings.TrimRight(field, ","), 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("the command printed '%s', not a number", field)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, false, fmt.Errorf("the command printed nothing")
+	}
+	return values, len(values) > 1, nil
+}
+
+/**
+ * This function runs a range query against the Prometheus server in prometheus_url,
+ * covering the last sparklineWidth intervals. If the query returns several series, the
+ * first is used.
+ *
+ * @param query - The PromQL query.
+ * @param step - The distance between samples.
+ * @returns The samples, oldest first, or an error.
+ */
+func queryPrometheus(query string, step time.Duration) ([]float64, error) {
+	if settings.PrometheusURL == "" {
+		return nil, fmt.Errorf("set prometheus_url to use Prometheus queries")
+	}
+	end := time.Now()
+	params := url.Values{
+		"query": {query},
+		"start": {strconv.FormatInt(end.Add(-step*sparklineWidth).Unix(), 10)},
+		"end":   {strconv.FormatInt(end.Unix(), 10)},
+		"step":  {strconv.Itoa(int(step.Seconds()))},
+	}
+	resp, err := httpClient.Get(strings.TrimRight(settings.PrometheusURL, "/") + "/api/v1/query_range?" + params.Encode())
+	if err != nil {
+		// The URL holds the time range, which would make every failure look new.
+		if uerr, ok := err.(*url.Error); ok {
+			return nil, uerr.Err
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Result []struct {
+				Values [][2]interface{} `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("unexpected Prometheus response: %s %v", resp.Status, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("Prometheus rejected the query: %s", defaultString(body.Error, resp.Status))
+	}
+	if len(body.Data.Result) == 0 {
+		return nil, fmt.Errorf("the query returned no data")
+	}
+	var values []float64
+	for _, sample := range body.Data.Result[0].Values {
+		// Samples are [timestamp, "value"] pairs.
+		text, _ := sample[1].(string)
+		if v, err := strconv.ParseFloat(text, 64); err == nil && !math.IsNaN(v) {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// sparkline draws values as a row of bars scaled between their minimum and maximum.
+func sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[i])
+	}
+	return b.String()
+}
+
+// formatMetric formats a sample compactly, e.g. 950, 12.5k, or 3.2M.
+func formatMetric(v float64) string {
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e9, "G"}, {1e6, "M"}, {1e3, "k"}} {
+		if math.Abs(v) >= unit.size {
+			return strconv.FormatFloat(v/unit.size, 'f', 1, 64) + unit.suffix
+		}
+	}
+	return strconv.FormatFloat(v, 'g', 3, 64)
+}
+
+// metricLine returns the sparkline and newest sample of a zone's metric for its view, or "".
+func metricLine(tz TimezoneConfig) string {
+	metricMu.Lock()
+	values := metricSeries[tz.Name]
+	metricMu.Unlock()
+	if tz.Metric == "" || len(values) == 0 {
+		return ""
+	}
+	return "\x1b[32m" + sparkline(values) + "\x1b[0m " + formatMetric(values[len(values)-1])
+}
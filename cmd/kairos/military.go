@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+)
+
+// zuluZone is the UTC view pinned at the top of the dashboard in military mode.
+var zuluZone = TimezoneConfig{Name: "Zulu", Location: "UTC"}
+
+// Military time zone letters and their phonetic names, indexed by UTC offset in hours.
+// J (Juliett) is not used for a zone; it denotes the observer's local time.
+var (
+	militaryEast = []string{"Z", "A", "B", "C", "D", "E", "F", "G", "H", "I", "K", "L", "M"}
+	militaryWest = []string{"Z", "N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y"}
+	phonetic     = map[string]string{
+		"A": "Alfa", "B": "Bravo", "C": "Charlie", "D": "Delta", "E": "Echo", "F": "Foxtrot",
+		"G": "Golf", "H": "Hotel", "I": "India", "K": "Kilo", "L": "Lima", "M": "Mike",
+		"N": "November", "O": "Oscar", "P": "Papa", "Q": "Quebec", "R": "Romeo", "S": "Sierra",
+		"T": "Tango", "U": "Uniform", "V": "Victor", "W": "Whiskey", "X": "X-ray", "Y": "Yankee", "Z": "Zulu",
+	}
+)
+
+/**
+ * This function returns the military time zone designator for the current offset of t.
+ * Offsets that are not whole hours, or beyond ±12 hours, have no designator.
+ *
+ * @param t - A time in the zone of interest.
+ * @returns The designator letter, its phonetic name, and false if there is none.
+ */
+func militaryDesignator(t time.Time) (letter, name string, ok bool) {
+	_, offset := t.Zone()
+	if offset%3600 != 0 {
+		return "", "", false
+	}
+	hours := offset / 3600
+	switch {
+	case hours >= 0 && hours < len(militaryEast):
+		letter = militaryEast[hours]
+	case hours < 0 && -hours < len(militaryWest):
+		letter = militaryWest[-hours]
+	default:
+		return "", "", false
+	}
+	return letter, phonetic[letter], true
+}
+
+/**
+ * This function returns the zones in display order: the primary (top) zone first, then the grid.
+ * Hidden zones are left out unless they are revealed with the "h" key (see hidden.go).
+ * In military mode a Zulu (UTC) view is pinned at the top and any configured UTC zones are
+ * folded into it. A zone pinned beside the primary one comes second (see split.go).
+ *
+ * @returns The zones to display.
+ */
+func displayZones() []TimezoneConfig {
+	visible := timezones
+	if !showHidden {
+		visible = nil
+		for _, tz := range timezones {
+			if !tz.Hidden {
+				visible = append(visible, tz)
+			}
+		}
+		// A config whose zones are all hidden shows them anyway.
+		if len(visible) == 0 {
+			visible = timezones
+		}
+	}
+	if !settings.Military {
+		return withPinnedZone(visible)
+	}
+	zones := []TimezoneConfig{zuluZone}
+	for _, tz := range visible {
+		if loc, ok := locations[tz.Name]; ok && loc.String() == "UTC" {
+			continue
+		}
+		zones = append(zones, tz)
+	}
+	return withPinnedZone(zones)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/jroimartin/gocui"
+)
+
+// inputMode decides what the dashboard's single-key shortcuts do, so that a key can mean
+// different things in different modes instead of every feature competing for its own key.
+type inputMode int
+
+const (
+	// modeNormal is the dashboard itself: swapping zones and toggling panels and widgets.
+	modeNormal inputMode = iota
+	// modeTimer is while the stopwatch is shown; its keys take precedence over the normal ones.
+	modeTimer
+	// modeIncident is while an incident is handled (see incident.go); its keys take precedence over the normal ones.
+	modeIncident
+	// modeEdit is while a text prompt (the session prompt, the zone palette, or the IANA browser) is open; keys are typed into it.
+	modeEdit
+)
+
+// String returns the mode's name as shown in the footer.
+func (m inputMode) String() string {
+	return [...]string{"NORMAL", "TIMER", "INCIDENT", "EDIT"}[m]
+}
+
+// currentMode works out the input mode from what the dashboard shows.
+func currentMode() inputMode {
+	switch {
+	case trackPromptOpen || paletteOpen || browserOpen:
+		return modeEdit
+	case stopwatchMode:
+		return modeTimer
+	case currentIncident != nil:
+		return modeIncident
+	}
+	return modeNormal
+}
+
+// modeStatus is the {mode} footer widget: the mode's name, or "" in normal mode, and the read-only badge.
+func modeStatus() string {
+	if m := currentMode(); m != modeNormal {
+		return strings.TrimSpace("\x1b[35m\x1b[1m-- " + m.String() + " --\x1b[0m " + readOnlyStatus())
+	}
+	return readOnlyStatus()
+}
+
+// keymap holds the single-key shortcuts of each mode; keys are runes, or gocui.KeySpace for space.
+type keymap map[inputMode]map[interface{}]func(g *gocui.Gui) error
+
+// bind adds a shortcut to a mode.
+func (km keymap) bind(mode inputMode, key interface{}, handler func(g *gocui.Gui) error) {
+	if km[mode] == nil {
+		km[mode] = map[interface{}]func(g *gocui.Gui) error{}
+	}
+	km[mode][key] = handler
+}
+
+/**
+ * This function installs the keymap's shortcuts. Each key is bound once and dispatched by
+ * the current mode: in edit mode it is typed into the prompt, in timer and incident mode the
+ * mode's shortcut runs if it has one and the normal one otherwise, and in normal mode the normal one.
+ *
+ * @param g - The GUI to bind the keys in.
+ * @returns An error if a key could not be bound.
+ */
+func (km keymap) install(g *gocui.Gui) error {
+	keys := map[interface{}]bool{}
+	for _, bindings := range km {
+		for key := range bindings {
+			keys[key] = true
+		}
+	}
+	for key := range keys {
+		key := key
+		if err := g.SetKeybinding("", key, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+			if consumeFocusSequence(key) {
+				return nil
+			}
+			mode := currentMode()
+			if mode == modeEdit {
+				if v != nil && v.Editable {
+					v.Editor.Edit(v, 0, typedRune(key), gocui.ModNone)
+				}
+				return nil
+			}
+			// The redraw after a shortcut shows the present, e.g. a stopwatch that just started (see snapshot.go).
+			defer takeSnapshot()
+			if h, ok := km[mode][key]; ok {
+				countKey(mode, key)
+				return h(g)
+			}
+			if h, ok := km[modeNormal][key]; ok {
+				countKey(modeNormal, key)
+				return h(g)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typedRune returns the character a shortcut key types into a prompt.
+func typedRune(key interface{}) rune {
+	if key == gocui.KeySpace {
+		return ' '
+	}
+	r, _ := key.(rune)
+	return r
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/zones"
+)
+
+const (
+	// mqttKeepAlive is the keep-alive interval announced to the broker; a ping is sent when idle for half of it.
+	mqttKeepAlive = 60 * time.Second
+	// mqttRetryDelay is how long the publisher waits before reconnecting to the broker.
+	mqttRetryDelay = 30 * time.Second
+)
+
+// mqttMessage is a message waiting to be published.
+type mqttMessage struct {
+	Topic   string
+	Payload []byte
+	Retain  bool
+}
+
+var (
+	// mqttQueue holds the messages for the publisher worker; nil unless a broker is configured.
+	mqttQueue chan mqttMessage
+	// lastMQTTMinute is the minute the zone times were last published for.
+	lastMQTTMinute time.Time
+	// mqttOpen remembers whether each zone was in business hours at the last check.
+	mqttOpen = map[string]bool{}
+)
+
+// mqttTopic joins a topic below the configured prefix, e.g. "kairos/zones/new-york/time".
+func mqttTopic(parts ...string) string {
+	return strings.Join(append([]string{defaultString(settings.MQTTTopic, "kairos")}, parts...), "/")
+}
+
+// mqttSlug turns a zone name into a topic level: lowercase, with spaces as dashes and no MQTT wildcards.
+func mqttSlug(name string) string {
+	return strings.NewReplacer(" ", "-", "/", "-", "+", "", "#", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+/**
+ * This function queues a message for the MQTT publisher without blocking the dashboard.
+ * Messages are dropped when no broker is configured or the queue is full (the broker
+ * has been unreachable for a while).
+ *
+ * @param topic - The full topic.
+ * @param payload - The message: a string is sent as is, anything else as JSON.
+ * @param retain - Whether the broker keeps it for new subscribers.
+ */
+func publishMQTT(topic string, payload interface{}, retain bool) {
+	if mqttQueue == nil {
+		return
+	}
+	var data []byte
+	switch p := payload.(type) {
+	case string:
+		data = []byte(p)
+	default:
+		encoded, err := json.Marshal(p)
+		if err != nil {
+			return
+		}
+		data = encoded
+	}
+	select {
+	case mqttQueue <- mqttMessage{Topic: topic, Payload: data, Retain: retain}:
+	default:
+	}
+}
+
+/**
+ * This function starts the MQTT publisher if a broker is configured. It keeps one
+ * connection open, reconnecting after failures, and publishes the queued messages with
+ * QoS 0.
+ */
+func startMQTTWorker() {
+	if settings.MQTTBroker == "" {
+		return
+	}
+	mqttQueue = make(chan mqttMessage, 256)
+	goWorker("mqtt worker", func(ctx context.Context) {
+		lastErr := ""
+		for {
+			err := runMQTTSession(ctx, settings.MQTTBroker)
+			if ctx.Err() != nil {
+				return
+			}
+			if err.Error() != lastErr {
+				logger.Warn("mqtt connection failed", "broker", settings.MQTTBroker, "err", err)
+			}
+			lastErr = err.Error()
+			if !sleepCtx(ctx, mqttRetryDelay) {
+				return
+			}
+		}
+	})
+}
+
+// runMQTTSession connects to the broker and publishes queued messages until the connection fails or ctx is done.
+func runMQTTSession(ctx context.Context, broker string) error {
+	conn, err := net.DialTimeout("tcp", broker, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := mqttConnect(conn); err != nil {
+		return err
+	}
+	logger.Info("mqtt connected", "broker", broker)
+	// The broker's replies (ping responses) are read and discarded so the connection doesn't stall.
+	failed := make(chan error, 1)
+	go func() {
+		defer recoverWorker("mqtt reader")
+		r := bufio.NewReader(conn)
+		for {
+			if _, err := readMQTTPacket(r); err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+	ping := time.NewTicker(mqttKeepAlive / 2)
+	defer ping.Stop()
+	for {
+		var packet []byte
+		select {
+		case m := <-mqttQueue:
+			packet = mqttPublishPacket(m)
+		case <-ping.C:
+			packet = []byte{0xc0, 0x00} // PINGREQ
+		case err := <-failed:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if _, err := conn.Write(packet); err != nil {
+			return err
+		}
+	}
+}
+
+/**
+ * This function sends an MQTT 3.1.1 CONNECT packet, with the username and password if
+ * they are set, and waits for the broker's CONNACK.
+ *
+ * @param conn - The connection to the broker.
+ * @returns An error if the broker refuses the connection.
+ */
+func mqttConnect(conn net.Conn) error {
+	var flags byte = 0x02 // Clean session.
+	payload := mqttString(fmt.Sprintf("kairos-%d", time.Now().UnixNano()%1e9))
+	if settings.MQTTUsername != "" {
+		flags |= 0x80
+		payload = append(payload, mqttString(settings.MQTTUsername)...)
+		if settings.MQTTPassword != "" {
+			flags |= 0x40
+			payload = append(payload, mqttString(settings.MQTTPassword)...)
+		}
+	}
+	body := append(mqttString("MQTT"), 0x04, flags, 0, 0)
+	binary.BigEndian.PutUint16(body[len(body)-2:], uint16(mqttKeepAlive/time.Second))
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	defer conn.SetDeadline(time.Time{})
+	if _, err := conn.Write(mqttPacket(0x10, append(body, payload...))); err != nil {
+		return err
+	}
+	ack, err := readMQTTPacket(bufio.NewReader(conn))
+	if err != nil {
+		return err
+	}
+	if ack[0]>>4 != 2 || len(ack) < 4 {
+		return fmt.Errorf("unexpected reply to CONNECT")
+	}
+	if code := ack[len(ack)-1]; code != 0 {
+		return fmt.Errorf("broker refused the connection (code %d)", code)
+	}
+	return nil
+}
+
+// mqttPublishPacket encodes a QoS 0 PUBLISH packet.
+func mqttPublishPacket(m mqttMessage) []byte {
+	var header byte = 0x30
+	if m.Retain {
+		header |= 0x01
+	}
+	return mqttPacket(header, append(mqttString(m.Topic), m.Payload...))
+}
+
+// mqttPacket prefixes a packet body with its fixed header: the type byte and the variable-length remaining length.
+func mqttPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		if n /= 128; n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// mqttString encodes a string with its two-byte length, as MQTT does.
+func mqttString(s string) []byte {
+	b := make([]byte, 2, 2+len(s))
+	binary.BigEndian.PutUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readMQTTPacket reads one packet from the broker and returns it with its fixed header.
+func readMQTTPacket(r *bufio.Reader) ([]byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	packet := []byte{header}
+	length, shift := 0, 0
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		packet = append(packet, b)
+		length |= int(b&0x7f) << shift
+		if shift += 7; b&0x80 == 0 {
+			break
+		}
+		if shift > 21 {
+			return nil, fmt.Errorf("malformed packet length")
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return append(packet, body...), nil
+}
+
+// resetMQTTState makes the next tick publish every zone's time again, e.g. for zones just loaded.
+func resetMQTTState() {
+	lastMQTTMinute = time.Time{}
+}
+
+/**
+ * This function publishes the zones' state. It is called on every UI tick: once a minute
+ * each zone's local time is published (retained) to <prefix>/zones/<zone>/time, and when
+ * a zone's business hours open or close the new state is published (retained) to
+ * <prefix>/zones/<zone>/business_hours and as an event to <prefix>/events.
+ *
+ * @param now - The current time.
+ */
+func checkMQTT(now time.Time) {
+	if mqttQueue == nil {
+		return
+	}
+	minute := now.Truncate(time.Minute)
+	publishTimes := !minute.Equal(lastMQTTMinute)
+	lastMQTTMinute = minute
+	for _, tz := range timezones {
+		loc, ok := locations[tz.Name]
+		if !ok {
+			continue
+		}
+		local := now.In(loc)
+		open := zones.BusinessHours(tz).Contains(local)
+		slug := mqttSlug(tz.Name)
+		if publishTimes {
+			abbr, offset := local.Zone()
+			publishMQTT(mqttTopic("zones", slug, "time"), map[string]interface{}{
+				"zone": tz.Name, "location": tz.Location, "time": local.Format("15:04"),
+				"iso": local.Format(time.RFC3339), "abbreviation": abbr, "utc_offset": offset, "open": open,
+			}, true)
+		}
+		was, seen := mqttOpen[tz.Name]
+		mqttOpen[tz.Name] = open
+		if seen && was == open {
+			continue
+		}
+		state := "closed"
+		if open {
+			state = "open"
+		}
+		publishMQTT(mqttTopic("zones", slug, "business_hours"), state, true)
+		// The first check only sets the retained state; events are for actual transitions.
+		if seen {
+			publishMQTT(mqttTopic("events"), map[string]interface{}{
+				"type": "business_hours", "zone": tz.Name, "state": state, "at": now.UTC().Format(time.RFC3339),
+			}, false)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/mattn/go-runewidth"
+)
+
+/**
+ * This function scrolls a text that is wider than its space, one column per second,
+ * like a marquee; text that fits is returned as is. With reduced motion the text is
+ * cut short with "…" instead.
+ *
+ * @param text - The text.
+ * @param width - The space available.
+ * @param now - The current time, which sets the scroll position.
+ * @returns The visible part of the text.
+ */
+func marquee(text string, width int, now time.Time) string {
+	return marqueeAt(text, width, int(now.Unix()))
+}
+
+// marqueeAt is marquee scrolled to a given column; 0 shows the start of the text.
+func marqueeAt(text string, width, offset int) string {
+	if width <= 0 || render.TextWidth(text) <= width {
+		return text
+	}
+	if settings.ReducedMotion {
+		return runewidth.Truncate(text, width, "…")
+	}
+	// The text loops with a gap, so its end and start are not run together.
+	runes := []rune(text + "   ·   ")
+	start := offset % len(runes)
+	loop := append(runes[start:], runes[:start]...)
+	loop = append(loop, runes...)
+	return runewidth.Truncate(string(loop), width, "")
+}
+
+// noteLine returns a zone's note for its view, scrolling if it is too long, or "" when it is not shown there.
+func noteLine(tz TimezoneConfig, now time.Time, width int) string {
+	if tz.Note == "" || !tz.NoteTile {
+		return ""
+	}
+	return "\x1b[33m" + marquee("✎ "+tz.Note, width-2, now) + "\x1b[0m"
+}
+
+// wrapText breaks text into lines of at most width columns at spaces; longer words get a line of their own.
+func wrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && render.TextWidth(line+" "+word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
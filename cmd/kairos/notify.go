@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// notifyLevel is how urgent a notification is: info, warn, or alert.
+type notifyLevel int
+
+const (
+	levelInfo notifyLevel = iota
+	levelWarn
+	levelAlert
+)
+
+// notifyLevelNames are the names of the levels, in order.
+var notifyLevelNames = []string{"info", "warn", "alert"}
+
+// notifyLevelColors are the footer colors of the levels: yellow, magenta, and red.
+var notifyLevelColors = []string{"\x1b[33m", "\x1b[35m", "\x1b[31m"}
+
+// notifyLevelMinimum is the least time a notification of each level is shown, whatever it asks for.
+var notifyLevelMinimum = []time.Duration{0, 5 * time.Second, 10 * time.Second}
+
+// String returns the level's name.
+func (l notifyLevel) String() string {
+	return notifyLevelNames[l]
+}
+
+// parseNotifyLevel reads a level's name.
+func parseNotifyLevel(v string) (notifyLevel, error) {
+	for i, name := range notifyLevelNames {
+		if strings.EqualFold(v, name) {
+			return notifyLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid level '%s' (expected one of: %s)", v, strings.Join(notifyLevelNames, ", "))
+}
+
+// setNotifyRoute validates and stores the lowest level sent somewhere, or off ("" stores the default).
+func setNotifyRoute(field *string, v string) error {
+	v = strings.ToLower(v)
+	if v == "default" || v == "none" || v == "" {
+		*field = ""
+		return nil
+	}
+	if v != "off" {
+		if _, err := parseNotifyLevel(v); err != nil {
+			return fmt.Errorf("invalid level '%s' (expected off, %s)", v, strings.Join(notifyLevelNames, ", "))
+		}
+	}
+	*field = v
+	return nil
+}
+
+// notifyDesktopRoute returns the notify_desktop setting: off in a dashboard by default, and every level in the daemon.
+func notifyDesktopRoute() string {
+	if daemonMode {
+		return defaultString(settings.NotifyDesktop, "info")
+	}
+	return defaultString(settings.NotifyDesktop, "off")
+}
+
+// routes reports whether a level reaches a route's lowest level (off reaches none).
+func routes(route string, level notifyLevel) bool {
+	lowest, err := parseNotifyLevel(route)
+	return err == nil && level >= lowest
+}
+
+/**
+ * This function shows a notification in the footer at a level: it is colored for its
+ * level and shown for at least the level's minimum, an alert rings the terminal bell with
+ * alert_bell on, and it is sent on to the terminal, the desktop, and the announce_webhook
+ * channel when its level reaches notify_terminal, notify_desktop, and notify_webhook. With
+ * quiet_unfocused on, the bell and the terminal and desktop notifications are held back while
+ * the terminal is not focused, since I am busy elsewhere.
+ *
+ * @param level - The level.
+ * @param msg - The message.
+ * @param d - How long it is shown in the footer.
+ */
+func notify(level notifyLevel, msg string, d time.Duration) {
+	notifyFooter(level, msg, d)
+	if routes(notifyDesktopRoute(), level) && !quietUnfocused() {
+		if err := desktopNotify("kairos", msg); err != nil {
+			logger.Debug("desktop notification failed", "err", err)
+		}
+	}
+	if webhook := settings.AnnounceWebhook; routes(settings.NotifyWebhook, level) && webhook != "" {
+		go func() {
+			defer recoverWorker("notification webhook")
+			// A failure is only logged, since a notification about it would be posted again.
+			if err := postAnnouncement(webhook, fmt.Sprintf("[%s] %s", level, msg)); err != nil {
+				logger.Warn("notification webhook failed", "level", level, "err", err)
+			}
+		}()
+	}
+}
+
+// notifyFooter shows a notification in the footer only, with its bell and terminal
+// notification: attached dashboards show the daemon's this way, which has already sent it on.
+func notifyFooter(level notifyLevel, msg string, d time.Duration) {
+	// The clock tick clears it once d has passed and shows the next, so it never changes mid-redraw.
+	appState.SetNotification(msg, level, max(d, notifyLevelMinimum[level]))
+	if daemonMode || quietUnfocused() {
+		return
+	}
+	if level == levelAlert && settings.AlertBell {
+		os.Stdout.WriteString("\a")
+	}
+	// The daemon has no terminal, so each dashboard sends the terminal notifications itself (see termnotify.go).
+	if routes(settings.NotifyTerminal, level) {
+		if err := terminalNotify("kairos", msg); err != nil {
+			logger.Debug("terminal notification failed", "err", err)
+		}
+	}
+}
+
+// showWarning shows a warning, such as a failed integration, in the footer.
+func showWarning(msg string) {
+	notify(levelWarn, msg, notificationDuration())
+}
+
+// showAlert shows an alert, such as an alarm going off, in the footer.
+func showAlert(msg string) {
+	notify(levelAlert, msg, notificationDuration())
+}
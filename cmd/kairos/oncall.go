@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// onCallServices lists the on-call services whose schedules can be shown next to the zones.
+var onCallServices = []string{"pagerduty", "opsgenie"}
+
+// onCallRefresh is how often the on-call worker asks the service who is on call.
+const onCallRefresh = 5 * time.Minute
+
+var (
+	// onCallMu guards onCallNames, which the worker writes and the dashboard reads.
+	onCallMu sync.Mutex
+	// onCallNames maps each schedule to who is on call for it, as last fetched.
+	onCallNames = map[string]string{}
+)
+
+/**
+ * This function asks the configured on-call service who is currently on call for a
+ * schedule.
+ *
+ * @param schedule - The schedule's id (PagerDuty or Opsgenie) or name (Opsgenie).
+ * @returns The names of the people on call, or an error.
+ */
+func fetchOnCall(schedule string) ([]string, error) {
+	if settings.OnCallToken == "" {
+		return nil, fmt.Errorf("set oncall_token to show who is on call")
+	}
+	var endpoint string
+	header := http.Header{}
+	switch settings.OnCall {
+	case "pagerduty":
+		endpoint = "https://api.pagerduty.com/oncalls?earliest=true&schedule_ids[]=" + url.QueryEscape(schedule)
+		header.Set("Authorization", "Token token="+settings.OnCallToken)
+		header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	case "opsgenie":
+		// Opsgenie schedules can be named by id (a UUID) or by name.
+		kind := "name"
+		if len(schedule) == 36 && strings.Count(schedule, "-") == 4 {
+			kind = "id"
+		}
+		endpoint = fmt.Sprintf("https://api.opsgenie.com/v2/schedules/%s/on-calls?flat=true&scheduleIdentifierType=%s",
+			url.PathEscape(schedule), kind)
+		header.Set("Authorization", "GenieKey "+settings.OnCallToken)
+	default:
+		return nil, fmt.Errorf("no on-call service is configured")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s rejected the request: %s %s", settings.OnCall, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	var names []string
+	if settings.OnCall == "pagerduty" {
+		var body struct {
+			OnCalls []struct {
+				User struct {
+					Summary string `json:"summary"`
+				} `json:"user"`
+			} `json:"oncalls"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		for _, o := range body.OnCalls {
+			if !containsString(names, o.User.Summary) {
+				names = append(names, o.User.Summary)
+			}
+		}
+		return names, nil
+	}
+	var body struct {
+		Data struct {
+			OnCallRecipients []string `json:"onCallRecipients"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Data.OnCallRecipients, nil
+}
+
+/**
+ * This function starts a worker that refreshes who is on call for every zone's
+ * schedule each onCallRefresh, but only if an on-call service is configured and at
+ * least one zone has a schedule.
+ */
+func startOnCallWorker() {
+	var schedules []string
+	for _, tz := range timezones {
+		if tz.OnCallSchedule != "" && !containsString(schedules, tz.OnCallSchedule) {
+			schedules = append(schedules, tz.OnCallSchedule)
+		}
+	}
+	if settings.OnCall == "" || len(schedules) == 0 {
+		return
+	}
+	goWorker("on-call worker", func(ctx context.Context) {
+		lastErr := map[string]string{}
+		for {
+			for _, schedule := range schedules {
+				names, err := fetchOnCall(schedule)
+				text := strings.Join(names, ", ")
+				if err != nil {
+					text = "\x1b[33munavailable\x1b[0m"
+					if err.Error() != lastErr[schedule] {
+						logger.Warn("on-call query failed", "service", settings.OnCall, "schedule", schedule, "err", err)
+					}
+					lastErr[schedule] = err.Error()
+				} else {
+					delete(lastErr, schedule)
+					if text == "" {
+						text = "nobody"
+					}
+				}
+				onCallMu.Lock()
+				onCallNames[schedule] = text
+				onCallMu.Unlock()
+			}
+			if !sleepCtx(ctx, onCallRefresh) {
+				return
+			}
+		}
+	})
+}
+
+// onCallStatus returns who is on call for a zone's schedule, or "" if it has none or it has not been fetched yet.
+func onCallStatus(tz TimezoneConfig) string {
+	if tz.OnCallSchedule == "" {
+		return ""
+	}
+	onCallMu.Lock()
+	defer onCallMu.Unlock()
+	return onCallNames[tz.OnCallSchedule]
+}
+
+// onCallLine returns the line naming who is on call for a zone's view, or "".
+func onCallLine(tz TimezoneConfig) string {
+	if names := onCallStatus(tz); names != "" {
+		return "\x1b[35m☎\x1b[0m " + names
+	}
+	return ""
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/workhours"
+)
+
+/**
+ * This function updates a single per-timezone option, identified by the timezone's display name.
+ * Passing "none" as the value clears the option and restores the default behavior.
+ *
+ * @param name - The display name of the timezone to update.
+ * @param option - The option to set (e.g. "calendar").
+ * @param value - The new value for the option.
+ * @returns An error if the timezone or option is unknown, or the value is invalid.
+ */
+func setZoneOption(name, option, value string) error {
+	idx := -1
+	for i, tz := range timezones {
+		if tz.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("timezone '%s' not found", name)
+	}
+
+	clear := strings.EqualFold(value, "none")
+	tz := &timezones[idx]
+	switch strings.ToLower(option) {
+	case "calendar":
+		if clear {
+			tz.Calendar = ""
+			return nil
+		}
+		value = strings.ToLower(value)
+		for _, c := range calendarNames {
+			if c == value {
+				tz.Calendar = value
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown calendar '%s' (expected one of: %s)", value, strings.Join(calendarNames, ", "))
+	case "coords":
+		if clear {
+			tz.Coordinates = nil
+			return nil
+		}
+		c, err := parseCoordinates(value)
+		if err != nil {
+			return err
+		}
+		tz.Coordinates = &c
+		return nil
+	case "prayer":
+		if clear {
+			tz.Prayer = ""
+			return nil
+		}
+		value = strings.ToLower(value)
+		if _, ok := prayerMethods[value]; !ok {
+			return fmt.Errorf("unknown prayer method '%s' (expected one of: %s)", value, strings.Join(prayerMethodNames(), ", "))
+		}
+		tz.Prayer = value
+		return nil
+	case "asr":
+		value = strings.ToLower(value)
+		if clear || value == "standard" {
+			tz.Asr = ""
+			return nil
+		}
+		if value != "hanafi" {
+			return fmt.Errorf("unknown asr method '%s' (expected standard or hanafi)", value)
+		}
+		tz.Asr = value
+		return nil
+	case "prayer_notify":
+		on, err := parseSwitch(value)
+		if err != nil {
+			return err
+		}
+		tz.PrayerNotify = on
+		return nil
+	case "hours":
+		if clear {
+			tz.Hours = ""
+			return nil
+		}
+		b, err := workhours.ParseSchedule(value)
+		if err != nil {
+			return err
+		}
+		tz.Hours = b.String()
+		return nil
+	case "focus":
+		if clear {
+			tz.Focus = ""
+			return nil
+		}
+		b, err := workhours.ParseSchedule(value)
+		if err != nil {
+			return err
+		}
+		tz.Focus = b.String()
+		return nil
+	case "progress":
+		value = strings.ToLower(value)
+		if clear || value == "day" {
+			tz.Progress = ""
+			return nil
+		}
+		if value != "workday" {
+			return fmt.Errorf("unknown progress mode '%s' (expected day or workday)", value)
+		}
+		tz.Progress = value
+		return nil
+	case "style":
+		value = strings.ToLower(value)
+		if clear || value == "digits" {
+			tz.Style = ""
+			return nil
+		}
+		if _, ok := clockStyles[value]; !ok {
+			return fmt.Errorf("unknown clock style '%s' (expected one of: %s)", value, strings.Join(clockStyleNames, ", "))
+		}
+		tz.Style = value
+		return nil
+	case "bars":
+		if clear {
+			tz.Bars = nil
+			return nil
+		}
+		var bars []string
+		for _, b := range strings.Split(strings.ToLower(value), ",") {
+			b = strings.TrimSpace(b)
+			valid := false
+			for _, p := range progressPeriods {
+				valid = valid || p == b
+			}
+			if !valid {
+				return fmt.Errorf("unknown progress bar '%s' (expected any of: %s)", b, strings.Join(progressPeriods, ", "))
+			}
+			bars = append(bars, b)
+		}
+		tz.Bars = bars
+		return nil
+	case "people":
+		if clear {
+			tz.People = nil
+			return nil
+		}
+		var people []string
+		for _, p := range strings.Split(value, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				people = append(people, p)
+			}
+		}
+		tz.People = people
+		return nil
+	case "holidays":
+		if clear {
+			tz.Holidays = nil
+			return nil
+		}
+		var holidays []string
+		for _, d := range strings.Split(value, ",") {
+			d = strings.TrimSpace(d)
+			if _, err := time.Parse("2006-01-02", d); err != nil {
+				return fmt.Errorf("invalid holiday '%s' (expected YYYY-MM-DD)", d)
+			}
+			holidays = append(holidays, d)
+		}
+		tz.Holidays = holidays
+		return nil
+	case "awake":
+		if clear {
+			tz.Awake = ""
+			return nil
+		}
+		b, err := workhours.Parse(value)
+		if err != nil {
+			return err
+		}
+		tz.Awake = b.String()
+		return nil
+	case "oncall":
+		if clear {
+			tz.OnCallSchedule = ""
+			return nil
+		}
+		tz.OnCallSchedule = strings.TrimSpace(value)
+		return nil
+	case "states":
+		if clear {
+			tz.States = nil
+			return nil
+		}
+		states, err := parseHoursStates(value)
+		if err != nil {
+			return err
+		}
+		tz.States = states
+		return nil
+	case "metric":
+		if clear {
+			tz.Metric = ""
+			return nil
+		}
+		if strings.TrimSpace(strings.TrimPrefix(value, promPrefix)) == "" {
+			return fmt.Errorf("metric needs a command or a Prometheus query, e.g. \"prom:up\"")
+		}
+		tz.Metric = value
+		return nil
+	case "health":
+		if clear {
+			tz.Health = ""
+			return nil
+		}
+		if err := parseHealthURL(value); err != nil {
+			return err
+		}
+		tz.Health = value
+		return nil
+	case "carbon":
+		if clear {
+			tz.Carbon = ""
+			return nil
+		}
+		grid, err := parseGridZone(value)
+		if err != nil {
+			return err
+		}
+		tz.Carbon = grid
+		return nil
+	case "shift":
+		if clear {
+			tz.Shift, tz.ShiftHours = "", ""
+			return nil
+		}
+		team, hours, err := parseShift(value)
+		if err != nil {
+			return err
+		}
+		tz.Shift, tz.ShiftHours = team, hours
+		return nil
+	case "wake_notify":
+		on, err := parseSwitch(value)
+		if err != nil {
+			return err
+		}
+		tz.WakeNotify = on
+		return nil
+	case "note":
+		if clear {
+			tz.Note = ""
+			return nil
+		}
+		tz.Note = strings.TrimSpace(value)
+		return nil
+	case "note_tile":
+		on, err := parseSwitch(value)
+		if err != nil {
+			return err
+		}
+		tz.NoteTile = on
+		return nil
+	case "hour_format":
+		if clear {
+			tz.HourFormat = ""
+			return nil
+		}
+		format := strings.ToLower(strings.TrimSpace(value))
+		if !slices.Contains(hourFormats, format) {
+			return fmt.Errorf("invalid hour format '%s' (expected one of: %s, or none for the hour_format setting)", value, strings.Join(hourFormats, ", "))
+		}
+		tz.HourFormat = format
+		return nil
+	case "voice":
+		if clear {
+			tz.Voice = ""
+			return nil
+		}
+		tz.Voice = strings.TrimSpace(value)
+		return nil
+	case "times":
+		if clear {
+			tz.Times = nil
+			return nil
+		}
+		var times []string
+		for _, key := range strings.Split(strings.ToLower(value), ",") {
+			key = strings.TrimSpace(key)
+			if _, ok := findRepresentation(key); !ok {
+				return fmt.Errorf("unknown time representation '%s' (expected any of: %s)", key, strings.Join(representationKeys(), ", "))
+			}
+			times = append(times, key)
+		}
+		tz.Times = times
+		return nil
+	case "subtime":
+		if clear {
+			tz.Subtime = ""
+			return nil
+		}
+		key := strings.ToLower(strings.TrimSpace(value))
+		if !slices.Contains(subtimeKeys(), key) {
+			return fmt.Errorf("unknown subtime format '%s' (expected any of: %s)", value, strings.Join(subtimeKeys(), ", "))
+		}
+		tz.Subtime = key
+		return nil
+	}
+	return fmt.Errorf("unknown option '%s'", option)
+}
+
+/**
+ * This function parses a "lat,lon" pair in decimal degrees.
+ *
+ * @param s - The coordinates string, e.g. "14.60,120.98".
+ * @returns The parsed coordinates, or an error if they are malformed or out of range.
+ */
+func parseCoordinates(s string) (Coordinates, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return Coordinates{}, fmt.Errorf("invalid coordinates '%s' (expected \"lat,lon\")", s)
+	}
+	lat, errLat := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lon, errLon := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if errLat != nil || errLon != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return Coordinates{}, fmt.Errorf("invalid coordinates '%s' (latitude -90..90, longitude -180..180)", s)
+	}
+	return Coordinates{Lat: lat, Lon: lon}, nil
+}
+
+/**
+ * This function parses an on/off style switch value.
+ *
+ * @param s - The value, e.g. "on", "off", "true", "false", "yes", "no".
+ * @returns The boolean value, or an error if it is not recognized.
+ */
+func parseSwitch(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "true", "yes", "1":
+		return true, nil
+	case "off", "false", "no", "0", "none":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid switch value '%s' (expected on or off)", s)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultPager pages help when $PAGER is not set; -R keeps the colors.
+const defaultPager = "less -R"
+
+/**
+ * This function prints help text, through $PAGER (or less) when stdout is a terminal
+ * and the text is taller than it, as `git help` does. less gets LESS=FRX unless LESS is
+ * set, so it shows the colors and leaves the text on screen. Piped output, a short text,
+ * or a pager that cannot be started prints the text directly.
+ *
+ * @param write - Prints the help to the writer it is given.
+ */
+func pageHelp(write func(w io.Writer)) {
+	var buf bytes.Buffer
+	write(&buf)
+	rows := terminalRows()
+	if !stdoutIsTerminal() || rows == 0 || strings.Count(buf.String(), "\n") < rows {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	pager := strings.TrimSpace(defaultString(os.Getenv("PAGER"), defaultPager))
+	if pager == "" || pager == "cat" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	// $PAGER is a command line like the hooks', e.g. "less -R" or "most -s".
+	cmd := shellCommand(context.Background(), pager)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(buf.Bytes()), os.Stdout, os.Stderr
+	cmd.Env = os.Environ()
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if err := cmd.Run(); err != nil {
+		logger.Debug("pager failed", "pager", pager, "err", err)
+		// A pager that never started (the shell's 127) leaves the help unread; one that was quit early is fine.
+		var exit *exec.ExitError
+		if !errors.As(err, &exit) || exit.ExitCode() == 127 {
+			fmt.Print(buf.String())
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package main
+
+// terminalRows is not supported here, so help is never paged.
+func terminalRows() int {
+	return 0
+}
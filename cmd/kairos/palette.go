@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/jroimartin/gocui"
+)
+
+// paletteItem is one match in the zone palette: a configured zone, or any IANA location.
+type paletteItem struct {
+	Zone       TimezoneConfig
+	Configured bool
+}
+
+// maxPaletteResults is how many matches the palette lists.
+const maxPaletteResults = 10
+
+var (
+	// paletteOpen is set while the Ctrl+P zone palette is shown.
+	paletteOpen bool
+	// paletteResults are the current matches, best first; paletteSelected indexes them.
+	paletteResults  []paletteItem
+	paletteSelected int
+	// previewName is the name of the zone being previewed as the primary, or "".
+	previewName string
+
+	ianaZonesOnce sync.Once
+	ianaZones     []string
+)
+
+/**
+ * This function lists every IANA location from the system's zone tables (zone1970.tab or
+ * zone.tab), falling back to the wizard's popular zones when no tables are installed.
+ *
+ * @returns The location names, sorted.
+ */
+func allZoneNames() []string {
+	ianaZonesOnce.Do(func() {
+		seen := map[string]bool{"UTC": true}
+		for _, dir := range zoneinfoDirs {
+			for _, table := range []string{"zone1970.tab", "zone.tab"} {
+				f, err := os.Open(filepath.Join(dir, table))
+				if err != nil {
+					continue
+				}
+				scanner := bufio.NewScanner(f)
+				for scanner.Scan() {
+					// Each line is "countries coordinates TZ [comments]".
+					fields := strings.Split(scanner.Text(), "\t")
+					if len(fields) >= 3 && !strings.HasPrefix(fields[0], "#") {
+						seen[fields[2]] = true
+					}
+				}
+				f.Close()
+			}
+		}
+		if len(seen) == 1 {
+			for _, z := range popularZones {
+				seen[z] = true
+			}
+		}
+		for z := range seen {
+			ianaZones = append(ianaZones, z)
+		}
+		sort.Strings(ianaZones)
+	})
+	return ianaZones
+}
+
+/**
+ * This function fills the palette's matches for a query: configured zones first (by
+ * display name or location), then IANA locations that are not configured yet.
+ *
+ * @param query - The search text.
+ */
+func filterPalette(query string) {
+	paletteResults = nil
+	configured := map[string]bool{}
+	var names []string
+	byName := map[string]TimezoneConfig{}
+	for _, tz := range displayZones() {
+		if tz.Name == previewName {
+			continue
+		}
+		configured[tz.Location] = true
+		label := tz.Name + " " + tz.Location
+		names = append(names, label)
+		byName[label] = tz
+	}
+	for _, label := range fuzzyFilter(query, names) {
+		paletteResults = append(paletteResults, paletteItem{Zone: byName[label], Configured: true})
+	}
+	if query != "" {
+		for _, loc := range fuzzyFilter(query, allZoneNames()) {
+			if !configured[loc] {
+				paletteResults = append(paletteResults, paletteItem{Zone: TimezoneConfig{Name: previewLabel(loc), Location: loc}})
+			}
+		}
+	}
+	paletteSelected = 0
+}
+
+// previewLabel names a previewed location after its city, e.g. "Tokyo (preview)".
+func previewLabel(location string) string {
+	city := strings.ReplaceAll(location[strings.LastIndex(location, "/")+1:], "_", " ")
+	return city + " (preview)"
+}
+
+/**
+ * This function acts on the selected palette match. A configured zone is promoted to the
+ * primary view, like the number keys do. Any other location is previewed as the primary
+ * zone until Esc is pressed; it is never saved (use `kairos add` to keep it).
+ *
+ * @param item - The selected match.
+ */
+func choosePaletteItem(item paletteItem) {
+	endPreview()
+	if !item.Configured {
+		loc, err := tzutil.LoadLocation(item.Zone.Location)
+		if err != nil {
+			showWarning("Cannot preview " + item.Zone.Location + ": " + err.Error())
+			return
+		}
+		locations[item.Zone.Name] = loc
+		timezones = append([]TimezoneConfig{item.Zone}, timezones...)
+		previewName = item.Zone.Name
+		showNotification(fmt.Sprintf("Previewing %s (Esc ends the preview)", item.Zone.Location))
+		return
+	}
+	if settings.Military {
+		showNotification("Zulu is pinned in military mode")
+		return
+	}
+	if promoteZone(item.Zone.Name) {
+		showNotification(fmt.Sprintf("Promoted %s to the top", item.Zone.Name))
+	}
+}
+
+// pinPaletteItem pins the selected match beside the primary zone in the split view, or unpins it (see split.go).
+func pinPaletteItem(item paletteItem) {
+	if !item.Configured {
+		showWarning("Only configured zones can be pinned; add " + item.Zone.Location + " with kairos add first")
+		return
+	}
+	togglePin(item.Zone)
+}
+
+// endPreview removes the previewed zone, if any.
+func endPreview() {
+	if previewName == "" {
+		return
+	}
+	for i, tz := range timezones {
+		if tz.Name == previewName {
+			timezones = append(timezones[:i], timezones[i+1:]...)
+			break
+		}
+	}
+	delete(locations, previewName)
+	previewName = ""
+}
+
+/**
+ * This function shows the palette while it is open: the search box with the matches
+ * below it. It is called from the dashboard layout after the other views are drawn.
+ *
+ * @param g - The dashboard's gocui.Gui.
+ * @returns An error if a view cannot be created.
+ */
+func layoutPalette(g *gocui.Gui) error {
+	if !paletteOpen {
+		return nil
+	}
+	maxX, maxY := g.Size()
+	width := min(60, maxX-2)
+	x0, y0 := (maxX-width)/2, max(0, maxY/4-2)
+	v, err := g.SetView("palette", x0, y0, x0+width, y0+2)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = " Go to zone (Enter promotes or previews, Ctrl+S pins, Esc closes) "
+		v.Editable = true
+		v.Editor = gocui.EditorFunc(paletteEdit)
+		filterPalette("")
+	}
+	rows := min(len(paletteResults), maxPaletteResults)
+	list, err := g.SetView("palette-results", x0, y0+2, x0+width, y0+3+max(rows, 1))
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	list.Clear()
+	if rows == 0 {
+		fmt.Fprint(list, " \x1b[90mNo matching zone\x1b[0m")
+	}
+	for i, item := range paletteResults[:rows] {
+		kind := "\x1b[90mpreview\x1b[0m"
+		if item.Configured {
+			kind = "\x1b[32mconfigured\x1b[0m"
+		}
+		line := fmt.Sprintf(" %-18s %-30s %s", item.Zone.Name, item.Zone.Location, kind)
+		if i == paletteSelected {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		fmt.Fprintln(list, consoleSafe(line))
+	}
+	g.Cursor = true
+	for _, name := range []string{"palette-results", "palette"} {
+		if _, err := g.SetViewOnTop(name); err != nil {
+			return err
+		}
+	}
+	_, err = g.SetCurrentView("palette")
+	return err
+}
+
+// paletteEdit edits the search box and refreshes the matches after every keystroke.
+func paletteEdit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	gocui.DefaultEditor.Edit(v, key, ch, mod)
+	filterPalette(strings.TrimSpace(v.Buffer()))
+}
+
+// closePalette removes the palette.
+func closePalette(g *gocui.Gui) {
+	paletteOpen = false
+	g.Cursor = false
+	g.DeleteView("palette")
+	g.DeleteView("palette-results")
+}
+
+// paletteBindings sets up Ctrl+P to open the palette, and the keys inside it.
+func paletteBindings(g *gocui.Gui) error {
+	bindings := []struct {
+		view    string
+		key     gocui.Key
+		handler func(g *gocui.Gui, v *gocui.View) error
+	}{
+		{"", gocui.KeyCtrlP, func(g *gocui.Gui, v *gocui.View) error {
+			if !trackPromptOpen && !browserOpen {
+				paletteOpen = true
+			}
+			return nil
+		}},
+		{"palette", gocui.KeyEnter, func(g *gocui.Gui, v *gocui.View) error {
+			if paletteSelected < len(paletteResults) {
+				choosePaletteItem(paletteResults[paletteSelected])
+			}
+			closePalette(g)
+			return nil
+		}},
+		{"palette", gocui.KeyCtrlS, func(g *gocui.Gui, v *gocui.View) error {
+			if paletteSelected < len(paletteResults) {
+				pinPaletteItem(paletteResults[paletteSelected])
+			}
+			closePalette(g)
+			return nil
+		}},
+		{"palette", gocui.KeyEsc, onEscape(func(g *gocui.Gui, v *gocui.View) error {
+			closePalette(g)
+			return nil
+		})},
+		{"palette", gocui.KeyArrowDown, func(g *gocui.Gui, v *gocui.View) error {
+			if paletteSelected < min(len(paletteResults), maxPaletteResults)-1 {
+				paletteSelected++
+			}
+			return nil
+		}},
+		{"palette", gocui.KeyArrowUp, func(g *gocui.Gui, v *gocui.View) error {
+			if paletteSelected > 0 {
+				paletteSelected--
+			}
+			return nil
+		}},
+	}
+	for _, b := range bindings {
+		if err := g.SetKeybinding(b.view, b.key, gocui.ModNone, b.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
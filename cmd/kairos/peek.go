@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/jroimartin/gocui"
+)
+
+// peekLocation is the IANA location shown in the peek card (Tab in the palette or the IANA
+// browser), or "" while the card is closed. It is never saved.
+var peekLocation string
+
+// openPeek shows a location in the peek card, or notifies if it cannot be loaded.
+func openPeek(location string) {
+	if browserLocation(location) == nil {
+		showWarning("Cannot peek at " + location)
+		return
+	}
+	peekLocation = location
+}
+
+/**
+ * This function lists the peek card: the zone's time in large digits with its date, the
+ * details of its location (see locationDetailLines), and the keys. The digits are left
+ * out when the screen is too small for them.
+ *
+ * @param now - The current time.
+ * @param width - The card's inner width.
+ * @param maxLines - The most lines the card can have.
+ * @returns The card's lines.
+ */
+func peekLines(now time.Time, width, maxLines int) []string {
+	loc := browserLocation(peekLocation)
+	local := now.In(loc)
+	var lines []string
+	for _, line := range render.PrintTimeASCII(local.Format("15:04")) {
+		lines = append(lines, render.Center(line, width))
+	}
+	lines = append(lines, render.Center(fmt.Sprintf("\x1b[1m%s\x1b[0m", local.Format("Monday, January 2, 2006 · 15:04:05")), width), "")
+	details := locationDetailLines(peekLocation, loc, now)
+	keys := " \x1b[90mEnter shows it in the top view, Esc closes\x1b[0m"
+	if tz, ok := browserConfigured(peekLocation); ok {
+		details = append(details, "", fmt.Sprintf(" \x1b[32mConfigured as %s\x1b[0m", tz.Name))
+		keys = " \x1b[90mEnter promotes it to the top, Esc closes\x1b[0m"
+	}
+	lines = append(append(lines, details...), "", keys)
+	if len(lines) > maxLines {
+		lines = lines[len(render.PrintTimeASCII("00:00")):]
+	}
+	return lines
+}
+
+/**
+ * This function shows the peek card while it is open, centered over the dashboard. The
+ * card is not a prompt: the dashboard's keys keep working under it, and it updates
+ * every second like the rest of the dashboard. It is called from the dashboard layout
+ * after the other views are drawn.
+ *
+ * @param g - The dashboard's gocui.Gui.
+ * @param now - The time to show.
+ * @returns An error if the view cannot be created.
+ */
+func layoutPeek(g *gocui.Gui, now time.Time) error {
+	if peekLocation == "" {
+		return nil
+	}
+	maxX, maxY := g.Size()
+	width := min(56, maxX-2)
+	lines := peekLines(now, width-1, maxY-2)
+	x0, y0 := (maxX-width)/2, max(0, (maxY-len(lines)-1)/2)
+	v, err := g.SetView("peek", x0, y0, x0+width, min(y0+len(lines)+1, maxY-1))
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	if _, err := drawTitle(g, v, " Peek: "+peekLocation+" ", g.FgColor); err != nil {
+		return err
+	}
+	v.Clear()
+	fmt.Fprint(v, consoleSafe(strings.Join(lines, "\n")))
+	g.SetViewOnTop("peek")
+	_, err = g.SetViewOnTop("peek" + titleViewSuffix)
+	if err == gocui.ErrUnknownView {
+		return nil
+	}
+	return err
+}
+
+// closePeek removes the peek card.
+func closePeek(g *gocui.Gui) {
+	peekLocation = ""
+	g.DeleteView("peek")
+	g.DeleteView("peek" + titleViewSuffix)
+}
+
+/**
+ * This function sets up the peek card's keys: Tab in the palette and in the IANA
+ * browser peeks at the selected zone, closing them, and while the card is shown Enter
+ * puts the zone in the top view (promoting a configured zone, previewing any other) and
+ * Esc closes it (see focusBindings).
+ *
+ * @param g - The dashboard's gocui.Gui.
+ * @returns An error if a key could not be bound.
+ */
+func peekBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding("palette", gocui.KeyTab, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		if paletteSelected < len(paletteResults) {
+			openPeek(paletteResults[paletteSelected].Zone.Location)
+		}
+		closePalette(g)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := g.SetKeybinding("browser", gocui.KeyTab, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		if browserSelected < len(browserRows) && browserRows[browserSelected].Leaf {
+			openPeek(browserRows[browserSelected].Path)
+			closeBrowser(g)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	return g.SetKeybinding("", gocui.KeyEnter, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		// Enter in a prompt is the prompt's, even once its own handler has closed it.
+		if peekLocation == "" || (v != nil && v.Editable) {
+			return nil
+		}
+		if tz, ok := browserConfigured(peekLocation); ok {
+			choosePaletteItem(paletteItem{Zone: tz, Configured: true})
+		} else {
+			choosePaletteItem(paletteItem{Zone: TimezoneConfig{Name: previewLabel(peekLocation), Location: peekLocation}})
+		}
+		closePeek(g)
+		return nil
+	})
+}
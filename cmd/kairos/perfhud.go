@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// perfHUD shows the performance overlay, toggled with Ctrl+D; it is meant for diagnosing
+// a sluggish dashboard (e.g. over a slow SSH link), so it is left out of the key hints.
+var perfHUD bool
+
+// perfWindow is how many layouts and ticks the overlay averages over.
+const perfWindow = 60
+
+// perfStats are the measurements shown by the overlay.
+var perfStats struct {
+	mu sync.Mutex
+	// layouts are the most recent layout durations, and layoutAt when each started.
+	layouts  [perfWindow]time.Duration
+	layoutAt [perfWindow]time.Time
+	layoutN  int
+	// lags are how late the most recent ticks ran on the GUI goroutine.
+	lags [perfWindow]time.Duration
+	lagN int
+	// mallocs and allocBytes are the totals at the last sample, taken with sampledAt.
+	mallocs, allocBytes uint64
+	sampledAt           time.Time
+	// mallocRate and byteRate are the allocations per second since the sample before.
+	mallocRate, byteRate float64
+	heap                 uint64
+	gcs                  uint32
+}
+
+// recordLayout records the duration of a layout that started at start; it is deferred by layout.
+func recordLayout(start time.Time) {
+	d := time.Since(start)
+	perfStats.mu.Lock()
+	defer perfStats.mu.Unlock()
+	i := perfStats.layoutN % perfWindow
+	perfStats.layouts[i], perfStats.layoutAt[i] = d, start
+	perfStats.layoutN++
+}
+
+/**
+ * This function records how late a clock tick ran: the time between the ticker firing
+ * and its update running on the GUI goroutine, which grows when drawing (or writing to
+ * a slow terminal) cannot keep up. While the overlay is shown, it also samples the
+ * allocation counters once a tick.
+ *
+ * @param tick - When the ticker fired.
+ * @param now - When the update ran.
+ */
+func recordTick(tick, now time.Time) {
+	perfStats.mu.Lock()
+	defer perfStats.mu.Unlock()
+	perfStats.lags[perfStats.lagN%perfWindow] = now.Sub(tick)
+	perfStats.lagN++
+	if !perfHUD {
+		return
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	if !perfStats.sampledAt.IsZero() {
+		secs := now.Sub(perfStats.sampledAt).Seconds()
+		perfStats.mallocRate = float64(m.Mallocs-perfStats.mallocs) / secs
+		perfStats.byteRate = float64(m.TotalAlloc-perfStats.allocBytes) / secs
+	}
+	perfStats.mallocs, perfStats.allocBytes, perfStats.sampledAt = m.Mallocs, m.TotalAlloc, now
+	perfStats.heap, perfStats.gcs = m.HeapAlloc, m.NumGC
+}
+
+// perfDurations returns the average and maximum of the first n durations of a window.
+func perfDurations(ds []time.Duration, n int) (avg, max time.Duration) {
+	n = min(n, len(ds))
+	if n == 0 {
+		return 0, 0
+	}
+	var sum time.Duration
+	for _, d := range ds[:n] {
+		sum += d
+		if d > max {
+			max = d
+		}
+	}
+	return sum / time.Duration(n), max
+}
+
+/**
+ * This function builds the overlay in the top-right corner: layout time and frame rate,
+ * clock tick lag, goroutines, and allocations.
+ *
+ * @param maxX - The terminal width.
+ * @param t - The active theme, for the border color.
+ * @returns The overlay's view.
+ */
+func perfHUDFrame(maxX int, t theme) viewFrame {
+	perfStats.mu.Lock()
+	defer perfStats.mu.Unlock()
+	layoutAvg, layoutMax := perfDurations(perfStats.layouts[:], perfStats.layoutN)
+	lagAvg, lagMax := perfDurations(perfStats.lags[:], perfStats.lagN)
+	fps := 0
+	for i := 0; i < min(perfStats.layoutN, perfWindow); i++ {
+		if time.Since(perfStats.layoutAt[i]) < time.Second {
+			fps++
+		}
+	}
+	lines := []string{
+		fmt.Sprintf(" layout %7s avg %7s max", roundDuration(layoutAvg), roundDuration(layoutMax)),
+		fmt.Sprintf(" frames %4d/s", fps),
+		fmt.Sprintf(" tick   %7s lag %7s max", roundDuration(lagAvg), roundDuration(lagMax)),
+		fmt.Sprintf(" gorout %4d", runtime.NumGoroutine()),
+		fmt.Sprintf(" alloc  %6.0f/s  %7s/s", perfStats.mallocRate, formatBytes(uint64(perfStats.byteRate))),
+		fmt.Sprintf(" heap   %7s  %d GCs", formatBytes(perfStats.heap), perfStats.gcs),
+	}
+	const width = 36
+	x0 := max(0, maxX-width-1)
+	return viewFrame{
+		Name: "perfhud", X0: x0, Y0: 0, X1: x0 + width, Y1: len(lines) + 1,
+		Frame: true, Title: "perf (Ctrl+D)", Lines: lines,
+		FrameColor: gocui.ColorMagenta, FgColor: t.Text,
+	}
+}
+
+// roundDuration shortens a duration for the overlay, e.g. 1.234ms.
+func roundDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
+
+// formatBytes shortens a byte count, e.g. 4.2MB.
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+// perfHUDBindings binds Ctrl+D to show or hide the performance overlay.
+func perfHUDBindings(g *gocui.Gui) error {
+	return g.SetKeybinding("", gocui.KeyCtrlD, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		perfHUD = !perfHUD
+		if !perfHUD {
+			g.DeleteView("perfhud")
+		}
+		return nil
+	})
+}
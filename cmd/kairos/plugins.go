@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// widgetPluginPrefix starts the names of plugin executables: kairos-widget-NAME is the {NAME} widget.
+const widgetPluginPrefix = "kairos-widget-"
+
+// widgetPlugin is a plugin executable found on the PATH.
+type widgetPlugin struct {
+	Name string
+	Path string
+}
+
+// widgetPlugins are the plugins found by loadWidgetPlugins, sorted by name.
+var widgetPlugins []widgetPlugin
+
+/**
+ * This function finds the widget plugins: executables named kairos-widget-NAME on the
+ * PATH, where NAME is lowercase letters. As with commands, the first one on the PATH wins.
+ * Built-in footer widgets and widget scripts take precedence over plugins of the same name.
+ * Each plugin is registered as a footer field, so this runs before the config is loaded.
+ */
+func loadWidgetPlugins() {
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		paths, _ := filepath.Glob(filepath.Join(defaultString(dir, "."), widgetPluginPrefix+"*"))
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			name, ok := pluginName(path, info.Mode())
+			if !ok || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if !widgetName.MatchString(name) {
+				logger.Warn("widget plugin skipped (the name must be lowercase letters)", "path", path)
+				continue
+			}
+			if _, taken := footerFields[name]; taken {
+				logger.Warn("widget plugin skipped (a footer widget or script has the same name)", "path", path)
+				continue
+			}
+			widgetPlugins = append(widgetPlugins, widgetPlugin{Name: name, Path: path})
+		}
+	}
+	sort.Slice(widgetPlugins, func(i, j int) bool { return widgetPlugins[i].Name < widgetPlugins[j].Name })
+	for _, p := range widgetPlugins {
+		registerWidget(p.Name)
+	}
+}
+
+/**
+ * This function takes a plugin's name from its file, e.g. "weather" from kairos-widget-weather.
+ * Unix plugins must be executable; on Windows the extension (from $PATHEXT, e.g. ".exe")
+ * marks them instead and is left out of the name.
+ *
+ * @param path - The plugin's path.
+ * @param mode - The file's mode.
+ * @returns The name, and false if the file is not executable.
+ */
+func pluginName(path string, mode os.FileMode) (string, bool) {
+	name := strings.TrimPrefix(filepath.Base(path), widgetPluginPrefix)
+	if runtime.GOOS != "windows" {
+		return name, mode&0o111 != 0
+	}
+	ext := filepath.Ext(name)
+	for _, e := range filepath.SplitList(defaultString(os.Getenv("PATHEXT"), ".COM;.EXE;.BAT;.CMD")) {
+		if ext != "" && strings.EqualFold(ext, e) {
+			return strings.TrimSuffix(name, ext), true
+		}
+	}
+	return name, false
+}
+
+/**
+ * This function runs a widget plugin once, the way status bars run their blocks. The
+ * plugin gets the time and the zones in its environment:
+ *
+ *	KAIROS_TIME     the current time, RFC 3339 in UTC
+ *	KAIROS_PRIMARY  the primary zone's name
+ *	KAIROS_ZONES    every zone as Name=Location, separated by commas
+ *
+ * The first line it prints is the footer text; each further "Name: text" line is shown
+ * under that zone's clock.
+ *
+ * @param ctx - Stops the plugin when the dashboard exits.
+ * @param path - The plugin executable.
+ * @param zones - The zones.
+ * @param now - The current time.
+ * @returns The output, or an error if the plugin fails or runs past widgetTimeout.
+ */
+func runWidgetPlugin(ctx context.Context, path string, zones []TimezoneConfig, now time.Time) (widgetOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, widgetTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	var list []string
+	for _, tz := range zones {
+		list = append(list, tz.Name+"="+tz.Location)
+	}
+	cmd.Env = append(os.Environ(), "KAIROS_TIME="+now.UTC().Format(time.RFC3339), "KAIROS_ZONES="+strings.Join(list, ","))
+	if len(zones) > 0 {
+		cmd.Env = append(cmd.Env, "KAIROS_PRIMARY="+zones[0].Name)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.Output()
+	if err != nil {
+		// The first line of stderr usually says why.
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+		return widgetOutput{}, err
+	}
+
+	out := widgetOutput{Tiles: map[string]string{}}
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	for first := true; scanner.Scan(); first = false {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if first {
+			out.Footer = line
+			continue
+		}
+		name, text, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		for _, tz := range zones {
+			if strings.EqualFold(tz.Name, strings.TrimSpace(name)) {
+				out.Tiles[tz.Name] = strings.TrimSpace(text)
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// prayerMethod defines the twilight angles used by a calculation convention.
+type prayerMethod struct {
+	Name  string
+	Fajr  float64 // Sun depression angle for Fajr, in degrees.
+	Isha  float64 // Sun depression angle for Isha, in degrees (ignored when IshaMinutes is set).
+	Label string
+	// IshaMinutes, when non-zero, places Isha a fixed interval after Maghrib.
+	IshaMinutes int
+}
+
+// prayerTime is a single named prayer on a given day.
+type prayerTime struct {
+	Name string
+	Time time.Time
+}
+
+var (
+	// prayerMethods lists the supported calculation conventions, keyed by the "prayer" option value.
+	prayerMethods = map[string]prayerMethod{
+		"mwl":     {Name: "mwl", Fajr: 18, Isha: 17, Label: "Muslim World League"},
+		"isna":    {Name: "isna", Fajr: 15, Isha: 15, Label: "Islamic Society of North America"},
+		"egypt":   {Name: "egypt", Fajr: 19.5, Isha: 17.5, Label: "Egyptian General Authority of Survey"},
+		"makkah":  {Name: "makkah", Fajr: 18.5, IshaMinutes: 90, Label: "Umm al-Qura University, Makkah"},
+		"karachi": {Name: "karachi", Fajr: 18, Isha: 18, Label: "University of Islamic Sciences, Karachi"},
+	}
+
+	// lastPrayerCheck remembers the last instant checked for prayer notifications.
+	lastPrayerCheck time.Time
+)
+
+/**
+ * This function returns the names of the supported prayer calculation methods in sorted order.
+ *
+ * @returns The method names accepted by the "prayer" option.
+ */
+func prayerMethodNames() []string {
+	names := make([]string, 0, len(prayerMethods))
+	for name := range prayerMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/**
+ * This function computes the five daily prayer times for the local date of day.
+ * Prayers that cannot be computed (e.g. twilight never ends at high latitudes in summer) are omitted.
+ *
+ * @param day - Any time on the local date of interest.
+ * @param c - The observer's coordinates.
+ * @param method - The calculation convention.
+ * @param hanafi - True to use the Hanafi shadow length (2x) for Asr instead of the standard (1x).
+ * @returns The prayer times in chronological order.
+ */
+func prayerTimes(day time.Time, c Coordinates, method prayerMethod, hanafi bool) []prayerTime {
+	var times []prayerTime
+	add := func(name string, t time.Time, ok bool) {
+		if ok {
+			times = append(times, prayerTime{Name: name, Time: t.In(day.Location())})
+		}
+	}
+
+	fajr, ok := sunAltitudeTime(day, c, -method.Fajr, true)
+	add("Fajr", fajr, ok)
+
+	// Dhuhr is observed just after the sun passes its zenith.
+	noon := solarNoon(day, c)
+	add("Dhuhr", noon.Add(time.Minute), true)
+
+	// Asr begins when an object's shadow equals its noon shadow plus its length (or twice its length for Hanafi).
+	factor := 1.0
+	if hanafi {
+		factor = 2
+	}
+	decl, _ := sunPosition(julianDate(noon))
+	asrAltitude := math.Atan(1/(factor+math.Tan(math.Abs(c.Lat-decl)*degToRad))) * radToDeg
+	asr, ok := sunAltitudeTime(day, c, asrAltitude, false)
+	add("Asr", asr, ok)
+
+	maghrib, ok := sunAltitudeTime(day, c, sunriseAltitude, false)
+	add("Maghrib", maghrib, ok)
+
+	if method.IshaMinutes > 0 {
+		add("Isha", maghrib.Add(time.Duration(method.IshaMinutes)*time.Minute), ok)
+	} else {
+		isha, ok := sunAltitudeTime(day, c, -method.Isha, false)
+		add("Isha", isha, ok)
+	}
+	return times
+}
+
+/**
+ * This function finds the next prayer after now for a timezone, looking into tomorrow if needed.
+ *
+ * @param tz - The timezone configuration (must have coordinates and a prayer method).
+ * @param now - The current time in the timezone.
+ * @returns The next prayer, and false if prayer times are not configured or cannot be computed.
+ */
+func nextPrayer(tz TimezoneConfig, now time.Time) (prayerTime, bool) {
+	method, ok := prayerMethods[tz.Prayer]
+	coords, hasCoords := zoneCoordinates(tz)
+	if !ok || !hasCoords {
+		return prayerTime{}, false
+	}
+	hanafi := strings.EqualFold(tz.Asr, "hanafi")
+	for _, day := range []time.Time{now, now.AddDate(0, 0, 1)} {
+		for _, p := range prayerTimes(day, coords, method, hanafi) {
+			if p.Time.After(now) {
+				return p, true
+			}
+		}
+	}
+	return prayerTime{}, false
+}
+
+/**
+ * This function formats the next prayer and a countdown for display in a timezone's view.
+ *
+ * @param tz - The timezone configuration.
+ * @param now - The current time in the timezone.
+ * @returns A line such as "Asr 15:42 in 1h 12m", or an empty string if not configured.
+ */
+func nextPrayerLine(tz TimezoneConfig, now time.Time) string {
+	p, ok := nextPrayer(tz, now)
+	if !ok {
+		return ""
+	}
+	remaining := p.Time.Sub(now)
+	return fmt.Sprintf("🕌 %s %s in %dh %02dm", p.Name, p.Time.Format("15:04"), int(remaining.Hours()), int(remaining.Minutes())%60)
+}
+
+/**
+ * This function shows a footer notification when a prayer time has been reached in any
+ * timezone with prayer notifications enabled. It is called on every UI tick and only
+ * reports prayers that fell between the previous check and now.
+ *
+ * @param now - The current time.
+ */
+func checkPrayerNotifications(now time.Time) {
+	since := lastPrayerCheck
+	lastPrayerCheck = now
+	if since.IsZero() {
+		return
+	}
+	for _, tz := range timezones {
+		if !tz.PrayerNotify {
+			continue
+		}
+		loc, ok := locations[tz.Name]
+		if !ok {
+			continue
+		}
+		// The prayer that was "next" at the previous check has started if it is no longer in the future.
+		if p, ok := nextPrayer(tz, since.In(loc)); ok && !p.Time.After(now) {
+			showNotification(fmt.Sprintf("%s time in %s (%s)", p.Name, tz.Name, p.Time.Format("15:04")))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// Periods accepted by a zone's "bars" option, in display order.
+var progressPeriods = []string{"year", "month", "week"}
+
+/**
+ * This function renders a bracketed progress bar followed by a label, filling the given width.
+ * It is the shared renderer behind the day, workday, and period progress bars.
+ *
+ * @param percent - The fraction to fill, from 0.0 to 1.0 (values outside are clamped).
+ * @param label - The text shown after the bar, including any leading space.
+ * @param width - The total width available for the bar and label.
+ * @param color - The ANSI color sequence applied to the whole bar.
+ * @returns The colored bar, reset to the default color at the end.
+ */
+func renderProgressBar(percent float64, label string, width int, color string) string {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 1 {
+		percent = 1
+	}
+	// It takes the total available width and subtracts 2 to account for the leading and trailing brackets [].
+	barWidth := width - 2 - len(label)
+	if barWidth < 0 {
+		barWidth = 0
+	}
+	// Multiplies the available bar width by the percentage to determine how many "solid" blocks (█) to draw.
+	fillWidth := int(float64(barWidth) * percent)
+	// The bar is assembled in one allocation, from prebuilt runs of blocks and spaces.
+	var b strings.Builder
+	b.Grow(len(color) + 2 + fillWidth*len("█") + barWidth - fillWidth + len(label) + len("\x1b[0m"))
+	b.WriteString(color)
+	b.WriteByte('[')
+	writeRun(&b, blocks, "█", fillWidth)
+	b.WriteString(render.Padding(barWidth - fillWidth))
+	b.WriteByte(']')
+	b.WriteString(label)
+	b.WriteString("\x1b[0m")
+	return b.String()
+}
+
+// blocks is a run of the progress bars' fill character, sliced by writeRun.
+var blocks = strings.Repeat("█", 512)
+
+// writeRun writes n copies of unit, slicing them from run (n copies at most of unit) where it is long enough.
+func writeRun(b *strings.Builder, run, unit string, n int) {
+	if n*len(unit) <= len(run) {
+		b.WriteString(run[:n*len(unit)])
+		return
+	}
+	b.WriteString(strings.Repeat(unit, n))
+}
+
+/**
+ * This function measures how far now is through the current year, month, or week.
+ * Weeks start on the week_start day and are numbered by week_numbering (see weeks.go).
+ *
+ * @param now - The current time in the timezone.
+ * @param period - One of "year", "month", or "week".
+ * @returns The fraction elapsed and a short label such as " Oct 55%", or false for an unknown period.
+ */
+func periodProgress(now time.Time, period string) (float64, string, bool) {
+	var start, end time.Time
+	var name string
+	switch period {
+	case "year":
+		start = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+		end = start.AddDate(1, 0, 0)
+		name = fmt.Sprintf("%d", now.Year())
+	case "month":
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		end = start.AddDate(0, 1, 0)
+		name = now.Format("Jan")
+	case "week":
+		start = startOfWeek(now)
+		end = start.AddDate(0, 0, 7)
+		_, week := weekNumber(now)
+		name = fmt.Sprintf("W%02d", week)
+	default:
+		return 0, "", false
+	}
+	percent := float64(now.Sub(start)) / float64(end.Sub(start))
+	return percent, fmt.Sprintf(" %s %3d%%", name, int(percent*100)), true
+}
+
+/**
+ * This function renders the additional period progress bars selected for a timezone.
+ *
+ * @param tz - The timezone configuration holding the selected periods.
+ * @param now - The current time in the timezone.
+ * @param width - The width available for each bar.
+ * @returns One rendered bar per selected period, in year, month, week order.
+ */
+func periodProgressBars(tz TimezoneConfig, now time.Time, width int) []string {
+	var bars []string
+	for _, period := range progressPeriods {
+		if !tz.HasBar(period) {
+			continue
+		}
+		if percent, label, ok := periodProgress(now, period); ok {
+			bars = append(bars, renderProgressBar(percent, label, width, "\x1b[36m"))
+		}
+	}
+	return bars
+}
@@ -0,0 +1,36 @@
+package main
+
+import "fmt"
+
+// readOnlyFlag is set by the global --readonly flag.
+var readOnlyFlag bool
+
+// readOnly reports whether the config must not be changed: with --readonly, or the read_only setting on.
+func readOnly() bool {
+	return readOnlyFlag || settings.ReadOnly
+}
+
+/**
+ * This function guards every change to the config file, for shared machines such as
+ * kiosks and jump hosts where nobody should change it by accident: the commands that add,
+ * remove, or set anything, undo, restore, edit, and the dashboard's saved toggles.
+ *
+ * @returns An error saying why the config is read-only, or nil if it can be changed.
+ */
+func checkWritable() error {
+	switch {
+	case readOnlyFlag:
+		return fmt.Errorf("the config is read-only (--readonly); nothing was changed")
+	case settings.ReadOnly:
+		return fmt.Errorf("the config is read-only (the read_only setting); nothing was changed. 'kairos config set read_only off' unlocks it")
+	}
+	return nil
+}
+
+// readOnlyStatus is the read-only badge in the {mode} footer widget, or "".
+func readOnlyStatus() string {
+	if !readOnly() {
+		return ""
+	}
+	return "\x1b[33m\x1b[1m[read-only]\x1b[0m"
+}
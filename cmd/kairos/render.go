@@ -0,0 +1,509 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// viewFrame is the rendered content of one dashboard view (see render.Frame).
+type viewFrame = render.Frame
+
+// clockFonts are the values of the clock_font setting.
+var clockFonts = []string{"auto", "braille", "text"}
+
+// clockFont returns the clock_font setting, with text for auto on terminals without UTF-8, which cannot draw the block digits.
+func clockFont() string {
+	if settings.ClockFont == "" && terminalCaps.NoUTF8 {
+		return "text"
+	}
+	return settings.ClockFont
+}
+
+// Flags of the `kairos render` command.
+var renderWidth, renderHeight int
+
+// renderCommand builds the `kairos render` command.
+func renderCommand() *command {
+	return &command{
+		Name:  "render",
+		Short: "Prints one dashboard frame and exits (--width N --height N)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&renderWidth, "width", 120, "Frame width in columns")
+			fs.IntVar(&renderHeight, "height", 40, "Frame height in rows")
+			registerDashboardFlags(fs)
+		},
+		Run: func(args []string) error {
+			if err := applyDashboardFlags(); err != nil {
+				return err
+			}
+			return printRender(renderWidth, renderHeight)
+		},
+	}
+}
+
+/**
+ * This function handles the `kairos render` command. It renders one dashboard frame
+ * with the same layout code as the TUI and prints it with ANSI colors, for use with
+ * `watch -c kairos render`, CI screenshots, and documentation.
+ *
+ * @param width - The frame width in columns.
+ * @param height - The frame height in rows.
+ * @returns An error if nothing is configured or the size is too small.
+ */
+func printRender(width, height int) error {
+	if len(timezones) == 0 {
+		return fmt.Errorf("No timezones configured. Use: kairos add \"Name\" \"Location\"")
+	}
+	if width < 20 || height < 10 {
+		return usageErrorf(findCommand(commands, "render"), "the frame must be at least 20x10 (got %dx%d)", width, height)
+	}
+	loadLocations()
+	loadInteractions()
+	travelMode = settings.Travel != nil
+	defer subscribeComponents()()
+	if err := sampleStats(); err != nil {
+		logger.Warn("reading CPU usage failed", "err", err)
+	}
+	fmt.Fprint(os.Stdout, render.Compose(renderDashboard(appClock.Now(time.UTC), width, height), width, height))
+	return nil
+}
+
+/**
+ * This function loads the location of every configured timezone into the locations map,
+ * skipping (and logging) invalid ones.
+ */
+func loadLocations() {
+	locations = make(map[string]*time.Location)
+	for _, tz := range timezones {
+		// Loads the timezone location from the IANA Time Zone database.
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			// Skip invalid ones from config; `kairos doctor` reports them too.
+			logger.Warn("skipping timezone with an invalid location", "zone", tz.Name, "location", tz.Location, "err", err)
+			continue
+		}
+		// "Local" follows the system timezone if it changed while the dashboard runs.
+		if tz.Location == "Local" {
+			loc = localZone()
+		}
+		// Stores the loaded location in the locations map with the timezone name as the key.
+		locations[tz.Name] = loc
+	}
+	// The pinned Zulu view of military mode always resolves to UTC.
+	locations[zuluZone.Name] = time.UTC
+	appState.PublishZones(timezones, locations)
+}
+
+/**
+ * This function renders the whole dashboard without a terminal, producing the exact text
+ * of each view for a given instant and terminal size under the current settings.
+ * The TUI draws these frames as they are, and headless output and tests can use them directly.
+ *
+ * It divides the screen into a top section for the primary timezone and a grid of smaller sections
+ * for additional timezones, plus a help footer at the bottom.
+ * The locations map must be loaded first (see loadLocations).
+ *
+ * @param now - The instant to render.
+ * @param maxX - The terminal width.
+ * @param maxY - The terminal height.
+ * @returns The views, top first and the help footer last.
+ */
+func renderDashboard(now time.Time, maxX, maxY int) []viewFrame {
+	// A terminal below the minimum size shows a message instead of cramped or broken views.
+	if tooSmall(maxX, maxY) {
+		return themes[0].apply([]viewFrame{renderTooSmall(maxX, maxY)})
+	}
+	// Reserves the bottom lines of the terminal so the "Help Footer" doesn't overlap,
+	// unless the footer is hidden. A long notification can take a second line (see footerLines).
+	footer := footerLines(now, maxX)
+	gridMaxY := maxY - 2 - len(footer)
+	if settings.FooterHidden {
+		gridMaxY = maxY
+	}
+	// The primary view takes the top third of the height, and the grid the rest.
+	topHeight := gridMaxY / 3
+
+	// Every view is drawn from the same snapshot of the clocks (see snapshot.go).
+	snap := snapshotAt(now)
+	// The zones to draw, primary first (military mode pins Zulu at the top).
+	zones := displayZones()
+	var frames []viewFrame
+	// The theme follows the primary zone's local time, so that night_dim matches the room.
+	th := themes[0]
+	if loc, ok := locations[zones[0].Name]; ok {
+		th = activeTheme(now.In(loc))
+		// Zen mode shows nothing but the primary zone's clock.
+		if zenMode {
+			frame := viewFrame{Name: "zen", X0: -1, Y0: -1, X1: maxX, Y1: maxY,
+				Lines: renderZenLines(zones[0], now.In(loc), maxX, maxY)}
+			return th.apply(applyCelebration(append(frames, frame), now))
+		}
+	}
+
+	// So does the countdown view (see countdown.go).
+	if countdownMode {
+		frame := viewFrame{Name: "countdown", X0: -1, Y0: -1, X1: maxX, Y1: maxY,
+			Lines: renderCountdownLines(now, maxX, maxY)}
+		return th.apply(applyCelebration(append(frames, frame), now))
+	}
+
+	// The world map takes the place of the clocks while it is shown (see worldmap.go).
+	if mapMode {
+		frame := viewFrame{Name: "map", X0: 0, Y0: 0, X1: maxX - 1, Y1: gridMaxY - 1, Frame: true,
+			Title: " World map (m closes) ", Lines: renderWorldMapLines(now, maxX-2, gridMaxY-2)}
+		return th.apply(appendFooter(append(frames, frame), footer, maxX, maxY))
+	}
+
+	// So does the agenda (see agenda.go).
+	if agendaMode {
+		frame := viewFrame{Name: "agenda", X0: 0, Y0: 0, X1: maxX - 1, Y1: gridMaxY - 1, Frame: true,
+			Title: " Agenda: the next 30 days (a closes) ", Lines: agendaPanelLines(now)}
+		return th.apply(appendFooter(append(frames, frame), footer, maxX, maxY))
+	}
+	// The holidays panel also takes the place of the clocks (see holidays.go).
+	if holidaysMode {
+		frame := viewFrame{Name: "holidays", X0: 0, Y0: 0, X1: maxX - 1, Y1: gridMaxY - 1, Frame: true,
+			Title: " Holidays today and tomorrow (o closes) ", Lines: holidayPanelLines(now)}
+		return th.apply(appendFooter(append(frames, frame), footer, maxX, maxY))
+	}
+
+	// So does the workweek heat map (see heatmap.go).
+	if heatmapMode {
+		frame := viewFrame{Name: "heatmap", X0: 0, Y0: 0, X1: maxX - 1, Y1: gridMaxY - 1, Frame: true,
+			Title: " Workweek heat map: zones within business hours (g closes) ", Lines: heatmapLines(now, maxX-2)}
+		return th.apply(appendFooter(append(frames, frame), footer, maxX, maxY))
+	}
+
+	// A focused view's details are shown in a panel on the right, and the clocks share the rest.
+	panelWidth := infoPanelWidth(maxX)
+	gridMaxX := maxX - panelWidth
+
+	// Travel mode pins home and destination side by side, and gives the other zones less room (see travel.go).
+	var travel []viewFrame
+	if travelMode && !stopwatchMode {
+		if views, ok := renderTravelViews(snap, gridMaxX-1, gridMaxY/2-1); ok {
+			travel, topHeight = views, gridMaxY/2
+		}
+	}
+	// Otherwise a pinned zone shares the enlarged top area with the primary zone (see split.go).
+	// first is the index of the first zone in the grid.
+	first, topX1 := 1, gridMaxX-1
+	if len(travel) == 0 && splitActive(zones) {
+		first, topX1, topHeight = 2, (gridMaxX-1)/2-1, gridMaxY/2
+	}
+
+	// Top View (Index 0)
+	top := viewFrame{Name: "top", X0: 0, Y0: 0, X1: topX1, Y1: topHeight - 1, Frame: true}
+	if loc, ok := locations[zones[0].Name]; ok {
+		z := snap.zone(zones[0], loc)
+		// The title format is: " UTC 🌞 🟢" (for example), where the icon and business hours indicator change based on the current time.
+		top.Title = fmt.Sprintf(" %s%s", zones[0].Name, z.Title)
+		// The primary view also shows the global strips, such as the epoch strip.
+		top.Lines = renderZoneLines(z, zones[0], top.X1-top.X0-1, top.Y1-top.Y0-1, true, primaryStripLines(now)...)
+	}
+	// The stopwatch takes over the primary view while it is shown (see stopwatch.go).
+	if stopwatchMode {
+		top.Title = " Stopwatch (space start/stop, l lap, r reset, y copy) "
+		top.Lines = renderStopwatchLines(now, top.X1-top.X0-1, top.Y1-top.Y0-1)
+	} else if currentIncident != nil {
+		// So does the incident timer while an incident is handled (see incident.go).
+		top.Title = fmt.Sprintf(" 🚨 INCIDENT since %s UTC (x exports the timeline, ! resolves) ", currentIncident.Start.Format("15:04:05"))
+		top.Lines = renderIncidentLines(now, top.X1-top.X0-1, top.Y1-top.Y0-1)
+	}
+	// The home view replaces it in travel mode; the destination is added after the grid, so the views keep their indices.
+	if len(travel) > 0 {
+		top = travel[0]
+	}
+	frames = append(frames, top)
+	if first == 2 {
+		frames = append(frames, renderSplitView(snap, zones, topX1+1, gridMaxX-1, topHeight-1))
+	}
+
+	// Bottom Grid (Indices 1 and up, or 2 and up below the split view)
+	// The rows and columns depend on the terminal size and the number of zones and command tiles, or on the layout setting (see layout.go).
+	grid := chooseGridLayout(len(zones)-first+len(settings.Tiles), gridMaxX, gridMaxY-topHeight)
+	// Zones that do not fit in a fixed layout are left out.
+	shown := min(len(zones), first+grid.Cols*grid.Rows)
+	visibleViews = shown
+	colWidth, rowHeight := 0, 0
+	if grid.Cols > 0 {
+		colWidth, rowHeight = gridMaxX/grid.Cols, (gridMaxY-topHeight)/grid.Rows
+	}
+	// The grid's zones are badged when their date is not the primary zone's.
+	primaryLocal := now
+	if loc, ok := locations[zones[0].Name]; ok {
+		primaryLocal = now.In(loc)
+	}
+	// Command tiles take the slots after the zones, as long as there are any (see cmdtile.go).
+	slots := shown - first + min(len(settings.Tiles), grid.Cols*grid.Rows-(shown-first))
+	for slot := 0; slot < slots; slot++ {
+		i := first + slot
+		// Calculates the row and column indices for the current slot in the grid.
+		rowNum := slot / grid.Cols
+		colNum := slot % grid.Cols
+
+		// Determines the coordinates for the current view based on its row and column position in the grid.
+		x0, y0 := colNum*colWidth, topHeight+rowNum*rowHeight
+		x1, y1 := x0+colWidth-1, y0+rowHeight-1
+		// The last column spans the remaining width of the screen.
+		if colNum == grid.Cols-1 {
+			x1 = gridMaxX - 1
+		}
+		// The last row spans the remaining height above the footer.
+		if rowNum == grid.Rows-1 {
+			y1 = gridMaxY - 1
+		}
+
+		if i >= shown {
+			t := settings.Tiles[i-shown]
+			f := viewFrame{Name: fmt.Sprintf("tile%d", i-shown+1), X0: x0, Y0: y0, X1: x1, Y1: y1, Frame: true}
+			f.Title, f.Lines = renderTileLines(t, y1-y0-1)
+			frames = append(frames, f)
+			continue
+		}
+		f := viewFrame{Name: fmt.Sprintf("bottom%d", i), X0: x0, Y0: y0, X1: x1, Y1: y1, Frame: true}
+		if loc, ok := locations[zones[i].Name]; ok {
+			z := snap.zone(zones[i], loc)
+			// The title is formatted to include the timezone name, the current time, and an indicator for day/night and business hours.
+			f.Title = fmt.Sprintf(" [%d] %s%s%s", i, zones[i].Name, dayBadge(z.Local, primaryLocal), z.Title)
+			f.Lines = renderZoneLines(z, zones[i], x1-x0-1, y1-y0-1, false)
+		}
+		frames = append(frames, f)
+	}
+
+	if len(travel) > 1 {
+		frames = append(frames, travel[1:]...)
+	}
+	if focusIndex >= 0 && focusIndex < len(frames) {
+		frames[focusIndex].Focused = true
+	}
+	if tz, ok := focusedZone(); ok && panelWidth > 0 {
+		frames = append(frames, viewFrame{Name: "info", X0: gridMaxX, Y0: 0, X1: maxX - 1, Y1: gridMaxY - 1, Frame: true,
+			Title: " " + tz.Name + " ", Lines: infoPanelLines(tz, now)})
+	}
+	// A celebration plays over its zone's view (see celebrate.go).
+	frames = applyCelebration(frames, now)
+
+	return th.apply(appendFooter(frames, footer, maxX, maxY))
+}
+
+// appendFooter adds the help footer's lines (see footerLines) to the frames unless it is hidden.
+func appendFooter(frames []viewFrame, footer []string, maxX, maxY int) []viewFrame {
+	// Help footer
+	// The footer spans the entire width of the terminal and is positioned just above the bottom edge.
+	// Its content comes from the footer template (see footer.go).
+	if settings.FooterHidden {
+		return frames
+	}
+	return append(frames, viewFrame{Name: "help", X0: -1, Y0: maxY - 2 - len(footer), X1: maxX, Y1: maxY - 1,
+		Lines: footer})
+}
+
+/**
+ * This function renders the content of one timezone view.
+ * It handles the blinking animation, adaptive layout for different screen sizes, and the progress bar placement.
+ * It is called every second to keep the displayed time up-to-date.
+ *
+ * @param z - The zone's part of the snapshot being drawn: its local time and business-hours line.
+ * @param tz - The configuration of the timezone shown in the view, used for per-zone display options.
+ * @param width - The inner width of the view.
+ * @param height - The inner height of the view.
+ * @param primary - Whether the view is the primary view, whose clock flips its digits (see flip.go).
+ * @param extra - Additional lines to show before the zone's own detail lines (e.g. the epoch strip).
+ * @returns The view's lines, with the day progress bar on the last one.
+ */
+func renderZoneLines(z zoneSnapshot, tz TimezoneConfig, width, height int, primary bool, extra ...string) []string {
+	now := z.Local
+	// Blinking colon logic
+	// The Modulo Operator: Checks if the current second is even or odd.
+	// If it's odd, it replaces the colon with a space (03 04 PM), creating the blinking animation effect.
+	// The blink can be turned off (see blinkEnabled).
+	format := "03:04 PM"
+	if blinkEnabled() && now.Second()%2 != 0 {
+		format = "03 04 PM"
+	}
+	// Zones can read 24-hour time (see uses24Hour); military mode adds the zone's designator letter.
+	letter, _, hasLetter := militaryDesignator(now)
+	if uses24Hour(tz) {
+		format = strings.Replace(strings.Replace(format, "03", "15", 1), " PM", "", 1)
+	}
+	if settings.Military && hasLetter {
+		format += " " + letter
+	}
+
+	// Converts the formatted time string into a slice of strings representing the large block characters.
+	// Views too narrow for them use the half-width digits instead.
+	draw := render.PrintTimeASCII
+	if render.TextWidth(draw(now.Format(format))[0]) > width {
+		draw = render.PrintCompactTimeASCII
+	}
+	// Views too short or narrow for the half-width digits use braille dots, which take two rows.
+	font := clockFont()
+	braille := font == "braille" || (font == "" && (height < 8 || render.TextWidth(draw(now.Format(format))[0]) > width))
+	if braille {
+		draw = render.PrintBrailleTimeASCII
+	}
+	art := draw(now.Format(format))
+	// Zones with another clock style draw it instead of the digits (see clockstyles.go).
+	style, styled := clockStyles[tz.Style]
+	if styled {
+		art, braille = style(now, width), false
+	} else if primary {
+		// The primary clock flips its digits over on minute changes.
+		art = flipArt(now, art, draw(now.Add(-time.Minute).Format(format)))
+	}
+	// The braille digits still fit with only the date, or alone.
+	if braille && height >= 2 && height < 6 && render.TextWidth(art[0]) <= width && font != "text" {
+		lines := colorArt(art, now, width)
+		if height > 2 {
+			lines = append(lines, render.Center(now.Format("Mon, Jan 2"), width))
+		}
+		if height > 4 {
+			lines = append(lines, getDayProgressBar(now, width, tz))
+		} else if height > 3 {
+			lines = append([]string{""}, lines...)
+		}
+		return lines
+	}
+
+	// Adaptive layout logic
+	// This is a fail-safe for small windows (like a resized terminal or a tablet).
+	// If there isn't enough space for even the braille digits, it switches to a simple, clean text format.
+	if (font == "text" && !styled) || height < len(art)+3 || render.TextWidth(art[0]) > width {
+		small := now.Format("03:04:05 PM")
+		if uses24Hour(tz) {
+			small = now.Format("15:04:05")
+		}
+		if settings.Military {
+			small += letter
+		}
+		lines := []string{render.Center(small, width), render.Center(now.Format("Mon, Jan 2"), width)}
+		// The top padding is dropped first when the view is too short for everything.
+		if height > len(lines)+1 {
+			lines = append([]string{""}, lines...)
+		}
+		return withBottomLine(lines, height, getDayProgressBar(now, width, tz))
+	}
+
+	// With --scale the digits are enlarged as far as the view allows; braille dots and other styles cannot be.
+	scale := clockScale
+	for scale > 1 && (braille || styled || height < 4+5*scale || render.TextWidth(art[0])*scale > width) {
+		scale--
+	}
+	// Each line of the ASCII art is then centered horizontally within the view.
+	lines := append([]string{""}, colorArt(scaleASCII(art, scale), now, width)...)
+	room := height - 4 - len(art)*scale
+
+	// Adds the zone's secondary time line right under the digits, when there is room for it.
+	if sub := subtimeLine(tz, now); sub != "" && room > 0 {
+		lines = append(lines, render.Center(sub, width))
+		room--
+	}
+
+	// Adds the date below the time, bolded using ANSI escape codes.
+	dateStr := fmt.Sprintf("\x1b[1m%s\x1b[0m", now.Format("Monday, January 2, 2006"))
+	lines = append(lines, render.Center(dateStr, width))
+
+	// Adds the optional per-zone detail lines (alternative calendar, next prayer, alternate times),
+	// keeping only as many as fit above the business hours indicator and the progress bar.
+	for _, line := range append(extra, zoneDetailLines(tz, now, width)...) {
+		if room <= 0 {
+			break
+		}
+		lines = append(lines, render.Center(line, width))
+		room--
+	}
+
+	// Adds the business hours indicator with a countdown to the next open/close.
+	lines = append(lines, render.Center(z.Business, width))
+
+	// Adds the optional year/month/week progress bars in the remaining space.
+	for _, bar := range periodProgressBars(tz, now, width) {
+		if room <= 0 {
+			break
+		}
+		lines = append(lines, bar)
+		room--
+	}
+	return withBottomLine(lines, height, getDayProgressBar(now, width, tz))
+}
+
+/**
+ * This function renders the zen (presentation) view: the time alone, as large as the
+ * screen allows, centered both ways.
+ *
+ * @param tz - The primary zone, whose hour format is used.
+ * @param now - The current time in the primary zone.
+ * @param width - The screen width.
+ * @param height - The screen height.
+ * @returns The lines of the view.
+ */
+func renderZenLines(tz TimezoneConfig, now time.Time, width, height int) []string {
+	format := "03:04 PM"
+	if uses24Hour(tz) {
+		format = "15:04"
+	}
+	art := render.PrintTimeASCII(now.Format(format))
+	scale := 1
+	for render.TextWidth(art[0])*(scale+1) <= width && 5*(scale+1) <= height {
+		scale++
+	}
+	var lines []string
+	for i := 0; i < (height-5*scale)/2; i++ {
+		lines = append(lines, "")
+	}
+	for _, line := range scaleASCII(art, scale) {
+		lines = append(lines, render.Center(line, width))
+	}
+	return lines
+}
+
+/**
+ * This function places a line on the last row of a view of the given height, padding
+ * the content with blank lines (or cutting it short) so that it always stays visible.
+ *
+ * @param lines - The content above it.
+ * @param height - The inner height of the view.
+ * @param bottom - The line to place at the bottom, e.g. the day progress bar.
+ * @returns The combined lines.
+ */
+func withBottomLine(lines []string, height int, bottom string) []string {
+	if height < 1 {
+		return lines
+	}
+	if len(lines) > height-1 {
+		lines = lines[:height-1]
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	return append(lines, bottom)
+}
+
+// dayBadge is the title badge of a zone whose date is not the primary zone's, e.g. " +1 day" for Sydney seen from LA, or "".
+func dayBadge(local, primary time.Time) string {
+	if diff := tzutil.CalendarDayDiff(local, primary); diff != 0 {
+		return fmt.Sprintf(" %+d day", diff)
+	}
+	return ""
+}
+
+// hourFormats are the values of the hour_format setting and zone option.
+var hourFormats = []string{"12h", "24h"}
+
+// uses24Hour reports whether a zone's clock reads 24-hour time: in military mode, else per
+// the zone's hour_format option, else per the hour_format setting (12-hour by default).
+func uses24Hour(tz TimezoneConfig) bool {
+	return settings.Military || defaultString(tz.HourFormat, settings.HourFormat) == "24h"
+}
+
+// blinkEnabled reports whether the clock colons blink; the blink and reduced_motion settings turn it off.
+func blinkEnabled() bool {
+	return !settings.NoBlink && !settings.ReducedMotion
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// update rewrites the golden files from the current output: go test -run Golden -update.
+var update = flag.Bool("update", false, "rewrite the testdata/*.golden files")
+
+// goldenNow is the instant every golden frame is rendered at, a Thursday afternoon in UTC.
+var goldenNow = time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC)
+
+/**
+ * This function sets up a known dashboard for a golden test: four zones, the default
+ * settings, no events, an empty home directory, a color terminal, and the clock frozen at
+ * goldenNow. Everything it changes is put back when the test ends.
+ *
+ * @param t - The test, benchmark, or fuzz target.
+ */
+func setupGolden(t testing.TB) {
+	t.Helper()
+	savedZones, savedEvents, savedSettings := timezones, events, settings
+	savedClock, savedCaps := appClock, terminalCaps
+	t.Cleanup(func() {
+		timezones, events, settings = savedZones, savedEvents, savedSettings
+		appClock, terminalCaps = savedClock, savedCaps
+		loadLocations()
+	})
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("NO_COLOR", "")
+	timezones = []TimezoneConfig{
+		{Name: "Manila", Location: "Asia/Manila"},
+		{Name: "London", Location: "Europe/London"},
+		{Name: "New York", Location: "America/New_York"},
+		{Name: "UTC", Location: "UTC"},
+	}
+	events, settings = nil, Settings{}
+	terminalCaps = termCaps{Colors: "256"}
+	appClock = &fixedClock{T: goldenNow}
+	loadLocations()
+}
+
+/**
+ * This function compares output with a golden file in testdata, or writes the file with
+ * -update. The output is compared as it is, ANSI codes included, so that a change of color
+ * shows up as well as a change of layout.
+ *
+ * @param t - The test.
+ * @param name - The golden file's name, without testdata/ and .golden.
+ * @param got - The output.
+ */
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -run Golden -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from %s; if the change is intended, run go test -run Golden -update\ngot:\n%s\nwant:\n%s", name, path, got, want)
+	}
+}
+
+func TestGoldenDashboard(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+	}{
+		{"dashboard-120x40", 120, 40},
+		{"dashboard-80x24", 80, 24},
+		// The smallest terminal the dashboard is drawn in; one column or row less shows only "Terminal too small".
+		{"dashboard-40x12", minDashboardWidth, minDashboardHeight},
+		{"toosmall-39x12", minDashboardWidth - 1, minDashboardHeight},
+		{"toosmall-100x11", 100, minDashboardHeight - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupGolden(t)
+			frames := renderDashboard(goldenNow, tt.width, tt.height)
+			if small := tooSmall(tt.width, tt.height); small != strings.HasPrefix(tt.name, "toosmall") {
+				t.Fatalf("tooSmall(%d, %d) = %v", tt.width, tt.height, small)
+			}
+			checkGolden(t, tt.name, render.Compose(frames, tt.width, tt.height))
+		})
+	}
+}
+
+func TestGoldenCentering(t *testing.T) {
+	var b strings.Builder
+	// Odd and even widths, with odd and even text, round the padding down alike.
+	for _, width := range []int{9, 10, 11, 12} {
+		for _, s := range []string{"12:34", "1234", "東京"} {
+			b.WriteString("|" + render.Center(s, width) + "|\n")
+		}
+		b.WriteString("|" + render.Center("\x1b[1mThu, 14 Mar\x1b[0m", width) + "|\n")
+		b.WriteString("|" + render.Center("\x1b[1mMar 14\x1b[0m", width) + "|\n")
+	}
+	checkGolden(t, "centering", b.String())
+}
+
+func TestGoldenProgressBar(t *testing.T) {
+	setupGolden(t)
+	var b strings.Builder
+	// The bar on the last row of a zone view, at the start, the middle, and the end of the day, and at odd and even widths.
+	for _, at := range []string{"00:00", "06:30", "12:00", "15:09", "23:59"} {
+		clock, _ := time.Parse("15:04", at)
+		now := time.Date(2024, 3, 14, clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+		for _, width := range []int{38, 39} {
+			b.WriteString(getDayProgressBar(now, width, timezones[3]) + "\n")
+		}
+		b.WriteString(getDayProgressBar(now, 39, TimezoneConfig{Name: "UTC", Location: "UTC", Progress: "workday"}) + "\n")
+	}
+	// In a rendered frame it is the last line of every zone view.
+	for _, f := range renderDashboard(goldenNow, 120, 40) {
+		if strings.HasPrefix(f.Name, "bottom") && len(f.Lines) > 0 {
+			b.WriteString(f.Name + ": " + f.Lines[len(f.Lines)-1] + "\n")
+		}
+	}
+	checkGolden(t, "progressbar", b.String())
+}
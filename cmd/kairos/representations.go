@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeRepresentation is an alternate way of writing an instant, shown as an extra line in a view.
+type timeRepresentation struct {
+	Key  string
+	Help string
+	// Format renders the instant for a zone; false means it cannot be shown (e.g. missing coordinates).
+	Format func(now time.Time, tz TimezoneConfig) (string, bool)
+}
+
+// timeRepresentations lists every representation selectable with `kairos set <zone> times`.
+var timeRepresentations = []timeRepresentation{
+	{
+		Key:    "beats",
+		Help:   "Swatch Internet Time (@000-@999, the same everywhere)",
+		Format: func(now time.Time, _ TimezoneConfig) (string, bool) { return formatBeats(now), true },
+	},
+	{
+		Key:    "decimal",
+		Help:   "French decimal time (10 hours of 100 minutes of 100 seconds)",
+		Format: func(now time.Time, _ TimezoneConfig) (string, bool) { return formatDecimalTime(now), true },
+	},
+	{
+		Key:    "epoch",
+		Help:   "Unix timestamp and UTC ISO 8601",
+		Format: func(now time.Time, _ TimezoneConfig) (string, bool) { return formatEpoch(now), true },
+	},
+	{
+		Key:    "tai",
+		Help:   "TAI and GPS time, and the next leap second",
+		Format: func(now time.Time, _ TimezoneConfig) (string, bool) { return formatPrecisionStrip(now), true },
+	},
+	{
+		Key:  "sidereal",
+		Help: "Local mean sidereal time (needs coords, or a location with a known city)",
+		Format: func(now time.Time, tz TimezoneConfig) (string, bool) {
+			c, ok := zoneCoordinates(tz)
+			if !ok {
+				return "", false
+			}
+			return formatSidereal(now, c.Lon), true
+		},
+	},
+	{
+		Key:  "daylight",
+		Help: "Length of the day's daylight (needs coords, or a location with a known city)",
+		Format: func(now time.Time, tz TimezoneConfig) (string, bool) {
+			c, ok := zoneCoordinates(tz)
+			if !ok {
+				return "", false
+			}
+			return "☀ " + formatDaylight(daylightDuration(now, c)), true
+		},
+	},
+	{
+		Key:  "golden",
+		Help: "Morning and evening golden hours (needs coords, or a location with a known city)",
+		Format: func(now time.Time, tz TimezoneConfig) (string, bool) {
+			c, ok := zoneCoordinates(tz)
+			if !ok {
+				return "", false
+			}
+			golden, ok := formatGoldenHours(now, c)
+			if !ok {
+				return "", false
+			}
+			return "Golden " + golden, true
+		},
+	},
+}
+
+// findRepresentation looks up a time representation by key.
+func findRepresentation(key string) (timeRepresentation, bool) {
+	for _, r := range timeRepresentations {
+		if r.Key == strings.ToLower(key) {
+			return r, true
+		}
+	}
+	return timeRepresentation{}, false
+}
+
+// representationKeys returns the keys of all time representations, in display order.
+func representationKeys() []string {
+	keys := make([]string, len(timeRepresentations))
+	for i, r := range timeRepresentations {
+		keys[i] = r.Key
+	}
+	return keys
+}
+
+/**
+ * This function renders the alternate time representations selected for a timezone.
+ *
+ * @param tz - The timezone configuration holding the selected representations.
+ * @param now - The current time in the timezone.
+ * @returns One line per representation that can be shown, in the configured order.
+ */
+func representationLines(tz TimezoneConfig, now time.Time) []string {
+	var lines []string
+	for _, key := range tz.Times {
+		r, ok := findRepresentation(key)
+		if !ok {
+			continue
+		}
+		if line, ok := r.Format(now, tz); ok {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+/**
+ * This function formats Swatch Internet Time. The day is divided into 1000 .beats
+ * counted from midnight in Biel Mean Time (UTC+1), so the value is the same worldwide.
+ *
+ * @param now - The instant to format.
+ * @returns The time as "@347.21 .beats".
+ */
+func formatBeats(now time.Time) string {
+	bmt := now.UTC().Add(time.Hour)
+	seconds := float64(bmt.Hour()*3600+bmt.Minute()*60+bmt.Second()) + float64(bmt.Nanosecond())/1e9
+	return fmt.Sprintf("\x1b[35m@%06.2f\x1b[0m .beats", seconds/86.4)
+}
+
+/**
+ * This function formats the local wall-clock time as French decimal time, where a day
+ * has 10 hours of 100 minutes of 100 seconds (so one decimal second is 0.864 s).
+ *
+ * @param now - The current time in the timezone.
+ * @returns The time as "4:16:32 decimal".
+ */
+func formatDecimalTime(now time.Time) string {
+	seconds := now.Hour()*3600 + now.Minute()*60 + now.Second()
+	decimal := seconds * 100000 / 86400
+	return fmt.Sprintf("\x1b[35m%d:%02d:%02d\x1b[0m decimal", decimal/10000, decimal/100%100, decimal%100)
+}
+
+// formatEpoch formats an instant as its Unix timestamp followed by the UTC ISO 8601 time.
+func formatEpoch(now time.Time) string {
+	return fmt.Sprintf("\x1b[33m%d\x1b[0m · %s", now.Unix(), now.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+/**
+ * This function formats the local mean sidereal time at a longitude, as used to find
+ * which right ascension is currently on the meridian.
+ *
+ * @param now - The instant to format.
+ * @param lon - The observer's longitude in degrees (east positive).
+ * @returns The time as "LMST 14:22:07".
+ */
+func formatSidereal(now time.Time, lon float64) string {
+	seconds := int(localSiderealTime(now, lon) * 3600)
+	return fmt.Sprintf("LMST \x1b[35m%02d:%02d:%02d\x1b[0m", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// subtimeFormats are the clock formats a zone's secondary time line can use, besides the time representations.
+var subtimeFormats = []struct {
+	Key    string
+	Format func(now time.Time) string
+}{
+	{"24h", func(now time.Time) string { return now.Format("15:04:05") }},
+	{"12h", func(now time.Time) string { return now.Format("03:04:05 PM") }},
+	{"utc", func(now time.Time) string { return now.UTC().Format("15:04:05") + " UTC" }},
+	{"iso", func(now time.Time) string { return now.Format(time.RFC3339) }},
+}
+
+// subtimeKeys returns every value `kairos set <zone> subtime` accepts.
+func subtimeKeys() []string {
+	var keys []string
+	for _, f := range subtimeFormats {
+		keys = append(keys, f.Key)
+	}
+	return append(keys, representationKeys()...)
+}
+
+/**
+ * This function renders a zone's secondary time line, shown in a smaller size under the
+ * clock's digits: the time in another format (e.g. 24-hour under the 12-hour digits) or
+ * one of the time representations (e.g. the Unix timestamp under the local time).
+ *
+ * @param tz - The timezone configuration holding the subtime format.
+ * @param now - The current time in the zone.
+ * @returns The line, or "" if the zone has none or it cannot be shown.
+ */
+func subtimeLine(tz TimezoneConfig, now time.Time) string {
+	if tz.Subtime == "" {
+		return ""
+	}
+	for _, f := range subtimeFormats {
+		if f.Key == tz.Subtime {
+			return f.Format(now)
+		}
+	}
+	if r, ok := findRepresentation(tz.Subtime); ok {
+		if line, ok := r.Format(now, tz); ok {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runtimeState is what the dashboard changes while it runs but never saves in the config:
+// the zone swapped into the primary view, the focused zone, the views toggled on, and the
+// stopwatch. It is saved when the dashboard exits and restored when it starts again.
+type runtimeState struct {
+	// Primary is the zone shown in the primary view.
+	Primary string `json:"primary,omitempty"`
+	// Pinned is the zone pinned beside the primary one in the split view.
+	Pinned string `json:"pinned,omitempty"`
+	// Focused is the zone focused with Tab or the arrow keys.
+	Focused string `json:"focused,omitempty"`
+	// Views are the dashboard views and modes that were on, e.g. "zen" or "stopwatch".
+	Views []string `json:"views,omitempty"`
+	// Incident is the incident being handled, which also goes on while the dashboard is closed.
+	Incident *incident `json:"incident,omitempty"`
+	// Stopwatch keeps running while the dashboard is closed, since it is measured from its start.
+	Stopwatch stopwatch `json:"stopwatch"`
+	// Saved is when the dashboard exited.
+	Saved time.Time `json:"saved"`
+}
+
+// runtimeViews are the views and modes kept in the runtime state, by name.
+var runtimeViews = map[string]*bool{
+	"zen":       &zenMode,
+	"countdown": &countdownMode,
+	"map":       &mapMode,
+	"agenda":    &agendaMode,
+	"holidays":  &holidaysMode,
+	"heatmap":   &heatmapMode,
+	"stopwatch": &stopwatchMode,
+	"hidden":    &showHidden,
+	"paused":    &carouselPaused,
+}
+
+// getRuntimeStatePath returns the runtime state file, next to (and named after) the config file.
+func getRuntimeStatePath() string {
+	return strings.TrimSuffix(getConfigPath(), filepath.Ext(getConfigPath())) + "_state.json"
+}
+
+// restoreStateEnabled reports whether the dashboard saves and restores its runtime state:
+// with restore_state on, and not in kiosk mode, whose view is set by its flags.
+func restoreStateEnabled() bool {
+	return !settings.NoRestoreState && !kioskMode
+}
+
+/**
+ * This function saves the dashboard's runtime state when it exits, so that an accidental
+ * quit does not lose the running stopwatch or the zone that was swapped in. An attached
+ * dashboard leaves the primary zone to the daemon.
+ */
+func saveRuntimeState() {
+	if !restoreStateEnabled() {
+		return
+	}
+	st := runtimeState{Pinned: pinnedZone, Incident: currentIncident, Stopwatch: watch, Saved: time.Now()}
+	if zones := displayZones(); len(zones) > 0 && !attached {
+		st.Primary = zones[0].Name
+	}
+	if tz, ok := focusedZone(); ok {
+		st.Focused = tz.Name
+	}
+	for name, on := range runtimeViews {
+		if *on {
+			st.Views = append(st.Views, name)
+		}
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(getRuntimeStatePath(), data, 0644)
+	}
+	if err != nil {
+		logger.Warn("runtime state not saved", "path", getRuntimeStatePath(), "err", err)
+	}
+}
+
+/**
+ * This function restores the runtime state saved when the dashboard last exited. Zones
+ * removed since then are skipped, and views already turned on by flags (e.g. --countdown)
+ * stay on. A missing or unreadable file leaves the dashboard as the config has it.
+ */
+func restoreRuntimeState() {
+	if !restoreStateEnabled() {
+		return
+	}
+	data, err := os.ReadFile(getRuntimeStatePath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("runtime state not restored", "path", getRuntimeStatePath(), "err", err)
+		}
+		return
+	}
+	var st runtimeState
+	if err := json.Unmarshal(data, &st); err != nil {
+		logger.Warn("runtime state not restored", "path", getRuntimeStatePath(), "err", err)
+		return
+	}
+	if st.Primary != "" && !attached && !settings.Military {
+		moveToTop(st.Primary)
+	}
+	// A zone pinned with --split stays pinned.
+	if pinnedZone == "" {
+		pinnedZone = st.Pinned
+	}
+	if st.Focused != "" {
+		for i, tz := range displayZones() {
+			if tz.Name == st.Focused {
+				focusIndex = i
+			}
+		}
+	}
+	for _, name := range st.Views {
+		if on, ok := runtimeViews[name]; ok {
+			*on = true
+		}
+	}
+	watch = st.Stopwatch
+	currentIncident = st.Incident
+	logger.Debug("runtime state restored", "primary", st.Primary, "pinned", pinnedZone, "views", st.Views, "saved", st.Saved)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// Formats of `kairos snapshot` and the s key, the values of the snapshot_format setting.
+var snapshotFormats = []string{"text", "ansi", "html"}
+
+// snapshotExtensions are the file extensions each snapshot format is saved with, and recognized by.
+var snapshotExtensions = map[string][]string{
+	"text": {".txt"},
+	"ansi": {".ans", ".ansi"},
+	"html": {".html", ".htm"},
+}
+
+// Flags of the `kairos snapshot` command.
+var (
+	snapshotFormat                string
+	snapshotWidth, snapshotHeight int
+)
+
+// snapshotCommand builds the `kairos snapshot` command.
+func snapshotCommand() *command {
+	return &command{
+		Name:    "snapshot",
+		Usage:   "[FILE]",
+		Short:   "Saves one dashboard frame as text, ANSI, or HTML, e.g. for a chat or a bug report (--format)",
+		MaxArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&snapshotFormat, "format", "", "Output format: "+strings.Join(snapshotFormats, ", ")+" (default from FILE's extension, or the snapshot_format setting)")
+			fs.IntVar(&snapshotWidth, "width", 120, "Frame width in columns")
+			fs.IntVar(&snapshotHeight, "height", 40, "Frame height in rows")
+			registerDashboardFlags(fs)
+		},
+		Run: func(args []string) error {
+			if err := applyDashboardFlags(); err != nil {
+				return err
+			}
+			path := ""
+			if len(args) > 0 {
+				path = expandHome(args[0])
+			}
+			return saveSnapshotCommand(path, strings.ToLower(snapshotFormat), snapshotWidth, snapshotHeight)
+		},
+	}
+}
+
+/**
+ * This function handles `kairos snapshot`. It renders one frame like `kairos render` and
+ * writes it to a file, or to stdout without one. The format is the --format flag, or else
+ * the one the file's extension names (.txt, .ans, .html), or else the snapshot_format setting.
+ *
+ * @param path - The file to write, or "" for stdout.
+ * @param format - One of snapshotFormats, or "" to pick one as above.
+ * @param width - The frame width in columns.
+ * @param height - The frame height in rows.
+ * @returns An error if nothing is configured, the format or size is invalid, or the file cannot be written.
+ */
+func saveSnapshotCommand(path, format string, width, height int) error {
+	if len(timezones) == 0 {
+		return fmt.Errorf("No timezones configured. Use: kairos add \"Name\" \"Location\"")
+	}
+	if width < 20 || height < 10 {
+		return usageErrorf(findCommand(commands, "snapshot"), "the frame must be at least 20x10 (got %dx%d)", width, height)
+	}
+	if format == "" {
+		format = snapshotFormatFor(path)
+	}
+	if !containsString(snapshotFormats, format) {
+		return usageErrorf(findCommand(commands, "snapshot"), "unknown format '%s' (expected one of: %s)", format, strings.Join(snapshotFormats, ", "))
+	}
+	loadLocations()
+	loadInteractions()
+	travelMode = settings.Travel != nil
+	defer subscribeComponents()()
+	if err := sampleStats(); err != nil {
+		logger.Warn("reading CPU usage failed", "err", err)
+	}
+	now := appClock.Now(time.UTC)
+	out := formatSnapshot(render.Compose(renderDashboard(now, width, height), width, height), format, now)
+	if path == "" {
+		fmt.Print(out)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Saved a %dx%d %s snapshot to %s\n", width, height, format, path)
+	return nil
+}
+
+// snapshotFormatFor returns the format a file's extension names, or the snapshot_format setting.
+func snapshotFormatFor(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, format := range snapshotFormats {
+		if containsString(snapshotExtensions[format], ext) {
+			return format
+		}
+	}
+	return defaultString(settings.SnapshotFormat, "text")
+}
+
+/**
+ * This function saves what the dashboard shows to a file in the home directory when s is
+ * pressed, in the snapshot_format setting's format, and names the file in the footer.
+ *
+ * @param width - The terminal width.
+ * @param height - The terminal height.
+ */
+func saveDashboardSnapshot(width, height int) {
+	now := currentSnapshot().Now
+	format := defaultString(settings.SnapshotFormat, "text")
+	out := formatSnapshot(render.Compose(renderDashboard(now, width, height), width, height), format, now)
+	path := filepath.Join(homeDir(), "kairos-snapshot-"+now.In(localZone()).Format("20060102-150405")+snapshotExtensions[format][0])
+	if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+		showWarning("Snapshot failed: " + err.Error())
+		return
+	}
+	logger.Info("snapshot saved", "path", path, "format", format)
+	showNotification("Saved the dashboard to " + path)
+}
+
+// formatSnapshot converts a composed screen (see render.Compose) to a snapshot format.
+func formatSnapshot(screen, format string, now time.Time) string {
+	switch format {
+	case "text":
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSuffix(screen, "\n"), "\n") {
+			lines = append(lines, strings.TrimRight(render.StripANSI(line), " "))
+		}
+		return strings.Join(lines, "\n") + "\n"
+	case "html":
+		return snapshotHTML(screen, now)
+	}
+	return screen
+}
+
+// snapshotPalette is the xterm palette the HTML snapshot uses for the 16 ANSI colors.
+var snapshotPalette = [16]string{
+	"#000000", "#cd0000", "#00cd00", "#cdcd00", "#0000ee", "#cd00cd", "#00cdcd", "#e5e5e5",
+	"#7f7f7f", "#ff0000", "#00ff00", "#ffff00", "#5c5cff", "#ff00ff", "#00ffff", "#ffffff",
+}
+
+// snapshotStyle is the text style in effect while an ANSI screen is converted to HTML.
+type snapshotStyle struct {
+	// fg and bg are palette indexes, or -1 for the default colors.
+	fg, bg              int
+	bold, faint, invert bool
+}
+
+// apply updates the style with the parameters of one SGR sequence, e.g. "1;32".
+func (s *snapshotStyle) apply(params string) {
+	for _, p := range strings.Split(params, ";") {
+		n, err := strconv.Atoi(p)
+		if p == "" {
+			n, err = 0, nil
+		}
+		if err != nil {
+			continue
+		}
+		switch {
+		case n == 0:
+			*s = snapshotStyle{fg: -1, bg: -1}
+		case n == 1:
+			s.bold = true
+		case n == 2:
+			s.faint = true
+		case n == 7:
+			s.invert = true
+		case n == 22:
+			s.bold, s.faint = false, false
+		case n == 27:
+			s.invert = false
+		case n >= 30 && n <= 37:
+			s.fg = n - 30
+		case n == 39:
+			s.fg = -1
+		case n >= 40 && n <= 47:
+			s.bg = n - 40
+		case n == 49:
+			s.bg = -1
+		case n >= 90 && n <= 97:
+			s.fg = n - 90 + 8
+		case n >= 100 && n <= 107:
+			s.bg = n - 100 + 8
+		}
+	}
+}
+
+// css returns the style's CSS declarations, or "" for the default style.
+func (s snapshotStyle) css() string {
+	fg, bg := "", ""
+	if s.fg >= 0 {
+		fg = snapshotPalette[s.fg]
+	}
+	if s.bg >= 0 {
+		bg = snapshotPalette[s.bg]
+	}
+	if s.invert {
+		fg, bg = defaultString(bg, "#1e1e1e"), defaultString(fg, "#d4d4d4")
+	}
+	var decls []string
+	if fg != "" {
+		decls = append(decls, "color:"+fg)
+	}
+	if bg != "" {
+		decls = append(decls, "background:"+bg)
+	}
+	if s.bold {
+		decls = append(decls, "font-weight:bold")
+	}
+	if s.faint {
+		decls = append(decls, "opacity:.6")
+	}
+	return strings.Join(decls, ";")
+}
+
+/**
+ * This function converts a composed ANSI screen into a standalone HTML page, with the
+ * colors as inline styles on a dark terminal background, so that it opens the same in any
+ * browser and can be attached to a bug report about the rendering.
+ *
+ * @param screen - The screen, one line per row, with ANSI SGR sequences.
+ * @param now - When it was rendered, for the page title.
+ * @returns The HTML page.
+ */
+func snapshotHTML(screen string, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>kairos %s</title>\n", now.In(localZone()).Format("2006-01-02 15:04:05"))
+	b.WriteString("<style>body{margin:0;background:#1e1e1e}pre{margin:0;padding:1em;color:#d4d4d4;background:#1e1e1e;" +
+		"font-family:\"DejaVu Sans Mono\",Menlo,Consolas,monospace;font-size:14px;line-height:1.2}</style>\n</head>\n<body>\n<pre>")
+	style := snapshotStyle{fg: -1, bg: -1}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		if css := style.css(); css != "" {
+			fmt.Fprintf(&b, "<span style=\"%s\">%s</span>", css, html.EscapeString(text.String()))
+		} else {
+			b.WriteString(html.EscapeString(text.String()))
+		}
+		text.Reset()
+	}
+	for i := 0; i < len(screen); {
+		if strings.HasPrefix(screen[i:], "\x1b[") {
+			j := strings.IndexByte(screen[i:], 'm')
+			if j < 0 {
+				break
+			}
+			flush()
+			style.apply(screen[i+2 : i+j])
+			i += j + 1
+			continue
+		}
+		if screen[i] == '\n' {
+			flush()
+			b.WriteByte('\n')
+			i++
+			continue
+		}
+		text.WriteByte(screen[i])
+		i++
+	}
+	flush()
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.String()
+}
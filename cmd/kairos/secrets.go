@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// secretSettings are the settings holding a token, password, or client secret, by key.
+var secretSettings = []struct {
+	Key   string
+	Field func(s *Settings) *string
+}{
+	{"timesheet_token", func(s *Settings) *string { return &s.TimesheetToken }},
+	{"oncall_token", func(s *Settings) *string { return &s.OnCallToken }},
+	{"slack_token", func(s *Settings) *string { return &s.SlackToken }},
+	{"gcal_client_secret", func(s *Settings) *string { return &s.GCalClientSecret }},
+	{"gcal_refresh_token", func(s *Settings) *string { return &s.GCalRefreshToken }},
+	{"mqtt_password", func(s *Settings) *string { return &s.MQTTPassword }},
+	{"ticker_token", func(s *Settings) *string { return &s.TickerToken }},
+	{"server_admin_token", func(s *Settings) *string { return &s.ServerAdminToken }},
+	{"server_read_token", func(s *Settings) *string { return &s.ServerReadToken }},
+	{"sync_token", func(s *Settings) *string { return &s.SyncToken }},
+	{"announce_webhook", func(s *Settings) *string { return &s.AnnounceWebhook }},
+	{"carbon_token", func(s *Settings) *string { return &s.CarbonToken }},
+}
+
+// secretRef is a secret setting that the config file refers to rather than holds.
+type secretRef struct {
+	// Ref is what the config file holds, e.g. "env:SLACK_TOKEN".
+	Ref string
+	// Value is the secret the reference resolved to.
+	Value string
+	// Err is why the reference could not be resolved.
+	Err error
+}
+
+// secretRefs are the secret settings loaded from references, by key. The settings
+// themselves hold the resolved values; saveConfig writes the references back.
+var secretRefs = map[string]secretRef{}
+
+// secretCommandTimeout bounds the cmd: and keychain: lookups.
+const secretCommandTimeout = 10 * time.Second
+
+// encryptedPrefix marks a secret encrypted with the local key.
+const encryptedPrefix = "enc:"
+
+// isSecretRef reports whether a secret setting's value refers to the secret instead of being it.
+func isSecretRef(v string) bool {
+	for _, prefix := range []string{"env:", "cmd:", "keychain:", encryptedPrefix} {
+		if strings.HasPrefix(v, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * This function looks up the secret a reference points at: "env:NAME" reads an
+ * environment variable, "cmd:COMMAND" runs a command (e.g. a password manager) and uses
+ * its first line of output, "keychain:NAME" reads the OS keychain entry with service
+ * "kairos" and account NAME, and "enc:..." is decrypted with the local key.
+ *
+ * @param ref - The reference.
+ * @returns The secret, or an error if it cannot be found.
+ */
+func resolveSecret(ref string) (string, error) {
+	kind, arg, _ := strings.Cut(ref, ":")
+	switch kind {
+	case "env":
+		v, ok := os.LookupEnv(arg)
+		if !ok || v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", arg)
+		}
+		return v, nil
+	case "cmd":
+		return secretCommandOutput(shellCommand, arg)
+	case "keychain":
+		return keychainSecret(arg)
+	case "enc":
+		return decryptSecret(arg)
+	}
+	return "", fmt.Errorf("unknown secret reference '%s'", ref)
+}
+
+// secretCommandOutput runs a lookup command and returns the first line it prints.
+func secretCommandOutput(build func(ctx context.Context, command string) *exec.Cmd, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+	cmd := build(ctx, command)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("'%s' failed: %v", command, err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if line = strings.TrimSpace(line); line == "" {
+		return "", fmt.Errorf("'%s' printed nothing", command)
+	}
+	return line, nil
+}
+
+/**
+ * This function reads a secret from the OS keychain: the login keychain on macOS (added
+ * with `security add-generic-password -s kairos -a NAME -w`) and the Secret Service
+ * elsewhere (added with `secret-tool store --label kairos service kairos account NAME`).
+ *
+ * @param name - The account name of the entry.
+ * @returns The secret, or an error if there is no keychain or no such entry.
+ */
+func keychainSecret(name string) (string, error) {
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		args = []string{"security", "find-generic-password", "-s", "kairos", "-a", name, "-w"}
+	case "windows":
+		return "", fmt.Errorf("keychain: is not supported on Windows; use env: or cmd: instead")
+	default:
+		args = []string{"secret-tool", "lookup", "service", "kairos", "account", name}
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return "", fmt.Errorf("keychain: needs %s, which is not installed", args[0])
+	}
+	v, err := secretCommandOutput(func(ctx context.Context, _ string) *exec.Cmd {
+		return exec.CommandContext(ctx, args[0], args[1:]...)
+	}, strings.Join(args, " "))
+	if err != nil {
+		return "", fmt.Errorf("no keychain entry for service kairos, account %s", name)
+	}
+	return v, nil
+}
+
+/**
+ * This function resolves the secret references in the loaded settings, keeping each
+ * reference in secretRefs so that saveConfig writes it back. A reference that cannot be
+ * resolved leaves its setting empty and is reported by `kairos doctor`.
+ */
+func resolveSecrets() {
+	secretRefs = map[string]secretRef{}
+	for _, s := range secretSettings {
+		field := s.Field(&settings)
+		if !isSecretRef(*field) {
+			continue
+		}
+		v, err := resolveSecret(*field)
+		if err != nil {
+			logger.Warn("secret not resolved", "setting", s.Key, "ref", redactRef(*field), "err", err)
+		}
+		secretRefs[s.Key] = secretRef{Ref: *field, Value: v, Err: err}
+		*field = v
+	}
+}
+
+// redactRef shortens an encrypted secret for logs and messages; the other references are not secret.
+func redactRef(ref string) string {
+	if strings.HasPrefix(ref, encryptedPrefix) {
+		return "enc:…"
+	}
+	return ref
+}
+
+/**
+ * This function returns the settings the way they are written to the config file: each
+ * secret loaded from a reference goes back as that reference, and any other secret is
+ * encrypted with the local key, so no token is ever saved in plain text.
+ *
+ * @param s - The settings in use.
+ * @returns The settings to save, or an error if a secret cannot be encrypted.
+ */
+func storedSettings(s Settings) (Settings, error) {
+	for _, def := range secretSettings {
+		field := def.Field(&s)
+		if ref, ok := secretRefs[def.Key]; ok && ref.Value == *field {
+			*field = ref.Ref
+			continue
+		}
+		if *field == "" {
+			continue
+		}
+		enc, err := encryptSecret(*field)
+		if err != nil {
+			return s, fmt.Errorf("cannot encrypt %s: %v", def.Key, err)
+		}
+		*field = enc
+	}
+	return s, nil
+}
+
+/**
+ * This function sets a secret setting from `kairos config set`: a reference (env:, cmd:,
+ * keychain:) is resolved right away so a typo is caught, and a plain token is kept as
+ * it is until saveConfig encrypts it.
+ *
+ * @param key - The setting key.
+ * @param field - The setting to update.
+ * @param v - The token, a reference, or "none" to clear it.
+ * @returns An error if the reference cannot be resolved.
+ */
+func setSecret(key string, field *string, v string) error {
+	delete(secretRefs, key)
+	if v == "none" {
+		*field = ""
+		return nil
+	}
+	if !isSecretRef(v) {
+		*field = v
+		return nil
+	}
+	resolved, err := resolveSecret(v)
+	if err != nil {
+		return err
+	}
+	secretRefs[key] = secretRef{Ref: v, Value: resolved}
+	*field = resolved
+	return nil
+}
+
+// getSecret shows a secret setting: its reference if it has one (encrypted ones are masked), the masked token otherwise.
+func getSecret(key, value string) string {
+	if ref, ok := secretRefs[key]; ok && ref.Value == value && !strings.HasPrefix(ref.Ref, encryptedPrefix) {
+		return ref.Ref
+	}
+	return maskToken(value)
+}
+
+// getSecretKeyPath returns the file holding the key that encrypts the tokens in the config.
+func getSecretKeyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = filepath.Dir(getConfigPath())
+	}
+	return filepath.Join(dir, "kairos", "secret.key")
+}
+
+/**
+ * This function returns the key that encrypts the tokens in the config: derived from
+ * $KAIROS_SECRET_KEY when it is set, and otherwise read from (or, when create is set,
+ * generated into) a private key file kept apart from the config, so a copied, synced,
+ * or committed config file does not give the tokens away.
+ *
+ * @param create - Whether to generate the key file if there is none.
+ * @returns The 32-byte key, or an error if there is none or it cannot be created.
+ */
+func secretKey(create bool) ([]byte, error) {
+	if passphrase := os.Getenv("KAIROS_SECRET_KEY"); passphrase != "" {
+		sum := sha256.Sum256([]byte(passphrase))
+		return sum[:], nil
+	}
+	path := getSecretKeyPath()
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("%s is not a valid key", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("cannot read the key in %s: %v", path, err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, err
+	}
+	logger.Info("secret key created", "path", path)
+	return key, nil
+}
+
+// encryptSecret encrypts a secret with AES-GCM under the local key, as "enc:" and the base64 of the nonce and ciphertext.
+func encryptSecret(secret string) (string, error) {
+	key, err := secretKey(true)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// decryptSecret reverses encryptSecret for the part after "enc:".
+func decryptSecret(encoded string) (string, error) {
+	key, err := secretKey(false)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newSecretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted secret")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		source := "the key in " + getSecretKeyPath()
+		if os.Getenv("KAIROS_SECRET_KEY") != "" {
+			source = "$KAIROS_SECRET_KEY"
+		}
+		return "", fmt.Errorf("cannot decrypt with %s (was the config copied from another machine?)", source)
+	}
+	return string(plain), nil
+}
+
+// newSecretCipher returns the AES-GCM cipher for a key.
+func newSecretCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// checkSecrets reports the secret settings whose reference could not be resolved.
+func checkSecrets() []doctorCheck {
+	var checks []doctorCheck
+	for _, s := range secretSettings {
+		ref, ok := secretRefs[s.Key]
+		switch {
+		case !ok:
+		case ref.Err != nil:
+			checks = append(checks, doctorCheck{"secrets", s.Key, checkFail,
+				fmt.Sprintf("%s: %v", redactRef(ref.Ref), ref.Err)})
+		default:
+			checks = append(checks, doctorCheck{"secrets", s.Key, checkPass, redactRef(ref.Ref)})
+		}
+	}
+	return checks
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// serverAddr is set by --server: the host:port of a shared kairos server to attach to instead of the local daemon.
+	serverAddr string
+	// serverTokenFlag is set by --token; $KAIROS_TOKEN is used when it is empty.
+	serverTokenFlag string
+	// serverListen is set by `kairos daemon --listen` and makes the daemon a shared server.
+	serverListen string
+)
+
+// readOnlyMethods are the control methods a read-only token may call.
+var readOnlyMethods = []string{"status", "state"}
+
+// serverToken returns the token sent to a shared server: --token, or $KAIROS_TOKEN.
+func serverToken() string {
+	if serverTokenFlag != "" {
+		return serverTokenFlag
+	}
+	return os.Getenv("KAIROS_TOKEN")
+}
+
+/**
+ * This function checks a request to the shared server against the configured tokens:
+ * the admin token may call every method, the read-only token only status and state.
+ *
+ * @param req - The request.
+ * @returns A JSON-RPC error, or nil if the request is allowed.
+ */
+func authorizeServerRequest(req controlRequest) *controlError {
+	matches := func(token string) bool {
+		return token != "" && subtle.ConstantTimeCompare([]byte(req.Token), []byte(token)) == 1
+	}
+	switch {
+	case matches(settings.ServerAdminToken):
+		return nil
+	case matches(settings.ServerReadToken):
+		if containsString(readOnlyMethods, req.Method) {
+			return nil
+		}
+		return &controlError{Code: -32003, Message: fmt.Sprintf("read-only access: %s needs the admin token", req.Method)}
+	}
+	return &controlError{Code: -32001, Message: "invalid or missing token (use --token or KAIROS_TOKEN)"}
+}
+
+// setPEMFile sets a TLS file setting to the absolute path of an existing file, or clears it with "none".
+func setPEMFile(field *string, v string) error {
+	if v == "none" || v == "" {
+		*field = ""
+		return nil
+	}
+	path, err := filepath.Abs(expandHome(v))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot read %s: %v", v, err)
+	}
+	*field = path
+	return nil
+}
+
+// isLoopbackAddr reports whether a host:port address is on this machine only, e.g. 127.0.0.1:7420 or localhost:7420.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+/**
+ * This function listens for the shared server or the calendar feeds. The tokens and the
+ * shared state must not cross the network in the clear, so any address but loopback is
+ * served with TLS from server_tls_cert and server_tls_key; a loopback address is plain,
+ * for clients that come through an SSH tunnel.
+ *
+ * @param addr - The address to listen on, e.g. ":7420".
+ * @param what - What is served, for the error message, e.g. "--listen".
+ * @returns The listener, or an error if it cannot listen or TLS is needed but not set up.
+ */
+func listenShared(addr, what string) (net.Listener, error) {
+	if isLoopbackAddr(addr) {
+		return net.Listen("tcp", addr)
+	}
+	if settings.ServerTLSCert == "" || settings.ServerTLSKey == "" {
+		return nil, fmt.Errorf("%s %s would send the tokens unencrypted: set server_tls_cert and server_tls_key, or listen on 127.0.0.1 and reach it through an SSH tunnel", what, addr)
+	}
+	cert, err := tls.LoadX509KeyPair(settings.ServerTLSCert, settings.ServerTLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the TLS certificate for %s: %v", what, err)
+	}
+	return tls.Listen("tcp", addr, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+}
+
+/**
+ * This function dials the shared server given with --server: with TLS, trusting the
+ * system's certificates and those in server_tls_ca, unless the address is loopback (an
+ * SSH tunnel to a server that listens on loopback).
+ *
+ * @param addr - The server's host:port.
+ * @returns The connection, or an error if the server cannot be reached or its certificate is not trusted.
+ */
+func dialServer(addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 2 * time.Second}
+	if isLoopbackAddr(addr) {
+		return dialer.Dial("tcp", addr)
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if settings.ServerTLSCA != "" {
+		pem, err := os.ReadFile(settings.ServerTLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read server_tls_ca: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("server_tls_ca %s holds no PEM certificates", settings.ServerTLSCA)
+		}
+		config.RootCAs = pool
+	}
+	return tls.DialWithDialer(dialer, "tcp", addr, config)
+}
+
+/**
+ * This function makes the daemon a shared server: dashboards on other machines attach
+ * to it over TLS with --server and a token, and see the same zones and notifications.
+ * It refuses to listen unless at least one token is configured, and off loopback unless
+ * TLS is (see listenShared).
+ *
+ * @param addr - The address to listen on, e.g. ":7420".
+ * @param run - Runs a request's handler under the daemon's lock.
+ * @returns A function that stops listening, or an error.
+ */
+func startServerListener(addr string, run func(func())) (func(), error) {
+	if settings.ServerAdminToken == "" && settings.ServerReadToken == "" {
+		return nil, fmt.Errorf("set server_admin_token or server_read_token before listening on %s", addr)
+	}
+	listener, err := listenShared(addr, "--listen")
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("server listening", "addr", listener.Addr().String(), "tls", !isLoopbackAddr(addr))
+	go func() {
+		defer recoverWorker("server listener")
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			logger.Debug("server client connected", "remote", conn.RemoteAddr().String())
+			go serveControl(run, conn, authorizeServerRequest)
+		}
+	}()
+	return func() { listener.Close() }, nil
+}
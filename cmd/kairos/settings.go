@@ -0,0 +1,768 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/iamstoick/kairos/config"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// settingDef describes one global setting for `kairos config`.
+type settingDef struct {
+	Key  string
+	Help string
+	Get  func() string
+	Set  func(value string) error
+}
+
+var (
+	settings Settings
+
+	// settingDefs lists every global setting, in the order shown by `kairos config list`.
+	settingDefs = []settingDef{
+		{
+			Key:  "copy_format",
+			Help: "Timestamp format copied with the y key (iso, epoch, slack)",
+			Get:  func() string { return defaultString(settings.CopyFormat, "iso") },
+			Set: func(v string) error {
+				return setChoice(&settings.CopyFormat, v, "iso", copyFormats)
+			},
+		},
+		{
+			Key:  "track_interactions",
+			Help: "Show when each zone's team was last in touch (its number key or kairos touch) in its view (on, off)",
+			Get:  func() string { return formatSwitch(settings.TrackInteractions) },
+			Set:  func(v string) error { return setSwitch(&settings.TrackInteractions, v) },
+		},
+		{
+			Key:  "hour_format",
+			Help: "Hour format of the clocks (12h, 24h); kairos set \"Zone\" hour_format overrides it per zone",
+			Get:  func() string { return defaultString(settings.HourFormat, "12h") },
+			Set:  func(v string) error { return setChoice(&settings.HourFormat, v, "12h", hourFormats) },
+		},
+		{
+			Key:  "snapshot_format",
+			Help: "Format the s key saves the dashboard in (text, ansi, html)",
+			Get:  func() string { return defaultString(settings.SnapshotFormat, "text") },
+			Set:  func(v string) error { return setChoice(&settings.SnapshotFormat, v, "text", snapshotFormats) },
+		},
+		{
+			Key:  "epoch_strip",
+			Help: "Show Unix epoch and UTC ISO 8601 in the primary view (on, off)",
+			Get:  func() string { return formatSwitch(settings.EpochStrip) },
+			Set:  func(v string) error { return setSwitch(&settings.EpochStrip, v) },
+		},
+		{
+			Key:  "precision_strip",
+			Help: "Show TAI and GPS time and announced leap seconds in the primary view (on, off)",
+			Get:  func() string { return formatSwitch(settings.PrecisionStrip) },
+			Set:  func(v string) error { return setSwitch(&settings.PrecisionStrip, v) },
+		},
+		{
+			Key:  "theme",
+			Help: "Dashboard colors (" + strings.Join(themeNames(), ", ") + ")",
+			Get:  func() string { return defaultString(settings.Theme, "default") },
+			Set:  func(v string) error { return setChoice(&settings.Theme, v, "default", themeNames()) },
+		},
+		{
+			Key:  "background",
+			Help: "Terminal background the default theme picks colors for (auto, dark, light); auto asks the terminal",
+			Get:  func() string { return defaultString(settings.Background, "auto") },
+			Set:  func(v string) error { return setChoice(&settings.Background, v, "auto", backgroundChoices) },
+		},
+		{
+			Key:  "night_dim",
+			Help: "Hours in the primary zone that use the night theme, e.g. 22:00-07:00 (or off)",
+			Get:  func() string { return defaultString(settings.NightDim, "off") },
+			Set:  setNightDim,
+		},
+		{
+			Key:  "chime",
+			Help: "Chime on the primary zone's clock every 15m, 30m, 60m, ... (or off)",
+			Get:  func() string { return defaultString(settings.Chime, "off") },
+			Set:  setChime,
+		},
+		{
+			Key:  "chime_command",
+			Help: "Shell command played as the chime instead of the terminal bell (or none)",
+			Get:  func() string { return defaultString(settings.ChimeCommand, "none") },
+			Set: func(v string) error {
+				if v == "none" || v == "bell" {
+					v = ""
+				}
+				settings.ChimeCommand = v
+				return nil
+			},
+		},
+		{
+			Key:  "speak_command",
+			Help: "Shell command that speaks $KAIROS_TEXT for the v key instead of say, spd-say, or espeak (or none)",
+			Get:  func() string { return defaultString(settings.SpeakCommand, "none") },
+			Set: func(v string) error {
+				if v == "none" {
+					v = ""
+				}
+				settings.SpeakCommand = v
+				return nil
+			},
+		},
+		{
+			Key:  "quiet_hours",
+			Help: "Hours in the primary zone without chimes, e.g. 22:00-08:00 (or off)",
+			Get:  func() string { return defaultString(settings.QuietHours, "off") },
+			Set:  setQuietHours,
+		},
+		{
+			Key:  "break_every",
+			Help: "Remind you to take a break after this long on screen, e.g. 20m or 50m (or off)",
+			Get:  func() string { return defaultString(settings.BreakEvery, "off") },
+			Set:  setBreakEvery,
+		},
+		{
+			Key:  "break_desktop",
+			Help: "Also send break reminders as desktop notifications (on, off)",
+			Get:  func() string { return formatSwitch(settings.BreakDesktop) },
+			Set:  func(v string) error { return setSwitch(&settings.BreakDesktop, v) },
+		},
+		{
+			Key:  "timesheet",
+			Help: "Push completed work sessions to " + strings.Join(timesheetServices, " or ") + " (or none)",
+			Get:  func() string { return defaultString(settings.Timesheet, "none") },
+			Set:  func(v string) error { return setChoice(&settings.Timesheet, v, "none", timesheetServices) },
+		},
+		{
+			Key:  "timesheet_token",
+			Help: "API token for the timesheet service (the config file is then kept private)",
+			Get:  func() string { return getSecret("timesheet_token", settings.TimesheetToken) },
+			Set:  func(v string) error { return setSecret("timesheet_token", &settings.TimesheetToken, v) },
+		},
+		{
+			Key:  "timesheet_workspace",
+			Help: "Workspace id that time entries are created in",
+			Get:  func() string { return defaultString(settings.TimesheetWorkspace, "none") },
+			Set: func(v string) error {
+				if v == "none" {
+					v = ""
+				}
+				settings.TimesheetWorkspace = v
+				return nil
+			},
+		},
+		{
+			Key:  "oncall",
+			Help: "Show who is on call from " + strings.Join(onCallServices, " or ") + " schedules (or none; set each zone's oncall option)",
+			Get:  func() string { return defaultString(settings.OnCall, "none") },
+			Set:  func(v string) error { return setChoice(&settings.OnCall, v, "none", onCallServices) },
+		},
+		{
+			Key:  "oncall_token",
+			Help: "API token for the on-call service (the config file is then kept private)",
+			Get:  func() string { return getSecret("oncall_token", settings.OnCallToken) },
+			Set:  func(v string) error { return setSecret("oncall_token", &settings.OnCallToken, v) },
+		},
+		{
+			Key:  "slack_token",
+			Help: "Slack user token to show teammates per zone and set your status (the config file is then kept private)",
+			Get:  func() string { return getSecret("slack_token", settings.SlackToken) },
+			Set:  func(v string) error { return setSecret("slack_token", &settings.SlackToken, v) },
+		},
+		{
+			Key:  "gcal_client_id",
+			Help: "Google OAuth client id (Desktop app) for kairos gcal login",
+			Get:  func() string { return defaultString(settings.GCalClientID, "none") },
+			Set: func(v string) error {
+				if v == "none" {
+					v = ""
+				}
+				settings.GCalClientID = v
+				return nil
+			},
+		},
+		{
+			Key:  "gcal_client_secret",
+			Help: "Google OAuth client secret for kairos gcal login (the config file is then kept private)",
+			Get:  func() string { return getSecret("gcal_client_secret", settings.GCalClientSecret) },
+			Set:  func(v string) error { return setSecret("gcal_client_secret", &settings.GCalClientSecret, v) },
+		},
+		{
+			Key:  "gcal_calendars",
+			Help: "Teammates' calendars whose busy times kairos table also avoids, e.g. ana@example.com (or none)",
+			Get: func() string {
+				if len(settings.GCalCalendars) == 0 {
+					return "none"
+				}
+				return strings.Join(settings.GCalCalendars, ",")
+			},
+			Set: func(v string) error {
+				settings.GCalCalendars = nil
+				if v == "none" || v == "" {
+					return nil
+				}
+				for _, c := range strings.Split(v, ",") {
+					if c = strings.TrimSpace(c); c != "" {
+						settings.GCalCalendars = append(settings.GCalCalendars, c)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Key:  "ticker",
+			Help: "Market data source of the {ticker} footer widget: finnhub (stocks), coingecko (crypto), or none",
+			Get:  func() string { return defaultString(settings.TickerSource, "none") },
+			Set:  func(v string) error { return setChoice(&settings.TickerSource, v, "none", tickerSources) },
+		},
+		{
+			Key:  "ticker_symbols",
+			Help: "Symbols for the {ticker} footer widget, e.g. \"AAPL,MSFT\" or coin ids \"bitcoin,ethereum\" (or none)",
+			Get:  func() string { return defaultString(strings.Join(settings.TickerSymbols, ","), "none") },
+			Set: func(v string) error {
+				settings.TickerSymbols = nil
+				if v == "none" || v == "" {
+					return nil
+				}
+				for _, symbol := range strings.Split(v, ",") {
+					if symbol = strings.TrimSpace(symbol); symbol != "" {
+						settings.TickerSymbols = append(settings.TickerSymbols, symbol)
+					}
+				}
+				return nil
+			},
+		},
+		{
+			Key:  "ticker_token",
+			Help: "API key for the ticker source (the config file is then kept private)",
+			Get:  func() string { return getSecret("ticker_token", settings.TickerToken) },
+			Set:  func(v string) error { return setSecret("ticker_token", &settings.TickerToken, v) },
+		},
+		{
+			Key:  "mqtt_broker",
+			Help: "Publish zone times, business-hours changes, and alarms to this MQTT broker, e.g. localhost:1883 (or none)",
+			Get:  func() string { return defaultString(settings.MQTTBroker, "none") },
+			Set: func(v string) error {
+				if v == "none" {
+					settings.MQTTBroker = ""
+					return nil
+				}
+				if _, _, err := net.SplitHostPort(v); err != nil {
+					return fmt.Errorf("invalid broker '%s' (expected host:port, e.g. localhost:1883)", v)
+				}
+				settings.MQTTBroker = v
+				return nil
+			},
+		},
+		{
+			Key:  "mqtt_topic",
+			Help: "Prefix of the published MQTT topics",
+			Get:  func() string { return defaultString(settings.MQTTTopic, "kairos") },
+			Set: func(v string) error {
+				v = strings.Trim(v, "/")
+				if strings.ContainsAny(v, "+#") {
+					return fmt.Errorf("invalid topic prefix '%s' (wildcards are not allowed)", v)
+				}
+				settings.MQTTTopic = v
+				if v == "kairos" || v == "" {
+					settings.MQTTTopic = ""
+				}
+				return nil
+			},
+		},
+		{
+			Key:  "mqtt_username",
+			Help: "Username for the MQTT broker (or none)",
+			Get:  func() string { return defaultString(settings.MQTTUsername, "none") },
+			Set: func(v string) error {
+				if v == "none" {
+					v = ""
+				}
+				settings.MQTTUsername = v
+				return nil
+			},
+		},
+		{
+			Key:  "mqtt_password",
+			Help: "Password for the MQTT broker (the config file is then kept private)",
+			Get:  func() string { return getSecret("mqtt_password", settings.MQTTPassword) },
+			Set:  func(v string) error { return setSecret("mqtt_password", &settings.MQTTPassword, v) },
+		},
+		{
+			Key:  "prometheus_url",
+			Help: "Prometheus server for zone metrics set to \"prom:QUERY\", e.g. http://localhost:9090 (or none)",
+			Get:  func() string { return defaultString(settings.PrometheusURL, "none") },
+			Set: func(v string) error {
+				if v == "none" {
+					settings.PrometheusURL = ""
+					return nil
+				}
+				if u, err := url.Parse(v); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("invalid Prometheus URL '%s' (expected e.g. http://localhost:9090)", v)
+				}
+				settings.PrometheusURL = v
+				return nil
+			},
+		},
+		{
+			Key:  "metric_interval",
+			Help: "How often zone metrics are refreshed, e.g. 30s or 5m (default 1m)",
+			Get:  func() string { return defaultString(settings.MetricInterval, "1m") },
+			Set:  setMetricInterval,
+		},
+		{
+			Key:  "health_interval",
+			Help: "How often zone health check URLs are requested, e.g. 1m or 10m (default 5m)",
+			Get:  func() string { return defaultString(settings.HealthInterval, "5m") },
+			Set:  setHealthInterval,
+		},
+		{
+			Key:  "carbon_token",
+			Help: "Electricity Maps API token for zones' grid carbon intensity; the GB grid needs none (the config file is then kept private)",
+			Get:  func() string { return getSecret("carbon_token", settings.CarbonToken) },
+			Set:  func(v string) error { return setSecret("carbon_token", &settings.CarbonToken, v) },
+		},
+		{
+			Key:  "green_threshold",
+			Help: "Grid carbon intensity in gCO2eq/kWh at or below which an hour counts as green (default 200)",
+			Get:  func() string { return defaultString(settings.GreenThreshold, strconv.Itoa(defaultGreenThreshold)) },
+			Set:  setGreenThreshold,
+		},
+		{
+			Key:  "server_admin_token",
+			Help: "Token that lets remote dashboards change the shared server's state (the config file is then kept private)",
+			Get:  func() string { return getSecret("server_admin_token", settings.ServerAdminToken) },
+			Set:  func(v string) error { return setSecret("server_admin_token", &settings.ServerAdminToken, v) },
+		},
+		{
+			Key:  "server_read_token",
+			Help: "Token that lets remote dashboards follow the shared server read-only",
+			Get:  func() string { return getSecret("server_read_token", settings.ServerReadToken) },
+			Set:  func(v string) error { return setSecret("server_read_token", &settings.ServerReadToken, v) },
+		},
+		{
+			Key:  "server_tls_cert",
+			Help: "PEM certificate file the shared server and the calendar feeds serve TLS with, needed on any address but loopback (or none)",
+			Get:  func() string { return defaultString(settings.ServerTLSCert, "none") },
+			Set:  func(v string) error { return setPEMFile(&settings.ServerTLSCert, v) },
+		},
+		{
+			Key:  "server_tls_key",
+			Help: "PEM private key file of server_tls_cert (or none)",
+			Get:  func() string { return defaultString(settings.ServerTLSKey, "none") },
+			Set:  func(v string) error { return setPEMFile(&settings.ServerTLSKey, v) },
+		},
+		{
+			Key:  "server_tls_ca",
+			Help: "PEM file of certificates to trust for --server besides the system's, e.g. the server's self-signed certificate (or none)",
+			Get:  func() string { return defaultString(settings.ServerTLSCA, "none") },
+			Set:  func(v string) error { return setPEMFile(&settings.ServerTLSCA, v) },
+		},
+		{
+			Key:  "usage_stats",
+			Help: "Count the commands, dashboard keys, and features you use in a local file for kairos stats; nothing is sent anywhere (on, off)",
+			Get:  func() string { return formatSwitch(settings.UsageStats) },
+			Set:  func(v string) error { return setSwitch(&settings.UsageStats, v) },
+		},
+		{
+			Key:  "sync_remote",
+			Help: "Where kairos sync shares the zones, events, and settings: gist:ID, git:URL, or an https:// URL (or none); commands and tokens stay local",
+			Get:  func() string { return defaultString(settings.SyncRemote, "none") },
+			Set:  setSyncRemote,
+		},
+		{
+			Key:  "sync_token",
+			Help: "Token for the sync remote: a GitHub token with the gist scope, or a bearer token for HTTPS (the config file is then kept private)",
+			Get:  func() string { return getSecret("sync_token", settings.SyncToken) },
+			Set:  func(v string) error { return setSecret("sync_token", &settings.SyncToken, v) },
+		},
+		{
+			Key:  "sync_strategy",
+			Help: "Which side kairos sync keeps when both changed the same zone, event, or the settings (remote-wins, local-wins)",
+			Get:  func() string { return defaultString(settings.SyncStrategy, "remote-wins") },
+			Set:  func(v string) error { return setChoice(&settings.SyncStrategy, v, "remote-wins", syncStrategies) },
+		},
+		hookSetting("swap", "Shell command run when a zone is swapped to the top, with KAIROS_ZONE and KAIROS_PREVIOUS (or none)"),
+		hookSetting("hour", "Shell command run when the hour changes in the primary zone, with KAIROS_ZONE and KAIROS_TIME (or none)"),
+		hookSetting("alarm", "Shell command run when an event alarm fires, with KAIROS_TITLE and KAIROS_START (or none)"),
+		hookSetting("profile", "Shell command run when the dashboard switches profile, with KAIROS_PROFILE (or none)"),
+		{
+			Key:  "announce_webhook",
+			Help: "Slack or Teams incoming webhook URL that kairos announce posts to (the config file is then kept private)",
+			Get:  func() string { return getSecret("announce_webhook", settings.AnnounceWebhook) },
+			Set:  func(v string) error { return setSecret("announce_webhook", &settings.AnnounceWebhook, v) },
+		},
+		{
+			Key:  "footer",
+			Help: "Footer template; sections between | with only empty placeholders are hidden (see README)",
+			Get:  footerTemplate,
+			Set:  setFooter,
+		},
+		{
+			Key:  "obs_format",
+			Help: "Line written by --obs for OBS, e.g. \"{title} {countdown}\" (see README)",
+			Get:  obsFormat,
+			Set:  setOBSFormat,
+		},
+		{
+			Key:  "footer_hidden",
+			Help: "Hide the footer and give its rows to the clocks (on, off; the f key)",
+			Get:  func() string { return formatSwitch(settings.FooterHidden) },
+			Set:  func(v string) error { return setSwitch(&settings.FooterHidden, v) },
+		},
+		{
+			Key:  "footer_hide",
+			Help: "Footer widgets to leave out, e.g. cpu,mem (or none; Shift+letter keys)",
+			Get: func() string {
+				if len(settings.FooterHide) == 0 {
+					return "none"
+				}
+				return strings.Join(settings.FooterHide, ",")
+			},
+			Set: setFooterHide,
+		},
+		{
+			Key:  "fiscal_start",
+			Help: "First month of the fiscal year for the {fiscal} footer widget, e.g. october (default january)",
+			Get:  func() string { return strings.ToLower(fiscalStartMonth().String()) },
+			Set:  setFiscalStart,
+		},
+		{
+			Key:  "week_start",
+			Help: "First day of the week (monday, sunday, saturday) for the week bars, week numbers, and the info panel's calendar",
+			Get:  func() string { return defaultString(settings.WeekStart, "monday") },
+			Set:  func(v string) error { return setChoice(&settings.WeekStart, v, "monday", weekStarts) },
+		},
+		{
+			Key:  "week_numbering",
+			Help: "How weeks are numbered (iso, us); iso's week 1 has four days of the new year, us's has January 1",
+			Get:  func() string { return defaultString(settings.WeekNumbering, "iso") },
+			Set:  func(v string) error { return setChoice(&settings.WeekNumbering, v, "iso", weekNumberings) },
+		},
+		{
+			Key:  "notification_duration",
+			Help: "How long footer notifications are shown, e.g. 5s (default 3s); later ones wait their turn",
+			Get:  func() string { return defaultString(settings.NotificationDuration, "3s") },
+			Set:  setNotificationDuration,
+		},
+		{
+			Key:  "long_notifications",
+			Help: "How a notification too long for the footer is shown (wrap: on a second footer line while it lasts, scroll: scrolling in place)",
+			Get:  func() string { return defaultString(settings.LongNotifications, "wrap") },
+			Set:  func(v string) error { return setChoice(&settings.LongNotifications, v, "wrap", longNotificationModes) },
+		},
+		{
+			Key:  "alert_bell",
+			Help: "Ring the terminal bell when an alert, such as an event's alarm, is shown (on, off)",
+			Get:  func() string { return formatSwitch(settings.AlertBell) },
+			Set:  func(v string) error { return setSwitch(&settings.AlertBell, v) },
+		},
+		{
+			Key:  "notify_desktop",
+			Help: "Lowest notification level also sent as a desktop notification (off, info, warn, alert); the daemon sends every level unless set",
+			Get:  notifyDesktopRoute,
+			Set:  func(v string) error { return setNotifyRoute(&settings.NotifyDesktop, v) },
+		},
+		{
+			Key:  "notify_webhook",
+			Help: "Lowest notification level also posted to the announce_webhook channel (off, info, warn, alert)",
+			Get:  func() string { return defaultString(settings.NotifyWebhook, "off") },
+			Set:  func(v string) error { return setNotifyRoute(&settings.NotifyWebhook, v) },
+		},
+		{
+			Key:  "notify_terminal",
+			Help: "Lowest notification level also sent through the terminal, which shows it even over SSH (off, info, warn, alert)",
+			Get:  func() string { return defaultString(settings.NotifyTerminal, "off") },
+			Set:  func(v string) error { return setNotifyRoute(&settings.NotifyTerminal, v) },
+		},
+		{
+			Key:  "terminal_notify",
+			Help: "Escape sequences of terminal notifications (auto, osc9, iterm2, kitty, osc777)",
+			Get:  func() string { return defaultString(settings.TerminalNotify, "auto") },
+			Set:  func(v string) error { return setChoice(&settings.TerminalNotify, v, "auto", terminalNotifyStyles) },
+		},
+		{
+			Key:  "dim_unfocused",
+			Help: "Dim the dashboard while its terminal is not focused, in terminals that report focus (on, off)",
+			Get:  func() string { return formatSwitch(!settings.NoDimUnfocused) },
+			Set: func(v string) error {
+				on, err := parseSwitch(v)
+				if err != nil {
+					return err
+				}
+				settings.NoDimUnfocused = !on
+				return nil
+			},
+		},
+		{
+			Key:  "quiet_unfocused",
+			Help: "Hold back the bell and terminal and desktop notifications while the terminal is not focused; the footer still shows them (on, off)",
+			Get:  func() string { return formatSwitch(settings.QuietUnfocused) },
+			Set:  func(v string) error { return setSwitch(&settings.QuietUnfocused, v) },
+		},
+		{
+			Key:  "layout",
+			Help: "Grid of secondary zones as COLSxROWS, e.g. 2x4 (or auto to fit the terminal)",
+			Get:  func() string { return defaultString(settings.Layout, "auto") },
+			Set:  setLayout,
+		},
+		{
+			Key:  "auto_sort",
+			Help: "Keep secondary zones sorted when adding (offset: east to west, opens: soonest to open; or off)",
+			Get:  func() string { return defaultString(settings.AutoSort, "off") },
+			Set:  func(v string) error { return setChoice(&settings.AutoSort, v, "off", zones.SortOrders) },
+		},
+		{
+			Key:  "blink",
+			Help: "Blink the clock colons every second (on, off)",
+			Get:  func() string { return formatSwitch(!settings.NoBlink) },
+			Set: func(v string) error {
+				on, err := parseSwitch(v)
+				if err != nil {
+					return err
+				}
+				settings.NoBlink = !on
+				return nil
+			},
+		},
+		{
+			Key:  "read_only",
+			Help: "Refuse every change to the config, from commands and the dashboard, for shared machines (on, off)",
+			Get:  func() string { return formatSwitch(settings.ReadOnly) },
+			Set:  func(v string) error { return setSwitch(&settings.ReadOnly, v) },
+		},
+		{
+			Key:  "restore_state",
+			Help: "Reopen the dashboard as it was left: the swapped-in primary zone, the focused zone, the views toggled on, and the stopwatch (on, off)",
+			Get:  func() string { return formatSwitch(!settings.NoRestoreState) },
+			Set: func(v string) error {
+				on, err := parseSwitch(v)
+				if err != nil {
+					return err
+				}
+				settings.NoRestoreState = !on
+				return nil
+			},
+		},
+		{
+			Key:  "exit_summary",
+			Help: "Print a recap when the dashboard quits: how long it ran, the alarms fired, the work sessions stopped, and each zone's next event (on, off)",
+			Get:  func() string { return formatSwitch(settings.ExitSummary) },
+			Set:  func(v string) error { return setSwitch(&settings.ExitSummary, v) },
+		},
+		{
+			Key:  "flip_clock",
+			Help: "Flip the primary clock's digits over like a flip clock on minute changes (on, off)",
+			Get:  func() string { return formatSwitch(settings.FlipClock) },
+			Set:  func(v string) error { return setSwitch(&settings.FlipClock, v) },
+		},
+		{
+			Key:  "reduced_motion",
+			Help: "Turn off all animations: blinking, the flip clock, scrolling notes and notifications, and celebrations (on, off)",
+			Get:  func() string { return formatSwitch(settings.ReducedMotion) },
+			Set:  func(v string) error { return setSwitch(&settings.ReducedMotion, v) },
+		},
+		{
+			Key:  "air_quality",
+			Help: "Show each zone's air quality index and UV index, from open-meteo.com (on, off)",
+			Get:  func() string { return formatSwitch(settings.AirQuality) },
+			Set:  func(v string) error { return setSwitch(&settings.AirQuality, v) },
+		},
+		{
+			Key:  "digit_color",
+			Help: "Color the clock digits by the zone's part of the day (off, phase, gradient); phase uses the progress bar's colors",
+			Get:  func() string { return defaultString(settings.DigitColor, "off") },
+			Set:  func(v string) error { return setChoice(&settings.DigitColor, v, "off", digitColorModes) },
+		},
+		{
+			Key:  "clock_font",
+			Help: "Digits of the zone clocks (auto, braille, text); auto uses braille dots in views too small for block digits",
+			Get:  func() string { return defaultString(settings.ClockFont, "auto") },
+			Set:  func(v string) error { return setChoice(&settings.ClockFont, v, "auto", clockFonts) },
+		},
+		{
+			Key:  "emoji",
+			Help: "Draw emoji (auto, on, off); off shows narrow symbols instead, and auto measures whether the terminal draws them two columns wide",
+			Get:  func() string { return defaultString(settings.Emoji, "auto") },
+			Set:  func(v string) error { return setChoice(&settings.Emoji, v, "auto", emojiChoices) },
+		},
+		{
+			Key:  "ambiguous_width",
+			Help: "How wide the terminal draws ambiguous-width symbols such as ● and ─ (auto, narrow, wide); wide draws ASCII stand-ins, and auto follows RUNEWIDTH_EASTASIAN or measures the terminal",
+			Get:  func() string { return defaultString(settings.AmbiguousWidth, "auto") },
+			Set:  func(v string) error { return setChoice(&settings.AmbiguousWidth, v, "auto", ambiguousWidthChoices) },
+		},
+		{
+			Key:  "military",
+			Help: "24-hour clocks with military zone letters and Zulu pinned on top (on, off)",
+			Get:  func() string { return formatSwitch(settings.Military) },
+			Set:  func(v string) error { return setSwitch(&settings.Military, v) },
+		},
+	}
+)
+
+// configCommand builds the `kairos config` command group.
+func configCommand() *command {
+	return &command{
+		Name:  "config",
+		Short: "Shows or changes global settings",
+		Subcommands: []*command{
+			{Name: "list", Short: "Lists every setting with its current value", Run: func(args []string) error {
+				fmt.Println("\n\x1b[36m\x1b[1mSETTINGS\x1b[0m")
+				for _, d := range settingDefs {
+					fmt.Printf("  %-20s %-12s \x1b[90m# %s\x1b[0m\n", d.Key, d.Get(), d.Help)
+				}
+				fmt.Println()
+				return nil
+			}},
+			{Name: "get", Usage: "key", MinArgs: 1, MaxArgs: 1, Short: "Prints the value of a setting", Run: func(args []string) error {
+				d, ok := findSetting(args[0])
+				if !ok {
+					return fmt.Errorf("unknown setting '%s'", args[0])
+				}
+				fmt.Println(d.Get())
+				return nil
+			}},
+			{Name: "set", Usage: "key value", MinArgs: 2, MaxArgs: 2, Short: "Changes a setting", Run: func(args []string) error {
+				d, ok := findSetting(args[0])
+				if !ok {
+					return fmt.Errorf("unknown setting '%s'", args[0])
+				}
+				// read_only is the one setting a read-only config still takes, so that it can be unlocked.
+				save := saveConfig
+				if d.Key == "read_only" && !readOnlyFlag {
+					save = writeConfig
+				} else if err := checkWritable(); err != nil {
+					return err
+				}
+				if err := d.Set(args[1]); err != nil {
+					return err
+				}
+				if err := save(); err != nil {
+					return err
+				}
+				fmt.Printf("Set %s to %s\n", d.Key, d.Get())
+				return nil
+			}},
+			{Name: "check", Short: "Lints the config and suggests fixes (--json for machine-readable output)", Run: func(args []string) error {
+				return runConfigCheck()
+			}},
+		},
+	}
+}
+
+/**
+ * This function saves a settings change made in the dashboard. Only the settings are
+ * written: the config file is read back and the change is applied to it, so that
+ * dashboard-only state such as swapped or previewed zones is never saved.
+ *
+ * @param change - Applies the change to a Settings value.
+ * @returns An error if the config file cannot be read or written.
+ */
+func persistSettings(change func(s *Settings)) error {
+	if err := checkWritable(); err != nil {
+		return err
+	}
+	change(&settings)
+	if configErr != nil {
+		return fmt.Errorf("not saved because %s could not be loaded", getConfigPath())
+	}
+	unlock, err := lockConfig()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cfg := Config{Timezones: timezones, Events: events}
+	data, err := os.ReadFile(getConfigPath())
+	// If the file is as it was loaded, the zones in memory still match it once the change is written.
+	inSync := (err == nil && stampOf(data) == loadedConfigStamp) || (os.IsNotExist(err) && loadedConfigStamp == "")
+	switch {
+	case err == nil:
+		migrated, _, err := config.Migrate(data)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(migrated, &cfg); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	change(&cfg.Settings)
+	cfg.Version = config.CurrentVersion
+	out, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(getConfigPath(), out, configFileMode()); err != nil {
+		return err
+	}
+	if inSync {
+		loadedConfigStamp = stampOf(out)
+	}
+	return nil
+}
+
+// findSetting looks up a setting definition by key.
+func findSetting(key string) (settingDef, bool) {
+	for _, d := range settingDefs {
+		if d.Key == strings.ToLower(key) {
+			return d, true
+		}
+	}
+	return settingDef{}, false
+}
+
+/**
+ * This function validates and stores a value that must be one of a fixed set of choices.
+ * The default choice (or "none") is stored as an empty string so the config stays minimal.
+ *
+ * @param field - The setting field to update.
+ * @param value - The requested value.
+ * @param def - The default choice.
+ * @param choices - All valid choices.
+ * @returns An error if the value is not one of the choices.
+ */
+func setChoice(field *string, value, def string, choices []string) error {
+	value = strings.ToLower(value)
+	if value == def || value == "none" {
+		*field = ""
+		return nil
+	}
+	for _, c := range choices {
+		if c == value {
+			*field = value
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid value '%s' (expected one of: %s)", value, strings.Join(choices, ", "))
+}
+
+// defaultString returns s, or def when s is empty.
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// setSwitch parses an on/off value into a boolean setting.
+func setSwitch(field *bool, value string) error {
+	on, err := parseSwitch(value)
+	if err != nil {
+		return err
+	}
+	*field = on
+	return nil
+}
+
+// formatSwitch formats a boolean setting as on/off.
+func formatSwitch(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
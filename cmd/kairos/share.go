@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// Output formats supported by `kairos share`.
+var shareFormats = []string{"markdown", "slack", "plain"}
+
+// shareFormat is set by the --format flag of `kairos share`.
+var shareFormat string
+
+// shareCommand builds the `kairos share` command.
+func shareCommand() *command {
+	return &command{
+		Name: "share", Usage: `"Time" "Zone"`, MinArgs: 2, MaxArgs: 2,
+		Short: "Prints a meeting time in every timezone (--format markdown|slack|plain)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&shareFormat, "format", "markdown", "Output format: "+strings.Join(shareFormats, ", "))
+		},
+		Run: func(args []string) error { return printShare(args[0], args[1], strings.ToLower(shareFormat)) },
+	}
+}
+
+/**
+ * This function handles the `kairos share` command.
+ * It converts one instant into every configured timezone and prints a ready-to-paste
+ * announcement block in Markdown, Slack mrkdwn (with a <!date> token that Slack renders
+ * in each reader's own timezone), or plain text.
+ *
+ * @param input - The meeting time, e.g. "2026-01-15 16:00".
+ * @param zone - The zone the time is given in.
+ * @param format - One of shareFormats.
+ * @returns An error if the zone, time, or format is invalid.
+ */
+func printShare(input, zone, format string) error {
+	loc, label, err := resolveZone(zone)
+	if err != nil {
+		return err
+	}
+	instant, note, err := parseLocalTime(input, loc)
+	if err != nil {
+		return err
+	}
+	// The note goes to stderr so that it is not pasted along with the snippet.
+	if note != "" {
+		fmt.Fprintf(os.Stderr, "\x1b[33mNote: %s\x1b[0m\n", note)
+	}
+
+	text, err := shareSnippet(instant.In(loc), label, format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(text)
+	return nil
+}
+
+/**
+ * This function builds the announcement text listing an instant in every configured timezone.
+ *
+ * @param instant - The instant, expressed in the zone it was given in.
+ * @param label - The name of the zone the instant was given in.
+ * @param format - One of shareFormats.
+ * @returns The snippet, or an error for an unknown format.
+ */
+func shareSnippet(instant time.Time, label, format string) (string, error) {
+	var b strings.Builder
+	headline := fmt.Sprintf("%s (%s)", instant.Format("Mon, 02 Jan 2006 15:04 MST"), label)
+
+	type row struct{ name, local string }
+	var rows []row
+	for _, tz := range timezones {
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			continue
+		}
+		local := instant.In(loc)
+		text := local.Format("Mon 02 Jan 15:04 MST")
+		// Flag a different calendar day than the one the time was given in.
+		if days := tzutil.CalendarDayDiff(local, instant); days != 0 {
+			text += fmt.Sprintf(" (%+d day)", days)
+		}
+		rows = append(rows, row{tz.Name, text})
+	}
+
+	switch format {
+	case "markdown":
+		fmt.Fprintf(&b, "**%s**\n\n", headline)
+		b.WriteString("| Zone | Local time |\n|---|---|\n")
+		for _, r := range rows {
+			fmt.Fprintf(&b, "| %s | %s |\n", r.name, r.local)
+		}
+	case "slack":
+		// Slack replaces the token with the time in each reader's own timezone,
+		// falling back to the text after the pipe in clients that can't render it.
+		token := fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|%s>", instant.Unix(), instant.UTC().Format("2006-01-02 15:04 UTC"))
+		fmt.Fprintf(&b, "*%s* — your time: %s\n", headline, token)
+		for _, r := range rows {
+			fmt.Fprintf(&b, "• *%s*: %s\n", r.name, r.local)
+		}
+	case "plain":
+		fmt.Fprintf(&b, "%s\n", headline)
+		for _, r := range rows {
+			fmt.Fprintf(&b, "  %-15s %s\n", r.name, r.local)
+		}
+	default:
+		return "", fmt.Errorf("unknown format '%s' (expected one of: %s)", format, strings.Join(shareFormats, ", "))
+	}
+	return b.String(), nil
+}
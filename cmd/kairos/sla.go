@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/workhours"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// slaUntil is set by `kairos sla --until`.
+var slaUntil string
+
+// slaCommand builds the `kairos sla` command.
+func slaCommand() *command {
+	return &command{
+		Name:    "sla",
+		Usage:   `"Start" "Zone"`,
+		Short:   "Counts the business hours elapsed since a time in a zone (--until to stop earlier)",
+		MinArgs: 2, MaxArgs: 2,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&slaUntil, "until", "", "Count up to this time instead of now")
+		},
+		Run: func(args []string) error { return printSLA(args[0], args[1], slaUntil) },
+	}
+}
+
+// isHoliday reports whether a date is one of a zone's holidays (YYYY-MM-DD).
+func isHoliday(tz TimezoneConfig, day time.Time) bool {
+	return containsString(tz.Holidays, day.Format("2006-01-02"))
+}
+
+/**
+ * This function adds up the business time between two instants: the parts of every
+ * working window (on weekdays that are not holidays) that fall between them.
+ *
+ * @param tz - The zone, for its business hours and holidays.
+ * @param from - The start, in the zone's location.
+ * @param to - The end, in the zone's location.
+ * @returns The business time, and how many holidays were skipped.
+ */
+func businessTimeBetween(tz TimezoneConfig, from, to time.Time) (time.Duration, int) {
+	var total time.Duration
+	holidays := 0
+	// Starting a day early catches an overnight window that began the day before.
+	for day := time.Date(from.Year(), from.Month(), from.Day()-1, 0, 0, 0, 0, from.Location()); !day.After(to); day = day.AddDate(0, 0, 1) {
+		holiday := false
+		for _, b := range zones.BusinessHours(tz) {
+			start := b.StartOn(day)
+			if start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+				continue
+			}
+			end := start.Add(b.Length())
+			if end.Before(from) || start.After(to) {
+				continue
+			}
+			if isHoliday(tz, start) {
+				holiday = true
+				continue
+			}
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+		if holiday {
+			holidays++
+		}
+	}
+	return total, holidays
+}
+
+/**
+ * This function handles `kairos sla`: it prints the business hours elapsed since a
+ * start time (e.g. when a ticket was opened) in a configured zone, or as JSON.
+ *
+ * @param start - The start time, read in the zone unless it has an offset.
+ * @param zone - The configured zone whose business hours and holidays apply.
+ * @param until - The end time, or "" for now.
+ * @returns An error if the zone or a time is invalid.
+ */
+func printSLA(start, zone, until string) error {
+	tz, loc, err := findZone(zone)
+	if err != nil {
+		return err
+	}
+	from, _, err := parseLocalTime(start, loc)
+	if err != nil {
+		return err
+	}
+	to := appClock.Now(loc)
+	if until != "" {
+		if to, _, err = parseLocalTime(until, loc); err != nil {
+			return err
+		}
+	}
+	if to.Before(from) {
+		return fmt.Errorf("the start %s is after the end %s", from.In(loc).Format("2006-01-02 15:04"), to.In(loc).Format("2006-01-02 15:04"))
+	}
+	elapsed, holidays := businessTimeBetween(tz, from.In(loc), to.In(loc))
+
+	if jsonOutput {
+		out, err := json.MarshalIndent(map[string]interface{}{
+			"zone":             tz.Name,
+			"start":            from.In(loc).Format(time.RFC3339),
+			"end":              to.In(loc).Format(time.RFC3339),
+			"business_hours":   zones.BusinessHours(tz).String(),
+			"business_minutes": int(elapsed.Minutes()),
+			"holidays_skipped": holidays,
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Printf("\n\x1b[36m\x1b[1mSLA\x1b[0m in %s (business hours %s, Monday to Friday)\n", tz.Name, zones.BusinessHours(tz))
+	fmt.Printf("From:     %s\n", from.In(loc).Format("Mon, 02 Jan 2006 15:04 MST"))
+	fmt.Printf("To:       %s\n", to.In(loc).Format("Mon, 02 Jan 2006 15:04 MST"))
+	fmt.Printf("Elapsed:  \x1b[1m%s\x1b[0m business time (%s wall-clock)\n", formatBusinessTime(elapsed, zones.BusinessHours(tz)),
+		workhours.FormatCountdown(to.Sub(from)))
+	if holidays > 0 {
+		fmt.Printf("Skipped:  %d holiday(s): %s\n", holidays, strings.Join(tz.Holidays, ", "))
+	}
+	fmt.Println()
+	return nil
+}
+
+// formatBusinessTime formats business time in hours, adding business days for long spans, e.g. "11h 30m (1.4 business days)".
+func formatBusinessTime(d time.Duration, b workhours.Schedule) string {
+	d = d.Round(time.Minute)
+	text := fmt.Sprintf("%dh %02dm", int(d.Hours()), int(d.Minutes())%60)
+	if d >= b.Length() {
+		text += fmt.Sprintf(" (%.1f business days)", float64(d)/float64(b.Length()))
+	}
+	return text
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// slackRefresh is how often the Slack worker reloads the teammates and their presence.
+const slackRefresh = 5 * time.Minute
+
+// slackAPI is the base URL of the Slack Web API.
+const slackAPI = "https://slack.com/api/"
+
+// slackMate is a Slack workspace member, placed in the zone whose location matches their Slack timezone.
+type slackMate struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status,omitempty"`
+	Online bool   `json:"online"`
+}
+
+var (
+	// slackMu guards slackMates, which the worker writes and the dashboard reads.
+	slackMu sync.Mutex
+	// slackMates maps each IANA location to the teammates there, as last fetched.
+	slackMates = map[string][]slackMate{}
+)
+
+// slackCommand builds the `kairos slack` command.
+func slackCommand() *command {
+	return &command{
+		Name:  "slack",
+		Short: "Shows Slack teammates by timezone, or sets your Slack status to your working hours (see kairos config)",
+		Subcommands: []*command{
+			{Name: "teammates", Short: "Lists the workspace's members in each configured zone (--json for machine-readable output)",
+				Run: func(args []string) error { return printSlackTeammates() }},
+			{Name: "status", Short: "Sets your Slack status to the primary zone's working hours",
+				Run: func(args []string) error { return setSlackStatus(time.Now()) }},
+		},
+	}
+}
+
+/**
+ * This function calls a Slack Web API method and decodes the response into out. Slack
+ * reports errors in the body ("ok": false) rather than with the status code.
+ *
+ * @param method - The API method, e.g. "users.list".
+ * @param params - The query parameters for a GET, or nil.
+ * @param body - The JSON body for a POST, or nil for a GET.
+ * @param out - The response to decode into.
+ * @returns An error if the request fails or Slack rejects it.
+ */
+func slackCall(method string, params url.Values, body interface{}, out interface{}) error {
+	if settings.SlackToken == "" {
+		return fmt.Errorf("set slack_token to use the Slack integration")
+	}
+	var req *http.Request
+	var err error
+	if body != nil {
+		data, _ := json.Marshal(body)
+		req, err = http.NewRequest(http.MethodPost, slackAPI+method, bytes.NewReader(data))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		}
+	} else {
+		req, err = http.NewRequest(http.MethodGet, slackAPI+method+"?"+params.Encode(), nil)
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+settings.SlackToken)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data := new(bytes.Buffer)
+	if _, err := data.ReadFrom(resp.Body); err != nil {
+		return err
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data.Bytes(), &result); err != nil {
+		return fmt.Errorf("slack %s: %s", method, resp.Status)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack %s failed: %s", method, result.Error)
+	}
+	return json.Unmarshal(data.Bytes(), out)
+}
+
+/**
+ * This function loads the Slack workspace's members and groups those whose timezone is
+ * a configured zone's location, skipping bots and deactivated accounts. Presence is
+ * only asked for when requested, since Slack allows one member per call.
+ *
+ * @param presence - Whether to look up who is online.
+ * @returns The teammates per location, or an error.
+ */
+func fetchSlackMates(presence bool) (map[string][]slackMate, error) {
+	wanted := map[string]bool{}
+	for _, tz := range timezones {
+		wanted[tz.Location] = true
+	}
+	mates := map[string][]slackMate{}
+	cursor := ""
+	for {
+		var page struct {
+			Members []struct {
+				ID      string `json:"id"`
+				Deleted bool   `json:"deleted"`
+				IsBot   bool   `json:"is_bot"`
+				TZ      string `json:"tz"`
+				Profile struct {
+					RealName    string `json:"real_name"`
+					DisplayName string `json:"display_name"`
+					StatusText  string `json:"status_text"`
+					StatusEmoji string `json:"status_emoji"`
+				} `json:"profile"`
+			} `json:"members"`
+			Metadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		params := url.Values{"limit": {"200"}}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		if err := slackCall("users.list", params, nil, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Members {
+			if m.Deleted || m.IsBot || m.ID == "USLACKBOT" || !wanted[m.TZ] {
+				continue
+			}
+			mate := slackMate{
+				ID:     m.ID,
+				Name:   defaultString(m.Profile.DisplayName, m.Profile.RealName),
+				Status: strings.TrimSpace(m.Profile.StatusEmoji + " " + m.Profile.StatusText),
+			}
+			if presence {
+				var p struct {
+					Presence string `json:"presence"`
+				}
+				if err := slackCall("users.getPresence", url.Values{"user": {m.ID}}, nil, &p); err != nil {
+					return nil, err
+				}
+				mate.Online = p.Presence == "active"
+			}
+			mates[m.TZ] = append(mates[m.TZ], mate)
+		}
+		if cursor = page.Metadata.NextCursor; cursor == "" {
+			return mates, nil
+		}
+	}
+}
+
+/**
+ * This function starts a worker that reloads the Slack teammates and who of them is
+ * online every slackRefresh, but only if a Slack token is configured.
+ */
+func startSlackWorker() {
+	if settings.SlackToken == "" {
+		return
+	}
+	goWorker("slack worker", func(ctx context.Context) {
+		lastErr := ""
+		for {
+			mates, err := fetchSlackMates(true)
+			if err != nil {
+				if err.Error() != lastErr {
+					logger.Warn("slack query failed", "err", err)
+				}
+				lastErr = err.Error()
+			} else {
+				lastErr = ""
+				slackMu.Lock()
+				slackMates = mates
+				slackMu.Unlock()
+			}
+			if !sleepCtx(ctx, slackRefresh) {
+				return
+			}
+		}
+	})
+}
+
+// zoneSlackMates returns the Slack teammates in a zone's location, as last fetched.
+func zoneSlackMates(tz TimezoneConfig) []slackMate {
+	slackMu.Lock()
+	defer slackMu.Unlock()
+	return slackMates[tz.Location]
+}
+
+// slackLine returns the "3 teammates online" line for a zone's view, or "" if nobody on Slack is there.
+func slackLine(tz TimezoneConfig) string {
+	mates := zoneSlackMates(tz)
+	if len(mates) == 0 {
+		return ""
+	}
+	online := 0
+	for _, m := range mates {
+		if m.Online {
+			online++
+		}
+	}
+	noun := "teammates"
+	if online == 1 {
+		noun = "teammate"
+	}
+	return fmt.Sprintf("\x1b[32m●\x1b[0m %d %s online", online, noun)
+}
+
+// printSlackTeammates handles `kairos slack teammates`.
+func printSlackTeammates() error {
+	mates, err := fetchSlackMates(false)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		byZone := map[string][]slackMate{}
+		for _, tz := range timezones {
+			byZone[tz.Name] = mates[tz.Location]
+		}
+		out, err := json.MarshalIndent(byZone, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	for _, tz := range timezones {
+		fmt.Printf("\n\x1b[1m%s\x1b[0m (%s): %d teammate(s)\n", tz.Name, tz.Location, len(mates[tz.Location]))
+		for _, m := range mates[tz.Location] {
+			if m.Status != "" {
+				fmt.Printf("  • %s — %s\n", m.Name, m.Status)
+			} else {
+				fmt.Printf("  • %s\n", m.Name)
+			}
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+/**
+ * This function handles `kairos slack status`: it sets the token owner's Slack status to
+ * the primary zone's working hours, e.g. "Working 09:00-17:00 CEST (Berlin)". The token
+ * must be a user token with the users.profile:write scope.
+ *
+ * @param now - The current time, for the zone's abbreviation.
+ * @returns An error if there is no zone or Slack rejects the change.
+ */
+func setSlackStatus(now time.Time) error {
+	if len(timezones) == 0 {
+		return fmt.Errorf("no timezones are configured")
+	}
+	tz := timezones[0]
+	loc, err := tzutil.LoadLocation(tz.Location)
+	if err != nil {
+		return err
+	}
+	abbr, _ := now.In(loc).Zone()
+	text := fmt.Sprintf("Working %s %s (%s)", zones.BusinessHours(tz), abbr, tz.Name)
+	body := map[string]interface{}{"profile": map[string]interface{}{
+		"status_text": text, "status_emoji": ":clock9:", "status_expiration": 0,
+	}}
+	var out struct{}
+	if err := slackCall("users.profile.set", nil, body, &out); err != nil {
+		return err
+	}
+	fmt.Printf("Slack status set to \"%s\"\n", text)
+	return nil
+}
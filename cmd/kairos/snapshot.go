@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iamstoick/kairos/zones"
+)
+
+// dashSnapshot is what the dashboard shows for one clock tick: the instant, and each
+// zone's local time, title, and business-hours line, worked out once and shared by every
+// view drawn until the next tick. Redraws in between (a key press, a notification) reuse
+// it, so views never show times a second apart and nothing is recomputed per view.
+type dashSnapshot struct {
+	// Now is the instant of the tick.
+	Now   time.Time
+	zones map[string]zoneSnapshot
+}
+
+// zoneSnapshot is one zone's part of a snapshot.
+type zoneSnapshot struct {
+	// Local is the tick's instant in the zone.
+	Local time.Time
+	// Title follows the zone's name in its view title: the military letter, the day/night icon, and the business-hours indicator.
+	Title string
+	// Business is the business-hours line: the indicator, the custom state, and the countdown to the next change.
+	Business string
+}
+
+// dashboardSnapshot is the snapshot the dashboard draws; it is only used on the GUI goroutine.
+var dashboardSnapshot *dashSnapshot
+
+// takeSnapshot starts a new snapshot at the current time; the clock ticker, the flip
+// frames, and the dashboard keys take one, so the next redraw shows the present.
+func takeSnapshot() *dashSnapshot {
+	dashboardSnapshot = &dashSnapshot{Now: appClock.Now(time.UTC), zones: map[string]zoneSnapshot{}}
+	return dashboardSnapshot
+}
+
+// currentSnapshot returns the dashboard's snapshot, taking the first one if there is none yet.
+func currentSnapshot() *dashSnapshot {
+	if dashboardSnapshot == nil {
+		return takeSnapshot()
+	}
+	return dashboardSnapshot
+}
+
+// snapshotAt returns the dashboard's snapshot if it is for now, or a new one that is not
+// kept, e.g. for `kairos render`.
+func snapshotAt(now time.Time) *dashSnapshot {
+	if dashboardSnapshot != nil && dashboardSnapshot.Now.Equal(now) {
+		return dashboardSnapshot
+	}
+	return &dashSnapshot{Now: now, zones: map[string]zoneSnapshot{}}
+}
+
+/**
+ * This function returns a zone's part of the snapshot, working it out the first time a
+ * view asks for it during the tick.
+ *
+ * @param tz - The zone.
+ * @param loc - Its location.
+ * @returns The zone's local time, title, and business-hours line at the snapshot's instant.
+ */
+func (s *dashSnapshot) zone(tz TimezoneConfig, loc *time.Location) zoneSnapshot {
+	if z, ok := s.zones[tz.Name]; ok && z.Local.Location() == loc {
+		return z
+	}
+	local := s.Now.In(loc)
+	indicator := getBusinessHoursIndicator(local, tz)
+	z := zoneSnapshot{
+		Local:    local,
+		Title:    fmt.Sprintf("%s %s %s", designatorTitle(local), getDayNightIcon(local), indicator),
+		Business: fmt.Sprintf("%s %s", indicator, zones.Countdown(local, tz)),
+	}
+	// A zone with custom states also names the state it is in.
+	if label := stateLabel(local, tz); label != "" {
+		z.Business = fmt.Sprintf("%s %s · %s", indicator, label, zones.Countdown(local, tz))
+	}
+	s.zones[tz.Name] = z
+	return z
+}
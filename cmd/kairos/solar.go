@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/iamstoick/kairos/zonemeta"
+)
+
+// formatCoordinates formats a position as e.g. "35.69°N 139.69°E".
+func formatCoordinates(c Coordinates) string {
+	ns, ew := "N", "E"
+	if c.Lat < 0 {
+		ns = "S"
+	}
+	if c.Lon < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%.2f°%s %.2f°%s", math.Abs(c.Lat), ns, math.Abs(c.Lon), ew)
+}
+
+// zoneCoordinates returns a zone's "coords" option, or else the position of its IANA location's principal city.
+func zoneCoordinates(tz TimezoneConfig) (Coordinates, bool) {
+	if tz.Coordinates != nil {
+		return *tz.Coordinates, true
+	}
+	if info, ok := zonemeta.Lookup(tz.Location); ok {
+		return Coordinates{Lat: info.Lat, Lon: info.Lon}, true
+	}
+	return Coordinates{}, false
+}
+
+// The apparent altitude of the sun's upper limb at sunrise and sunset,
+// accounting for atmospheric refraction and the solar radius.
+const sunriseAltitude = -0.833
+
+const (
+	degToRad = math.Pi / 180
+	radToDeg = 180 / math.Pi
+)
+
+/**
+ * This function converts a time to a Julian date.
+ *
+ * @param t - The instant to convert.
+ * @returns The Julian date (days since noon UT, January 1, 4713 BCE).
+ */
+func julianDate(t time.Time) float64 {
+	return float64(t.UnixNano())/86400e9 + 2440587.5
+}
+
+/**
+ * This function computes the sun's declination and the equation of time for a Julian date,
+ * using the low-precision formulas from the U.S. Naval Observatory (accurate to about a minute).
+ *
+ * @param jd - The Julian date.
+ * @returns The declination in degrees and the equation of time in hours.
+ */
+func sunPosition(jd float64) (decl, eqt float64) {
+	d := jd - 2451545.0
+	g := math.Mod(357.529+0.98560028*d, 360)
+	q := math.Mod(280.459+0.98564736*d, 360)
+	l := math.Mod(q+1.915*math.Sin(g*degToRad)+0.020*math.Sin(2*g*degToRad), 360)
+	e := 23.439 - 0.00000036*d
+
+	ra := math.Atan2(math.Cos(e*degToRad)*math.Sin(l*degToRad), math.Cos(l*degToRad)) * radToDeg / 15
+	eqt = q/15 - fixHour(ra)
+	// Keep the equation of time within ±12 hours.
+	if eqt > 12 {
+		eqt -= 24
+	} else if eqt < -12 {
+		eqt += 24
+	}
+	decl = math.Asin(math.Sin(e*degToRad)*math.Sin(l*degToRad)) * radToDeg
+	return decl, eqt
+}
+
+// fixHour normalizes an hour value into the range [0, 24).
+func fixHour(h float64) float64 {
+	h = math.Mod(h, 24)
+	if h < 0 {
+		h += 24
+	}
+	return h
+}
+
+/**
+ * This function returns the instant of solar noon on the local calendar date of day.
+ *
+ * @param day - Any time on the local date of interest (its location determines the date).
+ * @param c - The observer's coordinates.
+ * @returns The instant of solar transit.
+ */
+func solarNoon(day time.Time, c Coordinates) time.Time {
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	// Start from noon at the given longitude and refine once with the sun's position at that time.
+	noon := 12 - c.Lon/15
+	for i := 0; i < 2; i++ {
+		_, eqt := sunPosition(julianDate(midnight.Add(time.Duration(noon * float64(time.Hour)))))
+		noon = 12 - eqt - c.Lon/15
+	}
+	return midnight.Add(time.Duration(noon * float64(time.Hour))).In(day.Location())
+}
+
+/**
+ * This function finds when the sun crosses a given altitude on the local date of day,
+ * either before solar noon (morning) or after it.
+ *
+ * @param day - Any time on the local date of interest.
+ * @param c - The observer's coordinates.
+ * @param altitude - The solar altitude in degrees (negative below the horizon).
+ * @param morning - True for the crossing before noon, false for the one after.
+ * @returns The instant of the crossing, and false if the sun never reaches that altitude (polar day or night).
+ */
+func sunAltitudeTime(day time.Time, c Coordinates, altitude float64, morning bool) (time.Time, bool) {
+	noon := solarNoon(day, c)
+	event := noon
+	// Iterate so the declination is evaluated at the event itself rather than at noon.
+	for i := 0; i < 3; i++ {
+		decl, _ := sunPosition(julianDate(event))
+		cosH := (math.Sin(altitude*degToRad) - math.Sin(c.Lat*degToRad)*math.Sin(decl*degToRad)) /
+			(math.Cos(c.Lat*degToRad) * math.Cos(decl*degToRad))
+		if cosH < -1 || cosH > 1 {
+			return time.Time{}, false
+		}
+		hours := math.Acos(cosH) * radToDeg / 15
+		if morning {
+			hours = -hours
+		}
+		event = noon.Add(time.Duration(hours * float64(time.Hour)))
+	}
+	return event, true
+}
+
+/**
+ * This function returns the sunrise and sunset for the local date of day.
+ *
+ * @param day - Any time on the local date of interest.
+ * @param c - The observer's coordinates.
+ * @returns Sunrise, sunset, and false if the sun does not rise or set that day.
+ */
+func sunriseSunset(day time.Time, c Coordinates) (rise, set time.Time, ok bool) {
+	rise, okRise := sunAltitudeTime(day, c, sunriseAltitude, true)
+	set, okSet := sunAltitudeTime(day, c, sunriseAltitude, false)
+	return rise, set, okRise && okSet
+}
+
+// The sun's altitudes that bound the golden hour, in degrees: the warm, low light from just
+// below the horizon to 6° above it.
+const (
+	goldenHourLow  = -4.0
+	goldenHourHigh = 6.0
+)
+
+/**
+ * This function measures how long the sun is up on the local date of day.
+ *
+ * @param day - Any time on the local date of interest.
+ * @param c - The observer's coordinates.
+ * @returns The daylight length; 24h during polar day and 0 during polar night.
+ */
+func daylightDuration(day time.Time, c Coordinates) time.Duration {
+	if rise, set, ok := sunriseSunset(day, c); ok {
+		return set.Sub(rise)
+	}
+	// Without a sunrise, the sun is either up or down all day; its altitude at noon tells which.
+	decl, _ := sunPosition(julianDate(solarNoon(day, c)))
+	if 90-math.Abs(c.Lat-decl) > sunriseAltitude {
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+/**
+ * This function finds the morning and evening golden hours on the local date of day,
+ * when the sun is between goldenHourLow and goldenHourHigh.
+ *
+ * @param day - Any time on the local date of interest.
+ * @param c - The observer's coordinates.
+ * @returns The start and end of both windows, and false if the sun does not cross those altitudes that day.
+ */
+func goldenHours(day time.Time, c Coordinates) (morningStart, morningEnd, eveningStart, eveningEnd time.Time, ok bool) {
+	morningStart, ok1 := sunAltitudeTime(day, c, goldenHourLow, true)
+	morningEnd, ok2 := sunAltitudeTime(day, c, goldenHourHigh, true)
+	eveningStart, ok3 := sunAltitudeTime(day, c, goldenHourHigh, false)
+	eveningEnd, ok4 := sunAltitudeTime(day, c, goldenHourLow, false)
+	return morningStart, morningEnd, eveningStart, eveningEnd, ok1 && ok2 && ok3 && ok4
+}
+
+// formatDaylight formats a daylight length as e.g. "10h 42m of daylight".
+func formatDaylight(d time.Duration) string {
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh %02dm of daylight", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// formatGoldenHours formats both golden hours in the day's location, e.g. "06:12-06:58, 17:20-18:05".
+func formatGoldenHours(day time.Time, c Coordinates) (string, bool) {
+	ms, me, es, ee, ok := goldenHours(day, c)
+	if !ok {
+		return "", false
+	}
+	loc := day.Location()
+	return fmt.Sprintf("%s-%s, %s-%s", ms.In(loc).Format("15:04"), me.In(loc).Format("15:04"),
+		es.In(loc).Format("15:04"), ee.In(loc).Format("15:04")), true
+}
+
+/**
+ * This function computes where the sun is in an observer's sky, from the subsolar point.
+ * Refraction is ignored, so near the horizon the sun looks about half a degree higher.
+ *
+ * @param t - The instant.
+ * @param c - The observer's coordinates.
+ * @returns The elevation above the horizon and the azimuth clockwise from north, in degrees.
+ */
+func sunElevationAzimuth(t time.Time, c Coordinates) (elevation, azimuth float64) {
+	sunLat, sunLon := subsolarPoint(t)
+	decl, lat := sunLat*degToRad, c.Lat*degToRad
+	// The hour angle is positive after solar noon, when the sun is to the west.
+	h := (c.Lon - sunLon) * degToRad
+	elevation = math.Asin(math.Sin(lat)*math.Sin(decl)+math.Cos(lat)*math.Cos(decl)*math.Cos(h)) * radToDeg
+	azimuth = math.Atan2(-math.Cos(decl)*math.Sin(h), math.Sin(decl)*math.Cos(lat)-math.Cos(decl)*math.Sin(lat)*math.Cos(h)) * radToDeg
+	return elevation, math.Mod(azimuth+360, 360)
+}
+
+// compassPoint names the nearest of the 16 compass points to an azimuth, e.g. "SSW".
+func compassPoint(azimuth float64) string {
+	points := []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+	return points[int(math.Mod(azimuth+11.25, 360)/22.5)%16]
+}
+
+// formatSunPosition formats the sun's place in the sky, e.g. "34.2° up, 212° SSW" or "12.5° below, 310° NW".
+func formatSunPosition(t time.Time, c Coordinates) string {
+	elevation, azimuth := sunElevationAzimuth(t, c)
+	height := fmt.Sprintf("%.1f° up", elevation)
+	if elevation < 0 {
+		height = fmt.Sprintf("%.1f° below", -elevation)
+	}
+	return fmt.Sprintf("%s, %.0f° %s", height, azimuth, compassPoint(azimuth))
+}
+
+/**
+ * This function computes the local mean sidereal time, using the USNO approximation
+ * for Greenwich mean sidereal time (accurate to about 0.1 s per century).
+ *
+ * @param t - The instant.
+ * @param lon - The observer's longitude in degrees (east positive).
+ * @returns The local mean sidereal time in hours, in the range [0, 24).
+ */
+func localSiderealTime(t time.Time, lon float64) float64 {
+	gmst := 18.697374558 + 24.06570982441908*(julianDate(t)-2451545.0)
+	return fixHour(gmst + lon/15)
+}
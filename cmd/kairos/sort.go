@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/zones"
+)
+
+// sortBy is set by `kairos sort --by`.
+var sortBy string
+
+// sortCommand builds the `kairos sort` command.
+func sortCommand() *command {
+	return &command{
+		Name:  "sort",
+		Short: "Orders the secondary zones east to west (--by offset) or by when they open (--by opens)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&sortBy, "by", "", "Sort order: "+strings.Join(zones.SortOrders, ", ")+" (default: the auto_sort setting, or offset)")
+		},
+		Run: func(args []string) error {
+			return runSort(defaultString(sortBy, defaultString(settings.AutoSort, "offset")))
+		},
+	}
+}
+
+// sortZones sorts the secondary zones by the given order (see zones.Sort); the primary zone stays on top.
+func sortZones(by string, now time.Time) error {
+	return zones.Sort(timezones[min(1, len(timezones)):], by, now)
+}
+
+/**
+ * This function handles `kairos sort`: it sorts the secondary zones and saves the order.
+ *
+ * @param by - The sort order.
+ * @returns An error if the order is unknown or the config cannot be saved.
+ */
+func runSort(by string) error {
+	if err := sortZones(strings.ToLower(by), time.Now()); err != nil {
+		return err
+	}
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	var names []string
+	for _, tz := range timezones {
+		names = append(names, tz.Name)
+	}
+	fmt.Printf("Sorted by %s: %s\n", strings.ToLower(by), strings.Join(names, ", "))
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// linuxSpeechEngines are the speech programs tried on Linux and the BSDs, in order, with
+// the flag that selects a voice.
+var linuxSpeechEngines = [][2]string{{"spd-say", "-y"}, {"espeak-ng", "-v"}, {"espeak", "-v"}}
+
+// spokenTime is what the v key says for a zone, e.g. "Tokyo, 3:45 PM, Sunday, October 18".
+func spokenTime(tz TimezoneConfig, local time.Time) string {
+	clock := local.Format("3:04 PM")
+	if uses24Hour(tz) {
+		clock = local.Format("15:04")
+	}
+	return fmt.Sprintf("%s, %s, %s", tz.Name, clock, local.Format("Monday, January 2"))
+}
+
+/**
+ * This function finds the speech command for the platform: say on macOS, the first of
+ * spd-say, espeak-ng, and espeak on Linux and the BSDs, and PowerShell's System.Speech on
+ * Windows. The text is passed to PowerShell in KAIROS_TEXT, so it is never parsed as code.
+ *
+ * @param text - What to say.
+ * @param voice - The voice, in the engine's own naming (e.g. "Kyoko" for say, "ja" for espeak), or "" for its default.
+ * @returns The command, or an error if no speech program is installed.
+ */
+func speechEngine(text, voice string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if voice != "" {
+			return exec.Command("say", "-v", voice, text), nil
+		}
+		return exec.Command("say", text), nil
+	case "windows":
+		script := "Add-Type -AssemblyName System.Speech; $s = New-Object System.Speech.Synthesis.SpeechSynthesizer; "
+		if voice != "" {
+			script += "$s.SelectVoice($env:KAIROS_VOICE); "
+		}
+		return exec.Command("powershell", "-NoProfile", "-Command", script+"$s.Speak($env:KAIROS_TEXT)"), nil
+	}
+	for _, engine := range linuxSpeechEngines {
+		if path, err := exec.LookPath(engine[0]); err == nil {
+			if voice != "" {
+				return exec.Command(path, engine[1], voice, text), nil
+			}
+			return exec.Command(path, text), nil
+		}
+	}
+	return nil, fmt.Errorf("no speech program found; install speech-dispatcher or espeak-ng, or set speak_command")
+}
+
+/**
+ * This function says a zone's current time aloud, for low-vision users and for checking
+ * the time with busy hands. The speak_command setting replaces the platform's speech
+ * program; it runs through the shell with KAIROS_TEXT, KAIROS_VOICE, and KAIROS_ZONE set.
+ * Either runs in the background, so the dashboard keeps ticking while it speaks.
+ *
+ * @param tz - The zone, whose voice option picks the voice.
+ * @param local - Its current time.
+ * @returns An error if no speech program was found or it could not be started.
+ */
+func speakTime(tz TimezoneConfig, local time.Time) error {
+	text := spokenTime(tz, local)
+	var cmd *exec.Cmd
+	if settings.SpeakCommand != "" {
+		cmd = shellCommand(context.Background(), settings.SpeakCommand)
+	} else {
+		var err error
+		if cmd, err = speechEngine(text, tz.Voice); err != nil {
+			return err
+		}
+	}
+	cmd.Env = append(os.Environ(), "KAIROS_TEXT="+text, "KAIROS_VOICE="+tz.Voice, "KAIROS_ZONE="+tz.Name)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() {
+		defer recoverWorker("speech")
+		if err := cmd.Wait(); err != nil {
+			logger.Warn("speech failed", "command", cmd.Path, "err", err)
+		}
+	}()
+	logger.Debug("speaking the time", "zone", tz.Name, "text", text)
+	return nil
+}
+
+// speakFocusedTime says the focused zone's time, or the primary zone's if none is focused; it is bound to v.
+func speakFocusedTime() {
+	tz, ok := focusedZone()
+	if !ok {
+		tz = displayZones()[0]
+	}
+	loc, ok := locations[tz.Name]
+	if !ok {
+		return
+	}
+	if err := speakTime(tz, appClock.Now(loc)); err != nil {
+		showWarning("Speech failed: " + err.Error())
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// pinnedZone is the zone pinned beside the primary one in the split top view (Ctrl+S in the palette, or --split), or "".
+var pinnedZone string
+
+/**
+ * This function moves the pinned zone, if any, to just after the primary zone, so that it
+ * is the split view's zone [1] for the number keys, the focus, and the frames alike. A
+ * pinned zone that is not shown (e.g. hidden, or removed) leaves the zones as they are.
+ *
+ * @param zones - The zones to show, primary first.
+ * @returns The zones, with the pinned one second.
+ */
+func withPinnedZone(zones []TimezoneConfig) []TimezoneConfig {
+	if pinnedZone == "" {
+		return zones
+	}
+	for i := 2; i < len(zones); i++ {
+		if strings.EqualFold(zones[i].Name, pinnedZone) {
+			pinned := zones[i]
+			out := append([]TimezoneConfig{zones[0], pinned}, zones[1:i]...)
+			return append(out, zones[i+1:]...)
+		}
+	}
+	return zones
+}
+
+// splitActive reports whether the top area is split between the primary zone and the pinned one.
+func splitActive(zones []TimezoneConfig) bool {
+	return pinnedZone != "" && len(zones) > 1 && strings.EqualFold(zones[1].Name, pinnedZone)
+}
+
+// togglePin pins a zone beside the primary one, or unpins it if it already is.
+func togglePin(tz TimezoneConfig) {
+	if strings.EqualFold(tz.Name, pinnedZone) {
+		pinnedZone = ""
+		showNotification("Unpinned " + tz.Name)
+		return
+	}
+	if zones := displayZones(); zones[0].Name == tz.Name {
+		showNotification(tz.Name + " is the primary zone; pin another one beside it")
+		return
+	}
+	pinnedZone = tz.Name
+	showNotification(fmt.Sprintf("Pinned %s beside the primary zone (Ctrl+S in the palette again unpins it)", tz.Name))
+}
+
+/**
+ * This function draws the split view, the pinned zone beside the primary one in the
+ * enlarged top area, e.g. my zone and the customer's during an incident bridge. Its title
+ * gives the offset from the primary zone, and the date badge when their dates differ.
+ *
+ * @param snap - The snapshot being drawn.
+ * @param zones - The zones to show, with the pinned one second (see withPinnedZone).
+ * @param x0 - The left edge of the view.
+ * @param x1 - The right edge of the view.
+ * @param y1 - The bottom edge of the view.
+ * @returns The view's frame.
+ */
+func renderSplitView(snap *dashSnapshot, zones []TimezoneConfig, x0, x1, y1 int) viewFrame {
+	f := viewFrame{Name: "split", X0: x0, Y0: 0, X1: x1, Y1: y1, Frame: true}
+	primary, ok := locations[zones[0].Name]
+	loc, ok2 := locations[zones[1].Name]
+	if !ok || !ok2 {
+		return f
+	}
+	_, p := snap.Now.In(primary).Zone()
+	_, o := snap.Now.In(loc).Zone()
+	z := snap.zone(zones[1], loc)
+	f.Title = fmt.Sprintf(" [1] 📌 %s (%s)%s%s", zones[1].Name, tzutil.FormatOffsetDiff(o-p), dayBadge(z.Local, snap.Now.In(primary)), z.Title)
+	f.Lines = renderZoneLines(z, zones[1], x1-x0-1, y1-1, false)
+	return f
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// sharedState holds what the dashboard shares with its workers, the control socket, and the
+// daemon, behind one lock: the footer notification, the CPU and memory readings, and a copy
+// of the zones. The GUI goroutine (or the daemon, under daemonMu) still owns timezones and
+// locations, and publishes them here for the workers; everything else reads copies.
+type sharedState struct {
+	mu                  sync.RWMutex
+	notification        string
+	notificationLevel   notifyLevel
+	notificationShown   time.Time
+	notificationExpires time.Time
+	cpu, mem            string
+	zones               []TimezoneConfig
+	locations           map[string]*time.Location
+	// notificationQueue holds the notifications waiting for the current one to expire, oldest first.
+	notificationQueue []queuedNotification
+}
+
+// appState is the state shared by the dashboard's goroutines.
+var appState sharedState
+
+// maxQueuedNotifications is how many notifications can wait; beyond it, the oldest waiting one is dropped.
+const maxQueuedNotifications = 5
+
+// queuedNotification is a notification waiting its turn, with its level and how long it is shown.
+type queuedNotification struct {
+	Text     string
+	Level    notifyLevel
+	Duration time.Duration
+}
+
+// Notification returns the footer notification, or "" when none is shown.
+func (s *sharedState) Notification() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.notification
+}
+
+// NotificationStatus returns the footer notification, its level, when it was shown, and how many more are waiting.
+func (s *sharedState) NotificationStatus() (msg string, level notifyLevel, shown time.Time, waiting int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.notification, s.notificationLevel, s.notificationShown, len(s.notificationQueue)
+}
+
+/**
+ * This method shows msg in the footer for d. While another notification is shown, msg
+ * waits its turn in the queue instead of replacing it, though alerts go ahead of the
+ * other levels; the same message shown or waiting again is not repeated, but a shown one
+ * is kept up for at least d from now.
+ *
+ * @param msg - The message.
+ * @param level - Its level (see notify.go).
+ * @param d - How long it is shown once its turn comes.
+ */
+func (s *sharedState) SetNotification(msg string, level notifyLevel, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	switch {
+	case s.notification == "":
+		s.notification, s.notificationLevel, s.notificationShown, s.notificationExpires = msg, level, now, now.Add(d)
+		return
+	case s.notification == msg:
+		if now.Add(d).After(s.notificationExpires) {
+			s.notificationExpires = now.Add(d)
+		}
+		return
+	}
+	for _, q := range s.notificationQueue {
+		if q.Text == msg {
+			return
+		}
+	}
+	i := len(s.notificationQueue)
+	if level == levelAlert {
+		for i = 0; i < len(s.notificationQueue) && s.notificationQueue[i].Level == levelAlert; i++ {
+		}
+	}
+	s.notificationQueue = append(s.notificationQueue[:i], append([]queuedNotification{{Text: msg, Level: level, Duration: d}}, s.notificationQueue[i:]...)...)
+	if len(s.notificationQueue) > maxQueuedNotifications {
+		s.notificationQueue = s.notificationQueue[1:]
+	}
+}
+
+// ExpireNotification clears the notification once its time is up, and shows the next one waiting.
+func (s *sharedState) ExpireNotification(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.notification == "" || now.Before(s.notificationExpires) {
+		return
+	}
+	s.notification = ""
+	if len(s.notificationQueue) > 0 {
+		next := s.notificationQueue[0]
+		s.notificationQueue = s.notificationQueue[1:]
+		s.notification, s.notificationLevel, s.notificationShown, s.notificationExpires = next.Text, next.Level, now, now.Add(next.Duration)
+	}
+}
+
+// Stats returns the footer's CPU and memory readings.
+func (s *sharedState) Stats() (cpu, mem string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cpu, s.mem
+}
+
+// SetCPU stores the footer's CPU reading.
+func (s *sharedState) SetCPU(cpu string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cpu = cpu
+}
+
+// SetMEM stores the footer's memory reading.
+func (s *sharedState) SetMEM(mem string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mem = mem
+}
+
+// PublishZones stores a copy of the zones and their locations for the workers; loadLocations
+// and saveConfig call it whenever the zones may have changed.
+func (s *sharedState) PublishZones(zones []TimezoneConfig, locs map[string]*time.Location) {
+	copied := make(map[string]*time.Location, len(locs))
+	for name, loc := range locs {
+		copied[name] = loc
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.zones, s.locations = append([]TimezoneConfig(nil), zones...), copied
+}
+
+// Zones returns the zones and locations last published; callers must not modify them.
+func (s *sharedState) Zones() ([]TimezoneConfig, map[string]*time.Location) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.zones, s.locations
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// stopwatch measures elapsed time across any number of start/stop runs and records laps.
+type stopwatch struct {
+	Running bool
+	// Started is when the current run began; Banked is the time of the finished runs.
+	Started time.Time
+	Banked  time.Duration
+	// Laps holds the elapsed time at each lap, oldest first.
+	Laps []time.Duration
+}
+
+var (
+	// stopwatchMode is toggled with the "w" key and shows the stopwatch in the primary view.
+	stopwatchMode bool
+	// watch is the dashboard's stopwatch.
+	watch stopwatch
+)
+
+// Elapsed returns the total measured time at now.
+func (s *stopwatch) Elapsed(now time.Time) time.Duration {
+	if s.Running {
+		return s.Banked + now.Sub(s.Started)
+	}
+	return s.Banked
+}
+
+// Toggle starts or stops the stopwatch.
+func (s *stopwatch) Toggle(now time.Time) {
+	if s.Running {
+		s.Banked += now.Sub(s.Started)
+	} else {
+		s.Started = now
+	}
+	s.Running = !s.Running
+}
+
+// Lap records the current elapsed time; it does nothing while the stopwatch is stopped.
+func (s *stopwatch) Lap(now time.Time) bool {
+	if !s.Running {
+		return false
+	}
+	s.Laps = append(s.Laps, s.Elapsed(now))
+	return true
+}
+
+// Reset stops the stopwatch and clears the time and laps.
+func (s *stopwatch) Reset() {
+	*s = stopwatch{}
+}
+
+// formatStopwatch formats an elapsed time as MM:SS.t (minutes keep counting past 59).
+func formatStopwatch(d time.Duration) string {
+	tenths := int(d / (100 * time.Millisecond))
+	return fmt.Sprintf("%02d:%02d.%d", tenths/600, tenths/10%60, tenths%10)
+}
+
+// lapLines returns one line per lap, newest first, with the lap's own length and the total.
+func (s *stopwatch) lapLines() []string {
+	var lines []string
+	for i := len(s.Laps) - 1; i >= 0; i-- {
+		split := s.Laps[i]
+		if i > 0 {
+			split -= s.Laps[i-1]
+		}
+		lines = append(lines, fmt.Sprintf("Lap %2d   %s   %s", i+1, formatStopwatch(split), formatStopwatch(s.Laps[i])))
+	}
+	return lines
+}
+
+/**
+ * This function renders the stopwatch in the primary view: the elapsed time as MM:SS in
+ * big digits (small text when the view is short), then as many laps as fit, newest first.
+ *
+ * @param now - The current time.
+ * @param width - The inner width of the view.
+ * @param height - The inner height of the view.
+ * @returns The lines of the view.
+ */
+func renderStopwatchLines(now time.Time, width, height int) []string {
+	elapsed := watch.Elapsed(now)
+	state := "\x1b[90mstopped\x1b[0m"
+	if watch.Running {
+		state = "\x1b[32mrunning\x1b[0m"
+	}
+	var lines []string
+	if height < 8 {
+		lines = []string{render.Center(formatStopwatch(elapsed)+"  "+state, width)}
+	} else {
+		lines = []string{""}
+		mmss := formatStopwatch(elapsed)[:5]
+		for _, line := range render.PrintTimeASCII(mmss) {
+			lines = append(lines, render.Center(line, width))
+		}
+		lines = append(lines, render.Center(fmt.Sprintf("\x1b[1m%s\x1b[0m  %s", formatStopwatch(elapsed), state), width))
+	}
+	for _, lap := range watch.lapLines() {
+		if len(lines) >= height {
+			break
+		}
+		lines = append(lines, render.Center(lap, width))
+	}
+	return lines
+}
+
+/**
+ * This function handles the stopwatch keys: space starts or stops it, "l" records a lap,
+ * and "r" resets it. They only work while the stopwatch is shown (the "w" key).
+ *
+ * @param key - The key that was pressed.
+ */
+func stopwatchKey(key rune) {
+	if !stopwatchMode {
+		return
+	}
+	now := appClock.Now(time.UTC)
+	switch key {
+	case ' ':
+		watch.Toggle(now)
+	case 'l':
+		if watch.Lap(now) {
+			showNotification(fmt.Sprintf("Lap %d: %s", len(watch.Laps), formatStopwatch(watch.Laps[len(watch.Laps)-1])))
+		}
+	case 'r':
+		watch.Reset()
+		showNotification("Stopwatch reset")
+	}
+}
+
+// stopwatchClipboardText returns the total and every lap, oldest first, for the "y" key.
+func stopwatchClipboardText(now time.Time) string {
+	lines := []string{"Total " + formatStopwatch(watch.Elapsed(now))}
+	laps := watch.lapLines()
+	for i := len(laps) - 1; i >= 0; i-- {
+		lines = append(lines, laps[i])
+	}
+	return strings.Join(lines, "\n")
+}
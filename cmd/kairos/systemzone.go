@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// systemZoneInterval is how often the dashboard checks whether the system timezone changed.
+const systemZoneInterval = 10 * time.Second
+
+var (
+	// systemZone is the system timezone when it was last checked, e.g. "Europe/Berlin", or "" before the first check.
+	systemZone string
+	// systemZoneChecked is when the system timezone was last checked.
+	systemZoneChecked time.Time
+	// followedZone is the system timezone after it changed while the dashboard ran, or nil
+	// before that. time.Local is never changed: other goroutines read it without a lock.
+	followedZone atomic.Pointer[time.Location]
+)
+
+// localZone returns the local timezone: the one the system was in at startup (time.Local), or the one it changed to since.
+func localZone() *time.Location {
+	if loc := followedZone.Load(); loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+/**
+ * This function follows the system timezone while the dashboard runs, e.g. on a laptop
+ * that travels with its owner. Go reads the system zone once at startup, so without this
+ * the local time and the zones set to "Local" would stay in the old zone until a restart.
+ * When the zone changes, the local time follows it, the "Local" zones move with it, and a
+ * notification says so. A check that finds no zone (e.g. while /etc/localtime is being
+ * replaced) is not taken for a change.
+ *
+ * @param now - The current time.
+ */
+func checkSystemZone(now time.Time) {
+	if now.Sub(systemZoneChecked) < systemZoneInterval {
+		return
+	}
+	systemZoneChecked = now
+	name, ok := detectSystemZone()
+	if !ok || name == systemZone {
+		return
+	}
+	previous := systemZone
+	systemZone = name
+	if previous == "" {
+		return
+	}
+	loc, err := tzutil.LoadLocation(name)
+	if err != nil {
+		logger.Warn("system timezone not followed", "zone", name, "err", err)
+		return
+	}
+	// Views are drawn on this goroutine, the tick's, so they all see the new local zone at once.
+	followedZone.Store(loc)
+	for _, tz := range timezones {
+		if tz.Location == "Local" {
+			locations[tz.Name] = loc
+		}
+	}
+	appState.PublishZones(timezones, locations)
+	logger.Info("system timezone changed", "from", previous, "to", name)
+	showNotification(fmt.Sprintf("The system timezone changed from %s to %s; the local time follows it", previous, name))
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/zones"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+/**
+ * This function handles the `kairos table` command.
+ * It prints an hour-by-hour meeting grid for the coming hours across all configured
+ * timezones, shading each zone's business hours, so a time can be picked and shared.
+ * Hours that touch a zone's focus blocks are hatched and do not count the zone as open.
+ * Once Google Calendar is connected, a BUSY column shows whose calendars are busy in
+ * each hour, and the suggested slot (the first hour when the most zones are open) skips
+ * those hours.
+ *
+ * @param args - Optional arguments; the first one is the number of hours to show (1 to 48, default 24).
+ * @returns An error if the number of hours is invalid or no timezones are configured.
+ */
+func printTable(args []string) error {
+	hours := 24
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > 48 {
+			return fmt.Errorf("invalid number of hours '%s' (expected 1-48, default 24)", args[0])
+		}
+		hours = n
+	}
+	if len(timezones) == 0 {
+		return fmt.Errorf("No timezones configured. Use 'kairos help' to see how to add some.")
+	}
+
+	// Resolve every zone once up front, skipping invalid ones.
+	var valid []TimezoneConfig
+	var locs []*time.Location
+	for _, tz := range timezones {
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			continue
+		}
+		valid = append(valid, tz)
+		locs = append(locs, loc)
+	}
+
+	start := appClock.Now(localZone()).Truncate(time.Hour)
+	// Busy blocks are only fetched when Google Calendar is connected; failures leave them out.
+	var blocks []busyBlock
+	calendar := settings.GCalRefreshToken != ""
+	if calendar {
+		var err error
+		if blocks, err = fetchFreeBusy(start, start.Add(time.Duration(hours)*time.Hour)); err != nil {
+			fmt.Printf("\x1b[33mGoogle Calendar unavailable: %v\x1b[0m\n", err)
+			calendar = false
+		}
+	}
+
+	const cellWidth = 12
+	fmt.Printf("\n\x1b[36m\x1b[1mMEETING GRID\x1b[0m next %d hours\n", hours)
+	for _, tz := range valid {
+		fmt.Print(padCell(tz.Name, cellWidth))
+	}
+	if calendar {
+		fmt.Print(padCell("BUSY", cellWidth))
+	}
+	fmt.Println("OPEN")
+	columns := len(valid)
+	if calendar {
+		columns++
+	}
+	fmt.Println(strings.Repeat("-", cellWidth*columns+4))
+
+	var suggested time.Time
+	bestOpen := 0
+	for h := 0; h < hours; h++ {
+		instant := start.Add(time.Duration(h) * time.Hour)
+		open := 0
+		for i, tz := range valid {
+			local := instant.In(locs[i])
+			text := local.Format("Mon 15:04")
+			focus := zones.InFocusBlock(tz, local, local.Add(time.Hour))
+			if focus {
+				// Focus blocks are hatched, and the zone does not count as open for a meeting.
+				text += strings.Repeat("░", cellWidth-1-len(text))
+			}
+			cell := padCell(text, cellWidth)
+			switch {
+			case zones.BusinessHours(tz).Contains(local):
+				if !focus {
+					open++
+				}
+				// Business hours are shaded with a green background.
+				cell = "\x1b[42m\x1b[30m" + strings.TrimSuffix(cell, " ") + "\x1b[0m "
+			case local.Hour() >= 22 || local.Hour() < 6:
+				// Night hours are dimmed.
+				cell = "\x1b[90m" + cell + "\x1b[0m"
+			}
+			fmt.Print(cell)
+		}
+		busy := busyDuring(blocks, instant, instant.Add(time.Hour))
+		if calendar {
+			fmt.Print("\x1b[31m" + padCell(formatBusy(busy), cellWidth) + "\x1b[0m")
+		}
+		if len(busy) == 0 && open > bestOpen {
+			suggested, bestOpen = instant, open
+		}
+		count := fmt.Sprintf("%d/%d", open, len(valid))
+		if open == len(valid) {
+			count = "\x1b[32m\x1b[1m" + count + "\x1b[0m"
+		}
+		fmt.Println(count)
+	}
+	fmt.Println("\x1b[90m(green = business hours, grey = night, ░ = focus block)\x1b[0m")
+	if !suggested.IsZero() {
+		var times []string
+		for i, tz := range valid {
+			times = append(times, fmt.Sprintf("%s %s", suggested.In(locs[i]).Format("Mon 15:04"), tz.Name))
+		}
+		fmt.Printf("Suggested: \x1b[1m%s\x1b[0m (%d/%d open)\n", strings.Join(times, " / "), bestOpen, len(valid))
+	}
+	fmt.Println()
+	return nil
+}
+
+// formatBusy summarizes the busy calendars of an hour for the BUSY column: "me", a teammate, or a count.
+func formatBusy(calendars []string) string {
+	switch {
+	case len(calendars) == 0:
+		return ""
+	case len(calendars) > 1:
+		return fmt.Sprintf("%d busy", len(calendars))
+	case calendars[0] == "primary":
+		return "me"
+	}
+	return strings.SplitN(calendars[0], "@", 2)[0]
+}
+
+// padCell pads s with spaces to width, truncating it if needed.
+func padCell(s string, width int) string {
+	if w := render.TextWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return runewidth.FillRight(runewidth.Truncate(render.StripANSI(s), width-1, ""), width-1) + " "
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// emojiChoices are the values of the emoji setting.
+var emojiChoices = []string{"auto", "on", "off"}
+
+// ambiguousWidthChoices are the values of the ambiguous_width setting.
+var ambiguousWidthChoices = []string{"auto", "narrow", "wide"}
+
+// termCaps is what the terminal can draw, as detected by detectTermCaps. The zero value draws everything.
+type termCaps struct {
+	// Colors is "truecolor", "256", "8", or "none"; "" if it was not detected. The dashboard
+	// draws with the 8 basic colors, and without any on "none".
+	Colors, ColorsFrom string
+	// NoUTF8 is set when the terminal does not decode UTF-8: the borders are drawn in ASCII,
+	// the clock in plain text, and other symbols with ASCII stand-ins.
+	NoUTF8   bool
+	UTF8From string
+	// NarrowEmoji is set when the terminal draws emoji one column wide instead of two, which
+	// shifts everything after them; they are replaced with narrow symbols (see consoleSafe).
+	NarrowEmoji bool
+	EmojiFrom   string
+	// WideAmbiguous is set when the terminal draws ambiguous-width symbols, such as ● and the
+	// box-drawing lines, two columns wide (as CJK terminals do): the borders are drawn in
+	// ASCII, and the symbols with ASCII stand-ins.
+	WideAmbiguous bool
+	AmbiguousFrom string
+}
+
+// terminalCaps is the terminal's capabilities, detected when the dashboard starts.
+var terminalCaps termCaps
+
+// cursorPosition matches a terminal's reply to a cursor position report query: row and column.
+var cursorPosition = regexp.MustCompile(`\x1b\[(\d+);(\d+)R`)
+
+// monoTerminals are TERM values, or their suffixes, of terminals without colors.
+var monoTerminals = []string{"vt52", "vt100", "vt102", "vt220", "-mono", "-m"}
+
+/**
+ * This function works out what the terminal can draw, so that the dashboard can fall back
+ * to what it supports instead of drawing garbled or misaligned views. The colors come from
+ * TERM, COLORTERM, and NO_COLOR. Whether UTF-8 works, and how wide emoji are, is measured:
+ * a test character and a test emoji are drawn at the start of the line, and the terminal
+ * is asked where its cursor ended up (the line is cleared again). Without an answer, the
+ * locale and TERM decide. The emoji setting overrides the emoji check, except in the
+ * Windows console, which cannot draw most emoji at all. Like detectBackground, it must run
+ * before the terminal UI starts.
+ */
+func detectTermCaps() {
+	caps := termCaps{}
+	term, colorterm := os.Getenv("TERM"), strings.ToLower(os.Getenv("COLORTERM"))
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		caps.Colors, caps.ColorsFrom = "none", "NO_COLOR is set"
+	case term == "dumb" || isMonoTerminal(term):
+		caps.Colors, caps.ColorsFrom = "none", "TERM="+term
+	case colorterm == "truecolor" || colorterm == "24bit":
+		caps.Colors, caps.ColorsFrom = "truecolor", "COLORTERM="+colorterm
+	case runtime.GOOS == "windows" && os.Getenv("WT_SESSION") != "":
+		caps.Colors, caps.ColorsFrom = "truecolor", "Windows Terminal"
+	case colorterm != "":
+		caps.Colors, caps.ColorsFrom = "256", "COLORTERM="+colorterm
+	case strings.Contains(term, "256color"):
+		caps.Colors, caps.ColorsFrom = "256", "TERM="+term
+	default:
+		caps.Colors, caps.ColorsFrom = "8", "TERM="+term
+	}
+
+	var widths [3]int
+	measured := false
+	if runtime.GOOS != "windows" && stdoutIsTerminal() && term != "dumb" {
+		widths, measured = measureTerminalWidths()
+	}
+	utf8Width, emojiWidth, ambiguousWidth := widths[0], widths[1], widths[2]
+	locale := firstEnv("LC_ALL", "LC_CTYPE", "LANG")
+	switch upper := strings.ToUpper(locale); {
+	case runtime.GOOS == "windows":
+		caps.UTF8From = "the Windows console"
+	case measured:
+		caps.NoUTF8 = utf8Width != 1
+		caps.UTF8From = fmt.Sprintf("the terminal drew é in %d column(s)", utf8Width)
+	case locale != "" && !strings.Contains(upper, "UTF-8") && !strings.Contains(upper, "UTF8"):
+		caps.NoUTF8, caps.UTF8From = true, fmt.Sprintf("locale %q", locale)
+	default:
+		caps.UTF8From = fmt.Sprintf("locale %q", locale)
+	}
+
+	switch {
+	case runtime.GOOS == "windows":
+		caps.NarrowEmoji, caps.EmojiFrom = true, "the Windows console draws one UTF-16 character per cell"
+	case settings.Emoji != "":
+		caps.NarrowEmoji, caps.EmojiFrom = settings.Emoji == "off", "the emoji setting"
+	case caps.NoUTF8:
+		caps.NarrowEmoji, caps.EmojiFrom = true, "the terminal does not decode UTF-8"
+	case measured:
+		caps.NarrowEmoji = emojiWidth != 2
+		caps.EmojiFrom = fmt.Sprintf("the terminal drew 🌞 in %d column(s)", emojiWidth)
+	case term == "linux":
+		caps.NarrowEmoji, caps.EmojiFrom = true, "the Linux console has no emoji"
+	default:
+		caps.EmojiFrom = "assumed, since the terminal could not be measured"
+	}
+	switch env := os.Getenv("RUNEWIDTH_EASTASIAN"); {
+	case settings.AmbiguousWidth != "":
+		caps.WideAmbiguous, caps.AmbiguousFrom = settings.AmbiguousWidth == "wide", "the ambiguous_width setting"
+	case env != "":
+		caps.WideAmbiguous, caps.AmbiguousFrom = env == "1", "RUNEWIDTH_EASTASIAN="+env
+	case caps.NoUTF8:
+		caps.AmbiguousFrom = "the terminal does not decode UTF-8"
+	case measured:
+		caps.WideAmbiguous = ambiguousWidth == 2
+		caps.AmbiguousFrom = fmt.Sprintf("the terminal drew ● in %d column(s)", ambiguousWidth)
+	default:
+		caps.WideAmbiguous, caps.AmbiguousFrom = runewidth.IsEastAsian(), fmt.Sprintf("locale %q", locale)
+	}
+	// termbox draws ambiguous-width characters in one cell whatever the terminal does, so the
+	// dashboard measures them that way too; where the terminal draws them wide, they are replaced.
+	runewidth.DefaultCondition = &runewidth.Condition{StrictEmojiNeutral: runewidth.StrictEmojiNeutral}
+	terminalCaps = caps
+	logger.Debug("terminal capabilities", "colors", caps.Colors, "utf8", !caps.NoUTF8, "wide_emoji", !caps.NarrowEmoji, "wide_ambiguous", caps.WideAmbiguous)
+}
+
+// isMonoTerminal reports whether a TERM value names a terminal without colors.
+func isMonoTerminal(term string) bool {
+	for _, m := range monoTerminals {
+		if term == m || (strings.HasPrefix(m, "-") && strings.HasSuffix(term, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * This function measures how many columns the terminal draws "é" (two bytes in UTF-8),
+ * an emoji, and an ambiguous-width symbol in, by drawing each at the start of the line and
+ * asking for the cursor position after it. The line is cleared afterwards.
+ *
+ * @returns The three widths, and false if the terminal did not answer.
+ */
+func measureTerminalWidths() ([3]int, bool) {
+	var widths [3]int
+	reply, err := queryTerminal("\ré\x1b[6n\r🌞\x1b[6n\r●\x1b[6n\r\x1b[K")
+	if err != nil {
+		logger.Debug("terminal width query failed", "err", err)
+		return widths, false
+	}
+	reports := cursorPosition.FindAllStringSubmatch(reply, -1)
+	if len(reports) != len(widths) {
+		return widths, false
+	}
+	for i, report := range reports {
+		col, _ := strconv.Atoi(report[2])
+		widths[i] = col - 1
+	}
+	return widths, true
+}
+
+/**
+ * This function lists, for kairos doctor, what detectTermCaps found and how the dashboard
+ * draws because of it.
+ *
+ * @returns A check for the colors, UTF-8, character widths, and emoji.
+ */
+func termCapsChecks() []doctorCheck {
+	section, caps, term := "terminal", terminalCaps, os.Getenv("TERM")
+	var checks []doctorCheck
+	switch {
+	case term == "" && runtime.GOOS != "windows":
+		checks = append(checks, doctorCheck{section, "colors", checkFail, "TERM is not set; the dashboard may show a blank screen"})
+	case term == "dumb":
+		checks = append(checks, doctorCheck{section, "colors", checkFail, "TERM=dumb does not support cursor movement or colors"})
+	case caps.Colors == "none":
+		checks = append(checks, doctorCheck{section, "colors", checkWarn,
+			fmt.Sprintf("none (%s); the dashboard draws without colors, but CLI output still uses them", caps.ColorsFrom)})
+	case caps.Colors == "8":
+		checks = append(checks, doctorCheck{section, "colors", checkPass, fmt.Sprintf("basic colors (%s)", caps.ColorsFrom)})
+	default:
+		checks = append(checks, doctorCheck{section, "colors", checkPass,
+			fmt.Sprintf("%s (%s); the dashboard uses the basic colors", caps.Colors, caps.ColorsFrom)})
+	}
+	if caps.NoUTF8 {
+		checks = append(checks, doctorCheck{section, "unicode", checkWarn,
+			fmt.Sprintf("no UTF-8 (%s); the dashboard draws ASCII borders, text clocks, and ASCII stand-ins for symbols", caps.UTF8From)})
+	} else {
+		checks = append(checks, doctorCheck{section, "unicode", checkPass, "UTF-8 (" + caps.UTF8From + ")"})
+	}
+	switch {
+	case caps.WideAmbiguous:
+		checks = append(checks, doctorCheck{section, "character width", checkWarn,
+			fmt.Sprintf("ambiguous-width symbols are two columns wide (%s); the dashboard draws ASCII borders and stand-ins such as * for ● (kairos config set ambiguous_width narrow if they are single width)", caps.AmbiguousFrom)})
+	default:
+		checks = append(checks, doctorCheck{section, "character width", checkPass,
+			"ambiguous-width symbols such as ● and the box-drawing lines are single width (" + caps.AmbiguousFrom + ")"})
+	}
+	replaced := "replaced with narrow symbols such as ● for 🟢 (" + caps.EmojiFrom + ")"
+	switch {
+	case caps.NarrowEmoji && (runtime.GOOS == "windows" || settings.Emoji != ""):
+		checks = append(checks, doctorCheck{section, "emoji", checkPass, replaced})
+	case caps.NarrowEmoji:
+		checks = append(checks, doctorCheck{section, "emoji", checkWarn,
+			replaced + "; if they are drawn correctly, run 'kairos config set emoji on'"})
+	default:
+		checks = append(checks, doctorCheck{section, "emoji", checkPass, "two columns wide (" + caps.EmojiFrom + ")"})
+	}
+	return checks
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// escapeSequenceWait is how long an Esc is held back for the rest of a focus sequence,
+// which the terminal sends in a single write.
+const escapeSequenceWait = 25 * time.Millisecond
+
+var (
+	// focusReporting is set while the terminal is asked to report focus changes (DEC mode 1004).
+	focusReporting bool
+	// terminalFocused is false while the terminal reports that the dashboard's window or pane is not focused.
+	terminalFocused = true
+	// focusSeqStage follows a focus sequence, Esc [ I or Esc [ O, through the keys termbox
+	// makes of it: 1 after the Esc, and 2 after the [.
+	focusSeqStage int
+	// heldEscapes are the Esc handlers held back until the Esc turns out not to begin a focus
+	// sequence; escapeGeneration tells each held Esc's release from a later one's.
+	heldEscapes      []func() error
+	escapeGeneration int
+)
+
+// enableFocusEvents asks the terminal to report focus changes, if dim_unfocused or quiet_unfocused wants them.
+func enableFocusEvents() {
+	if settings.NoDimUnfocused && !settings.QuietUnfocused {
+		return
+	}
+	os.Stdout.WriteString("\x1b[?1004h")
+	focusReporting = true
+}
+
+// disableFocusEvents stops the focus reports, so that they do not reach the shell after the dashboard.
+func disableFocusEvents() {
+	if focusReporting {
+		os.Stdout.WriteString("\x1b[?1004l")
+		focusReporting, terminalFocused = false, true
+	}
+}
+
+/**
+ * This function wraps an Esc handler. termbox does not know the focus sequences, so it
+ * reports them as Esc, [, and I or O; while focus changes are reported, an Esc is held back
+ * for a moment and runs only if the rest of a focus sequence does not follow it.
+ *
+ * @param h - The Esc handler.
+ * @returns The handler to bind.
+ */
+func onEscape(h func(g *gocui.Gui, v *gocui.View) error) func(g *gocui.Gui, v *gocui.View) error {
+	return func(g *gocui.Gui, v *gocui.View) error {
+		if !focusReporting {
+			return h(g, v)
+		}
+		// Every Esc handler bound to the key runs for the same Esc, and is held with the first.
+		if focusSeqStage != 1 {
+			focusSeqStage = 1
+			escapeGeneration++
+			generation := escapeGeneration
+			time.AfterFunc(escapeSequenceWait, func() {
+				g.Update(func(g *gocui.Gui) error { return releaseEscapes(generation) })
+			})
+		}
+		heldEscapes = append(heldEscapes, func() error { return h(g, v) })
+		return nil
+	}
+}
+
+// releaseEscapes runs the held Esc handlers once no focus sequence followed their Esc.
+func releaseEscapes(generation int) error {
+	if generation != escapeGeneration || heldEscapes == nil {
+		return nil
+	}
+	held := heldEscapes
+	heldEscapes, focusSeqStage = nil, 0
+	for _, h := range held {
+		if err := h(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * This function takes the keys of a focus sequence out of the dashboard's keys, and
+ * follows the terminal's focus with it. It is called for every shortcut key first.
+ *
+ * @param key - The key that was pressed.
+ * @returns Whether the key was part of a focus sequence, and so must be ignored.
+ */
+func consumeFocusSequence(key interface{}) bool {
+	switch {
+	case focusSeqStage == 1 && key == '[':
+		focusSeqStage = 2
+		return true
+	case focusSeqStage == 2 && (key == 'I' || key == 'O'):
+		heldEscapes, focusSeqStage = nil, 0
+		if focused := key == 'I'; focused != terminalFocused {
+			terminalFocused = focused
+			logger.Debug("terminal focus changed", "focused", focused)
+		}
+		return true
+	}
+	return false
+}
+
+// dimUnfocused reports whether the dashboard is drawn dimmed, while its terminal is not focused with dim_unfocused on.
+func dimUnfocused() bool {
+	return !terminalFocused && !settings.NoDimUnfocused
+}
+
+// quietUnfocused reports whether the bell and notifications are held back, while the terminal is not focused with quiet_unfocused on.
+func quietUnfocused() bool {
+	return !terminalFocused && settings.QuietUnfocused
+}
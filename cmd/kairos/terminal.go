@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"syscall"
+	"unicode"
+
+	"github.com/jroimartin/gocui"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+var (
+	// activeGui is the running terminal UI, closed by closeGUI.
+	activeGui *gocui.Gui
+	// closeGuiOnce makes closing idempotent: closing termbox twice blocks forever.
+	closeGuiOnce sync.Once
+)
+
+/**
+ * This function starts a terminal UI and makes it the one restored by closeGUI.
+ * Callers must `defer closeGUI()` and `defer recoverGUI(...)` so that a panic never
+ * leaves the terminal in raw mode.
+ *
+ * @returns The GUI, or an error if the terminal cannot be initialized.
+ */
+func newGUI() (*gocui.Gui, error) {
+	g, err := gocui.NewGui(gocui.OutputNormal)
+	if err != nil {
+		return nil, err
+	}
+	// Without UTF-8, or where box-drawing lines are drawn wide, the borders are ASCII characters (see termcaps.go).
+	g.ASCII = terminalCaps.NoUTF8 || terminalCaps.WideAmbiguous
+	activeGui = g
+	closeGuiOnce = sync.Once{}
+	return g, nil
+}
+
+// closeGUI closes the active terminal UI, restoring the terminal's normal mode, cursor, and mouse reporting.
+func closeGUI() {
+	closeGuiOnce.Do(func() {
+		if activeGui != nil {
+			disableFocusEvents()
+			activeGui.Close()
+			activeGui = nil
+		}
+	})
+}
+
+/**
+ * This function recovers a panic on the GUI goroutine. It must be deferred directly.
+ * The terminal is restored first, so the message and the log entry are readable.
+ *
+ * @param err - Receives the panic as an error; if nil, the panic is printed instead.
+ */
+func recoverGUI(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	closeGUI()
+	logger.Error("panic", "panic", r, "stack", string(debug.Stack()))
+	perr := fmt.Errorf("internal error: %v (details in %s)", r, getLogPath())
+	if err != nil {
+		*err = perr
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\x1b[31m%v\x1b[0m\n", perr)
+}
+
+/**
+ * This function recovers a panic in a background goroutine. A panic there cannot be
+ * returned to the main loop, so the terminal is restored and kairos exits.
+ * It must be deferred directly at the top of the goroutine.
+ *
+ * @param name - What the goroutine does, for the log.
+ */
+func recoverWorker(name string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	closeGUI()
+	logger.Error("panic", "worker", name, "panic", r, "stack", string(debug.Stack()))
+	fmt.Fprintf(os.Stderr, "\x1b[31minternal error in %s: %v (details in %s)\x1b[0m\n", name, r, getLogPath())
+	closeLogging()
+	os.Exit(exitError)
+}
+
+/**
+ * This function makes SIGTERM and SIGHUP (e.g. `kill` or a closed terminal window) quit
+ * the GUI through its main loop, so that the terminal is restored as on a normal exit.
+ *
+ * @param g - The running GUI.
+ * @returns A function that stops handling the signals.
+ */
+func quitOnSignals(g *gocui.Gui) func() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGTERM, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		defer recoverWorker("signal handler")
+		select {
+		case sig := <-ch:
+			logger.Info("quitting on signal", "signal", sig.String())
+			g.Update(func(g *gocui.Gui) error { return gocui.ErrQuit })
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// consoleGlyphs are the stand-ins for the dashboard's emoji where they cannot be drawn (see consoleSafe).
+var consoleGlyphs = map[rune]rune{
+	'🟢': '●', '🟡': '●', '🔴': '●', '🟠': '●', '🔵': '●', '🟣': '●',
+	'🌞': '☀', '🌙': '☾', '🕌': '☪', '📟': '☎', '⚫': '○', '⏰': '◷', '⌛': '◷', '⏳': '◷',
+}
+
+// asciiGlyphs are the ASCII stand-ins for the dashboard's symbols on terminals without UTF-8.
+var asciiGlyphs = map[rune]rune{
+	'█': '#', '▓': '#', '▒': '+', '░': '.', '▀': '"', '▄': '_', '─': '-', '│': '|', '━': '=',
+	'●': '*', '○': 'o', '◆': '*', '•': '*', '·': '.', '…': '.', '☀': '*', '☾': 'C',
+	'→': '>', '←': '<', '↑': '^', '↓': 'v', '▲': '^', '▼': 'v', '✓': '+', '✗': 'x', '°': 'o',
+	'☪': 'C', '☎': 'T', '◷': 'o',
+}
+
+/**
+ * This function makes dashboard text drawable by the terminal (see termcaps.go). gocui
+ * puts each character in a cell of its own, and termbox skips the cell after a double-width
+ * character, so such characters are followed by a space for it to skip; otherwise they
+ * would swallow the next character and shift the rest of the line. Terminals that draw
+ * emoji one column wide would shift everything after them, and the Windows console, whose
+ * cells hold a single UTF-16 unit, would draw characters outside the Basic Multilingual
+ * Plane (most emoji, and flags) as broken surrogates; there they are replaced with similar
+ * symbols, flags with their two-letter country code, and anything else with "?". Terminals
+ * that draw ambiguous-width symbols (such as ●, ·, and the block digits' █) two columns wide
+ * get ASCII stand-ins for them, and on terminals without UTF-8 every other non-ASCII
+ * character is replaced too. The stand-ins are padded to the original width so columns
+ * stay aligned.
+ *
+ * @param s - The text to draw.
+ * @returns The text to write to the view.
+ */
+func consoleSafe(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	caps := terminalCaps
+	narrowEmoji := caps.NarrowEmoji || runtime.GOOS == "windows"
+	var b strings.Builder
+	for _, r := range s {
+		width := runewidth.RuneWidth(r)
+		// Wide symbols in the BMP, such as ⚫ and ⏰, are emoji too.
+		emoji := r > 0xFFFF || (r >= 0x2300 && r <= 0x2BFF && width == 2)
+		// Letters of ambiguous width, as in Greek and Cyrillic zone names, have no stand-ins.
+		ambiguous := caps.WideAmbiguous && runewidth.IsAmbiguousWidth(r) && !unicode.IsLetter(r)
+		if r < 0x80 || (!(emoji && narrowEmoji) && !ambiguous && !caps.NoUTF8) {
+			b.WriteRune(r)
+			if width == 2 {
+				b.WriteByte(' ')
+			}
+			continue
+		}
+		if caps.NoUTF8 && width == 0 {
+			// Combining marks and variation selectors have nothing to stand in for.
+			continue
+		}
+		sub, ok := consoleGlyphs[r]
+		switch {
+		case ok:
+		case !emoji:
+			sub = r
+		case r >= 0x1F1E6 && r <= 0x1F1FF:
+			// A regional indicator symbol; two of them make a flag.
+			sub = 'A' + (r - 0x1F1E6)
+		default:
+			sub = '?'
+		}
+		if (caps.NoUTF8 && sub >= 0x80) || (caps.WideAmbiguous && runewidth.IsAmbiguousWidth(sub)) {
+			if ascii, ok := asciiGlyphs[sub]; ok {
+				sub = ascii
+			} else {
+				sub = '?'
+			}
+		}
+		b.WriteRune(sub)
+		if pad := width - runewidth.RuneWidth(sub); pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	return b.String()
+}
+
+// isASCII reports whether a string is plain ASCII, which every terminal draws one column per byte.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// titleViewSuffix names the view that draws another view's title (see drawTitle).
+const titleViewSuffix = ".title"
+
+/**
+ * This function sets the title of a framed view. gocui places each character of a title
+ * at its byte offset, so a character longer than one byte (a CJK zone name, an emoji, or
+ * even "·") leaves a gap in the border after it. Such titles are drawn instead by a
+ * frameless view over the border, which puts them one column per cell like any other text.
+ *
+ * @param g - The dashboard.
+ * @param v - The view.
+ * @param title - The title.
+ * @param color - The color of the view's border.
+ * @returns Whether the title view was created, so that the caller can restack the views.
+ */
+func drawTitle(g *gocui.Gui, v *gocui.View, title string, color gocui.Attribute) (bool, error) {
+	name := v.Name() + titleViewSuffix
+	x0, y0, x1, _, err := g.ViewPosition(v.Name())
+	if err != nil {
+		return false, err
+	}
+	// The title ends two columns before the corner, like gocui's.
+	title = runewidth.Truncate(title, max(x1-x0-3, 0), "")
+	safe := consoleSafe(title)
+	if isASCII(safe) {
+		v.Title = safe
+		if err := g.DeleteView(name); err != nil && err != gocui.ErrUnknownView {
+			return false, err
+		}
+		return false, nil
+	}
+	v.Title = ""
+	tv, err := g.SetView(name, x0+1, y0-1, x0+2+runewidth.StringWidth(title), y0+1)
+	created := err == gocui.ErrUnknownView
+	if err != nil && !created {
+		return false, err
+	}
+	if created {
+		tv.Frame = false
+		tv.BgColor = gocui.ColorDefault
+	}
+	tv.FgColor = color
+	tv.Clear()
+	fmt.Fprint(tv, safe)
+	return created, nil
+}
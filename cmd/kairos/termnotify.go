@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/iamstoick/kairos/render"
+)
+
+// Values of the terminal_notify setting: the escape sequences a terminal notification is sent with.
+var terminalNotifyStyles = []string{"auto", "osc9", "iterm2", "kitty", "osc777"}
+
+/**
+ * This function picks the escape sequences for terminal notifications: the
+ * terminal_notify setting, or with auto the terminal's own, recognized from the
+ * environment. LC_TERMINAL is usually passed on by ssh, so iTerm2 is recognized on a
+ * server too; anything else gets OSC 9, which most terminals that show notifications know.
+ *
+ * @returns One of terminalNotifyStyles other than auto.
+ */
+func terminalNotifyStyle() string {
+	if style := defaultString(settings.TerminalNotify, "auto"); style != "auto" {
+		return style
+	}
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("TERM") == "xterm-kitty":
+		return "kitty"
+	case os.Getenv("LC_TERMINAL") == "iTerm2" || os.Getenv("TERM_PROGRAM") == "iTerm.app":
+		return "iterm2"
+	}
+	return "osc9"
+}
+
+// terminalNotifyText makes a message safe inside an escape sequence: no colors or control characters.
+func terminalNotifyText(msg string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, render.StripANSI(msg))
+}
+
+/**
+ * This function shows a notification through the terminal emulator, with the escape
+ * sequences of OSC 9, iTerm2 (OSC 9 and a dock bounce), kitty (OSC 99), or urxvt and foot
+ * (OSC 777). The terminal shows it on the desktop it runs on, so a dashboard on a server
+ * reached over SSH notifies without a notification helper on the server.
+ *
+ * @param title - The notification title.
+ * @param msg - The notification text.
+ * @returns An error if the sequence could not be written.
+ */
+func terminalNotify(title, msg string) error {
+	title, msg = terminalNotifyText(title), terminalNotifyText(msg)
+	switch terminalNotifyStyle() {
+	case "kitty":
+		return writeTerminalSequence("\x1b]99;i=kairos:d=0:p=title;" + title + "\x1b\\" +
+			"\x1b]99;i=kairos:d=1:p=body;" + msg + "\x1b\\")
+	case "iterm2":
+		return writeTerminalSequence("\x1b]9;" + title + ": " + msg + "\x07\x1b]1337;RequestAttention=yes\x07")
+	case "osc777":
+		return writeTerminalSequence("\x1b]777;notify;" + strings.ReplaceAll(title, ";", ",") + ";" + msg + "\x07")
+	}
+	return writeTerminalSequence("\x1b]9;" + title + ": " + msg + "\x07")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// textListen is set by `kairos daemon --text` and serves the `kairos now` table over plain TCP.
+var textListen string
+
+// textRequestWait is how long the text service waits for a client to name a zone before sending every zone.
+const textRequestWait = 500 * time.Millisecond
+
+/**
+ * This function starts the text service, a daytime-protocol-style endpoint for embedded
+ * devices and quick checks with nc or telnet: a client that connects gets the `kairos now`
+ * table without colors and is disconnected. A client that sends a line first gets only
+ * that zone, looked up by name or as a location such as "Europe/Paris". It needs no
+ * token, since it only shows what the table shows.
+ *
+ * @param addr - The address to listen on, e.g. ":7421".
+ * @param run - Runs a request's handler under the daemon's lock.
+ * @returns A function that stops listening, or an error.
+ */
+func startTextService(addr string, run func(func())) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("text service listening", "addr", listener.Addr().String())
+	go func() {
+		defer recoverWorker("text service")
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveText(run, conn)
+		}
+	}()
+	return func() { listener.Close() }, nil
+}
+
+// serveText answers one text service connection and closes it.
+func serveText(run func(func()), conn net.Conn) {
+	defer recoverWorker("text connection")
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(textRequestWait))
+	request, _ := bufio.NewReader(conn).ReadString('\n')
+	request = strings.TrimSpace(request)
+	logger.Debug("text client connected", "remote", conn.RemoteAddr().String(), "zone", request)
+
+	var lines []string
+	run(func() {
+		now := appClock.Now(time.UTC)
+		if request == "" {
+			lines = nowTable(now, false)
+			return
+		}
+		tz, _, err := findZone(request)
+		if err != nil {
+			if _, name, err := resolveZone(request); err == nil {
+				tz = TimezoneConfig{Name: name, Location: name}
+			} else {
+				lines = []string{err.Error()}
+				return
+			}
+		}
+		lines = []string{nowRow(tz, now, false, false)}
+	})
+	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprint(conn, strings.Join(lines, "\r\n")+"\r\n")
+}
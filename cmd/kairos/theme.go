@@ -0,0 +1,188 @@
+package main
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/iamstoick/kairos/workhours"
+	"github.com/jroimartin/gocui"
+)
+
+// theme is a color scheme for the dashboard.
+type theme struct {
+	Name string
+	// Frame colors the view borders and titles, Text the views' plain text, Footer the help footer,
+	// and Focus the border of the focused view.
+	Frame, Text, Footer, Focus gocui.Attribute
+	// Recolor, if set, rewrites the ANSI colors embedded in rendered lines.
+	Recolor func(line string) string
+}
+
+// ansiForeground matches the foreground color and bold codes that views embed in their lines.
+var ansiForeground = regexp.MustCompile(`\x1b\[(3[0-7]|1)m`)
+
+var (
+	// themes lists every theme, selected with `kairos config set theme NAME`.
+	themes = []theme{
+		{Name: "default", Frame: gocui.ColorDefault, Text: gocui.ColorDefault, Footer: gocui.ColorCyan, Focus: gocui.ColorGreen},
+		{
+			// night is dim and red-shifted, for dark rooms.
+			Name: "night", Frame: gocui.ColorRed, Text: gocui.ColorRed, Footer: gocui.ColorRed, Focus: gocui.ColorMagenta,
+			Recolor: func(line string) string { return ansiForeground.ReplaceAllString(line, "\x1b[31m") },
+		},
+		{
+			// light swaps the colors that wash out on a white background for darker ones.
+			Name: "light", Frame: gocui.ColorDefault, Text: gocui.ColorDefault, Footer: gocui.ColorBlue, Focus: gocui.ColorBlue,
+			Recolor: recolorWith(lightColors, ""),
+		},
+		{
+			// colorblind shows good, warning, and bad (normally green, yellow, and red) as blue,
+			// cyan, and bold yellow, which stay apart with deuteranopia and protanopia.
+			Name: "colorblind", Frame: gocui.ColorDefault, Text: gocui.ColorDefault, Footer: gocui.ColorCyan, Focus: gocui.ColorBlue,
+			Recolor: recolorWith(colorblindColors, ""),
+		},
+		{
+			// high-contrast draws everything in bold, with cyan for the dim blue.
+			Name: "high-contrast", Frame: gocui.ColorWhite | gocui.AttrBold, Text: gocui.ColorDefault | gocui.AttrBold,
+			Footer: gocui.ColorWhite | gocui.AttrBold, Focus: gocui.ColorYellow | gocui.AttrBold,
+			Recolor: recolorWith(map[string]string{"\x1b[34m": "\x1b[36m"}, "\x1b[1m"),
+		},
+	}
+	// nightTheme is the theme switched to during the night_dim hours.
+	nightTheme = themes[1]
+	// lightTheme is the default theme on a light terminal background (see background.go).
+	lightTheme = themes[2]
+	// monoTheme draws without colors, keeping bold, on terminals that have none (see termcaps.go).
+	monoTheme = theme{
+		Name: "mono", Frame: gocui.ColorDefault, Text: gocui.ColorDefault, Footer: gocui.ColorDefault | gocui.AttrBold,
+		Focus: gocui.ColorDefault | gocui.AttrBold, Recolor: recolorWith(monoColors, ""),
+	}
+)
+
+var (
+	// lightColors maps the colors that are hard to read on a light background to legible ones.
+	lightColors = map[string]string{
+		"\x1b[33m": "\x1b[35m", // yellow to magenta
+		"\x1b[36m": "\x1b[34m", // cyan to blue
+		"\x1b[37m": "\x1b[30m", // white to black
+	}
+	// colorblindColors maps the status colors to ones told apart without red-green vision.
+	colorblindColors = map[string]string{
+		"\x1b[32m": "\x1b[34m",        // good: green to blue
+		"\x1b[33m": "\x1b[36m",        // warning: yellow to cyan
+		"\x1b[31m": "\x1b[33m\x1b[1m", // bad: red to bold yellow
+	}
+	// monoColors drops every foreground color.
+	monoColors = map[string]string{
+		"\x1b[30m": "\x1b[0m", "\x1b[31m": "\x1b[0m", "\x1b[32m": "\x1b[0m", "\x1b[33m": "\x1b[0m",
+		"\x1b[34m": "\x1b[0m", "\x1b[35m": "\x1b[0m", "\x1b[36m": "\x1b[0m", "\x1b[37m": "\x1b[0m",
+	}
+)
+
+/**
+ * This function builds a theme's Recolor from a table of color codes.
+ *
+ * @param colors - Replacements for the foreground codes; others are kept.
+ * @param suffix - Codes added after every color, e.g. bold. gocui resets bold with each
+ *                 color code, so it has to follow the color.
+ * @returns The Recolor function.
+ */
+func recolorWith(colors map[string]string, suffix string) func(line string) string {
+	return func(line string) string {
+		return ansiForeground.ReplaceAllStringFunc(line, func(code string) string {
+			if code == "\x1b[1m" {
+				return code
+			}
+			return defaultString(colors[code], code) + suffix
+		})
+	}
+}
+
+// themeNames returns the names of all themes, for validation and help.
+func themeNames() []string {
+	var names []string
+	for _, t := range themes {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+/**
+ * This function selects the theme to draw with: the night theme while the primary zone's
+ * local time is within the night_dim hours, and otherwise the configured theme. The
+ * default theme uses the light theme's colors on a light terminal background. Terminals
+ * without colors always get the mono theme, and it is dimmed while the terminal is not
+ * focused (see termfocus.go).
+ *
+ * @param now - The current time in the primary zone.
+ * @returns The active theme.
+ */
+func activeTheme(now time.Time) theme {
+	if dimUnfocused() {
+		return selectTheme(now).dimmed()
+	}
+	return selectTheme(now)
+}
+
+// selectTheme returns the theme to draw with, before dimming.
+func selectTheme(now time.Time) theme {
+	if terminalCaps.Colors == "none" {
+		return monoTheme
+	}
+	if settings.NightDim != "" {
+		if h, err := workhours.Parse(settings.NightDim); err == nil && h.CoversClock(now) {
+			return nightTheme
+		}
+	}
+	for _, t := range themes {
+		if t.Name == settings.Theme {
+			return t
+		}
+	}
+	if backgroundIsLight() {
+		return lightTheme
+	}
+	return themes[0]
+}
+
+// setNightDim validates and stores the night_dim hours ("off" disables them).
+func setNightDim(v string) error {
+	if v == "off" || v == "none" || v == "" {
+		settings.NightDim = ""
+		return nil
+	}
+	h, err := workhours.Parse(v)
+	if err != nil {
+		return err
+	}
+	settings.NightDim = h.String()
+	return nil
+}
+
+// dimmed returns the theme drawn faint and without colors: gocui ends the faint attribute at
+// every color code in a line, so the codes are reset to the view's own, faint color.
+func (t theme) dimmed() theme {
+	t.Frame, t.Text, t.Footer, t.Focus = render.AttrDim, render.AttrDim, render.AttrDim, render.AttrDim
+	t.Recolor = func(line string) string { return ansiForeground.ReplaceAllString(line, "\x1b[0m") }
+	return t
+}
+
+// apply colors rendered views with the theme.
+func (t theme) apply(frames []viewFrame) []viewFrame {
+	for i := range frames {
+		frames[i].FrameColor, frames[i].FgColor = t.Frame, t.Text
+		if frames[i].Name == "help" {
+			frames[i].FgColor = t.Footer
+		}
+		if frames[i].Focused {
+			frames[i].FrameColor = t.Focus
+		}
+		if t.Recolor != nil {
+			for j, line := range frames[i].Lines {
+				frames[i].Lines[j] = t.Recolor(line)
+			}
+		}
+	}
+	return frames
+}
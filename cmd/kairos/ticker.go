@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tickerRefresh is how often the {ticker} footer widget fetches prices.
+const tickerRefresh = 5 * time.Minute
+
+// quote is a symbol's price and its change over the day, in percent.
+type quote struct {
+	Symbol string
+	Price  float64
+	Change float64
+}
+
+// tickerSources are the market data sources the {ticker} footer widget can use: Finnhub for stocks, CoinGecko for crypto.
+var tickerSources = []string{"finnhub", "coingecko"}
+
+var (
+	// tickerMu guards tickerText, which the ticker worker writes and the footer reads.
+	tickerMu sync.Mutex
+	// tickerText is the {ticker} footer widget's text.
+	tickerText string
+)
+
+// tickerStatus returns the {ticker} footer widget's text.
+func tickerStatus(time.Time) string {
+	tickerMu.Lock()
+	defer tickerMu.Unlock()
+	return tickerText
+}
+
+/**
+ * This function starts the ticker worker when the footer shows {ticker} and symbols are
+ * configured. It refreshes the prices every five minutes in the background; a failed
+ * refresh keeps the last prices, marked as stale.
+ */
+func startTickerWorker() {
+	if settings.TickerSource == "" || len(settings.TickerSymbols) == 0 || !strings.Contains(footerTemplate(), "{ticker}") {
+		return
+	}
+	symbols := append([]string(nil), settings.TickerSymbols...)
+	goWorker("ticker worker", func(ctx context.Context) {
+		lastErr := ""
+		for {
+			quotes, err := fetchQuotes(symbols)
+			tickerMu.Lock()
+			if err != nil {
+				if tickerText == "" {
+					tickerText = "\x1b[33mprices unavailable\x1b[0m"
+				} else if !strings.HasSuffix(tickerText, "(stale)") {
+					tickerText += " (stale)"
+				}
+				if err.Error() != lastErr {
+					logger.Warn("ticker refresh failed", "source", settings.TickerSource, "err", err)
+				}
+				lastErr = err.Error()
+			} else {
+				tickerText = formatQuotes(quotes)
+				lastErr = ""
+			}
+			tickerMu.Unlock()
+			if !sleepCtx(ctx, tickerRefresh) {
+				return
+			}
+		}
+	})
+}
+
+// fetchQuotes fetches the symbols' quotes from the configured ticker source.
+func fetchQuotes(symbols []string) ([]quote, error) {
+	switch settings.TickerSource {
+	case "finnhub":
+		return fetchFinnhubQuotes(symbols)
+	case "coingecko":
+		return fetchCoinGeckoQuotes(symbols)
+	}
+	return nil, fmt.Errorf("no ticker source is configured")
+}
+
+// formatQuotes formats quotes for the footer, e.g. "AAPL 189.20 ▲1.2%", with gains in green and losses in red.
+func formatQuotes(quotes []quote) string {
+	var parts []string
+	for _, q := range quotes {
+		change := fmt.Sprintf("\x1b[32m▲%.1f%%\x1b[0m", q.Change)
+		if q.Change < 0 {
+			change = fmt.Sprintf("\x1b[31m▼%.1f%%\x1b[0m", -q.Change)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", q.Symbol, formatPrice(q.Price), change))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatPrice formats a price with two decimals below 10,000 and as a whole number above, e.g. 67412.
+func formatPrice(p float64) string {
+	if p >= 10000 {
+		return strconv.FormatFloat(p, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(p, 'f', 2, 64)
+}
+
+// getTickerJSON fetches a market data endpoint and decodes its JSON response into out.
+func getTickerJSON(endpoint string, header http.Header, out interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// The URL can hold the API key, which should not end up in the log.
+		if uerr, ok := err.(*url.Error); ok {
+			return uerr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s rejected the request: %s %s", settings.TickerSource, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+/**
+ * This function fetches stock quotes from Finnhub, one request per symbol, which needs
+ * the ticker_token API key.
+ *
+ * @param symbols - The stock symbols, e.g. "AAPL".
+ * @returns The quotes, or an error.
+ */
+func fetchFinnhubQuotes(symbols []string) ([]quote, error) {
+	if settings.TickerToken == "" {
+		return nil, fmt.Errorf("set ticker_token to a Finnhub API key")
+	}
+	header := http.Header{"X-Finnhub-Token": {settings.TickerToken}}
+	var quotes []quote
+	for _, symbol := range symbols {
+		var body struct {
+			Current       float64  `json:"c"`
+			PercentChange *float64 `json:"dp"`
+		}
+		if err := getTickerJSON("https://finnhub.io/api/v1/quote?symbol="+url.QueryEscape(symbol), header, &body); err != nil {
+			return nil, err
+		}
+		// Unknown symbols come back as zeros rather than as an error.
+		if body.Current == 0 || body.PercentChange == nil {
+			return nil, fmt.Errorf("finnhub has no quote for %s", symbol)
+		}
+		quotes = append(quotes, quote{Symbol: symbol, Price: body.Current, Change: *body.PercentChange})
+	}
+	return quotes, nil
+}
+
+/**
+ * This function fetches crypto prices in US dollars from CoinGecko in one request. The
+ * symbols are CoinGecko coin ids (e.g. "bitcoin"); ticker_token, if set, is sent as a
+ * demo API key.
+ *
+ * @param symbols - The coin ids.
+ * @returns The quotes, with the 24-hour change, or an error.
+ */
+func fetchCoinGeckoQuotes(symbols []string) ([]quote, error) {
+	header := http.Header{}
+	if settings.TickerToken != "" {
+		header.Set("x-cg-demo-api-key", settings.TickerToken)
+	}
+	var body map[string]struct {
+		USD       float64 `json:"usd"`
+		USDChange float64 `json:"usd_24h_change"`
+	}
+	endpoint := "https://api.coingecko.com/api/v3/simple/price?vs_currencies=usd&include_24hr_change=true&ids=" +
+		url.QueryEscape(strings.Join(symbols, ","))
+	if err := getTickerJSON(endpoint, header, &body); err != nil {
+		return nil, err
+	}
+	var quotes []quote
+	for _, symbol := range symbols {
+		price, ok := body[symbol]
+		if !ok {
+			return nil, fmt.Errorf("coingecko has no coin with the id %s", symbol)
+		}
+		quotes = append(quotes, quote{Symbol: strings.ToUpper(symbol), Price: price.USD, Change: price.USDChange})
+	}
+	return quotes, nil
+}
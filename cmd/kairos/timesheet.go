@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timesheetServices lists the services that completed sessions can be pushed to.
+var timesheetServices = []string{"toggl", "clockify"}
+
+// timesheetClient is used for every timesheet API call.
+var timesheetClient = &http.Client{Timeout: 15 * time.Second}
+
+/**
+ * This function creates a time entry for a completed session in the configured
+ * timesheet service (Toggl Track or Clockify).
+ *
+ * @param s - The completed session.
+ * @returns An error if the service is not configured or rejects the entry.
+ */
+func pushSession(s Session) error {
+	if settings.TimesheetToken == "" || settings.TimesheetWorkspace == "" {
+		return fmt.Errorf("set timesheet_token and timesheet_workspace to push sessions to %s", settings.Timesheet)
+	}
+	description := s.Name
+	if s.Zone != "" {
+		description = fmt.Sprintf("%s (%s)", s.Name, s.Zone)
+	}
+	var url string
+	var body map[string]interface{}
+	header := http.Header{"Content-Type": {"application/json"}}
+	switch settings.Timesheet {
+	case "toggl":
+		workspace, err := strconv.Atoi(settings.TimesheetWorkspace)
+		if err != nil {
+			return fmt.Errorf("invalid Toggl workspace id '%s' (expected a number)", settings.TimesheetWorkspace)
+		}
+		url = fmt.Sprintf("https://api.track.toggl.com/api/v9/workspaces/%d/time_entries", workspace)
+		body = map[string]interface{}{
+			"description": description, "workspace_id": workspace, "created_with": "kairos",
+			"start": s.Start.UTC().Format(time.RFC3339), "stop": s.End.UTC().Format(time.RFC3339),
+			"duration": int(s.End.Sub(s.Start).Seconds()),
+		}
+		header.Set("Authorization", "Basic "+basicAuth(settings.TimesheetToken, "api_token"))
+	case "clockify":
+		url = fmt.Sprintf("https://api.clockify.me/api/v1/workspaces/%s/time-entries", settings.TimesheetWorkspace)
+		body = map[string]interface{}{
+			"description": description,
+			"start":       s.Start.UTC().Format(time.RFC3339), "end": s.End.UTC().Format(time.RFC3339),
+		}
+		header.Set("X-Api-Key", settings.TimesheetToken)
+	default:
+		return fmt.Errorf("no timesheet service is configured")
+	}
+
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	resp, err := timesheetClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s rejected the entry: %s %s", settings.Timesheet, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+/**
+ * This function pushes every completed session that has not been pushed yet and marks
+ * it as pushed. It works on its own copy of the sessions file, so the dashboard can run
+ * it in the background.
+ *
+ * @returns The number of sessions pushed, and the first error (pushing stops there).
+ */
+func pushPendingSessions() (int, error) {
+	if settings.Timesheet == "" {
+		return 0, fmt.Errorf("no timesheet service is configured; run kairos config set timesheet toggl|clockify")
+	}
+	sessionsMu.Lock()
+	list, err := readSessions()
+	sessionsMu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	var pushed []time.Time
+	var pushErr error
+	for _, s := range list {
+		if s.End == nil || s.Pushed {
+			continue
+		}
+		if pushErr = pushSession(s); pushErr != nil {
+			break
+		}
+		pushed = append(pushed, s.Start)
+	}
+	if len(pushed) == 0 {
+		return 0, pushErr
+	}
+
+	// The file is read again so that sessions started or stopped during the push are kept.
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	list, err = readSessions()
+	if err != nil {
+		return 0, err
+	}
+	for i := range list {
+		for _, start := range pushed {
+			if list[i].Start.Equal(start) {
+				list[i].Pushed = true
+			}
+		}
+	}
+	if err := writeSessions(list); err != nil {
+		return 0, err
+	}
+	logger.Info("sessions pushed", "service", settings.Timesheet, "count", len(pushed))
+	return len(pushed), pushErr
+}
+
+// runPush handles `kairos track push`.
+func runPush() error {
+	n, err := pushPendingSessions()
+	if n > 0 {
+		fmt.Printf("Pushed %d session(s) to %s\n", n, settings.Timesheet)
+	}
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		fmt.Println("Nothing to push; every completed session is already in " + settings.Timesheet)
+	}
+	return nil
+}
+
+// basicAuth encodes HTTP basic credentials.
+func basicAuth(user, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+}
+
+// configFileMode returns the permissions for the config file and its backups, which are
+// private once they hold an API token.
+func configFileMode() os.FileMode {
+	if holdsToken() {
+		return 0600
+	}
+	return 0644
+}
+
+// holdsToken reports whether the settings hold a secret: an API or server token, the Google credentials, or the MQTT password.
+func holdsToken() bool {
+	for _, s := range secretSettings {
+		if *s.Field(&settings) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// maskToken hides all but the last four characters of an API token.
+func maskToken(token string) string {
+	if token == "" {
+		return "none"
+	}
+	if len(token) <= 4 {
+		return "****"
+	}
+	return "****" + token[len(token)-4:]
+}
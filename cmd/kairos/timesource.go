@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// Clock tells the current time. The dashboard, alarms, and commands read the time only
+// through appClock, so that tests and a time-travel view can run against a fake clock.
+type Clock interface {
+	// Now returns the current instant in loc.
+	Now(loc *time.Location) time.Time
+}
+
+// systemClock is the real wall clock.
+type systemClock struct{}
+
+func (systemClock) Now(loc *time.Location) time.Time { return time.Now().In(loc) }
+
+// fixedClock is a fake clock that is frozen at T until it is moved with Set or Advance.
+type fixedClock struct {
+	T time.Time
+}
+
+func (c *fixedClock) Now(loc *time.Location) time.Time { return c.T.In(loc) }
+
+// Set moves the clock to t.
+func (c *fixedClock) Set(t time.Time) { c.T = t }
+
+// Advance moves the clock forward by d.
+func (c *fixedClock) Advance(d time.Duration) { c.T = c.T.Add(d) }
+
+// appClock is the clock used throughout kairos.
+var appClock Clock = systemClock{}
+
+// untilNow returns how long until t according to appClock; negative for past instants.
+func untilNow(t time.Time) time.Duration {
+	return t.Sub(appClock.Now(time.UTC))
+}
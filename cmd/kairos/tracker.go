@@ -0,0 +1,437 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/workhours"
+	"github.com/jroimartin/gocui"
+)
+
+// Session is one tracked stretch of work on a project.
+type Session struct {
+	Name string `json:"name"`
+	// Zone is the primary timezone when the session started; Start and End are in its local time.
+	Zone  string     `json:"zone"`
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"` // Nil while the session is running.
+	// Pushed is set once the session has been sent to the timesheet service (see timesheet.go).
+	Pushed bool `json:"pushed,omitempty"`
+}
+
+var (
+	// sessions holds the tracked sessions, oldest first; see loadSessions.
+	sessions []Session
+	// sessionsMu guards the sessions file while it is read, changed, and written.
+	sessionsMu sync.Mutex
+	// trackPromptOpen is set while the dashboard asks for the name of a new session.
+	trackPromptOpen bool
+	// trackOut is set by the --out flag of `kairos track export`.
+	trackOut string
+)
+
+// trackCommand builds the `kairos track` command group.
+func trackCommand() *command {
+	return &command{
+		Name:  "track",
+		Short: "Tracks time spent on projects (also the t key in the dashboard)",
+		Subcommands: []*command{
+			{Name: "start", Usage: `"Name"`, MinArgs: 1, MaxArgs: 1, Short: "Starts a session, stopping the running one",
+				Run: func(args []string) error {
+					return runTrack(func(now time.Time) (string, error) { return startSession(args[0], now) })
+				}},
+			{Name: "stop", Short: "Stops the running session (and pushes it to the timesheet service, if set)",
+				Run: func(args []string) error {
+					if err := runTrack(stopSession); err != nil || settings.Timesheet == "" {
+						return err
+					}
+					return runPush()
+				}},
+			{Name: "push", Short: "Pushes completed sessions to Toggl or Clockify (see kairos config)",
+				Run: func(args []string) error { return runPush() }},
+			{Name: "list", Short: "Shows the total time per project (--json for machine-readable output)",
+				Run: func(args []string) error { return listSessions() }},
+			{Name: "export", Short: "Exports every session as CSV (--out file.csv)",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&trackOut, "out", "", "Write the CSV to this file instead of stdout")
+				},
+				Run: func(args []string) error { return exportSessions(trackOut) }},
+		},
+	}
+}
+
+// getSessionsPath returns the file the sessions are kept in, next to (and named after) the config file.
+func getSessionsPath() string {
+	return strings.TrimSuffix(getConfigPath(), filepath.Ext(getConfigPath())) + "_sessions.json"
+}
+
+/**
+ * This function reads the tracked sessions. They are kept apart from the config file so
+ * that starting and stopping sessions from the dashboard never rewrites the config (or
+ * fills the undo history).
+ *
+ * @returns The sessions, or an error if the file exists but cannot be read or parsed.
+ */
+func readSessions() ([]Session, error) {
+	data, err := os.ReadFile(getSessionsPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Session
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", getSessionsPath(), err)
+	}
+	return list, nil
+}
+
+// writeSessions writes the tracked sessions.
+func writeSessions(list []Session) error {
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(getSessionsPath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to save the sessions: %v", err)
+	}
+	return nil
+}
+
+// loadSessions reads the tracked sessions into sessions.
+func loadSessions() error {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	list, err := readSessions()
+	if err != nil {
+		return err
+	}
+	sessions = list
+	return nil
+}
+
+/**
+ * This function applies a session change on the latest sessions file and saves it. The
+ * file is reloaded first so that a dashboard and the CLI can be used side by side.
+ *
+ * @param change - Starts or stops a session at the given time and returns a message.
+ * @returns An error if the sessions cannot be loaded, changed, or saved.
+ */
+func changeSessions(change func(now time.Time) (string, error)) (string, error) {
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	list, err := readSessions()
+	if err != nil {
+		return "", err
+	}
+	sessions = list
+	msg, err := change(appClock.Now(time.UTC))
+	if err != nil {
+		return "", err
+	}
+	if err := writeSessions(sessions); err != nil {
+		return "", err
+	}
+	logger.Debug("sessions saved", "path", getSessionsPath(), "count", len(sessions))
+	return msg, nil
+}
+
+// runTrack handles `kairos track start` and `kairos track stop`.
+func runTrack(change func(now time.Time) (string, error)) error {
+	msg, err := changeSessions(change)
+	if err != nil {
+		return err
+	}
+	fmt.Println(msg)
+	return nil
+}
+
+// runningSession returns the session that has not been stopped, if any.
+func runningSession() *Session {
+	if n := len(sessions); n > 0 && sessions[n-1].End == nil {
+		return &sessions[n-1]
+	}
+	return nil
+}
+
+/**
+ * This function starts a session in the primary zone, stopping the running one first.
+ *
+ * @param name - The project name.
+ * @param now - The start time.
+ * @returns A message describing the change, or an error if the name is empty.
+ */
+func startSession(name string, now time.Time) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("a session needs a name")
+	}
+	prefix := ""
+	if running := runningSession(); running != nil {
+		msg, _ := stopSession(now)
+		prefix = msg + "; "
+	}
+	zone := "UTC"
+	loc := time.UTC
+	if len(timezones) > 0 {
+		primary := displayZones()[0]
+		if l, err := tzutil.LoadLocation(primary.Location); err == nil {
+			zone, loc = primary.Name, l
+		}
+	}
+	sessions = append(sessions, Session{Name: name, Zone: zone, Start: now.In(loc).Truncate(time.Second)})
+	return fmt.Sprintf("%sStarted %s at %s (%s)", prefix, name, now.In(loc).Format("15:04"), zone), nil
+}
+
+// stopSession stops the running session.
+func stopSession(now time.Time) (string, error) {
+	running := runningSession()
+	if running == nil {
+		return "", fmt.Errorf("no session is running")
+	}
+	end := now.In(running.Start.Location()).Truncate(time.Second)
+	running.End = &end
+	return fmt.Sprintf("Stopped %s after %s", running.Name, formatElapsed(end.Sub(running.Start))), nil
+}
+
+// sessionDuration returns how long a session lasted, or has lasted so far if it is running.
+func sessionDuration(s Session, now time.Time) time.Duration {
+	if s.End != nil {
+		return s.End.Sub(s.Start)
+	}
+	return now.Sub(s.Start)
+}
+
+// formatElapsed formats a duration as H:MM:SS, like a stopwatch.
+func formatElapsed(d time.Duration) string {
+	s := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s%3600/60, s%60)
+}
+
+// trackerStatus returns the running session's timer for the dashboard footer, or "".
+func trackerStatus(now time.Time) string {
+	running := runningSession()
+	if running == nil {
+		return ""
+	}
+	return fmt.Sprintf("⏱ %s %s", running.Name, formatElapsed(sessionDuration(*running, now)))
+}
+
+/**
+ * This function handles `kairos track list`: the total time per project and zone, with
+ * the running session marked.
+ *
+ * @returns An error if the sessions cannot be loaded.
+ */
+func listSessions() error {
+	if err := loadSessions(); err != nil {
+		return err
+	}
+	now := appClock.Now(time.UTC)
+	type total struct {
+		Name     string `json:"name"`
+		Zone     string `json:"zone"`
+		Sessions int    `json:"sessions"`
+		Seconds  int64  `json:"seconds"`
+		Running  bool   `json:"running"`
+	}
+	var totals []*total
+	byKey := map[string]*total{}
+	for _, s := range sessions {
+		key := s.Name + "\x00" + s.Zone
+		t, ok := byKey[key]
+		if !ok {
+			t = &total{Name: s.Name, Zone: s.Zone}
+			byKey[key] = t
+			totals = append(totals, t)
+		}
+		t.Sessions++
+		t.Seconds += int64(sessionDuration(s, now).Seconds())
+		t.Running = t.Running || s.End == nil
+	}
+	sort.SliceStable(totals, func(i, j int) bool { return totals[i].Seconds > totals[j].Seconds })
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if totals == nil {
+			totals = []*total{}
+		}
+		return enc.Encode(totals)
+	}
+	if len(totals) == 0 {
+		fmt.Println("No sessions tracked yet. Use 'kairos track start \"Name\"' or press t in the dashboard.")
+		return nil
+	}
+	fmt.Println("\n\x1b[36m\x1b[1mTRACKED TIME\x1b[0m")
+	for _, t := range totals {
+		mark := ""
+		if t.Running {
+			mark = " \x1b[32m● running\x1b[0m"
+		}
+		fmt.Printf("  %-20s %-15s %9s  %d session(s)%s\n", t.Name, t.Zone,
+			workhours.FormatCountdown(time.Duration(t.Seconds)*time.Second), t.Sessions, mark)
+	}
+	fmt.Println()
+	return nil
+}
+
+/**
+ * This function handles `kairos track export`: every session as CSV with the name, zone,
+ * local start and end (RFC 3339), and length in minutes. A running session has an empty end.
+ *
+ * @param out - The file to write, or "" for stdout.
+ * @returns An error if the sessions cannot be loaded or the CSV cannot be written.
+ */
+func exportSessions(out string) error {
+	if err := loadSessions(); err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	now := appClock.Now(time.UTC)
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "zone", "start", "end", "minutes"})
+	for _, s := range sessions {
+		end := ""
+		if s.End != nil {
+			end = s.End.Format(time.RFC3339)
+		}
+		minutes := strconv.FormatFloat(sessionDuration(s, now).Minutes(), 'f', 1, 64)
+		cw.Write([]string{s.Name, s.Zone, s.Start.Format(time.RFC3339), end, minutes})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	if out != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d session(s) to %s\n", len(sessions), out)
+	}
+	return nil
+}
+
+/**
+ * This function starts or stops a session from the dashboard (the "t" key): a running
+ * session is stopped (and pushed to the timesheet service in the background, if one is
+ * set), and otherwise a prompt asks for the name of a new one.
+ *
+ * @param g - The dashboard's gocui.Gui.
+ */
+func toggleSession(g *gocui.Gui) {
+	if err := loadSessions(); err != nil {
+		showWarning("Tracking failed: " + err.Error())
+		return
+	}
+	if runningSession() == nil {
+		trackPromptOpen = true
+		return
+	}
+	msg, err := changeSessions(stopSession)
+	if err != nil {
+		showWarning("Tracking failed: " + err.Error())
+		return
+	}
+	showNotification(msg)
+	if settings.Timesheet == "" {
+		return
+	}
+	go func() {
+		defer recoverWorker("timesheet push")
+		n, err := pushPendingSessions()
+		g.Update(func(g *gocui.Gui) error {
+			if err != nil {
+				logger.Warn("timesheet push failed", "service", settings.Timesheet, "err", err)
+				showWarning("Push to " + settings.Timesheet + " failed: " + err.Error())
+			} else if n > 0 {
+				showNotification(fmt.Sprintf("Pushed %d session(s) to %s", n, settings.Timesheet))
+			}
+			return nil
+		})
+	}()
+}
+
+/**
+ * This function shows the session name prompt while it is open. It is called from the
+ * dashboard layout after the other views are drawn, so the prompt stays on top.
+ *
+ * @param g - The dashboard's gocui.Gui.
+ * @returns An error if the prompt cannot be created.
+ */
+func layoutTrackPrompt(g *gocui.Gui) error {
+	if !trackPromptOpen {
+		return nil
+	}
+	maxX, maxY := g.Size()
+	width := min(50, maxX-2)
+	x0, y0 := (maxX-width)/2, maxY/2-1
+	v, err := g.SetView("track", x0, y0, x0+width, y0+2)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = " Track a session (Enter starts, Esc cancels) "
+		v.Editable = true
+		v.Editor = gocui.DefaultEditor
+	}
+	g.Cursor = true
+	if _, err := g.SetViewOnTop("track"); err != nil {
+		return err
+	}
+	_, err = g.SetCurrentView("track")
+	return err
+}
+
+// closeTrackPrompt removes the session name prompt.
+func closeTrackPrompt(g *gocui.Gui) {
+	trackPromptOpen = false
+	g.Cursor = false
+	g.DeleteView("track")
+}
+
+// trackPromptBindings sets up Enter and Esc in the session name prompt.
+func trackPromptBindings(g *gocui.Gui) error {
+	if err := g.SetKeybinding("track", gocui.KeyEnter, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
+		name := strings.TrimSpace(v.Buffer())
+		closeTrackPrompt(g)
+		if name == "" {
+			return nil
+		}
+		msg, err := changeSessions(func(now time.Time) (string, error) { return startSession(name, now) })
+		if err != nil {
+			showWarning("Tracking failed: " + err.Error())
+			return nil
+		}
+		showNotification(msg)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return g.SetKeybinding("track", gocui.KeyEsc, gocui.ModNone, onEscape(func(g *gocui.Gui, v *gocui.View) error {
+		closeTrackPrompt(g)
+		return nil
+	}))
+}
+
+// promptActive reports whether v is an open text prompt (the session prompt, the zone palette, or the IANA browser).
+func promptActive(v *gocui.View) bool {
+	return v != nil && v.Editable && (trackPromptOpen || paletteOpen || browserOpen)
+}
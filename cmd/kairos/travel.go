@@ -0,0 +1,245 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+var (
+	// travelMode is toggled with the "j" key; it starts on while a trip is planned.
+	travelMode bool
+	// travelFlight is set by `kairos travel start --flight`.
+	travelFlight string
+)
+
+// travelCommand returns the `kairos travel` command.
+func travelCommand() *command {
+	return &command{
+		Name:  "travel",
+		Short: "Plans a trip: the home and destination zones are pinned side by side on the dashboard, with jet-lag tips (j toggles)",
+		Subcommands: []*command{
+			{Name: "start", Usage: `"Home" "Destination"`, MinArgs: 2, MaxArgs: 2,
+				Short: "Saves the trip, e.g. \"Berlin\" \"Asia/Tokyo\" --flight \"2026-11-03 14:35\"",
+				Flags: func(fs *flag.FlagSet) {
+					fs.StringVar(&travelFlight, "flight", "", "The departure, YYYY-MM-DD HH:MM in the home zone's time, counted down on the dashboard")
+				},
+				Run: func(args []string) error { return startTravel(args[0], args[1], travelFlight) }},
+			{Name: "status", Short: "Shows the trip: both times, the offset, the flight countdown, and jet-lag tips",
+				Run: func(args []string) error { return printTravel(time.Now()) }},
+			{Name: "stop", Short: "Ends the trip and leaves travel mode",
+				Run: func(args []string) error { return stopTravel() }},
+		},
+	}
+}
+
+/**
+ * This function resolves one end of a trip: a configured zone, or any IANA location,
+ * named after its city (e.g. "Tokyo" for Asia/Tokyo).
+ *
+ * @param name - The zone name or location.
+ * @returns The zone and its location, or an error if neither matches.
+ */
+func travelZone(name string) (TimezoneConfig, *time.Location, error) {
+	if tz, loc, err := findZone(name); err == nil {
+		return tz, loc, nil
+	}
+	loc, err := tzutil.LoadLocation(name)
+	if err != nil {
+		return TimezoneConfig{}, nil, fmt.Errorf("'%s' is neither a configured timezone nor a known location", name)
+	}
+	city := strings.ReplaceAll(name[strings.LastIndex(name, "/")+1:], "_", " ")
+	return TimezoneConfig{Name: city, Location: name}, loc, nil
+}
+
+// travelDeparture returns the trip's departure time, if it has a flight.
+func travelDeparture(plan TravelPlan, home *time.Location) (time.Time, bool) {
+	wall, err := time.Parse(eventTimeLayout, plan.Flight)
+	if plan.Flight == "" || err != nil {
+		return time.Time{}, false
+	}
+	at, _ := tzutil.ResolveWallClock(wall, home)
+	return at, true
+}
+
+// startTravel handles `kairos travel start`.
+func startTravel(home, destination, flight string) error {
+	plan := TravelPlan{Home: home, Destination: destination, Flight: strings.TrimSpace(flight)}
+	homeTZ, _, err := travelZone(home)
+	if err != nil {
+		return err
+	}
+	destTZ, _, err := travelZone(destination)
+	if err != nil {
+		return err
+	}
+	if plan.Flight != "" {
+		if _, err := time.Parse(eventTimeLayout, plan.Flight); err != nil {
+			return fmt.Errorf("invalid flight time '%s' (expected YYYY-MM-DD HH:MM in %s's time)", plan.Flight, homeTZ.Name)
+		}
+	}
+	settings.Travel = &plan
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Travel mode: %s to %s. The dashboard pins both at the top (j toggles); end the trip with kairos travel stop.\n", homeTZ.Name, destTZ.Name)
+	return nil
+}
+
+// stopTravel handles `kairos travel stop`.
+func stopTravel() error {
+	if settings.Travel == nil {
+		return fmt.Errorf("no trip is planned")
+	}
+	settings.Travel = nil
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Println("Trip ended; the dashboard is back to normal.")
+	return nil
+}
+
+/**
+ * This function gives the body-clock shift of a trip: the destination's offset from home,
+ * taken the short way around, so a +14h trip is a 10 hour shift west.
+ *
+ * @param home - The home zone's location.
+ * @param dest - The destination's location.
+ * @param now - The current time.
+ * @returns The shift in hours, positive going east.
+ */
+func travelShift(home, dest *time.Location, now time.Time) float64 {
+	_, h := now.In(home).Zone()
+	_, d := now.In(dest).Zone()
+	hours := float64(d-h) / 3600
+	if hours > 12 {
+		hours -= 24
+	} else if hours < -12 {
+		hours += 24
+	}
+	return hours
+}
+
+/**
+ * This function gives jet-lag tips for a shift: how to shift bedtime before leaving, when
+ * to seek daylight after arriving, and about how long the body clock takes to catch up
+ * (about a day per hour going east, and two thirds of that going west).
+ *
+ * @param shift - The body-clock shift in hours, positive going east.
+ * @param departed - Whether the flight has left, so the pre-flight tip no longer applies.
+ * @returns The tips, one per line.
+ */
+func jetLagTips(shift float64, departed bool) []string {
+	abs := math.Abs(shift)
+	if abs < 3 {
+		return []string{"Little jet lag: keep your usual schedule"}
+	}
+	var tips []string
+	days := int(math.Ceil(abs))
+	if shift > 0 {
+		if !departed {
+			tips = append(tips, "Go to bed an hour earlier each night before the flight")
+		}
+		tips = append(tips, "Get morning daylight there, and avoid bright evenings")
+	} else {
+		days = int(math.Ceil(abs * 2 / 3))
+		if !departed {
+			tips = append(tips, "Stay up an hour later each night before the flight")
+		}
+		tips = append(tips, "Get evening daylight there, and avoid bright mornings")
+	}
+	return append(tips, fmt.Sprintf("About %d days to adjust", days))
+}
+
+// flightLine returns the flight countdown, e.g. "✈ Departs in 2d 4h 10m (Tue 14:35)", or "".
+func flightLine(plan TravelPlan, home *time.Location, now time.Time) string {
+	at, ok := travelDeparture(plan, home)
+	if !ok {
+		return ""
+	}
+	if at.After(now) {
+		return fmt.Sprintf("✈ Departs %s (%s)", formatRelative(at.Sub(now)), at.In(home).Format("Mon 15:04"))
+	}
+	return fmt.Sprintf("✈ Departed %s", formatRelative(at.Sub(now)))
+}
+
+// printTravel handles `kairos travel status`.
+func printTravel(now time.Time) error {
+	plan := settings.Travel
+	if plan == nil {
+		return fmt.Errorf("no trip is planned; start one with kairos travel start \"Home\" \"Destination\"")
+	}
+	homeTZ, home, err := travelZone(plan.Home)
+	if err != nil {
+		return err
+	}
+	destTZ, dest, err := travelZone(plan.Destination)
+	if err != nil {
+		return err
+	}
+	_, h := now.In(home).Zone()
+	_, d := now.In(dest).Zone()
+	fmt.Printf("\n\x1b[36m\x1b[1mTRAVEL\x1b[0m %s → %s (%s)\n", homeTZ.Name, destTZ.Name, tzutil.FormatOffsetDiff(d-h))
+	fmt.Printf("  %-16s %s\n", homeTZ.Name, now.In(home).Format("Mon, Jan 2 15:04 MST"))
+	fmt.Printf("  %-16s %s\n", destTZ.Name, now.In(dest).Format("Mon, Jan 2 15:04 MST"))
+	at, ok := travelDeparture(*plan, home)
+	if line := flightLine(*plan, home, now); line != "" {
+		fmt.Printf("\n  %s\n", line)
+	}
+	fmt.Println()
+	for _, tip := range jetLagTips(travelShift(home, dest, now), ok && !at.After(now)) {
+		fmt.Printf("  • %s\n", tip)
+	}
+	fmt.Println()
+	return nil
+}
+
+/**
+ * This function renders the split primary view of travel mode: home on the left and the
+ * destination on the right, titled with the offset between them, with the flight
+ * countdown under home's clock and the jet-lag tips under the destination's.
+ *
+ * @param snap - The snapshot being drawn.
+ * @param x1 - The right edge of the split view.
+ * @param y1 - Its bottom edge.
+ * @returns The two views, or false if the trip's zones cannot be loaded.
+ */
+func renderTravelViews(snap *dashSnapshot, x1, y1 int) ([]viewFrame, bool) {
+	plan := settings.Travel
+	if plan == nil {
+		return nil, false
+	}
+	homeTZ, home, err := travelZone(plan.Home)
+	if err != nil {
+		return nil, false
+	}
+	destTZ, dest, err := travelZone(plan.Destination)
+	if err != nil {
+		return nil, false
+	}
+	now := snap.Now
+	_, h := now.In(home).Zone()
+	_, d := now.In(dest).Zone()
+	mid := x1 / 2
+	left := viewFrame{Name: "top", X0: 0, Y0: 0, X1: mid - 1, Y1: y1, Frame: true}
+	right := viewFrame{Name: "travel", X0: mid, Y0: 0, X1: x1, Y1: y1, Frame: true}
+
+	z := snap.zone(homeTZ, home)
+	left.Title = fmt.Sprintf(" 🏠 %s%s", homeTZ.Name, z.Title)
+	var extra []string
+	if line := flightLine(*plan, home, now); line != "" {
+		extra = append(extra, line)
+	}
+	left.Lines = renderZoneLines(z, homeTZ, left.X1-left.X0-1, left.Y1-left.Y0-1, true, extra...)
+
+	z = snap.zone(destTZ, dest)
+	right.Title = fmt.Sprintf(" ✈ %s (%s)%s%s", destTZ.Name, tzutil.FormatOffsetDiff(d-h), dayBadge(z.Local, now.In(home)), z.Title)
+	at, ok := travelDeparture(*plan, home)
+	right.Lines = renderZoneLines(z, destTZ, right.X1-right.X0-1, right.Y1-right.Y0-1, false,
+		jetLagTips(travelShift(home, dest, now), ok && !at.After(now))...)
+	return []viewFrame{left, right}, true
+}
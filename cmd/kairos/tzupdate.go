@@ -0,0 +1,229 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// tzdataURL is the latest IANA tz database release, as source for zic.
+const tzdataURL = "https://data.iana.org/time-zones/tzdata-latest.tar.gz"
+
+// tzdataSources are the source files of the release that zic compiles; files a release lacks are skipped.
+var tzdataSources = []string{"africa", "antarctica", "asia", "australasia", "etcetera", "europe", "factory", "northamerica", "southamerica", "backward"}
+
+// maxTZDataSize bounds the download and each file in it.
+const maxTZDataSize = 16 << 20
+
+// tzdataClient fetches the tz database; the release is a few hundred KB.
+var tzdataClient = &http.Client{Timeout: 60 * time.Second}
+
+var (
+	// tzupdateURL is set by `kairos tzupdate --url`, e.g. for a mirror.
+	tzupdateURL string
+	// tzupdateRemove is set by `kairos tzupdate --remove`.
+	tzupdateRemove bool
+)
+
+// tzupdateCommand builds the `kairos tzupdate` command.
+func tzupdateCommand() *command {
+	return &command{
+		Name:  "tzupdate",
+		Short: "Installs the latest IANA tz database for kairos, ahead of the system's (needs zic)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&tzupdateURL, "url", tzdataURL, "The tzdata release to install, as a .tar.gz of the tz source files")
+			fs.BoolVar(&tzupdateRemove, "remove", false, "Delete the installed tz database and go back to the system's")
+		},
+		Run: func(args []string) error { return runTZUpdate() },
+	}
+}
+
+// getZoneinfoDir returns where `kairos tzupdate` installs the compiled tz database.
+func getZoneinfoDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "kairos", "zoneinfo")
+}
+
+// useInstalledTZData makes zones load from the tz database installed by `kairos tzupdate`, if there is one.
+func useInstalledTZData() {
+	if _, err := os.Stat(getZoneinfoDir()); err == nil {
+		tzutil.UseZoneinfo(getZoneinfoDir())
+	}
+}
+
+/**
+ * This function handles `kairos tzupdate`: it downloads the tz database release, compiles
+ * it with zic into a staging directory, and swaps that in for the installed one. The
+ * configured zones whose offsets change over the next two years with the new rules are
+ * listed, since those are the DST changes the update brings.
+ *
+ * @returns An error if zic is missing, or the download or compilation fails; the
+ * installed database is untouched in that case.
+ */
+func runTZUpdate() error {
+	dir := getZoneinfoDir()
+	if tzupdateRemove {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		fmt.Println("Removed the installed tz database; zones load from the system's again.")
+		return nil
+	}
+	zic, err := exec.LookPath("zic")
+	if err != nil {
+		return fmt.Errorf("kairos tzupdate compiles the tz database with zic, which was not found; install it (it comes with glibc or the tzdata tools) and try again")
+	}
+
+	src, err := os.MkdirTemp("", "kairos-tzdata-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(src)
+	fmt.Printf("Downloading %s...\n", tzupdateURL)
+	if err := downloadTZData(tzupdateURL, src); err != nil {
+		return fmt.Errorf("tz database not downloaded: %v", err)
+	}
+	release := tzdataVersion(src)
+	if release == "" {
+		return fmt.Errorf("%s is not a tz database release (it has no version file)", tzupdateURL)
+	}
+	if installed := tzdataVersion(dir); installed == release {
+		fmt.Printf("The installed tz database is already %s.\n", release)
+		return nil
+	}
+
+	staging := dir + ".new"
+	os.RemoveAll(staging)
+	args := []string{"-d", staging}
+	for _, name := range tzdataSources {
+		if _, err := os.Stat(filepath.Join(src, name)); err == nil {
+			args = append(args, name)
+		}
+	}
+	cmd := exec.Command(zic, args...)
+	cmd.Dir = src
+	if out, err := cmd.CombinedOutput(); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("zic failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	// The release and its leap second table (see leapseconds.go) are kept with the zones.
+	for _, name := range []string{"version", "leap-seconds.list"} {
+		data, err := os.ReadFile(filepath.Join(src, name))
+		if os.IsNotExist(err) && name != "version" {
+			continue
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(staging, name), data, 0644)
+		}
+		if err != nil {
+			os.RemoveAll(staging)
+			return err
+		}
+	}
+
+	// The zones are loaded with the current rules before the swap, to compare them after.
+	before := map[string]*time.Location{}
+	for _, tz := range timezones {
+		if loc, err := tzutil.LoadLocation(tz.Location); err == nil {
+			before[tz.Name] = loc
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return err
+	}
+	tzutil.UseZoneinfo(dir)
+	logger.Info("tz database installed", "version", release, "dir", dir)
+	fmt.Printf("Installed tz database %s in %s.\n", release, dir)
+
+	now := time.Now()
+	for _, tz := range timezones {
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil || before[tz.Name] == nil {
+			continue
+		}
+		if !sameTransitions(before[tz.Name], loc, now, now.AddDate(2, 0, 0)) {
+			fmt.Printf("  \x1b[33m%s\x1b[0m (%s): the rules changed\n", tz.Name, tz.Location)
+		}
+	}
+	return nil
+}
+
+// sameTransitions reports whether two versions of a location change offset at the same times between from and to.
+func sameTransitions(a, b *time.Location, from, to time.Time) bool {
+	_, offA := from.In(a).Zone()
+	_, offB := from.In(b).Zone()
+	if offA != offB {
+		return false
+	}
+	ta, tb := tzutil.TransitionsBetween(a, from, to), tzutil.TransitionsBetween(b, from, to)
+	if len(ta) != len(tb) {
+		return false
+	}
+	for i := range ta {
+		if !ta[i].At.Equal(tb[i].At) || ta[i].After != tb[i].After {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * This function downloads a tz database release and unpacks its top-level files (the
+ * sources and the version file) into a directory.
+ *
+ * @param url - The .tar.gz release.
+ * @param dir - Where to unpack it.
+ * @returns An error if the download fails or the file is not a gzipped tarball.
+ */
+func downloadTZData(url, dir string) error {
+	resp, err := tzdataClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	gz, err := gzip.NewReader(io.LimitReader(resp.Body, maxTZDataSize))
+	if err != nil {
+		return fmt.Errorf("%s is not a .tar.gz: %v", url, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Only plain files at the top level are needed, which also keeps paths inside dir.
+		name := strings.TrimPrefix(hdr.Name, "./")
+		if hdr.Typeflag != tar.TypeReg || strings.ContainsAny(name, `/\`) || !filepath.IsLocal(name) {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxTZDataSize))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jroimartin/gocui"
+)
+
+// usageStats counts how kairos is used, when the usage_stats setting allows it. Only
+// names and counts are kept, in a local file that is never sent anywhere.
+type usageStats struct {
+	// Since is the day counting started.
+	Since string `json:"since"`
+	// Commands counts the commands run, e.g. "config set".
+	Commands map[string]int `json:"commands,omitempty"`
+	// Keys counts the dashboard shortcuts pressed, prefixed with the mode when it is not normal.
+	Keys map[string]int `json:"keys,omitempty"`
+	// Dashboards counts the dashboard sessions.
+	Dashboards int `json:"dashboards,omitempty"`
+	// Features counts the dashboard sessions each setting, footer placeholder, or zone option was in use for.
+	Features map[string]int `json:"features,omitempty"`
+}
+
+var (
+	// usageMu guards usagePending.
+	usageMu sync.Mutex
+	// usagePending holds the counts not yet added to the stats file.
+	usagePending usageStats
+
+	// statsReset is set by `kairos stats --reset`.
+	statsReset bool
+)
+
+// statsCommand builds the `kairos stats` command.
+func statsCommand() *command {
+	return &command{
+		Name:  "stats",
+		Short: "Shows which commands, keys, and features you use (recorded locally with usage_stats on)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&statsReset, "reset", false, "Delete the recorded stats")
+		},
+		Run: func(args []string) error { return runStats() },
+	}
+}
+
+// getUsageStatsPath returns the usage stats file, next to (and named after) the config file.
+func getUsageStatsPath() string {
+	return strings.TrimSuffix(getConfigPath(), filepath.Ext(getConfigPath())) + "_stats.json"
+}
+
+// countUsage adds one to a usage count if usage_stats is on.
+func countUsage(counts *map[string]int, name string) {
+	if !settings.UsageStats {
+		return
+	}
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	if *counts == nil {
+		*counts = map[string]int{}
+	}
+	(*counts)[name]++
+}
+
+// countCommand records a command run.
+func countCommand(cmd *command) {
+	countUsage(&usagePending.Commands, strings.TrimPrefix(cmd.path(), "kairos "))
+}
+
+// countKey records a dashboard shortcut, e.g. "s" or "timer: l".
+func countKey(mode inputMode, key interface{}) {
+	name := string(typedRune(key))
+	if key == gocui.KeySpace {
+		name = "space"
+	}
+	if mode != modeNormal {
+		name = strings.ToLower(mode.String()) + ": " + name
+	}
+	countUsage(&usagePending.Keys, name)
+}
+
+/**
+ * This function records a dashboard session and what it had turned on: each setting that
+ * differs from its default (by name only, so tokens are never recorded), each footer
+ * placeholder, and each per-zone option set on at least one zone.
+ */
+func countDashboardFeatures() {
+	if !settings.UsageStats {
+		return
+	}
+	var features []string
+	saved := settings
+	settings = Settings{}
+	defaults := map[string]string{}
+	for _, d := range settingDefs {
+		defaults[d.Key] = d.Get()
+	}
+	settings = saved
+	for _, d := range settingDefs {
+		if d.Key != "usage_stats" && d.Get() != defaults[d.Key] {
+			features = append(features, d.Key)
+		}
+	}
+	for _, m := range footerPlaceholder.FindAllStringSubmatch(footerTemplate(), -1) {
+		features = append(features, "footer {"+m[1]+"}")
+	}
+	options := map[string]bool{}
+	for _, tz := range timezones {
+		for _, o := range zoneOptionValues {
+			if o.value(tz) != "" {
+				options["zone "+o.option] = true
+			}
+		}
+	}
+	for opt := range options {
+		features = append(features, opt)
+	}
+
+	usageMu.Lock()
+	usagePending.Dashboards++
+	usageMu.Unlock()
+	for _, f := range features {
+		countUsage(&usagePending.Features, f)
+	}
+}
+
+// loadUsageStats reads the stats file; a missing file is empty stats.
+func loadUsageStats() (usageStats, error) {
+	var stats usageStats
+	data, err := os.ReadFile(getUsageStatsPath())
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return stats, fmt.Errorf("%s is not valid: %v", getUsageStatsPath(), err)
+	}
+	return stats, nil
+}
+
+// flushUsageStats adds the pending counts to the stats file; it runs as kairos exits.
+func flushUsageStats() {
+	usageMu.Lock()
+	pending := usagePending
+	usagePending = usageStats{}
+	usageMu.Unlock()
+	if pending.Commands == nil && pending.Keys == nil && pending.Dashboards == 0 {
+		return
+	}
+
+	stats, err := loadUsageStats()
+	if err != nil {
+		logger.Warn("usage stats not saved", "err", err)
+		return
+	}
+	stats.Since = defaultString(stats.Since, time.Now().Format("2006-01-02"))
+	stats.Commands = addCounts(stats.Commands, pending.Commands)
+	stats.Keys = addCounts(stats.Keys, pending.Keys)
+	stats.Features = addCounts(stats.Features, pending.Features)
+	stats.Dashboards += pending.Dashboards
+	data, err := json.Marshal(stats)
+	if err == nil {
+		err = writeFileAtomic(getUsageStatsPath(), data, 0644)
+	}
+	if err != nil {
+		logger.Warn("usage stats not saved", "path", getUsageStatsPath(), "err", err)
+	}
+}
+
+// addCounts adds the counts of b to a.
+func addCounts(a, b map[string]int) map[string]int {
+	if a == nil && len(b) > 0 {
+		a = map[string]int{}
+	}
+	for k, n := range b {
+		a[k] += n
+	}
+	return a
+}
+
+/**
+ * This function handles `kairos stats`: the recorded commands, dashboard keys, and
+ * features, most used first, with each feature's share of the dashboard sessions.
+ *
+ * @returns An error if the stats file cannot be read or deleted.
+ */
+func runStats() error {
+	if statsReset {
+		usageMu.Lock()
+		usagePending = usageStats{}
+		usageMu.Unlock()
+		if err := os.Remove(getUsageStatsPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		fmt.Println("Usage stats deleted.")
+		return nil
+	}
+	stats, err := loadUsageStats()
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		out, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	if stats.Since == "" {
+		if !settings.UsageStats {
+			fmt.Println("No usage stats recorded. Turn recording on with: kairos config set usage_stats on")
+		} else {
+			fmt.Println("No usage stats recorded yet.")
+		}
+		return nil
+	}
+
+	fmt.Printf("\n\x1b[36m\x1b[1mUSAGE SINCE %s\x1b[0m", stats.Since)
+	if !settings.UsageStats {
+		fmt.Print(" \x1b[90m(recording is off)\x1b[0m")
+	}
+	fmt.Printf("\n  %d dashboard session(s)\n", stats.Dashboards)
+	printUsageCounts("COMMANDS", stats.Commands, 0)
+	printUsageCounts("DASHBOARD KEYS", stats.Keys, 0)
+	printUsageCounts("FEATURES IN USE", stats.Features, stats.Dashboards)
+	fmt.Println()
+	return nil
+}
+
+// printUsageCounts lists counts, most used first; with sessions set, each count is also shown as a share of them.
+func printUsageCounts(title string, counts map[string]int, sessions int) {
+	if len(counts) == 0 {
+		return
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	fmt.Printf("\n\x1b[1m%s\x1b[0m\n", title)
+	for _, name := range names {
+		if sessions > 0 {
+			fmt.Printf("  %-24s %6d  \x1b[90m%3d%% of sessions\x1b[0m\n", name, counts[name], counts[name]*100/sessions)
+		} else {
+			fmt.Printf("  %-24s %6d\n", name, counts[name])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// These are set at build time for releases, e.g.
+// go build -ldflags "-X main.version=v1.4.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%FT%TZ)".
+// Left empty, they are filled in from the module and VCS information Go embeds in the binary.
+var (
+	version   string
+	commit    string
+	buildDate string
+)
+
+// pseudoVersion matches the versions Go makes up for untagged commits, e.g. v0.0.0-20260101120000-abcdef123456.
+var pseudoVersion = regexp.MustCompile(`-(0\.)?\d{14}-[0-9a-f]{12}$`)
+
+// showVersion is set by `kairos --version`.
+var showVersion bool
+
+// buildInfo describes the running binary, for `kairos version` and bug reports.
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	TZData    string `json:"tzdata"`
+	TZSource  string `json:"tzdata_source"`
+}
+
+/**
+ * This function collects the build information: the version, commit, and date set with
+ * -ldflags, falling back to what Go records in the binary (the module version for
+ * `go install ...@v1.4.0`, the VCS revision for a build from a checkout), and the tz
+ * database the runtime resolves zones from.
+ *
+ * @returns The build information; the version is "dev" for an untagged build.
+ */
+func getBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		// Builds from a checkout get a pseudo-version; the commit below says more.
+		if v := strings.TrimSuffix(bi.Main.Version, "+dirty"); info.Version == "" && v != "(devel)" && !pseudoVersion.MatchString(v) {
+			info.Version = v
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Commit = defaultString(info.Commit, s.Value)
+			case "vcs.time":
+				info.Date = defaultString(info.Date, s.Value)
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	info.Version = defaultString(info.Version, "dev")
+
+	var tzVersion string
+	info.TZSource, tzVersion = tzdataSource()
+	// The embedded database does not record its release; it is the one shipped with that Go version.
+	info.TZData = defaultString(tzVersion, "unknown (bundled with "+info.GoVersion+")")
+	return info
+}
+
+// versionLine is the one-line version shown by `kairos --version` and in the help header.
+func versionLine(info buildInfo) string {
+	line := "kairos " + info.Version
+	var details []string
+	if info.Commit != "" {
+		c := info.Commit
+		if len(c) > 12 {
+			c = c[:12]
+		}
+		if info.Modified {
+			c += "-dirty"
+		}
+		details = append(details, c)
+	}
+	if info.Date != "" {
+		details = append(details, info.Date)
+	}
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	return line
+}
+
+/**
+ * This function handles `kairos version`: the version line, then the Go version,
+ * platform, and tz database, which is everything a bug report needs.
+ *
+ * @returns An error if the JSON output cannot be encoded.
+ */
+func runVersion() error {
+	info := getBuildInfo()
+	if jsonOutput {
+		out, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Println(versionLine(info))
+	fmt.Printf("  %-9s %s %s\n", "go", info.GoVersion, info.Platform)
+	fmt.Printf("  %-9s %s from %s\n", "tzdata", info.TZData, info.TZSource)
+	fmt.Printf("  %-9s %s\n", "config", getConfigPath())
+	return nil
+}
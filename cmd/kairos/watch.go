@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// defaultWatchInterval is how many seconds `kairos watch` waits between updates.
+const defaultWatchInterval = 5
+
+// Flags of the `kairos watch` command.
+var (
+	watchInterval int
+	watchPlain    bool
+)
+
+// nowCommand builds the `kairos now` command.
+func nowCommand() *command {
+	return &command{
+		Name:  "now",
+		Short: "Prints the current time and business-hours state in every zone, one line each",
+		Run: func(args []string) error {
+			fmt.Print(strings.Join(nowTable(appClock.Now(time.UTC), stdoutIsTerminal()), "\n") + "\n")
+			return nil
+		},
+	}
+}
+
+// watchCommand builds the `kairos watch` command.
+func watchCommand() *command {
+	return &command{
+		Name:  "watch",
+		Short: "Reprints the `kairos now` table every few seconds without a full-screen UI (--interval N, --plain)",
+		Flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&watchInterval, "interval", defaultWatchInterval, "Seconds between updates")
+			fs.BoolVar(&watchPlain, "plain", false, "Append each update without colors or cursor movement, e.g. for CI logs")
+		},
+		Run: func(args []string) error { return runWatch(watchInterval, watchPlain) },
+	}
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal rather than a pipe or file.
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+/**
+ * This function builds the compact zone table shared by `kairos now`, `kairos watch`, and
+ * the text service: one line per zone with its local time and date, abbreviation, UTC
+ * offset, and business-hours state. Hidden zones are left out.
+ *
+ * @param now - The current time.
+ * @param color - Whether to color the primary zone and the business-hours state.
+ * @returns The lines, without trailing newlines.
+ */
+func nowTable(now time.Time, color bool) []string {
+	var lines []string
+	for i, tz := range timezones {
+		if !tz.Hidden {
+			lines = append(lines, nowRow(tz, now, color && i == 0, color))
+		}
+	}
+	return lines
+}
+
+/**
+ * This function formats one zone's line of the `kairos now` table.
+ *
+ * @param tz - The zone.
+ * @param now - The current time.
+ * @param bold - Whether to print the name in bold, as for the primary zone.
+ * @param color - Whether to color the business-hours state.
+ * @returns The line.
+ */
+func nowRow(tz TimezoneConfig, now time.Time, bold, color bool) string {
+	name := padCell(tz.Name, 14)
+	loc, err := tzutil.LoadLocation(tz.Location)
+	if err != nil {
+		return fmt.Sprintf("%s unknown location %s", name, tz.Location)
+	}
+	local := now.In(loc)
+	abbr, _ := local.Zone()
+	state := zoneHoursState(local, tz)
+	label := state.Label + ", " + zones.Countdown(local, tz)
+	if bold {
+		name = "\x1b[1m" + name + "\x1b[0m"
+	}
+	if color {
+		code := stateColors[state.Color]
+		switch {
+		case state.Label == "open" && len(tz.States) == 0:
+			code = "\x1b[32m"
+		case state.Label == "closed" && len(tz.States) == 0:
+			code = "\x1b[90m"
+		}
+		if code != "" {
+			label = code + label + "\x1b[0m"
+		}
+	}
+	return fmt.Sprintf("%s %s  %s  %-5s UTC%s  %s",
+		name, local.Format("15:04:05"), local.Format("Mon 02 Jan"), abbr, local.Format("-07:00"), label)
+}
+
+/**
+ * This function handles `kairos watch`: a low-footprint alternative to the dashboard for
+ * minimal terminals, serial consoles, and logs. It reprints the zone table every interval,
+ * moving the cursor back up to overwrite the previous table; with plain (or when stdout
+ * is not a terminal) each update is appended with a timestamp instead. It stops on Ctrl+C.
+ *
+ * @param interval - Seconds between updates.
+ * @param plain - Whether to append plain updates rather than redraw in place.
+ * @returns An error if the interval is invalid or there are no zones.
+ */
+func runWatch(interval int, plain bool) error {
+	if interval < 1 {
+		return fmt.Errorf("invalid interval %d (expected at least 1 second)", interval)
+	}
+	if len(timezones) == 0 {
+		return fmt.Errorf("No timezones configured. Use: kairos add \"Name\" \"Location\"")
+	}
+	if !stdoutIsTerminal() {
+		plain = true
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	printed := 0
+	for {
+		now := appClock.Now(time.UTC)
+		lines := nowTable(now, !plain)
+		if plain {
+			fmt.Printf("--- %s\n%s\n", now.In(localZone()).Format("2006-01-02 15:04:05 MST"), strings.Join(lines, "\n"))
+		} else {
+			// Move up over the previous table and clear each line before rewriting it.
+			if printed > 0 {
+				fmt.Printf("\x1b[%dA", printed)
+			}
+			for _, line := range lines {
+				fmt.Printf("\r\x1b[2K%s\n", line)
+			}
+			// A table that shrank (e.g. after a zone was hidden) leaves no stale lines behind.
+			for i := len(lines); i < printed; i++ {
+				fmt.Print("\r\x1b[2K\n")
+			}
+			printed = max(printed, len(lines))
+		}
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
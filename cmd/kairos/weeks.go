@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// weekStarts are the values of the week_start setting; the first is the default.
+	weekStarts = []string{"monday", "sunday", "saturday"}
+	// weekNumberings are the values of the week_numbering setting; the first is the default.
+	weekNumberings = []string{"iso", "us"}
+)
+
+// weekStartDay returns the first day of the week from the week_start setting (Monday by default).
+func weekStartDay() time.Weekday {
+	switch settings.WeekStart {
+	case "sunday":
+		return time.Sunday
+	case "saturday":
+		return time.Saturday
+	}
+	return time.Monday
+}
+
+// startOfWeek returns midnight at the start of the week that t is in, in t's location.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) - int(weekStartDay()) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+/**
+ * This function numbers the week that a date is in, by the week_numbering setting, with
+ * weeks starting on the week_start day. ISO numbering (the default) makes week 1 the first
+ * week with at least four days in the new year, so the days around New Year can belong to
+ * the previous or next year's weeks; with Monday as the first day this is ISO 8601. US
+ * numbering makes week 1 the week with January 1 in it, and every week belongs to the
+ * calendar year, so the last one is cut short by the new year.
+ *
+ * @param t - The date.
+ * @returns The year the week belongs to, and its number.
+ */
+func weekNumber(t time.Time) (year, week int) {
+	// Whole days are counted in UTC, so DST changes do not shorten a day.
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start := startOfWeek(day)
+	if settings.WeekNumbering == "us" {
+		first := startOfWeek(time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC))
+		return t.Year(), int(start.Sub(first).Hours()/24)/7 + 1
+	}
+	// A week belongs to the year that has four or more of its days, the year of its fourth day.
+	year = start.AddDate(0, 0, 3).Year()
+	first := startOfWeek(time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC))
+	return year, int(start.Sub(first).Hours()/24)/7 + 1
+}
+
+// isWorkday reports whether a date is a working day in a zone: a weekday that is not one of its holidays.
+func isWorkday(tz TimezoneConfig, day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday && !isHoliday(tz, day)
+}
+
+// workdaysLeftInWeek counts the working days from a date to the end of its week, the date included.
+func workdaysLeftInWeek(tz TimezoneConfig, day time.Time) int {
+	n := 0
+	end := startOfWeek(day).AddDate(0, 0, 7)
+	for d := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location()); d.Before(end); d = d.AddDate(0, 0, 1) {
+		if isWorkday(tz, d) {
+			n++
+		}
+	}
+	return n
+}
+
+/**
+ * This function draws the month of a date as a small calendar for the info panel, with
+ * the days starting on the week_start day, the week numbers on the left, weekends and
+ * holidays dimmed, and the date highlighted. Its heading gives the week number and how
+ * many working days are left in the week.
+ *
+ * @param tz - The zone, for its holidays.
+ * @param local - The date, in the zone.
+ * @returns The calendar's lines.
+ */
+func miniCalendarLines(tz TimezoneConfig, local time.Time) []string {
+	_, week := weekNumber(local)
+	left := workdaysLeftInWeek(tz, local)
+	lines := []string{fmt.Sprintf(" \x1b[1m%s\x1b[0m · W%02d, %d workday(s) left", local.Format("January 2006"), week, left)}
+
+	header := []string{" \x1b[90mWk\x1b[0m"}
+	for i := 0; i < 7; i++ {
+		header = append(header, time.Weekday((int(weekStartDay()) + i) % 7).String()[:2])
+	}
+	lines = append(lines, strings.Join(header, " "))
+
+	first := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, local.Location())
+	for row := startOfWeek(first); row.Month() == first.Month() || row.Before(first); row = row.AddDate(0, 0, 7) {
+		// With US numbering a week is cut at New Year, so the row is numbered by its days in the month.
+		shown := row
+		if row.Before(first) {
+			shown = first
+		}
+		_, n := weekNumber(shown)
+		cells := []string{fmt.Sprintf(" \x1b[90m%2d\x1b[0m", n)}
+		for i := 0; i < 7; i++ {
+			d := row.AddDate(0, 0, i)
+			switch {
+			case d.Month() != first.Month():
+				cells = append(cells, "  ")
+			case d.Day() == local.Day():
+				cells = append(cells, fmt.Sprintf("\x1b[7m%2d\x1b[0m", d.Day()))
+			case !isWorkday(tz, d):
+				cells = append(cells, fmt.Sprintf("\x1b[90m%2d\x1b[0m", d.Day()))
+			default:
+				cells = append(cells, fmt.Sprintf("%2d", d.Day()))
+			}
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, " "), " "))
+	}
+	return lines
+}
@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iamstoick/kairos/zonemeta"
+	"github.com/iamstoick/kairos/zones"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	// defaultWidgetInterval is how often a widget script runs unless it sets "interval" (in seconds).
+	defaultWidgetInterval = 60 * time.Second
+	// widgetTimeout bounds one run of a widget script, fetches included.
+	widgetTimeout = 10 * time.Second
+	// maxFetchBytes is the most kairos.fetch reads of a response.
+	maxFetchBytes = 64 << 10
+)
+
+// widgetName matches the file names of widget scripts, which become footer placeholders.
+var widgetName = regexp.MustCompile(`^[a-z]+$`)
+
+// widgetOutput is what a widget script last returned.
+type widgetOutput struct {
+	Footer string
+	Tiles  map[string]string
+}
+
+var (
+	// widgetMu guards widgetOutputs, which the script worker writes and the dashboard reads.
+	widgetMu sync.Mutex
+	// widgetOutputs maps each widget script's name to its last output.
+	widgetOutputs = map[string]widgetOutput{}
+	// widgetScripts are the names of the widget scripts found by loadWidgetScripts.
+	widgetScripts []string
+)
+
+// getWidgetsDir returns the directory of widget scripts, next to (and named after) the config file.
+func getWidgetsDir() string {
+	return strings.TrimSuffix(getConfigPath(), filepath.Ext(getConfigPath())) + "_widgets"
+}
+
+/**
+ * This function finds the widget scripts: every NAME.lua in the widgets directory whose
+ * name is lowercase letters, so that it can be used as the {NAME} footer placeholder.
+ * Each script is registered as a footer field that shows its cached output, so this
+ * runs before the config (and its footer template) is loaded.
+ */
+func loadWidgetScripts() {
+	paths, _ := filepath.Glob(filepath.Join(getWidgetsDir(), "*.lua"))
+	var names []string
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".lua")
+		if !widgetName.MatchString(name) {
+			logger.Warn("widget script skipped (the name must be lowercase letters)", "path", path)
+			continue
+		}
+		if _, builtin := footerFields[name]; builtin {
+			logger.Warn("widget script skipped (the name is a built-in footer widget)", "path", path)
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		registerWidget(name)
+	}
+	widgetScripts = names
+}
+
+// registerWidget adds a widget's {name} footer placeholder, which shows its last footer text.
+func registerWidget(name string) {
+	footerFields[name] = func(time.Time) string {
+		widgetMu.Lock()
+		defer widgetMu.Unlock()
+		return widgetOutputs[name].Footer
+	}
+}
+
+/**
+ * This function starts a worker per widget script and plugin (see plugins.go). Each
+ * script keeps its own Lua state and runs its footer and tile functions every interval,
+ * off the GUI goroutine, since a script may fetch from the network; the dashboard only
+ * shows the cached results.
+ */
+func startWidgetWorkers() {
+	for _, name := range widgetScripts {
+		name := name
+		goWorker("widget "+name, func(ctx context.Context) {
+			L, err := newWidgetState(filepath.Join(getWidgetsDir(), name+".lua"))
+			if err != nil {
+				logger.Warn("widget script failed to load", "widget", name, "err", err)
+				return
+			}
+			defer L.Close()
+			// The GUI goroutine owns timezones, so each run takes the zones it last published.
+			run := func(now time.Time) (widgetOutput, error) {
+				zones, locs := appState.Zones()
+				return runWidget(ctx, L, zones, locs, now)
+			}
+			pollWidget(ctx, name, run, func() time.Duration { return widgetInterval(L) })
+		})
+	}
+	for _, p := range widgetPlugins {
+		p := p
+		goWorker("widget "+p.Name, func(ctx context.Context) {
+			run := func(now time.Time) (widgetOutput, error) {
+				zones, _ := appState.Zones()
+				return runWidgetPlugin(ctx, p.Path, zones, now)
+			}
+			pollWidget(ctx, p.Name, run, func() time.Duration { return defaultWidgetInterval })
+		})
+	}
+}
+
+/**
+ * This function runs a widget until ctx is done, caching each output for the dashboard. A
+ * failing widget keeps its last output, and each new error is logged once.
+ *
+ * @param ctx - Stops the widget when the dashboard exits.
+ * @param name - The widget's name.
+ * @param run - Runs the widget once.
+ * @param interval - Returns how long to wait between runs.
+ */
+func pollWidget(ctx context.Context, name string, run func(now time.Time) (widgetOutput, error), interval func() time.Duration) {
+	lastErr := ""
+	for {
+		out, err := run(appClock.Now(localZone()))
+		if err != nil {
+			if err.Error() != lastErr {
+				logger.Warn("widget failed", "widget", name, "err", err)
+			}
+			lastErr = err.Error()
+		} else {
+			lastErr = ""
+			widgetMu.Lock()
+			widgetOutputs[name] = out
+			widgetMu.Unlock()
+		}
+		if !sleepCtx(ctx, interval()) {
+			return
+		}
+	}
+}
+
+/**
+ * This function creates the Lua state for a widget script. Only the base, string, table,
+ * and math libraries are available (no file or process access), plus the kairos table
+ * with fetch(url) and json_decode(text); print writes to the log file.
+ *
+ * @param path - The script.
+ * @returns The state with the script loaded, or an error.
+ */
+func newWidgetState(path string) (*lua.LState, error) {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{{lua.BaseLibName, lua.OpenBase}, {lua.StringLibName, lua.OpenString}, {lua.TabLibName, lua.OpenTable}, {lua.MathLibName, lua.OpenMath}} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, unsafe := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		L.SetGlobal(unsafe, lua.LNil)
+	}
+	// print would write to stdout underneath the dashboard and corrupt the screen.
+	L.SetGlobal("print", L.NewFunction(luaPrint(filepath.Base(path))))
+	api := L.NewTable()
+	L.SetField(api, "fetch", L.NewFunction(luaFetch))
+	L.SetField(api, "json_decode", L.NewFunction(luaJSONDecode))
+	L.SetGlobal("kairos", api)
+
+	source, err := os.Open(path)
+	if err != nil {
+		L.Close()
+		return nil, err
+	}
+	defer source.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), widgetTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	fn, err := L.Load(source, filepath.Base(path))
+	if err == nil {
+		L.Push(fn)
+		err = L.PCall(0, lua.MultRet, nil)
+	}
+	L.RemoveContext()
+	if err != nil {
+		L.Close()
+		return nil, err
+	}
+	return L, nil
+}
+
+// widgetInterval returns the script's "interval" global in seconds, or the default; it is never under a second.
+func widgetInterval(L *lua.LState) time.Duration {
+	if n, ok := L.GetGlobal("interval").(lua.LNumber); ok && n >= 1 {
+		return time.Duration(float64(n) * float64(time.Second))
+	}
+	return defaultWidgetInterval
+}
+
+/**
+ * This function runs a widget script once: footer(ctx) for its footer text, and
+ * tile(zone) for a line under each zone, if the script defines them.
+ *
+ * @param ctx - Stops the script when the dashboard exits.
+ * @param L - The script's Lua state.
+ * @param zones - The zones; the first is passed to footer(ctx) as ctx.primary.
+ * @param locs - The zones' locations, by name.
+ * @param now - The current time.
+ * @returns The output, or an error if a function fails or runs past widgetTimeout.
+ */
+func runWidget(ctx context.Context, L *lua.LState, zones []TimezoneConfig, locs map[string]*time.Location, now time.Time) (widgetOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, widgetTimeout)
+	defer cancel()
+	L.SetContext(ctx)
+	defer L.RemoveContext()
+
+	out := widgetOutput{Tiles: map[string]string{}}
+	if fn, ok := L.GetGlobal("footer").(*lua.LFunction); ok {
+		ctxTable := L.NewTable()
+		L.SetField(ctxTable, "unix", lua.LNumber(now.Unix()))
+		L.SetField(ctxTable, "iso", lua.LString(now.UTC().Format(time.RFC3339)))
+		if len(zones) > 0 {
+			L.SetField(ctxTable, "primary", zoneTable(L, zones[0], locs[zones[0].Name], now))
+		}
+		text, err := callWidget(L, fn, ctxTable)
+		if err != nil {
+			return out, err
+		}
+		out.Footer = text
+	}
+	if fn, ok := L.GetGlobal("tile").(*lua.LFunction); ok {
+		for _, tz := range zones {
+			text, err := callWidget(L, fn, zoneTable(L, tz, locs[tz.Name], now))
+			if err != nil {
+				return out, err
+			}
+			if text != "" {
+				out.Tiles[tz.Name] = text
+			}
+		}
+	}
+	return out, nil
+}
+
+// callWidget calls a widget function with one argument and returns its result as a single line.
+func callWidget(L *lua.LState, fn *lua.LFunction, arg lua.LValue) (string, error) {
+	if err := L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, arg); err != nil {
+		return "", err
+	}
+	ret := L.Get(-1)
+	L.Pop(1)
+	if ret == lua.LNil {
+		return "", nil
+	}
+	return strings.ReplaceAll(ret.String(), "\n", " "), nil
+}
+
+// zoneTable describes a zone to a widget script: its name, location, local time, UTC offset, business-hours state, and position.
+func zoneTable(L *lua.LState, tz TimezoneConfig, loc *time.Location, now time.Time) *lua.LTable {
+	t := L.NewTable()
+	L.SetField(t, "name", lua.LString(tz.Name))
+	L.SetField(t, "location", lua.LString(tz.Location))
+	L.SetField(t, "note", lua.LString(tz.Note))
+	if loc == nil {
+		return t
+	}
+	local := now.In(loc)
+	abbr, offset := local.Zone()
+	L.SetField(t, "time", lua.LString(local.Format("15:04")))
+	L.SetField(t, "date", lua.LString(local.Format("2006-01-02")))
+	L.SetField(t, "hour", lua.LNumber(local.Hour()))
+	L.SetField(t, "minute", lua.LNumber(local.Minute()))
+	L.SetField(t, "weekday", lua.LString(local.Weekday().String()))
+	L.SetField(t, "abbreviation", lua.LString(abbr))
+	L.SetField(t, "offset", lua.LNumber(offset))
+	L.SetField(t, "open", lua.LBool(zones.BusinessHours(tz).Contains(local)))
+	if info, ok := zonemeta.Lookup(tz.Location); ok {
+		L.SetField(t, "country", lua.LString(info.Country))
+	}
+	if c, ok := zoneCoordinates(tz); ok {
+		L.SetField(t, "lat", lua.LNumber(c.Lat))
+		L.SetField(t, "lon", lua.LNumber(c.Lon))
+	}
+	return t
+}
+
+// luaPrint implements print for a widget script: its arguments go to the log file (see logging.go), as print would join them.
+func luaPrint(script string) lua.LGFunction {
+	return func(L *lua.LState) int {
+		parts := make([]string, L.GetTop())
+		for i := range parts {
+			parts[i] = L.ToStringMeta(L.Get(i + 1)).String()
+		}
+		logger.Info("widget print", "script", script, "text", strings.Join(parts, "\t"))
+		return 0
+	}
+}
+
+// luaFetch implements kairos.fetch(url): it returns the body of a GET request, or nil and an error message.
+func luaFetch(L *lua.LState) int {
+	req, err := http.NewRequestWithContext(L.Context(), http.MethodGet, L.CheckString(1), nil)
+	if err == nil {
+		var resp *http.Response
+		if resp, err = httpClient.Do(req); err == nil {
+			defer resp.Body.Close()
+			if resp.StatusCode/100 != 2 {
+				err = fmt.Errorf("%s", resp.Status)
+			} else {
+				var body []byte
+				if body, err = io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes)); err == nil {
+					L.Push(lua.LString(body))
+					return 1
+				}
+			}
+		}
+	}
+	L.Push(lua.LNil)
+	L.Push(lua.LString(err.Error()))
+	return 2
+}
+
+// luaJSONDecode implements kairos.json_decode(text): it returns the value as Lua tables, or nil and an error message.
+func luaJSONDecode(L *lua.LState) int {
+	var v interface{}
+	if err := json.Unmarshal([]byte(L.CheckString(1)), &v); err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	L.Push(toLua(L, v))
+	return 1
+}
+
+// toLua converts a decoded JSON value to Lua; arrays become tables indexed from 1.
+func toLua(L *lua.LState, v interface{}) lua.LValue {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		t := L.NewTable()
+		for k, item := range v {
+			L.SetField(t, k, toLua(L, item))
+		}
+		return t
+	case []interface{}:
+		t := L.NewTable()
+		for _, item := range v {
+			t.Append(toLua(L, item))
+		}
+		return t
+	case string:
+		return lua.LString(v)
+	case float64:
+		return lua.LNumber(v)
+	case bool:
+		return lua.LBool(v)
+	}
+	return lua.LNil
+}
+
+// widgetTileLines returns the widget scripts' lines for a zone's view, in script order.
+func widgetTileLines(tz TimezoneConfig) []string {
+	widgetMu.Lock()
+	defer widgetMu.Unlock()
+	names := make([]string, 0, len(widgetOutputs))
+	for name := range widgetOutputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var lines []string
+	for _, name := range names {
+		if line := widgetOutputs[name].Tiles[tz.Name]; line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+/**
+ * This function handles `kairos widgets`: it runs every widget script and plugin once
+ * and prints what it returns, to try out widgets without the dashboard.
+ *
+ * @returns An error if there are no widgets.
+ */
+func runWidgetsCommand() error {
+	if len(widgetScripts) == 0 && len(widgetPlugins) == 0 {
+		return fmt.Errorf("no widgets: add NAME.lua scripts to %s, or kairos-widget-NAME executables to your PATH", getWidgetsDir())
+	}
+	loadLocations()
+	now := appClock.Now(localZone())
+	for _, name := range widgetScripts {
+		path := filepath.Join(getWidgetsDir(), name+".lua")
+		L, err := newWidgetState(path)
+		if err != nil {
+			printWidgetOutput(name, path, widgetOutput{}, err)
+			continue
+		}
+		out, err := runWidget(context.Background(), L, timezones, locations, now)
+		L.Close()
+		printWidgetOutput(name, path, out, err)
+	}
+	for _, p := range widgetPlugins {
+		out, err := runWidgetPlugin(context.Background(), p.Path, timezones, now)
+		printWidgetOutput(p.Name, p.Path, out, err)
+	}
+	fmt.Println()
+	return nil
+}
+
+// printWidgetOutput prints one widget's footer text and tile lines, or its error, for `kairos widgets`.
+func printWidgetOutput(name, path string, out widgetOutput, err error) {
+	fmt.Printf("\n\x1b[1m{%s}\x1b[0m %s\n", name, path)
+	if err != nil {
+		fmt.Printf("  \x1b[31m%v\x1b[0m\n", err)
+		return
+	}
+	fmt.Printf("  footer: %s\n", defaultString(out.Footer, "(none)"))
+	for _, tz := range timezones {
+		if line, ok := out.Tiles[tz.Name]; ok {
+			fmt.Printf("  %-7s %s\n", tz.Name+":", line)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/render"
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/jroimartin/gocui"
+)
+
+// popularZones are offered by the first-run wizard before the user types anything.
+var popularZones = []string{
+	"UTC",
+	"America/New_York", "America/Chicago", "America/Denver", "America/Los_Angeles",
+	"America/Toronto", "America/Mexico_City", "America/Sao_Paulo", "America/Buenos_Aires",
+	"Europe/London", "Europe/Dublin", "Europe/Lisbon", "Europe/Paris", "Europe/Berlin",
+	"Europe/Madrid", "Europe/Rome", "Europe/Amsterdam", "Europe/Stockholm", "Europe/Warsaw",
+	"Europe/Athens", "Europe/Istanbul", "Europe/Moscow",
+	"Africa/Cairo", "Africa/Lagos", "Africa/Nairobi", "Africa/Johannesburg",
+	"Asia/Dubai", "Asia/Riyadh", "Asia/Karachi", "Asia/Kolkata", "Asia/Dhaka",
+	"Asia/Bangkok", "Asia/Jakarta", "Asia/Singapore", "Asia/Manila", "Asia/Hong_Kong",
+	"Asia/Shanghai", "Asia/Taipei", "Asia/Seoul", "Asia/Tokyo",
+	"Australia/Perth", "Australia/Sydney", "Pacific/Auckland", "Pacific/Honolulu",
+}
+
+// wizardState holds the first-run wizard's selection while it is open.
+type wizardState struct {
+	chosen   []TimezoneConfig
+	results  []string
+	selected int
+	saved    bool
+}
+
+/**
+ * This function runs the first-run wizard: a small TUI that starts with the detected
+ * local timezone, lets the user fuzzy-search popular zones and add them, and writes
+ * the initial config.
+ *
+ * @returns True if the user saved a configuration, false if they quit.
+ */
+func runWizard() bool {
+	g, err := newGUI()
+	if err != nil {
+		fmt.Printf("\x1b[31mCannot start the setup wizard: %v\x1b[0m\n", err)
+		return false
+	}
+	defer closeGUI()
+	defer recoverGUI(nil)
+	defer quitOnSignals(g)()
+
+	local := detectLocalZone()
+	w := &wizardState{chosen: []TimezoneConfig{{Name: zoneDisplayName(local), Location: local}}}
+	w.results = fuzzyFilter("", popularZones)
+
+	g.Cursor = true
+	g.SetManagerFunc(w.layout)
+	if err := w.keyBindings(g); err != nil {
+		fmt.Printf("\x1b[31mCannot start the setup wizard: %v\x1b[0m\n", err)
+		return false
+	}
+	if err := g.MainLoop(); err != nil && err != gocui.ErrQuit {
+		fmt.Printf("\x1b[31m%v\x1b[0m\n", err)
+		return false
+	}
+	if !w.saved {
+		return false
+	}
+	timezones = w.chosen
+	if err := saveConfig(); err != nil {
+		fmt.Printf("\x1b[31m%v\x1b[0m\n", err)
+		return false
+	}
+	return true
+}
+
+/**
+ * This function lays out the wizard: the search box, the matching zones, and the
+ * zones chosen so far (the first one becomes the primary view).
+ *
+ * @param g - The wizard's gocui.Gui.
+ * @returns An error if any view cannot be created.
+ */
+func (w *wizardState) layout(g *gocui.Gui) error {
+	maxX, maxY := g.Size()
+	half := maxX / 2
+
+	if v, err := g.SetView("wizard-search", 0, 0, half-1, 2); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = " Welcome to Kairos! Search timezones "
+		v.Editable = true
+		v.Editor = gocui.EditorFunc(w.edit)
+		g.SetCurrentView("wizard-search")
+	}
+
+	if v, err := g.SetView("wizard-results", 0, 3, half-1, maxY-3); err != nil && err != gocui.ErrUnknownView {
+		return err
+	} else {
+		v.Title = " Matches "
+		v.Clear()
+		for i, name := range w.results {
+			if i == w.selected {
+				fmt.Fprintf(v, "\x1b[7m %s \x1b[0m\n", name)
+			} else {
+				fmt.Fprintf(v, " %s\n", name)
+			}
+		}
+		// Keep the selection in view when the list is longer than the box.
+		_, height := v.Size()
+		origin := 0
+		if w.selected >= height {
+			origin = w.selected - height + 1
+		}
+		v.SetOrigin(0, origin)
+	}
+
+	if v, err := g.SetView("wizard-chosen", half, 0, maxX-1, maxY-3); err != nil && err != gocui.ErrUnknownView {
+		return err
+	} else {
+		v.Title = " Your timezones "
+		v.Clear()
+		for i, tz := range w.chosen {
+			label := fmt.Sprintf(" %d", i)
+			if i == 0 {
+				label = "\x1b[32m[P]\x1b[0m"
+			}
+			fmt.Fprintf(v, "%s %-15s %s\n", label, tz.Name, tz.Location)
+		}
+		if len(w.chosen) > 1 {
+			fmt.Fprintln(v, "\n (P) = Primary timezone (top view)")
+		}
+	}
+
+	if v, err := g.SetView("wizard-help", -1, maxY-2, maxX, maxY); err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Frame = false
+		v.FgColor = gocui.ColorCyan
+		fmt.Fprint(v, render.Center("Type to search | ↑/↓ select | Enter add | Ctrl+D remove last | Ctrl+S save and start | Ctrl+C quit", maxX))
+	}
+	return nil
+}
+
+/**
+ * This function edits the search box and refreshes the matches after every keystroke.
+ * Arrow keys are left to the keybindings, which move the selection instead of the cursor.
+ */
+func (w *wizardState) edit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	gocui.DefaultEditor.Edit(v, key, ch, mod)
+	w.results = fuzzyFilter(strings.TrimSpace(v.Buffer()), popularZones)
+	// Any valid IANA name can be added, even if it is not in the popular list.
+	if q := strings.TrimSpace(v.Buffer()); q != "" && !containsString(w.results, q) {
+		if _, err := tzutil.LoadLocation(q); err == nil {
+			w.results = append([]string{q}, w.results...)
+		}
+	}
+	w.selected = 0
+}
+
+// keyBindings sets up the wizard's keys.
+func (w *wizardState) keyBindings(g *gocui.Gui) error {
+	bindings := []struct {
+		key     interface{}
+		handler func(g *gocui.Gui, v *gocui.View) error
+	}{
+		{gocui.KeyCtrlC, func(g *gocui.Gui, v *gocui.View) error { return gocui.ErrQuit }},
+		{gocui.KeyCtrlS, func(g *gocui.Gui, v *gocui.View) error {
+			w.saved = true
+			return gocui.ErrQuit
+		}},
+		{gocui.KeyArrowUp, func(g *gocui.Gui, v *gocui.View) error {
+			if w.selected > 0 {
+				w.selected--
+			}
+			return nil
+		}},
+		{gocui.KeyArrowDown, func(g *gocui.Gui, v *gocui.View) error {
+			if w.selected < len(w.results)-1 {
+				w.selected++
+			}
+			return nil
+		}},
+		{gocui.KeyEnter, func(g *gocui.Gui, v *gocui.View) error {
+			if w.selected >= len(w.results) {
+				return nil
+			}
+			loc := w.results[w.selected]
+			for _, tz := range w.chosen {
+				if tz.Location == loc {
+					return nil
+				}
+			}
+			w.chosen = append(w.chosen, TimezoneConfig{Name: zoneDisplayName(loc), Location: loc})
+			return nil
+		}},
+		{gocui.KeyCtrlD, func(g *gocui.Gui, v *gocui.View) error {
+			if len(w.chosen) > 1 {
+				w.chosen = w.chosen[:len(w.chosen)-1]
+			}
+			return nil
+		}},
+	}
+	for _, b := range bindings {
+		if err := g.SetKeybinding("", b.key, gocui.ModNone, b.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectLocalZone detects the system's IANA timezone name, e.g. "Asia/Manila", falling back to UTC.
+func detectLocalZone() string {
+	if name := time.Local.String(); name != "Local" && name != "" {
+		return name
+	}
+	if name, ok := detectSystemZone(); ok {
+		return name
+	}
+	return "UTC"
+}
+
+/**
+ * This function reads the system's IANA timezone name as it is now. Go reports the system
+ * zone as "Local", and reads it only once, so the name is taken from $TZ, from the Windows
+ * zone ID, or from the /etc/localtime symlink instead.
+ *
+ * @returns The location, e.g. "Asia/Manila", and whether one was found.
+ */
+func detectSystemZone() (string, bool) {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if _, err := tzutil.LoadLocation(tz); err == nil {
+			return tz, true
+		}
+	}
+	if runtime.GOOS == "windows" {
+		// Windows keeps its own zone IDs; tzutil (the Windows tool) prints the current one.
+		if out, err := exec.Command("tzutil", "/g").Output(); err == nil {
+			if name, ok := tzutil.FromWindows(string(out)); ok {
+				return name, true
+			}
+		}
+	}
+	if target, err := filepath.EvalSymlinks("/etc/localtime"); err == nil {
+		if i := strings.Index(target, "zoneinfo/"); i >= 0 {
+			name := target[i+len("zoneinfo/"):]
+			if _, err := tzutil.LoadLocation(name); err == nil {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+/**
+ * This function derives a short display name from a location, e.g. "New York" from "America/New_York".
+ *
+ * @param location - The IANA location.
+ * @returns The display name.
+ */
+func zoneDisplayName(location string) string {
+	name := location[strings.LastIndex(location, "/")+1:]
+	return strings.ReplaceAll(name, "_", " ")
+}
+
+/**
+ * This function filters candidates with a fuzzy subsequence match (case-insensitive,
+ * ignoring "/" and "_"), ranking tighter matches first.
+ *
+ * @param query - The search text; empty returns all candidates.
+ * @param candidates - The strings to search.
+ * @returns The matching candidates, best first.
+ */
+func fuzzyFilter(query string, candidates []string) []string {
+	type match struct {
+		text  string
+		score int
+	}
+	var matches []match
+	for _, c := range candidates {
+		if score, ok := fuzzyScore(query, c); ok {
+			matches = append(matches, match{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score < matches[j].score })
+	out := make([]string, len(matches))
+	for i, m := range matches {
+		out[i] = m.text
+	}
+	return out
+}
+
+/**
+ * This function scores a fuzzy subsequence match; lower is better.
+ * The score is the span of the candidate covered by the match, so contiguous
+ * matches rank ahead of scattered ones.
+ *
+ * @param query - The search text.
+ * @param candidate - The string to match.
+ * @returns The score, and false if query is not a subsequence of candidate.
+ */
+func fuzzyScore(query, candidate string) (int, bool) {
+	norm := strings.NewReplacer("/", " ", "_", " ")
+	q := []rune(strings.ToLower(strings.ReplaceAll(norm.Replace(query), " ", "")))
+	c := []rune(strings.ToLower(norm.Replace(candidate)))
+	if len(q) == 0 {
+		return 0, true
+	}
+	first, qi := -1, 0
+	for ci, r := range c {
+		if r != q[qi] {
+			continue
+		}
+		if first < 0 {
+			first = ci
+		}
+		qi++
+		if qi == len(q) {
+			return ci - first, true
+		}
+	}
+	return 0, false
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// worldLand is a coarse land mask of the world in an equirectangular projection:
+// 72 columns of 5° of longitude from 180°W, and 20 rows of 9° of latitude from 90°N.
+var worldLand = []string{
+	"                        ######                                          ",
+	"            ####################      ###      ##      ###              ",
+	"   ##################### ###### #     ##################################",
+	"    #############    ###   #       # ###############################    ",
+	"           ##############          ##############################       ",
+	"           ##########             ### ############ ######### #  #       ",
+	"            ########              ########################### #         ",
+	"              ###  ##            ############### ########## #           ",
+	"                 ##              #############    ##   ###  #           ",
+	"                    ######        ############         #####            ",
+	"                   ##########         ######           #####  ####      ",
+	"                    #########         ###### #               ####       ",
+	"                      ######          #####  #            ########      ",
+	"                      ####             ###                 ########     ",
+	"                      ##                                         #    # ",
+	"                     ##                                                #",
+	"                                                                        ",
+	"                       #                    ########################    ",
+	"      ##################      ##########################################",
+	"########################################################################",
+}
+
+// mapMode is toggled with the "m" key and shows the world map instead of the clocks.
+var mapMode bool
+
+// isLand looks up a position in the land mask.
+func isLand(lat, lon float64) bool {
+	row := min(len(worldLand)-1, max(0, int((90-lat)/180*float64(len(worldLand)))))
+	line := worldLand[row]
+	col := min(len(line)-1, max(0, int((lon+180)/360*float64(len(line)))))
+	return line[col] == '#'
+}
+
+/**
+ * This function finds the point where the sun is directly overhead.
+ *
+ * @param now - The instant.
+ * @returns The subsolar latitude and longitude in degrees.
+ */
+func subsolarPoint(now time.Time) (lat, lon float64) {
+	decl, eqt := sunPosition(julianDate(now))
+	utc := now.UTC()
+	hours := float64(utc.Hour()) + float64(utc.Minute())/60 + float64(utc.Second())/3600
+	lon = math.Mod(15*(12-hours-eqt)+540, 360) - 180
+	return decl, lon
+}
+
+// isDaylight reports whether the sun is above the horizon at a position, given the subsolar point.
+func isDaylight(lat, lon, sunLat, sunLon float64) bool {
+	cosZenith := math.Sin(lat*degToRad)*math.Sin(sunLat*degToRad) +
+		math.Cos(lat*degToRad)*math.Cos(sunLat*degToRad)*math.Cos((lon-sunLon)*degToRad)
+	return cosZenith > 0
+}
+
+/**
+ * This function renders the world map: land in green by day and blue by night, the
+ * night side of the oceans dotted, the sun as "☀", and each zone with a known position
+ * as a marker ("*" for the primary, then its number in the grid). A legend of the
+ * markers is on the last line.
+ *
+ * @param now - The current time.
+ * @param width - The inner width of the view.
+ * @param height - The inner height of the view.
+ * @returns The lines of the view.
+ */
+func renderWorldMapLines(now time.Time, width, height int) []string {
+	if width < 1 || height < 2 {
+		return nil
+	}
+	mapHeight := height - 1
+	sunLat, sunLon := subsolarPoint(now)
+	cell := func(lat, lon float64) (x, y int) {
+		x = min(width-1, int((lon+180)/360*float64(width)))
+		y = min(mapHeight-1, int((90-lat)/180*float64(mapHeight)))
+		return x, y
+	}
+
+	markers := map[[2]int]string{}
+	var legend []string
+	for i, tz := range displayZones() {
+		c, ok := zoneCoordinates(tz)
+		if !ok {
+			continue
+		}
+		mark := "*"
+		if i > 0 {
+			mark = fmt.Sprint(i % 10)
+		}
+		x, y := cell(c.Lat, c.Lon)
+		markers[[2]int{x, y}] = mark
+		legend = append(legend, fmt.Sprintf("\x1b[1m%s\x1b[0m %s", mark, tz.Name))
+	}
+	sunX, sunY := cell(sunLat, sunLon)
+
+	lines := make([]string, 0, height)
+	for y := 0; y < mapHeight; y++ {
+		var b strings.Builder
+		for x := 0; x < width; x++ {
+			// Each cell is sampled at its center.
+			lat := 90 - (float64(y)+0.5)/float64(mapHeight)*180
+			lon := (float64(x)+0.5)/float64(width)*360 - 180
+			day := isDaylight(lat, lon, sunLat, sunLon)
+			switch mark, ok := markers[[2]int{x, y}]; {
+			case ok:
+				b.WriteString("\x1b[1m\x1b[31m" + mark + "\x1b[0m")
+			case x == sunX && y == sunY:
+				b.WriteString("\x1b[33m☀\x1b[0m")
+			case isLand(lat, lon) && day:
+				b.WriteString("\x1b[32m█\x1b[0m")
+			case isLand(lat, lon):
+				b.WriteString("\x1b[34m▓\x1b[0m")
+			case day:
+				b.WriteString(" ")
+			default:
+				b.WriteString("\x1b[90m·\x1b[0m")
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return append(lines, " "+strings.Join(legend, "  "))
+}
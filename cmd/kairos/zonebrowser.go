@@ -0,0 +1,434 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/zonemeta"
+	"github.com/jroimartin/gocui"
+)
+
+// browserRow is one line of the IANA browser's tree: an area such as "America" (or a
+// sub-area such as "America/Argentina"), or a location.
+type browserRow struct {
+	Path  string
+	Depth int
+	Leaf  bool
+}
+
+var (
+	// browserOpen is set while the IANA browser ("i") is shown.
+	browserOpen bool
+	// browserQuery is the browser's search text; while it is set, every match is shown expanded.
+	browserQuery string
+	// browserExpanded holds the areas opened with Enter or the right arrow.
+	browserExpanded = map[string]bool{}
+	// browserRows are the visible lines of the tree; browserSelected indexes them, and
+	// browserTop is the first one shown.
+	browserRows     []browserRow
+	browserSelected int
+	browserTop      int
+	// browserCounts is the number of (matching) locations under each area.
+	browserCounts map[string]int
+	// browserLocs caches the locations loaded for the tree's times and the preview.
+	browserLocs = map[string]*time.Location{}
+)
+
+// accentFolder strips the accents that place names are commonly typed with, so that
+// "São Paulo" finds America/Sao_Paulo and "Zürich" Europe/Zurich.
+var accentFolder = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"ç", "c", "é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// foldAccents lowercases text and strips its accents for the browser's search.
+func foldAccents(s string) string {
+	return accentFolder.Replace(strings.ToLower(s))
+}
+
+// browserLocation loads a location for the browser, caching it; nil if it cannot be loaded.
+func browserLocation(name string) *time.Location {
+	loc, ok := browserLocs[name]
+	if !ok {
+		loc, _ = tzutil.LoadLocation(name)
+		browserLocs[name] = loc
+	}
+	return loc
+}
+
+/**
+ * This function rebuilds the browser's tree for a search. Without a query it lists the
+ * areas, with the locations of the expanded ones below them; with a query it lists every
+ * location whose name or country matches (accents aside), under its areas, and selects
+ * the best match.
+ *
+ * @param query - The search text.
+ */
+func filterBrowser(query string) {
+	browserQuery = query
+	selected := ""
+	if browserSelected < len(browserRows) {
+		selected = browserRows[browserSelected].Path
+	}
+	var matched map[string]bool
+	if query != "" {
+		matched = map[string]bool{}
+		byText := map[string]string{}
+		var candidates []string
+		for _, name := range allZoneNames() {
+			text := name
+			if info, ok := zonemeta.Lookup(name); ok {
+				text += " " + info.Country
+			}
+			text = foldAccents(text)
+			byText[text] = name
+			candidates = append(candidates, text)
+		}
+		for i, text := range fuzzyFilter(foldAccents(query), candidates) {
+			if i == 0 {
+				selected = byText[text]
+			}
+			matched[byText[text]] = true
+		}
+	}
+
+	browserRows, browserCounts = nil, map[string]int{}
+	seen := map[string]bool{}
+	for _, name := range allZoneNames() {
+		if matched != nil && !matched[name] {
+			continue
+		}
+		parts := strings.Split(name, "/")
+		open := true
+		for i := 1; i < len(parts); i++ {
+			area := strings.Join(parts[:i], "/")
+			browserCounts[area]++
+			if open && !seen[area] {
+				browserRows = append(browserRows, browserRow{Path: area, Depth: i - 1})
+				seen[area] = true
+			}
+			open = open && (matched != nil || browserExpanded[area])
+		}
+		if open {
+			browserRows = append(browserRows, browserRow{Path: name, Depth: len(parts) - 1, Leaf: true})
+		}
+	}
+	selectBrowserPath(selected)
+}
+
+// selectBrowserPath selects the row of a path, or keeps the selection within the rows if it is gone.
+func selectBrowserPath(path string) {
+	for i, row := range browserRows {
+		if row.Path == path {
+			browserSelected = i
+			return
+		}
+	}
+	browserSelected = max(0, min(browserSelected, len(browserRows)-1))
+}
+
+/**
+ * This function expands or collapses the selected area with the arrow keys. Right opens
+ * an area, or on an open one moves to its first entry; left closes an open area, or moves
+ * to the area a location is in.
+ *
+ * @param expand - True for the right arrow, false for the left.
+ */
+func browseTree(expand bool) {
+	if browserSelected >= len(browserRows) {
+		return
+	}
+	row := browserRows[browserSelected]
+	switch {
+	case expand && !row.Leaf && !browserExpanded[row.Path]:
+		browserExpanded[row.Path] = true
+	case expand && !row.Leaf:
+		browserSelected = min(browserSelected+1, len(browserRows)-1)
+		return
+	case !expand && !row.Leaf && browserExpanded[row.Path]:
+		browserExpanded[row.Path] = false
+	case !expand:
+		if i := strings.LastIndex(row.Path, "/"); i >= 0 {
+			selectBrowserPath(row.Path[:i])
+		}
+		return
+	default:
+		return
+	}
+	filterBrowser(browserQuery)
+}
+
+// browserConfigured returns the displayed zone at a location, if there is one.
+func browserConfigured(location string) (TimezoneConfig, bool) {
+	for _, tz := range displayZones() {
+		if tz.Location == location && tz.Name != previewName {
+			return tz, true
+		}
+	}
+	return TimezoneConfig{}, false
+}
+
+// browserRowLine formats a row of the tree: an area with its count, or a location with its
+// current time; the selected row is drawn in reverse video across the whole width.
+func browserRowLine(row browserRow, now time.Time, width int, selected bool) string {
+	indent := strings.Repeat("  ", row.Depth)
+	name := strings.ReplaceAll(row.Path[strings.LastIndex(row.Path, "/")+1:], "_", " ")
+	var line, color string
+	if !row.Leaf {
+		arrow := "▸"
+		if browserQuery != "" || browserExpanded[row.Path] {
+			arrow = "▾"
+		}
+		line = fmt.Sprintf(" %s%s %s (%d)", indent, arrow, name, browserCounts[row.Path])
+	} else {
+		mark := " "
+		if _, ok := browserConfigured(row.Path); ok {
+			mark, color = "●", "\x1b[32m"
+		}
+		clock := ""
+		if loc := browserLocation(row.Path); loc != nil {
+			clock = now.In(loc).Format("15:04 MST")
+		}
+		line = padCell(fmt.Sprintf(" %s%s %s", indent, mark, name), max(width-len(clock)-1, 2)) + clock
+	}
+	if selected {
+		return "\x1b[7m" + padCell(line, width) + "\x1b[0m"
+	}
+	if color != "" {
+		return strings.Replace(line, "●", color+"●\x1b[0m", 1)
+	}
+	return line
+}
+
+/**
+ * This function lists the preview of the selected row. A location shows its current time
+ * and date, its details (see locationDetailLines), and whether it is configured; an area
+ * shows how many locations it has and the range of their offsets.
+ *
+ * @param row - The selected row.
+ * @param now - The current time.
+ * @returns The preview's lines.
+ */
+func browserPreviewLines(row browserRow, now time.Time) []string {
+	label := func(key, value string) string { return fmt.Sprintf(" \x1b[1m%-10s\x1b[0m %s", key, value) }
+	if !row.Leaf {
+		lines := []string{" \x1b[1m" + row.Path + "\x1b[0m", "", label("Locations", fmt.Sprint(browserCounts[row.Path]))}
+		lowest, highest, found := 0, 0, false
+		for _, name := range allZoneNames() {
+			if !strings.HasPrefix(name, row.Path+"/") {
+				continue
+			}
+			if loc := browserLocation(name); loc != nil {
+				_, offset := now.In(loc).Zone()
+				if !found || offset < lowest {
+					lowest = offset
+				}
+				if !found || offset > highest {
+					highest = offset
+				}
+				found = true
+			}
+		}
+		if found {
+			lines = append(lines, label("Offsets", fmt.Sprintf("UTC%s to UTC%s", now.In(time.FixedZone("", lowest)).Format("-07:00"), now.In(time.FixedZone("", highest)).Format("-07:00"))))
+		}
+		return append(lines, "", " \x1b[90mEnter or → opens it, ← closes it\x1b[0m")
+	}
+
+	loc := browserLocation(row.Path)
+	if loc == nil {
+		return []string{" Cannot load " + row.Path}
+	}
+	local := now.In(loc)
+	lines := append([]string{fmt.Sprintf(" \x1b[1m%s\x1b[0m  %s", local.Format("15:04:05"), local.Format("Mon 2 Jan 2006")), ""}, locationDetailLines(row.Path, loc, now)...)
+	lines = append(lines, "")
+	if tz, ok := browserConfigured(row.Path); ok {
+		return append(lines, fmt.Sprintf(" \x1b[32mConfigured as %s\x1b[0m", tz.Name), " \x1b[90mEnter promotes it to the top, Tab peeks\x1b[0m")
+	}
+	city := strings.ReplaceAll(row.Path[strings.LastIndex(row.Path, "/")+1:], "_", " ")
+	return append(lines, " \x1b[90mEnter previews it at the top, Tab peeks; to keep it:\x1b[0m", fmt.Sprintf(" \x1b[90mkairos add %q %s\x1b[0m", city, row.Path))
+}
+
+/**
+ * This function lists the details of any IANA location for the browser's preview and the
+ * peek card: its country, UTC offset, the difference from the local and primary zones,
+ * DST, and the next offset change.
+ *
+ * @param location - The IANA location.
+ * @param loc - The loaded location.
+ * @param now - The current time.
+ * @returns The lines.
+ */
+func locationDetailLines(location string, loc *time.Location, now time.Time) []string {
+	label := func(key, value string) string { return fmt.Sprintf(" \x1b[1m%-10s\x1b[0m %s", key, value) }
+	local := now.In(loc)
+	name, offset := local.Zone()
+	_, localOffset := now.In(localZone()).Zone()
+	lines := []string{label("Location", location)}
+	if info, ok := zonemeta.Lookup(location); ok {
+		lines = append(lines, label("Country", info.Flag()+" "+info.Country))
+	}
+	lines = append(lines,
+		label("Offset", fmt.Sprintf("UTC%s (%s)", local.Format("-07:00"), name)),
+		label("vs. local", tzutil.FormatOffsetDiff(offset-localOffset)))
+	if zones := displayZones(); len(zones) > 0 && locations[zones[0].Name] != nil {
+		_, primaryOffset := now.In(locations[zones[0].Name]).Zone()
+		lines = append(lines, label("vs. "+zones[0].Name, tzutil.FormatOffsetDiff(offset-primaryOffset)))
+	}
+	dst := "not in effect"
+	if local.IsDST() {
+		dst = "in effect"
+	}
+	lines = append(lines, label("DST", dst))
+	if tr, ok := tzutil.NextTransition(loc, now, transitionSearchWindow); ok {
+		return append(lines, label("Next", fmt.Sprintf("%s → %s", tr.At.In(loc).Format("2 Jan 2006 15:04"), tr.AfterName)))
+	}
+	return append(lines, label("Next", "no offset change in two years"))
+}
+
+/**
+ * This function shows the IANA browser while it is open: the search box, the tree of
+ * areas and locations with their current times, and the preview of the selected row
+ * beside it (below 70 columns the preview is left out). It is called from the dashboard
+ * layout after the other views are drawn.
+ *
+ * @param g - The dashboard's gocui.Gui.
+ * @param now - The time to show.
+ * @returns An error if a view cannot be created.
+ */
+func layoutBrowser(g *gocui.Gui, now time.Time) error {
+	if !browserOpen {
+		return nil
+	}
+	maxX, maxY := g.Size()
+	width, height := min(96, maxX-2), min(26, maxY-2)
+	x0, y0 := (maxX-width)/2, max(0, (maxY-height)/2)
+	v, err := g.SetView("browser", x0, y0, x0+width, y0+2)
+	if err != nil {
+		if err != gocui.ErrUnknownView {
+			return err
+		}
+		v.Title = " IANA zones (type to search, Enter picks, Tab peeks, Esc closes) "
+		v.Editable = true
+		v.Editor = gocui.EditorFunc(browserEdit)
+		filterBrowser("")
+	}
+	treeWidth := width
+	if width >= 70 {
+		treeWidth = min(46, width/2)
+	}
+	tree, err := g.SetView("browser-tree", x0, y0+2, x0+treeWidth, y0+height)
+	if err != nil && err != gocui.ErrUnknownView {
+		return err
+	}
+	// The tree scrolls to keep the selected row in view.
+	rows := max(height-3, 1)
+	browserTop = max(0, min(browserTop, browserSelected), browserSelected-rows+1)
+	tree.Clear()
+	if len(browserRows) == 0 {
+		fmt.Fprint(tree, " \x1b[90mNo matching zone\x1b[0m")
+	}
+	for i := browserTop; i < min(len(browserRows), browserTop+rows); i++ {
+		fmt.Fprintln(tree, consoleSafe(browserRowLine(browserRows[i], now, treeWidth-1, i == browserSelected)))
+	}
+	views := []string{"browser-tree", "browser"}
+	if treeWidth < width {
+		preview, err := g.SetView("browser-preview", x0+treeWidth, y0+2, x0+width, y0+height)
+		if err != nil && err != gocui.ErrUnknownView {
+			return err
+		}
+		preview.Clear()
+		if browserSelected < len(browserRows) {
+			fmt.Fprint(preview, consoleSafe(strings.Join(browserPreviewLines(browserRows[browserSelected], now), "\n")))
+		}
+		views = append([]string{"browser-preview"}, views...)
+	} else {
+		g.DeleteView("browser-preview")
+	}
+	g.Cursor = true
+	for _, name := range views {
+		if _, err := g.SetViewOnTop(name); err != nil {
+			return err
+		}
+	}
+	_, err = g.SetCurrentView("browser")
+	return err
+}
+
+// browserEdit edits the search box and refreshes the tree; without a search, the left and right arrows close and open areas.
+func browserEdit(v *gocui.View, key gocui.Key, ch rune, mod gocui.Modifier) {
+	if (key == gocui.KeyArrowLeft || key == gocui.KeyArrowRight) && browserQuery == "" {
+		browseTree(key == gocui.KeyArrowRight)
+		return
+	}
+	gocui.DefaultEditor.Edit(v, key, ch, mod)
+	if query := strings.TrimSpace(v.Buffer()); query != browserQuery {
+		filterBrowser(query)
+	}
+}
+
+// closeBrowser removes the browser; the expanded areas are kept for the next time it opens.
+func closeBrowser(g *gocui.Gui) {
+	browserOpen, browserQuery = false, ""
+	g.Cursor = false
+	for _, name := range []string{"browser", "browser-tree", "browser-preview"} {
+		g.DeleteView(name)
+	}
+}
+
+// browserBindings sets up the keys inside the IANA browser; "i" opens it (see KeyBindings).
+func browserBindings(g *gocui.Gui) error {
+	bindings := []struct {
+		key     gocui.Key
+		handler func(g *gocui.Gui, v *gocui.View) error
+	}{
+		{gocui.KeyEnter, func(g *gocui.Gui, v *gocui.View) error {
+			if browserSelected >= len(browserRows) {
+				return nil
+			}
+			row := browserRows[browserSelected]
+			if !row.Leaf {
+				browseTree(!browserExpanded[row.Path] || browserQuery != "")
+				return nil
+			}
+			if tz, ok := browserConfigured(row.Path); ok {
+				choosePaletteItem(paletteItem{Zone: tz, Configured: true})
+			} else {
+				choosePaletteItem(paletteItem{Zone: TimezoneConfig{Name: previewLabel(row.Path), Location: row.Path}})
+			}
+			closeBrowser(g)
+			return nil
+		}},
+		{gocui.KeyEsc, onEscape(func(g *gocui.Gui, v *gocui.View) error {
+			closeBrowser(g)
+			return nil
+		})},
+		{gocui.KeyArrowDown, func(g *gocui.Gui, v *gocui.View) error {
+			browserSelected = min(browserSelected+1, max(len(browserRows)-1, 0))
+			return nil
+		}},
+		{gocui.KeyArrowUp, func(g *gocui.Gui, v *gocui.View) error {
+			browserSelected = max(browserSelected-1, 0)
+			return nil
+		}},
+		{gocui.KeyPgdn, func(g *gocui.Gui, v *gocui.View) error {
+			browserSelected = min(browserSelected+10, max(len(browserRows)-1, 0))
+			return nil
+		}},
+		{gocui.KeyPgup, func(g *gocui.Gui, v *gocui.View) error {
+			browserSelected = max(browserSelected-10, 0)
+			return nil
+		}},
+	}
+	for _, b := range bindings {
+		if err := g.SetKeybinding("browser", b.key, gocui.ModNone, b.handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultHealthInterval is how often zone health checks run unless health_interval says otherwise.
+const defaultHealthInterval = 5 * time.Minute
+
+// zoneHealthResult is the outcome of a zone's last health check.
+type zoneHealthResult struct {
+	Up bool
+	// Status is the HTTP status code, or 0 when the request failed.
+	Status  int
+	Latency time.Duration
+}
+
+var (
+	// zoneHealthMu guards zoneHealth, which the health worker writes and the dashboard reads.
+	zoneHealthMu sync.Mutex
+	// zoneHealth holds each zone's last health check by zone name.
+	zoneHealth = map[string]zoneHealthResult{}
+	// zoneHealthClient checks the endpoints; a status page that hangs counts as down.
+	zoneHealthClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// healthInterval returns the health check interval from the health_interval setting.
+func healthInterval() time.Duration {
+	if d, err := time.ParseDuration(settings.HealthInterval); err == nil {
+		return d
+	}
+	return defaultHealthInterval
+}
+
+// setHealthInterval validates and stores the health check interval ("default" restores five minutes).
+func setHealthInterval(v string) error {
+	if v == "default" || v == "none" || v == "" {
+		settings.HealthInterval = ""
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 30*time.Second {
+		return fmt.Errorf("invalid health interval '%s' (expected e.g. 1m or 10m, at least 30s)", v)
+	}
+	settings.HealthInterval = v
+	return nil
+}
+
+// parseHealthURL checks that a zone's health endpoint is an http:// or https:// URL.
+func parseHealthURL(v string) error {
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid health URL '%s' (expected e.g. https://status.example.com/healthz)", v)
+	}
+	return nil
+}
+
+/**
+ * This function starts the health worker if any zone has a health URL. Every interval it
+ * requests each zone's endpoint off the GUI goroutine and keeps the outcome for the
+ * zone's badge. After the first round, a zone that goes down or comes back up is also
+ * shown as a notification.
+ */
+func startZoneHealthWorker() {
+	var zones []TimezoneConfig
+	for _, tz := range timezones {
+		if tz.Health != "" {
+			zones = append(zones, tz)
+		}
+	}
+	if len(zones) == 0 {
+		return
+	}
+	interval := healthInterval()
+	goWorker("zone health worker", func(ctx context.Context) {
+		for {
+			for _, tz := range zones {
+				result, err := checkZoneHealth(ctx, tz.Health)
+				if ctx.Err() != nil {
+					return
+				}
+				zoneHealthMu.Lock()
+				previous, seen := zoneHealth[tz.Name]
+				zoneHealth[tz.Name] = result
+				zoneHealthMu.Unlock()
+				if seen && previous.Up == result.Up {
+					continue
+				}
+				if result.Up {
+					logger.Info("zone health check up", "zone", tz.Name, "status", result.Status)
+				} else {
+					logger.Warn("zone health check down", "zone", tz.Name, "status", result.Status, "err", err)
+				}
+				if seen && result.Up {
+					showNotification(tz.Name + " is up again")
+				} else if seen {
+					showAlert(tz.Name + " is down")
+				}
+			}
+			if !sleepCtx(ctx, interval) {
+				return
+			}
+		}
+	})
+}
+
+/**
+ * This function requests a zone's health endpoint. Any 2xx or 3xx answer is up; an error
+ * status, a timeout, or a failed connection is down.
+ *
+ * @param ctx - Stops the request when the dashboard exits.
+ * @param endpoint - The URL to request.
+ * @returns The outcome, and the reason when the endpoint is down.
+ */
+func checkZoneHealth(ctx context.Context, endpoint string) (zoneHealthResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return zoneHealthResult{}, err
+	}
+	req.Header.Set("User-Agent", "kairos")
+	start := time.Now()
+	resp, err := zoneHealthClient.Do(req)
+	if err != nil {
+		return zoneHealthResult{Latency: time.Since(start)}, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	result := zoneHealthResult{Up: resp.StatusCode < 400, Status: resp.StatusCode, Latency: time.Since(start)}
+	if !result.Up {
+		return result, fmt.Errorf("%s", resp.Status)
+	}
+	return result, nil
+}
+
+// healthLine returns a zone's health badge for its view, e.g. "▲ up 120ms", or "" before the first check.
+func healthLine(tz TimezoneConfig) string {
+	zoneHealthMu.Lock()
+	result, ok := zoneHealth[tz.Name]
+	zoneHealthMu.Unlock()
+	if tz.Health == "" || !ok {
+		return ""
+	}
+	if !result.Up {
+		if result.Status == 0 {
+			return "\x1b[31m▼ down\x1b[0m (unreachable)"
+		}
+		return fmt.Sprintf("\x1b[31m▼ down\x1b[0m (%d)", result.Status)
+	}
+	return fmt.Sprintf("\x1b[32m▲ up\x1b[0m %dms", result.Latency.Milliseconds())
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/tzutil"
+	"github.com/iamstoick/kairos/zones"
+)
+
+// findZone looks up a configured timezone by display name (see zones.Find).
+func findZone(name string) (TimezoneConfig, *time.Location, error) {
+	return zones.Find(timezones, name)
+}
+
+// resolveZone resolves a configured display name or a location string (see zones.Resolve).
+func resolveZone(name string) (*time.Location, string, error) {
+	return zones.Resolve(timezones, name)
+}
+
+// addForce is set by `kairos add --force` and allows a location that is already configured.
+var addForce bool
+
+/**
+ * This function handles the `kairos add` command, appending a timezone to the config.
+ * Names must be unique, since commands look zones up by name. A location that is
+ * already configured is refused too, unless --force is given (e.g. for two teams in
+ * the same city).
+ *
+ * @param name - The display name, e.g. "Manila".
+ * @param location - An IANA location, a Windows zone ID, or a UTC offset, e.g. "Asia/Manila",
+ *                   "Singapore Standard Time", or "+08:30".
+ * @returns An error if the location is invalid, the zone is a duplicate, or the config cannot be saved.
+ */
+func addZone(name, location string) error {
+	if _, err := tzutil.LoadLocation(location); err != nil {
+		// Windows users know their zones by IDs such as "Pacific Standard Time"; they are stored as IANA names.
+		iana, ok := tzutil.FromWindows(location)
+		if !ok {
+			return fmt.Errorf("%v (use an IANA name such as \"Asia/Manila\", a Windows name such as \"Pacific Standard Time\", or a UTC offset such as \"+08:30\")", err)
+		}
+		fmt.Printf("Using %s for the Windows zone %s\n", iana, location)
+		location = iana
+	}
+	for _, tz := range timezones {
+		if strings.EqualFold(tz.Name, name) {
+			return fmt.Errorf("a timezone named '%s' already exists (remove it first or pick another name)", tz.Name)
+		}
+		if strings.EqualFold(tz.Location, location) && !addForce {
+			return fmt.Errorf("%s is already configured as '%s' (use --force to add it again)", location, tz.Name)
+		}
+	}
+	timezones = append(timezones, TimezoneConfig{Name: name, Location: location})
+	if settings.AutoSort != "" {
+		if err := sortZones(settings.AutoSort, time.Now()); err != nil {
+			return err
+		}
+	}
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Added %s successfully!\n", name)
+	return nil
+}
+
+/**
+ * This function handles the `kairos remove` command, deleting a timezone from the config.
+ * The previous config is backed up, so `kairos undo` brings the timezone back.
+ *
+ * @param name - The display name of the timezone to remove.
+ * @returns An error if no timezone has that name or the config cannot be saved.
+ */
+func removeZone(name string) error {
+	var kept []TimezoneConfig
+	for _, tz := range timezones {
+		if tz.Name != name {
+			kept = append(kept, tz)
+		}
+	}
+	if len(kept) == len(timezones) {
+		return fmt.Errorf("timezone '%s' not found", name)
+	}
+	timezones = kept
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s successfully!\n", name)
+	return nil
+}
+
+// zoneIndex returns the index of a configured timezone by display name (case-insensitive), or -1.
+func zoneIndex(name string) int {
+	return zones.Index(timezones, name)
+}
+
+/**
+ * This function handles `kairos set-primary`: it moves a timezone to the top of the
+ * config, so the dashboard shows it in the primary view. The others keep their order.
+ *
+ * @param name - The display name of the timezone.
+ * @returns An error if no timezone has that name or the config cannot be saved.
+ */
+func setPrimary(name string) error {
+	idx := zoneIndex(name)
+	if idx < 0 {
+		return fmt.Errorf("timezone '%s' not found", name)
+	}
+	tz := timezones[idx]
+	copy(timezones[1:idx+1], timezones[:idx])
+	timezones[0] = tz
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("%s is now the primary timezone\n", tz.Name)
+	return nil
+}
+
+/**
+ * This function handles `kairos swap`: it exchanges the positions of two timezones.
+ *
+ * @param a - The display name of one timezone.
+ * @param b - The display name of the other.
+ * @returns An error if either timezone is unknown or the config cannot be saved.
+ */
+func swapZones(a, b string) error {
+	i, j := zoneIndex(a), zoneIndex(b)
+	if i < 0 {
+		return fmt.Errorf("timezone '%s' not found", a)
+	}
+	if j < 0 {
+		return fmt.Errorf("timezone '%s' not found", b)
+	}
+	timezones[i], timezones[j] = timezones[j], timezones[i]
+	if err := saveConfig(); err != nil {
+		return err
+	}
+	fmt.Printf("Swapped %s and %s\n", timezones[j].Name, timezones[i].Name)
+	return nil
+}
+
+// listCSV is set by `kairos list --csv`.
+var listCSV bool
+
+// printListCSV prints the zone statuses as CSV with a header row.
+func printListCSV(w io.Writer, list []zones.Status) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "location", "primary", "hidden", "local_time", "utc_offset", "abbreviation", "business_hours", "open", "next_change"})
+	for _, s := range list {
+		cw.Write([]string{s.Name, s.Location, strconv.FormatBool(s.Primary), strconv.FormatBool(s.Hidden), s.LocalTime,
+			s.UTCOffset, s.Abbreviation, s.BusinessHours, strconv.FormatBool(s.Open), s.NextChange})
+	}
+	cw.Flush()
+	return cw.Error()
+}
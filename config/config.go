@@ -0,0 +1,188 @@
+// Package config holds the schema of the kairos config file (zones, events, and
+// settings) and decodes it, upgrading files written by older versions on the way.
+// It only describes the file: reading, locking, and saving it stay with the program.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/workhours"
+)
+
+// Config is the structure of the configuration file.
+// Older versions saved a bare array of timezones, which Migrate upgrades on load.
+type Config struct {
+	// Version is the schema version (see CurrentVersion); older files are upgraded on load.
+	Version   int              `json:"version"`
+	Timezones []TimezoneConfig `json:"timezones"`
+	Events    []EventConfig    `json:"events,omitempty"`
+	Settings  Settings         `json:"settings"`
+}
+
+// TimezoneConfig defines the structure for saved timezones.
+// Fields must be capitalized to be exported for JSON encoding.
+type TimezoneConfig struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	// Calendar optionally adds a second date line in another calendar system (hijri, hebrew, chinese).
+	Calendar string `json:"calendar,omitempty"`
+	// Coordinates locate the zone for solar calculations such as prayer times.
+	Coordinates *Coordinates `json:"coordinates,omitempty"`
+	// Prayer selects the prayer-time calculation method; empty disables prayer times.
+	Prayer string `json:"prayer,omitempty"`
+	// Asr selects the juristic method for Asr ("standard" or "hanafi").
+	Asr string `json:"asr,omitempty"`
+	// PrayerNotify shows a footer notification when each prayer time begins.
+	PrayerNotify bool `json:"prayer_notify,omitempty"`
+	// Hours overrides the default 09:00-17:00 business hours (HH:MM-HH:MM, Monday to Friday).
+	Hours string `json:"hours,omitempty"`
+	// Progress selects what the progress bar measures: "day" (default) or "workday".
+	Progress string `json:"progress,omitempty"`
+	// Style draws the zone's clock as a binary clock ("binary") or in words ("words") instead of the digits.
+	Style string `json:"style,omitempty"`
+	// Bars lists additional period progress bars to show (year, month, week).
+	Bars []string `json:"bars,omitempty"`
+	// Times lists alternate time representations to show as extra lines (beats, decimal, epoch, tai, sidereal, daylight, golden).
+	Times []string `json:"times,omitempty"`
+	// Subtime shows a smaller second time line under the clock, in another format (24h, 12h, utc, iso) or representation (e.g. epoch).
+	Subtime string `json:"subtime,omitempty"`
+	// People lists who works in the zone, shown in the info panel of a focused view.
+	People []string `json:"people,omitempty"`
+	// Holidays lists dates (YYYY-MM-DD) with no business hours, used by `kairos sla`.
+	Holidays []string `json:"holidays,omitempty"`
+	// Awake overrides the default 08:00-22:00 humane hours, when it is polite to ping someone (every day).
+	Awake string `json:"awake,omitempty"`
+	// WakeNotify shows a notification when the zone enters or leaves its humane hours.
+	WakeNotify bool `json:"wake_notify,omitempty"`
+	// Focus lists no-meeting windows (HH:MM-HH:MM, Monday to Friday) that the meeting grid and the fairness planner avoid.
+	Focus string `json:"focus,omitempty"`
+	// Shift is the team that covers the zone in the shift roster (e.g. "EMEA"), for the {handoff} footer widget.
+	Shift string `json:"shift,omitempty"`
+	// ShiftHours overrides the business hours as the team's shift (HH:MM-HH:MM, Monday to Friday).
+	ShiftHours string `json:"shift_hours,omitempty"`
+	// OnCallSchedule is the PagerDuty or Opsgenie schedule whose on-call people are shown in the zone's view.
+	OnCallSchedule string `json:"oncall_schedule,omitempty"`
+	// States are custom business-hours states (e.g. "core hours") that replace the open/closed indicator while they apply.
+	States []HoursState `json:"states,omitempty"`
+	// Metric is a shell command, or "prom:" and a Prometheus query, whose values are drawn as a sparkline in the zone's view.
+	Metric string `json:"metric,omitempty"`
+	// Health is an HTTP(S) endpoint, such as the region's status page, whose up/down badge is shown in the zone's view.
+	Health string `json:"health,omitempty"`
+	// Carbon is the zone's electricity grid (an Electricity Maps zone such as "GB" or "DE"), whose carbon intensity and green hours are shown in the zone's view.
+	Carbon string `json:"carbon,omitempty"`
+	// Note is free text about the zone (e.g. "office closed Fridays"), shown in the info panel.
+	Note string `json:"note,omitempty"`
+	// NoteTile also shows the note in the zone's view, scrolling when it is too long.
+	NoteTile bool `json:"note_tile,omitempty"`
+	// HourFormat is "12h" or "24h" for the zone's clock, or "" for the hour_format setting.
+	HourFormat string `json:"hour_format,omitempty"`
+	// Voice is the voice the v key speaks the zone's time in, named as the speech program names it.
+	Voice string `json:"voice,omitempty"`
+	// Hidden keeps the zone out of the dashboard; CLI commands still find it by name.
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// HasBar reports whether a period bar is enabled for the timezone.
+func (tz TimezoneConfig) HasBar(period string) bool {
+	for _, b := range tz.Bars {
+		if b == period {
+			return true
+		}
+	}
+	return false
+}
+
+// EventConfig defines a saved event, such as a meeting or a launch.
+// The start is stored as wall-clock time in the event's zone so that it stays
+// correct if the zone's UTC offset rules change before the event happens.
+type EventConfig struct {
+	Title string `json:"title"`
+	// Start is the local start time in "2006-01-02 15:04" form.
+	Start string `json:"start"`
+	// Zone is a configured timezone name or a location such as "UTC".
+	Zone string `json:"zone"`
+	// Duration is the length of the event in minutes (0 for an instant).
+	Duration int `json:"duration,omitempty"`
+	// Alarm, when set, is how long before the start to notify (a Go duration such as "10m"; "0s" notifies at the start).
+	Alarm string `json:"alarm,omitempty"`
+}
+
+// HoursState is a custom business-hours state of a zone, such as "core hours", with its own glyph and color.
+type HoursState struct {
+	Label string `json:"label"`
+	// Hours is the state's HH:MM-HH:MM window, on weekdays unless Daily is set.
+	Hours string `json:"hours"`
+	Glyph string `json:"glyph"`
+	// Color is red, green, yellow, blue, magenta, or cyan, or empty for the default color.
+	Color string `json:"color,omitempty"`
+	Daily bool   `json:"daily,omitempty"`
+}
+
+// Covers reports whether the state's window contains now.
+func (s HoursState) Covers(now time.Time) bool {
+	h, err := workhours.Parse(s.Hours)
+	if err != nil {
+		return false
+	}
+	if s.Daily {
+		return h.CoversClock(now)
+	}
+	return h.Contains(now)
+}
+
+// Coordinates holds the geographic position used for solar calculations.
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+/**
+ * Parse decodes the contents of a config file, upgrading files written by older
+ * versions to the current schema first.
+ *
+ * @param data - The contents of the file.
+ * @returns The config and the version the file was written with, or an error pointing at the problem.
+ */
+func Parse(data []byte) (Config, int, error) {
+	var cfg Config
+	migrated, version, err := Migrate(data)
+	if err != nil {
+		return cfg, 0, fmt.Errorf("%s", DescribeJSONError(data, err))
+	}
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		return cfg, 0, fmt.Errorf("%s", DescribeJSONError(migrated, err))
+	}
+	for i, tz := range cfg.Timezones {
+		if tz.Name == "" || tz.Location == "" {
+			return cfg, 0, fmt.Errorf("timezone #%d needs both a name and a location", i+1)
+		}
+	}
+	return cfg, version, nil
+}
+
+/**
+ * DescribeJSONError turns a JSON decoding error into a message that points at the problem,
+ * including the line and column for syntax and type errors.
+ *
+ * @param data - The JSON that failed to decode.
+ * @param err - The error from json.Unmarshal.
+ * @returns The description.
+ */
+func DescribeJSONError(data []byte, err error) string {
+	var offset int64
+	switch e := err.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return err.Error()
+	}
+	before := string(data[:min(int(offset), len(data))])
+	line := strings.Count(before, "\n") + 1
+	col := len(before) - strings.LastIndex(before, "\n")
+	return fmt.Sprintf("%v (line %d, column %d)", err, line, col)
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The fuzz targets below run their seeds with go test, and search further with e.g.
+// go test -run '^$' -fuzz FuzzParseConfig -fuzztime 1m ./config; inputs that fail are
+// saved to testdata/fuzz and become seeds too.
+
+// FuzzParseConfig checks that any config file is decoded or refused with a message, and
+// that a decoded one has a name and a location for every zone.
+func FuzzParseConfig(f *testing.F) {
+	for _, seed := range []string{
+		`{"version": 2, "timezones": [{"name": "Manila", "location": "Asia/Manila"}]}`,
+		`[{"name": "London", "location": "Europe/London"}]`,
+		`{"timezones": [{"name": "UTC", "location": "UTC"}], "events": [{"title": "Standup", "time": "09:00", "zone": "UTC"}]}`,
+		`{"version": 99}`,
+		`{"version": "two"}`,
+		`{"timezones": [{"name": "", "location": "UTC"}]}`,
+		`{"timezones": [{"name": "A", "location": "UTC"},]}`,
+		"{\n  \"settings\": {\"compact\": 1}\n}",
+		`{"settings": {"hooks": {"alarm": "echo hi"}}, "timezones": null}`,
+		``,
+		`   [`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		cfg, _, err := Parse(data)
+		if err != nil {
+			if err.Error() == "" {
+				t.Fatalf("Parse(%q) failed with an empty message", data)
+			}
+			return
+		}
+		for i, tz := range cfg.Timezones {
+			if tz.Name == "" || tz.Location == "" {
+				t.Fatalf("Parse(%q) accepted timezone #%d without a name or location: %+v", data, i+1, tz)
+			}
+		}
+	})
+}
+
+// jsonErrorPosition matches the position DescribeJSONError adds to a message.
+var jsonErrorPosition = regexp.MustCompile(`\(line (\d+), column (\d+)\)$`)
+
+// FuzzDescribeJSONError checks that a decoding error is always placed at a line and column
+// inside the input.
+func FuzzDescribeJSONError(f *testing.F) {
+	for _, seed := range []string{`{"a": }`, "{\n\n  \"timezones\": 3\n}", `[1, 2`, "\n\n\n}", `{"version": 1.5}`, "\xff"} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var cfg Config
+		err := json.Unmarshal(data, &cfg)
+		if err == nil {
+			return
+		}
+		msg := DescribeJSONError(data, err)
+		if !strings.HasPrefix(msg, err.Error()) {
+			t.Fatalf("DescribeJSONError(%q) = %q, want it to start with %q", data, msg, err.Error())
+		}
+		if m := jsonErrorPosition.FindStringSubmatch(msg); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			col, _ := strconv.Atoi(m[2])
+			if lines := strings.Count(string(data), "\n") + 1; line < 1 || line > lines || col < 1 {
+				t.Fatalf("DescribeJSONError(%q) = %q, outside the input's %d line(s)", data, msg, lines)
+			}
+		}
+	})
+}
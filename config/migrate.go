@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CurrentVersion is the config schema version written by this build.
+// Bump it together with a new entry in migrations whenever the schema changes
+// in a way older files cannot be read as-is.
+const CurrentVersion = 1
+
+// migration upgrades a decoded config from one schema version to the next.
+type migration struct {
+	// From is the version this migration upgrades; it produces From+1.
+	From int
+	// Apply rewrites the raw config in place.
+	Apply func(raw map[string]json.RawMessage) error
+}
+
+// migrations lists every schema upgrade, in order.
+var migrations = []migration{
+	{
+		// Version 0 was a bare array of timezones, which Migrate wraps into an
+		// object before this runs; objects saved before versioning already match version 1.
+		From:  0,
+		Apply: func(raw map[string]json.RawMessage) error { return nil },
+	},
+}
+
+/**
+ * Migrate upgrades raw config JSON to the current schema version by running
+ * every migration after the file's version, in order.
+ *
+ * @param data - The config file contents.
+ * @returns The upgraded JSON, the version the file was written with, and an error if it
+ * cannot be decoded or was written by a newer version of kairos.
+ */
+func Migrate(data []byte) ([]byte, int, error) {
+	var raw map[string]json.RawMessage
+	version := 0
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		// Version 0: a bare array of timezones.
+		raw = map[string]json.RawMessage{"timezones": json.RawMessage(trimmed)}
+	} else {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, 0, err
+		}
+		// Objects written before the version field existed have the version 1 layout.
+		version = 1
+		if v, ok := raw["version"]; ok {
+			if err := json.Unmarshal(v, &version); err != nil {
+				return nil, 0, fmt.Errorf("invalid version field: %v", err)
+			}
+		}
+	}
+	if version > CurrentVersion {
+		return nil, version, fmt.Errorf("config version %d was written by a newer kairos (this one supports up to %d)", version, CurrentVersion)
+	}
+	// Current files are returned untouched so that decoding errors point at the right line.
+	if version == CurrentVersion {
+		return data, version, nil
+	}
+
+	from := version
+	for _, m := range migrations {
+		if m.From < version {
+			continue
+		}
+		if err := m.Apply(raw); err != nil {
+			return nil, from, fmt.Errorf("upgrading config from version %d: %v", m.From, err)
+		}
+		version = m.From + 1
+	}
+	raw["version"], _ = json.Marshal(version)
+	out, err := json.Marshal(raw)
+	return out, from, err
+}
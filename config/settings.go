@@ -0,0 +1,195 @@
+package config
+
+// Settings holds global preferences that apply to the whole dashboard rather than a single zone.
+type Settings struct {
+	// CopyFormat selects the timestamp format copied by the "y" key: iso (default), epoch, or slack.
+	CopyFormat string `json:"copy_format,omitempty"`
+	// TrackInteractions records when I last pressed each zone's key or ran `kairos touch` for it, and shows it in the zone's view.
+	TrackInteractions bool `json:"track_interactions,omitempty"`
+	// HourFormat is how the clocks read: "12h" (default) or "24h"; zones can override it.
+	HourFormat string `json:"hour_format,omitempty"`
+	// SnapshotFormat selects the format the "s" key saves the dashboard in: text (default), ansi, or html.
+	SnapshotFormat string `json:"snapshot_format,omitempty"`
+	// EpochStrip shows the current Unix timestamp and UTC ISO 8601 time in the primary view.
+	EpochStrip bool `json:"epoch_strip,omitempty"`
+	// PrecisionStrip shows TAI and GPS time and the next leap second in the primary view.
+	PrecisionStrip bool `json:"precision_strip,omitempty"`
+	// Theme selects the dashboard colors: default or night.
+	Theme string `json:"theme,omitempty"`
+	// Background is the terminal's background for the default theme: dark, light, or empty to detect it.
+	Background string `json:"background,omitempty"`
+	// NightDim switches to the night theme during these hours (HH:MM-HH:MM) in the primary zone.
+	NightDim string `json:"night_dim,omitempty"`
+	// Chime sounds at every multiple of this interval (e.g. 60m) on the primary zone's clock.
+	Chime string `json:"chime,omitempty"`
+	// ChimeCommand is a shell command run instead of the terminal bell when the chime sounds.
+	ChimeCommand string `json:"chime_command,omitempty"`
+	// SpeakCommand is a shell command run instead of the platform's speech program when the v key speaks the time.
+	SpeakCommand string `json:"speak_command,omitempty"`
+	// QuietHours silences the chime during these hours (HH:MM-HH:MM) in the primary zone.
+	QuietHours string `json:"quiet_hours,omitempty"`
+	// BreakEvery reminds the user to take a break after this much continuous dashboard time (e.g. 50m).
+	BreakEvery string `json:"break_every,omitempty"`
+	// BreakDesktop also sends break reminders as desktop notifications.
+	BreakDesktop bool `json:"break_desktop,omitempty"`
+	// Timesheet is the service that completed work sessions are pushed to: toggl or clockify.
+	Timesheet string `json:"timesheet,omitempty"`
+	// TimesheetToken is the API token for the timesheet service.
+	TimesheetToken string `json:"timesheet_token,omitempty"`
+	// TimesheetWorkspace is the workspace id that time entries are created in.
+	TimesheetWorkspace string `json:"timesheet_workspace,omitempty"`
+	// OnCall is the service that on-call schedules are read from: pagerduty or opsgenie.
+	OnCall string `json:"oncall,omitempty"`
+	// OnCallToken is the API token for the on-call service.
+	OnCallToken string `json:"oncall_token,omitempty"`
+	// SlackToken is the Slack API token used to show teammates per zone and set the Slack status.
+	SlackToken string `json:"slack_token,omitempty"`
+	// GCalClientID and GCalClientSecret are the Google OAuth client used by `kairos gcal login`.
+	GCalClientID     string `json:"gcal_client_id,omitempty"`
+	GCalClientSecret string `json:"gcal_client_secret,omitempty"`
+	// GCalRefreshToken is saved by `kairos gcal login` and cleared by `kairos gcal logout`.
+	GCalRefreshToken string `json:"gcal_refresh_token,omitempty"`
+	// GCalCalendars lists teammates' calendars (e.g. email addresses) whose busy times the meeting grid also avoids.
+	GCalCalendars []string `json:"gcal_calendars,omitempty"`
+	// MQTTBroker is the host:port of an MQTT broker that zone times, business-hours changes, and alarms are published to.
+	MQTTBroker string `json:"mqtt_broker,omitempty"`
+	// MQTTTopic is the prefix of the published topics (default "kairos").
+	MQTTTopic string `json:"mqtt_topic,omitempty"`
+	// MQTTUsername and MQTTPassword authenticate with the broker.
+	MQTTUsername string `json:"mqtt_username,omitempty"`
+	MQTTPassword string `json:"mqtt_password,omitempty"`
+	// TickerSource is the market data source of the {ticker} footer widget: finnhub or coingecko.
+	TickerSource string `json:"ticker,omitempty"`
+	// TickerSymbols lists the stock symbols or coin ids the {ticker} footer widget shows.
+	TickerSymbols []string `json:"ticker_symbols,omitempty"`
+	// TickerToken is the API key for the ticker source.
+	TickerToken string `json:"ticker_token,omitempty"`
+	// PrometheusURL is the Prometheus server that "prom:" zone metrics are queried from.
+	PrometheusURL string `json:"prometheus_url,omitempty"`
+	// MetricInterval is how often zone metrics are refreshed (default 1m).
+	MetricInterval string `json:"metric_interval,omitempty"`
+	// HealthInterval is how often zone health checks run (default 5m).
+	HealthInterval string `json:"health_interval,omitempty"`
+	// CarbonToken is the Electricity Maps API token for zones' grid carbon intensity (GB needs none).
+	CarbonToken string `json:"carbon_token,omitempty"`
+	// GreenThreshold is the carbon intensity, in gCO2eq/kWh, at or below which an hour is green (default 200).
+	GreenThreshold string `json:"green_threshold,omitempty"`
+	// ServerAdminToken lets dashboards attached to `kairos daemon --listen` change the shared state.
+	ServerAdminToken string `json:"server_admin_token,omitempty"`
+	// ServerReadToken lets dashboards attach to `kairos daemon --listen` without changing anything.
+	ServerReadToken string `json:"server_read_token,omitempty"`
+	// ServerTLSCert and ServerTLSKey are the PEM certificate and key the shared server and the calendar feeds serve TLS with.
+	ServerTLSCert string `json:"server_tls_cert,omitempty"`
+	ServerTLSKey  string `json:"server_tls_key,omitempty"`
+	// ServerTLSCA is a PEM file of the certificates --server trusts besides the system's, e.g. a shared server's self-signed one.
+	ServerTLSCA string `json:"server_tls_ca,omitempty"`
+	// UsageStats counts the commands, dashboard keys, and features used in a local file for `kairos stats`.
+	UsageStats bool `json:"usage_stats,omitempty"`
+	// SyncRemote is where `kairos sync` shares the config: gist:ID, git:URL, or an https:// URL.
+	SyncRemote string `json:"sync_remote,omitempty"`
+	// SyncToken authenticates to the sync remote (a GitHub token for gists, a bearer token for HTTPS).
+	SyncToken string `json:"sync_token,omitempty"`
+	// SyncStrategy decides conflicts when both sides changed the same thing: remote-wins (default) or local-wins.
+	SyncStrategy string `json:"sync_strategy,omitempty"`
+	// Hooks maps events (swap, hour, alarm, profile) to shell commands run when they happen.
+	Hooks map[string]string `json:"hooks,omitempty"`
+	// AnnounceWebhook is the Slack or Teams incoming webhook that announcements are posted to.
+	AnnounceWebhook string `json:"announce_webhook,omitempty"`
+	// Announcements are the messages posted to the webhook at local times (kairos announce).
+	Announcements []Announcement `json:"announcements,omitempty"`
+	// Tiles are the grid tiles that show a shell command's output (kairos tile).
+	Tiles []CommandTile `json:"tiles,omitempty"`
+	// Travel is the trip planned with `kairos travel start`, or nil.
+	Travel *TravelPlan `json:"travel,omitempty"`
+	// Footer is the footer template, with placeholders such as {cpu} and {heartbeat}.
+	Footer string `json:"footer,omitempty"`
+	// FooterHidden hides the footer, giving its rows to the clocks (the "f" key).
+	FooterHidden bool `json:"footer_hidden,omitempty"`
+	// FooterHide lists footer widgets (placeholder names) that are left out (Shift+letter keys).
+	FooterHide []string `json:"footer_hide,omitempty"`
+	// FiscalStart is the first month of the fiscal year for the {fiscal} footer widget (default January).
+	FiscalStart string `json:"fiscal_start,omitempty"`
+	// WeekStart is the first day of the week: sunday or saturday; empty is Monday.
+	WeekStart string `json:"week_start,omitempty"`
+	// WeekNumbering is how weeks are numbered: us; empty is ISO 8601.
+	WeekNumbering string `json:"week_numbering,omitempty"`
+	// NotificationDuration is how long footer notifications are shown, e.g. "5s" (default 3s).
+	NotificationDuration string `json:"notification_duration,omitempty"`
+	// LongNotifications is how a notification too long for the footer is shown: "wrap" (default) or "scroll".
+	LongNotifications string `json:"long_notifications,omitempty"`
+	// AlertBell rings the terminal bell when an alert is shown, such as an event's alarm.
+	AlertBell bool `json:"alert_bell,omitempty"`
+	// NotifyDesktop and NotifyWebhook are the lowest notification levels (info, warn, alert) sent
+	// to desktop notifications and to the announce_webhook channel, or "off".
+	NotifyDesktop string `json:"notify_desktop,omitempty"`
+	NotifyWebhook string `json:"notify_webhook,omitempty"`
+	// NotifyTerminal is the lowest notification level sent through the terminal emulator's
+	// own notifications, or "off" (the default); TerminalNotify picks their escape sequences.
+	NotifyTerminal string `json:"notify_terminal,omitempty"`
+	TerminalNotify string `json:"terminal_notify,omitempty"`
+	// NoDimUnfocused keeps the dashboard's colors while its terminal is not focused.
+	NoDimUnfocused bool `json:"no_dim_unfocused,omitempty"`
+	// QuietUnfocused holds back the bell and the terminal and desktop notifications while the terminal is not focused.
+	QuietUnfocused bool `json:"quiet_unfocused,omitempty"`
+	// Layout fixes the grid of secondary zones as COLSxROWS (e.g. 2x4) instead of fitting it to the terminal.
+	Layout string `json:"layout,omitempty"`
+	// AutoSort keeps the secondary zones sorted (offset or opens) when zones are added.
+	AutoSort string `json:"auto_sort,omitempty"`
+	// ReadOnly refuses every change to the config, for shared machines.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// ExitSummary prints a recap of the session when the dashboard quits.
+	ExitSummary bool `json:"exit_summary,omitempty"`
+	// NoRestoreState starts the dashboard as the config has it, instead of as it was left.
+	NoRestoreState bool `json:"no_restore_state,omitempty"`
+	// NoBlink keeps the clock colons steady instead of blinking every second.
+	NoBlink bool `json:"no_blink,omitempty"`
+	// ReducedMotion turns off every animation: the blinking colons, the flip clock, scrolling notes, and celebrations.
+	ReducedMotion bool `json:"reduced_motion,omitempty"`
+	// FlipClock animates the primary clock's digits like a flip clock on minute changes.
+	FlipClock bool `json:"flip_clock,omitempty"`
+	// AirQuality shows the air quality index and UV index of each zone with a known position.
+	AirQuality bool `json:"air_quality,omitempty"`
+	// DigitColor colors the clock digits by the zone's part of the day: "phase" or "gradient"; empty leaves them plain.
+	DigitColor string `json:"digit_color,omitempty"`
+	// ClockFont forces the digits of every zone's clock: braille or text; empty picks them by the view's size.
+	ClockFont string `json:"clock_font,omitempty"`
+	// Emoji is "on" or "off" to draw the dashboard's emoji or narrow stand-ins for them; empty measures the terminal.
+	Emoji string `json:"emoji,omitempty"`
+	// AmbiguousWidth is "narrow" or "wide", how the terminal draws ambiguous-width symbols; empty measures it.
+	AmbiguousWidth string `json:"ambiguous_width,omitempty"`
+	// OBSFormat is the template of the line written by --obs, with placeholders such as {countdown}.
+	OBSFormat string `json:"obs_format,omitempty"`
+	// Military renders all clocks in 24-hour time with military zone letters and pins Zulu (UTC) at the top.
+	Military bool `json:"military,omitempty"`
+}
+
+// Announcement is a message posted to the announce_webhook channel at a local time in a zone.
+type Announcement struct {
+	// Zone is a configured timezone name or a location such as "Europe/Berlin".
+	Zone string `json:"zone"`
+	// Time is the local time in the zone, in "15:04" form.
+	Time string `json:"time"`
+	// Days is "daily" (or empty), "weekdays" (Monday to Friday, not on the zone's holidays),
+	// "weekends", or a list such as "mon,wed,fri".
+	Days string `json:"days,omitempty"`
+	// Message is the text posted, with placeholders such as {zone} and {time}.
+	Message string `json:"message"`
+}
+
+// CommandTile is a grid tile that shows a shell command's output, rerun on an interval (kairos tile).
+type CommandTile struct {
+	Title   string `json:"title"`
+	Command string `json:"command"`
+	// Every is the interval, e.g. "30s" or "5m"; "" is a minute.
+	Every string `json:"every,omitempty"`
+}
+
+// TravelPlan is a trip planned with `kairos travel start`: its home and destination zones
+// are pinned side by side at the top of the dashboard while travel mode is on.
+type TravelPlan struct {
+	// Home and Destination are configured zone names or IANA locations.
+	Home        string `json:"home"`
+	Destination string `json:"destination"`
+	// Flight is the departure in the home zone's time, "YYYY-MM-DD HH:MM", or "".
+	Flight string `json:"flight,omitempty"`
+}
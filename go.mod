@@ -2,18 +2,22 @@ module github.com/iamstoick/kairos
 
 go 1.22.5
 
+require (
+	github.com/jroimartin/gocui v0.5.0
+	github.com/mattn/go-runewidth v0.0.16
+	github.com/nsf/termbox-go v1.1.1
+	github.com/shirou/gopsutil/v3 v3.24.5
+	github.com/yuin/gopher-lua v1.1.1
+	golang.org/x/sys v0.20.0
+)
+
 require (
 	github.com/go-ole/go-ole v1.2.6 // indirect
-	github.com/jroimartin/gocui v0.5.0 // indirect
 	github.com/lufia/plan9stats v0.0.0-20211012122336-39d0f177ccd0 // indirect
-	github.com/mattn/go-runewidth v0.0.16 // indirect
-	github.com/nsf/termbox-go v1.1.1 // indirect
 	github.com/power-devops/perfstat v0.0.0-20210106213030-5aafc221ea8c // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
-	github.com/shirou/gopsutil/v3 v3.24.5 // indirect
 	github.com/shoenig/go-m1cpu v0.1.6 // indirect
 	github.com/tklauser/go-sysconf v0.3.12 // indirect
 	github.com/tklauser/numcpus v0.6.1 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
-	golang.org/x/sys v0.20.0 // indirect
 )
@@ -0,0 +1,181 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	// digits is the full-width font of the clocks, five rows per character.
+	digits = map[rune][]string{
+		'0': {"█████", "█   █", "█   █", "█   █", "█████"},
+		'1': {"  █  ", " ██  ", "  █  ", "  █  ", "█████"},
+		'2': {"█████", "    █", "█████", "█    ", "█████"},
+		'3': {"█████", "    █", "█████", "    █", "█████"},
+		'4': {"█   █", "█   █", "█████", "    █", "    █"},
+		'5': {"█████", "█    ", "█████", "    █", "█████"},
+		'6': {"█████", "█    ", "█████", "█   █", "█████"},
+		'7': {"█████", "    █", "    █", "    █", "    █"},
+		'8': {"█████", "█   █", "█████", "█   █", "█████"},
+		'9': {"█████", "█   █", "█████", "    █", "█████"},
+		':': {"     ", "  █  ", "     ", "  █  ", "     "},
+		'A': {"     ", " ██  ", "█  █ ", "████ ", "█  █ "},
+		'M': {"     ", "█ █ █", "█████", "█ █ █", "█   █"},
+		'P': {"     ", "████ ", "█  █ ", "████ ", "█    "},
+		' ': {"     ", "     ", "     ", "     ", "     "},
+		// Letters for the military time zone designators shown in military mode.
+		'B': {"     ", "███  ", "███  ", "█  █ ", "███  "},
+		'C': {"     ", " ███ ", "█    ", "█    ", " ███ "},
+		'D': {"     ", "███  ", "█  █ ", "█  █ ", "███  "},
+		'E': {"     ", "████ ", "███  ", "█    ", "████ "},
+		'F': {"     ", "████ ", "█    ", "███  ", "█    "},
+		'G': {"     ", " ███ ", "█    ", "█ ██ ", " ███ "},
+		'H': {"     ", "█  █ ", "████ ", "█  █ ", "█  █ "},
+		'I': {"     ", " ███ ", "  █  ", "  █  ", " ███ "},
+		'K': {"     ", "█  █ ", "███  ", "█ █  ", "█  █ "},
+		'L': {"     ", "█    ", "█    ", "█    ", "████ "},
+		'N': {"     ", "█  █ ", "██ █ ", "█ ██ ", "█  █ "},
+		'O': {"     ", " ██  ", "█  █ ", "█  █ ", " ██  "},
+		'Q': {"     ", " ██  ", "█  █ ", "█ ██ ", " ███ "},
+		'R': {"     ", "███  ", "█  █ ", "███  ", "█  █ "},
+		'S': {"     ", " ███ ", "██   ", "  ██ ", "███  "},
+		'T': {"     ", "█████", "  █  ", "  █  ", "  █  "},
+		'U': {"     ", "█  █ ", "█  █ ", "█  █ ", " ██  "},
+		'V': {"     ", "█   █", "█   █", " █ █ ", "  █  "},
+		'W': {"     ", "█   █", "█ █ █", "█ █ █", " █ █ "},
+		'X': {"     ", "█  █ ", " ██  ", " ██  ", "█  █ "},
+		'Y': {"     ", "█   █", " █ █ ", "  █  ", "  █  "},
+		'Z': {"     ", "████ ", "  █  ", " █   ", "████ "},
+	}
+	// compactDigits is a half-width font for tiles too narrow for the digits above.
+	// Characters without a compact glyph (the military letters) use the full-width one.
+	compactDigits = map[rune][]string{
+		'0': {"███", "█ █", "█ █", "█ █", "███"},
+		'1': {" █ ", "██ ", " █ ", " █ ", "███"},
+		'2': {"███", "  █", "███", "█  ", "███"},
+		'3': {"███", "  █", "███", "  █", "███"},
+		'4': {"█ █", "█ █", "███", "  █", "  █"},
+		'5': {"███", "█  ", "███", "  █", "███"},
+		'6': {"███", "█  ", "███", "█ █", "███"},
+		'7': {"███", "  █", "  █", "  █", "  █"},
+		'8': {"███", "█ █", "███", "█ █", "███"},
+		'9': {"███", "█ █", "███", "  █", "███"},
+		':': {" ", "█", " ", "█", " "},
+		' ': {" ", " ", " ", " ", " "},
+		'A': {"   ", " █ ", "█ █", "███", "█ █"},
+		'M': {"   ", "█ █", "███", "█ █", "█ █"},
+		'P': {"   ", "██ ", "█ █", "██ ", "█  "},
+	}
+)
+
+/**
+ * PrintTimeASCII converts a given time string into its ASCII art representation.
+ * It iterates over each character in the time string, retrieves the corresponding ASCII art from the digits map,
+ * and constructs the final ASCII art lines by combining the lines of each character.
+ *
+ * @param t - The time string to be converted into ASCII art.
+ * @returns A slice of strings, where each string represents a line of the ASCII art.
+ */
+func PrintTimeASCII(t string) []string {
+	return printASCII(t, digits)
+}
+
+// PrintCompactTimeASCII is PrintTimeASCII with the half-width font, for narrow views.
+func PrintCompactTimeASCII(t string) []string {
+	return printASCII(t, compactDigits)
+}
+
+// brailleDots are the bits of the braille dots, by column and row within a character cell.
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+/**
+ * PrintBrailleTimeASCII draws the half-width font with braille dots: each character cell holds
+ * 2x4 dots, so the time fits in two rows and about half the width of the half-width font,
+ * e.g. 14 columns for "03:04 PM". It keeps a clock readable in the smallest tiles.
+ *
+ * @param t - The time string.
+ * @returns The two lines of the art.
+ */
+func PrintBrailleTimeASCII(t string) []string {
+	var grid [][]rune
+	for _, line := range printASCII(t, compactDigits) {
+		// The space after the last character is dropped.
+		grid = append(grid, []rune(strings.TrimSuffix(line, " ")))
+	}
+	lines := make([]string, 2)
+	for x := 0; x < len(grid[0]); x += 2 {
+		for row := range lines {
+			cell := rune(0)
+			for dx := 0; dx < 2; dx++ {
+				for dy := 0; dy < 4; dy++ {
+					// The five rows of the font sit one dot below the top of the eight.
+					y := row*4 + dy - 1
+					if y >= 0 && y < len(grid) && x+dx < len(grid[y]) && grid[y][x+dx] != ' ' {
+						cell |= brailleDots[dx][dy]
+					}
+				}
+			}
+			if cell == 0 {
+				lines[row] += " "
+			} else {
+				lines[row] += string(0x2800 + cell)
+			}
+		}
+	}
+	return lines
+}
+
+// printASCII renders a string in a font, using the full-width glyph for characters the font lacks.
+func printASCII(t string, font map[rune][]string) []string {
+	// A clock shows the same few strings all minute, so each one is built once and reused
+	// (see asciiFrames); the returned lines must not be modified.
+	key := asciiFrameKey{font: reflect.ValueOf(font).Pointer(), text: t}
+	asciiFrames.mu.Lock()
+	defer asciiFrames.mu.Unlock()
+	if lines, ok := asciiFrames.frames[key]; ok {
+		return lines
+	}
+	// Each line is built by joining the corresponding lines of each character's ASCII art.
+	var builders [5]strings.Builder
+	for _, char := range t {
+		// Retrieves the ASCII art for the current character from the digits map.
+		// If the character is not found in the map, it skips to the next character.
+		art, ok := font[char]
+		if !ok {
+			if art, ok = digits[char]; !ok {
+				continue
+			}
+		}
+		// Each line of the ASCII art is followed by a space to separate characters.
+		for i := range builders {
+			builders[i].WriteString(art[i])
+			builders[i].WriteByte(' ')
+		}
+	}
+	lines := make([]string, len(builders))
+	for i := range builders {
+		lines[i] = builders[i].String()
+	}
+	// The cache only ever holds a few minutes' worth of frames per font.
+	if len(asciiFrames.frames) >= maxASCIIFrames {
+		asciiFrames.frames = map[asciiFrameKey][]string{}
+	}
+	asciiFrames.frames[key] = lines
+	return lines
+}
+
+// maxASCIIFrames bounds the cache of rendered clock strings.
+const maxASCIIFrames = 256
+
+// asciiFrameKey identifies a rendered string: the font and the text.
+type asciiFrameKey struct {
+	font uintptr
+	text string
+}
+
+// asciiFrames caches the art of the strings printASCII has rendered.
+var asciiFrames = struct {
+	mu     sync.Mutex
+	frames map[asciiFrameKey][]string
+}{frames: map[asciiFrameKey][]string{}}
@@ -0,0 +1,32 @@
+package render
+
+import "testing"
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{in: "03:04 PM", want: 8},
+		{in: "\x1b[1m\x1b[32mopen\x1b[0m", want: 4},
+		{in: "東京", want: 4},
+		{in: "\x1b]8;;https://example.com\x07link\x1b]8;;\x07", want: 4},
+		{in: "", want: 0},
+	}
+	for _, tt := range tests {
+		if got := TextWidth(tt.in); got != tt.want {
+			t.Errorf("TextWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompose(t *testing.T) {
+	frames := []Frame{{X0: 0, Y0: 0, X1: 5, Y1: 2, Frame: true, Title: "ab", Lines: []string{"\x1b[1mhi\x1b[0m!", "cut"}}}
+	// The second line is clipped by the bottom border, and the title sits on the top one.
+	want := "┌─ab─┐ \x1b[0m\n" +
+		"│\x1b[0m\x1b[1mhi\x1b[0m! │ \x1b[0m\n" +
+		"└────┘ \x1b[0m\n"
+	if got := Compose(frames, 7, 3); got != want {
+		t.Errorf("Compose = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,177 @@
+// Package render composes the views of the kairos dashboard into text: it lays frames
+// out on a screen the way gocui draws them, measures and centers text that holds ANSI
+// colors and wide characters, and draws clock times in block and braille digits.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jroimartin/gocui"
+	runewidth "github.com/mattn/go-runewidth"
+	"github.com/nsf/termbox-go"
+)
+
+// Frame is the rendered content of one dashboard view: where it goes on the screen,
+// its title, and its text lines (which may contain ANSI color codes).
+type Frame struct {
+	// Name is the gocui view name, e.g. "top", "bottom1", or "help".
+	Name string
+	// X0, Y0, X1, Y1 are the view's corners, as passed to gocui's SetView.
+	X0, Y0, X1, Y1 int
+	// Frame is false for views drawn without a border (the help footer and the zen clock).
+	Frame bool
+	Title string
+	Lines []string
+	// FrameColor and FgColor color the border and title, and the plain text, per the active theme.
+	FrameColor, FgColor gocui.Attribute
+	// Focused marks the view selected with Tab or the arrow keys.
+	Focused bool
+}
+
+// AttrDim draws text faint; gocui has no name for termbox's attribute.
+const AttrDim = gocui.Attribute(termbox.AttrDim)
+
+// ansiColor returns the ANSI escape codes for a gocui color and its bold and faint attributes, or "" for the plain default color.
+func ANSIColor(c gocui.Attribute) string {
+	bold := ""
+	if c&gocui.AttrBold != 0 {
+		c &^= gocui.AttrBold
+		bold = "\x1b[1m"
+	}
+	if c&AttrDim != 0 {
+		c &^= AttrDim
+		bold += "\x1b[2m"
+	}
+	if c == gocui.ColorDefault {
+		return bold
+	}
+	return fmt.Sprintf("\x1b[%dm", 30+int(c)-int(gocui.ColorBlack)) + bold
+}
+
+// cell is one character cell of a composed screen.
+type cell struct {
+	ch rune
+	// sgr holds the ANSI attributes in effect for the cell, e.g. "\x1b[1m\x1b[32m".
+	sgr string
+	// wide marks the second cell covered by a double-width character.
+	wide bool
+}
+
+/**
+ * Compose draws rendered views onto a screen the way gocui does, with box-drawing
+ * frames, titles on the top border, and content clipped to each view, and serializes it
+ * as ANSI text.
+ *
+ * @param frames - The views to draw, in order.
+ * @param width - The screen width.
+ * @param height - The screen height.
+ * @returns The screen, one line per row, with ANSI colors.
+ */
+func Compose(frames []Frame, width, height int) string {
+	grid := make([][]cell, height)
+	for y := range grid {
+		grid[y] = make([]cell, width)
+		for x := range grid[y] {
+			grid[y][x].ch = ' '
+		}
+	}
+	set := func(x, y int, ch rune, sgr string) {
+		if x >= 0 && x < width && y >= 0 && y < height {
+			grid[y][x] = cell{ch: ch, sgr: sgr}
+		}
+	}
+
+	for _, f := range frames {
+		if f.Frame {
+			border := ANSIColor(f.FrameColor)
+			for x := f.X0 + 1; x < f.X1; x++ {
+				set(x, f.Y0, '─', border)
+				set(x, f.Y1, '─', border)
+			}
+			for y := f.Y0 + 1; y < f.Y1; y++ {
+				set(f.X0, y, '│', border)
+				set(f.X1, y, '│', border)
+			}
+			set(f.X0, f.Y0, '┌', border)
+			set(f.X1, f.Y0, '┐', border)
+			set(f.X0, f.Y1, '└', border)
+			set(f.X1, f.Y1, '┘', border)
+			drawText(grid, f.Title, f.X0+2, f.Y0, f.X1-1, border)
+		}
+		base := ANSIColor(f.FgColor)
+		for i, line := range f.Lines {
+			y := f.Y0 + 1 + i
+			if y >= f.Y1 {
+				break
+			}
+			drawText(grid, line, f.X0+1, y, f.X1, base)
+		}
+	}
+
+	var b strings.Builder
+	for _, row := range grid {
+		sgr := ""
+		for _, c := range row {
+			if c.wide {
+				continue
+			}
+			if c.sgr != sgr {
+				b.WriteString("\x1b[0m" + c.sgr)
+				sgr = c.sgr
+			}
+			b.WriteRune(c.ch)
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	return b.String()
+}
+
+/**
+ * drawText writes a line containing ANSI color codes into a screen row, one cell per
+ * column, stopping before column end.
+ *
+ * @param grid - The screen.
+ * @param s - The text, possibly with ANSI SGR sequences.
+ * @param x - The first column.
+ * @param y - The row; rows outside the screen are ignored.
+ * @param end - The column at which the text is clipped.
+ * @param base - The attributes to start with and to return to on a reset.
+ */
+func drawText(grid [][]cell, s string, x, y, end int, base string) {
+	if y < 0 || y >= len(grid) {
+		return
+	}
+	row := grid[y]
+	end = min(end, len(row))
+	sgr := base
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "\x1b[") {
+			j := strings.IndexByte(s[i:], 'm')
+			if j < 0 {
+				return
+			}
+			if seq := s[i : i+j+1]; seq == "\x1b[0m" {
+				sgr = base
+			} else {
+				sgr += seq
+			}
+			i += j + 1
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		w := runewidth.RuneWidth(r)
+		if x+w > end {
+			return
+		}
+		if x >= 0 {
+			row[x] = cell{ch: r, sgr: sgr}
+			if w == 2 {
+				row[x+1] = cell{wide: true}
+			}
+		}
+		x += w
+	}
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// ansiSequence matches the escape sequences that take no columns on screen: CSI sequences
+// such as colors and styles (ESC [ parameters, intermediates, and a final byte), OSC
+// sequences such as hyperlinks (ended by BEL or ESC \), and two-character escapes.
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[0-Z\\-_]`)
+
+// StripANSI removes the escape sequences from a string, leaving the text shown on screen.
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
+// TextWidth returns how many columns a string takes on screen: its wide characters count twice, and its escape sequences not at all.
+func TextWidth(s string) int {
+	return runewidth.StringWidth(StripANSI(s))
+}
+
+/**
+ * Center centers a given string within a specified width by adding leading spaces.
+ * If the string is shorter than the width, it calculates the necessary padding and adds spaces to the left.
+ * If the string is longer than the width, it returns the original string without modification.
+ *
+ * @param s - The string to be centered.
+ * @param width - The total width within which to center the string.
+ * @returns The centered string with leading spaces if necessary.
+ */
+func Center(s string, width int) string {
+	// TextWidth counts wide characters (like emojis) as two columns, and ANSI escape codes
+	// (like bold formatting) as none.
+	pad := (width - TextWidth(s)) / 2
+	if pad > 0 {
+		return Padding(pad) + s
+	}
+	return s
+}
+
+// spaces is sliced by Padding, so centering does not build a new run of spaces every time.
+var spaces = strings.Repeat(" ", 512)
+
+// Padding returns n spaces.
+func Padding(n int) string {
+	if n <= len(spaces) {
+		return spaces[:n]
+	}
+	return strings.Repeat(" ", n)
+}
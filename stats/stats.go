@@ -0,0 +1,52 @@
+// Package stats samples the CPU and memory usage that the kairos footer shows, and paces
+// the retries when the platform cannot report CPU usage.
+package stats
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// Interval is how often a dashboard samples CPU and memory usage.
+const Interval = 2 * time.Second
+
+// MaxBackoff caps how long to wait before retrying to read CPU usage after failures.
+const MaxBackoff = 5 * time.Minute
+
+/**
+ * CPU reads the total CPU usage of the machine. A reading with no interval compares
+ * against the previous call, so the first one, or a one-off one, should measure over
+ * an interval instead.
+ *
+ * @param interval - How long to measure over, or 0 for the time since the previous call.
+ * @returns The usage as a percentage, or an error if the platform does not report it.
+ */
+func CPU(interval time.Duration) (float64, error) {
+	percentages, err := cpu.Percent(interval, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(percentages) == 0 {
+		return 0, fmt.Errorf("no CPU usage reported")
+	}
+	return percentages[0], nil
+}
+
+// Memory returns the bytes allocated on the program's heap, and that as a percentage of the memory it obtained from the system.
+func Memory() (alloc uint64, percent float64) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.Alloc, float64(m.Alloc) / float64(m.Sys) * 100
+}
+
+// Backoff returns how long to wait after the given number of failed CPU reads in a row: Interval, doubling up to MaxBackoff.
+func Backoff(failures int) time.Duration {
+	backoff := Interval
+	for i := 1; i < failures && backoff < MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, MaxBackoff)
+}
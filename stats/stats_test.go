@@ -0,0 +1,32 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: Interval},
+		{failures: 2, want: 2 * Interval},
+		{failures: 4, want: 8 * Interval},
+		{failures: 8, want: 256 * time.Second},
+		{failures: 9, want: MaxBackoff},
+		{failures: 100, want: MaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.failures); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	alloc, percent := Memory()
+	if alloc == 0 || percent <= 0 || percent > 100 {
+		t.Errorf("Memory() = %d, %.1f%%, want a positive allocation within the memory obtained", alloc, percent)
+	}
+}
@@ -0,0 +1,30 @@
+package tzutil
+
+import (
+	"testing"
+	"time"
+)
+
+// FuzzLoadLocation checks that any location string resolves or is refused, and that a
+// resolved one has an offset in the range real zones and fixed offsets use.
+func FuzzLoadLocation(f *testing.F) {
+	for _, seed := range []string{
+		"Asia/Manila", "UTC", "Local", "America/Argentina/Buenos_Aires", "+08:30", "-0500", "+5", "UTC+8",
+		"gmt-3", " +14:00 ", "+14:01", "+08:60", "-99", "Mars/Olympus_Mons", "../../etc/passwd", "/etc/localtime", "",
+	} {
+		f.Add(seed)
+	}
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, location string) {
+		loc, err := LoadLocation(location)
+		if err != nil {
+			return
+		}
+		if loc == nil {
+			t.Fatalf("LoadLocation(%q) returned no location and no error", location)
+		}
+		if _, offset := at.In(loc).Zone(); offset < -14*3600 || offset > 14*3600 {
+			t.Fatalf("LoadLocation(%q) has offset %ds, outside -14:00 to +14:00", location, offset)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package tzutil
+
+import (
+	"fmt"
+	"time"
+)
+
+// Transition describes a change of UTC offset (usually a DST change) in a location.
+type Transition struct {
+	// At is the first instant with the new offset.
+	At time.Time
+	// Before and After are the offsets in effect around the transition, in seconds east of UTC.
+	Before, After int
+	// BeforeName and AfterName are the zone abbreviations around the transition.
+	BeforeName, AfterName string
+}
+
+/**
+ * NextTransition finds the next UTC offset change in a location after from.
+ * The tz database does not expose its transition table, so this scans forward in
+ * 12-hour steps and then bisects down to the exact second.
+ *
+ * @param loc - The location to inspect.
+ * @param from - The instant to search after.
+ * @param limit - How far ahead to search.
+ * @returns The transition, and false if there is none within the limit.
+ */
+func NextTransition(loc *time.Location, from time.Time, limit time.Duration) (Transition, bool) {
+	const step = 12 * time.Hour
+	lo := from.In(loc)
+	name, offset := lo.Zone()
+	end := from.Add(limit)
+	for lo.Before(end) {
+		hi := lo.Add(step)
+		hiName, hiOffset := hi.Zone()
+		if hiOffset != offset || hiName != name {
+			// Narrow the interval until it is one second wide.
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				midName, midOffset := mid.Zone()
+				if midOffset == offset && midName == name {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			hi = hi.Truncate(time.Second)
+			afterName, afterOffset := hi.Zone()
+			return Transition{At: hi, Before: offset, After: afterOffset, BeforeName: name, AfterName: afterName}, true
+		}
+		lo = hi
+	}
+	return Transition{}, false
+}
+
+/**
+ * TransitionsBetween lists all UTC offset changes in a location between two instants.
+ *
+ * @param loc - The location to inspect.
+ * @param from - The start of the range.
+ * @param to - The end of the range.
+ * @returns The transitions in chronological order.
+ */
+func TransitionsBetween(loc *time.Location, from, to time.Time) []Transition {
+	var list []Transition
+	for {
+		tr, ok := NextTransition(loc, from, to.Sub(from))
+		if !ok || tr.At.After(to) {
+			return list
+		}
+		list = append(list, tr)
+		from = tr.At
+	}
+}
+
+/**
+ * ResolveWallClock turns a wall-clock reading into an instant in a location, detecting
+ * readings that fall into a DST gap (they never happen) or overlap (they happen twice).
+ * Skipped times are moved forward by the length of the gap, as most calendars do, and
+ * repeated times resolve to their first occurrence.
+ *
+ * @param wall - The wall-clock reading; only its date and clock fields are used.
+ * @param loc - The location in which to interpret it.
+ * @returns The chosen instant and a note describing the problem, empty if the reading is unique.
+ */
+func ResolveWallClock(wall time.Time, loc *time.Location) (time.Time, string) {
+	naive := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), time.UTC)
+	sameWall := func(t time.Time) bool {
+		l := t.In(loc)
+		return l.Year() == naive.Year() && l.YearDay() == naive.YearDay() && l.Hour() == naive.Hour() &&
+			l.Minute() == naive.Minute() && l.Second() == naive.Second()
+	}
+
+	// Offset changes are never closer together than a few days, so the offsets on
+	// either side of the reading are all the candidates there are.
+	_, offBefore := naive.Add(-36 * time.Hour).In(loc).Zone()
+	_, offAfter := naive.Add(36 * time.Hour).In(loc).Zone()
+	var matches []time.Time
+	for _, off := range []int{offBefore, offAfter} {
+		t := naive.Add(-time.Duration(off) * time.Second).In(loc)
+		if sameWall(t) && (len(matches) == 0 || !matches[0].Equal(t)) {
+			matches = append(matches, t)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], ""
+	case 2:
+		first, second := matches[0], matches[1]
+		if second.Before(first) {
+			first, second = second, first
+		}
+		return first, fmt.Sprintf("%s occurs twice here (%s and %s); using the first",
+			naive.Format("15:04"), first.Format("15:04 MST"), second.Format("15:04 MST"))
+	}
+	// The reading was skipped; reading it with the earlier offset lands after the gap.
+	t := naive.Add(-time.Duration(offBefore) * time.Second).In(loc)
+	return t, fmt.Sprintf("%s does not exist here on %s (skipped by a DST change); using %s",
+		naive.Format("15:04"), naive.Format("Mon 02 Jan 2006"), t.Format("15:04 MST"))
+}
@@ -0,0 +1,109 @@
+// Package tzutil holds the timezone logic behind kairos that does not depend on its
+// configuration or terminal UI: resolving locations (including fixed UTC offsets),
+// mapping Windows zone IDs to IANA names, formatting offsets, finding DST transitions,
+// and resolving wall-clock times that a DST change skips or repeats.
+package tzutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	// The tz database is embedded so that zones resolve in minimal containers and on
+	// systems without a zoneinfo directory; the system's copy is still preferred.
+	_ "time/tzdata"
+)
+
+// zoneinfoDir is a compiled tz database that LoadLocation reads before the system's (see UseZoneinfo).
+var zoneinfoDir string
+
+// UseZoneinfo makes LoadLocation read zones from a compiled tz database directory first,
+// e.g. one installed by `kairos tzupdate`; zones it lacks still come from the system. An
+// empty dir goes back to the system's database alone.
+func UseZoneinfo(dir string) {
+	zoneinfoDir = dir
+}
+
+// Matches a raw UTC offset such as "+08:30", "-0500", "+5" or "UTC+8".
+var fixedOffsetPattern = regexp.MustCompile(`^(?i:UTC|GMT)?([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+/**
+ * LoadLocation resolves a location string into a time.Location.
+ * All code paths that turn a config entry into a location should go through here,
+ * so that every command accepts the same set of location formats.
+ *
+ * Besides IANA names, a raw UTC offset such as "+08:30" gives a fixed zone with no DST,
+ * for places (ships, field operations, embedded systems) that have no IANA identifier.
+ *
+ * @param location - The location from the config, e.g. "Asia/Manila" or "+08:30".
+ * @returns The loaded location, or an error if it cannot be resolved.
+ */
+func LoadLocation(location string) (*time.Location, error) {
+	if m := fixedOffsetPattern.FindStringSubmatch(strings.TrimSpace(location)); m != nil {
+		return parseFixedOffset(m)
+	}
+	// UTC and Local are not files in the database; other names must stay inside it.
+	if zoneinfoDir != "" && location != "UTC" && location != "Local" && filepath.IsLocal(location) {
+		if data, err := os.ReadFile(filepath.Join(zoneinfoDir, filepath.FromSlash(location))); err == nil {
+			return time.LoadLocationFromTZData(location, data)
+		}
+	}
+	return time.LoadLocation(location)
+}
+
+/**
+ * This function builds a fixed zone from a matched raw UTC offset.
+ *
+ * @param m - The submatches of fixedOffsetPattern: sign, hours, and optional minutes.
+ * @returns A location named like "UTC+08:30", or an error if the offset is out of range.
+ */
+func parseFixedOffset(m []string) (*time.Location, error) {
+	hours, _ := strconv.Atoi(m[2])
+	minutes := 0
+	if m[3] != "" {
+		minutes, _ = strconv.Atoi(m[3])
+	}
+	if hours > 14 || minutes > 59 || hours*60+minutes > 14*60 {
+		return nil, fmt.Errorf("UTC offset %s%02d:%02d is out of range (-14:00 to +14:00)", m[1], hours, minutes)
+	}
+	seconds := (hours*60 + minutes) * 60
+	if m[1] == "-" {
+		seconds = -seconds
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%s%02d:%02d", m[1], hours, minutes), seconds), nil
+}
+
+/**
+ * FormatOffsetDiff formats a UTC offset in seconds as a signed difference, e.g. "+5h 30m" or "-3h".
+ *
+ * @param seconds - The offset in seconds.
+ * @returns The formatted offset.
+ */
+func FormatOffsetDiff(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	h, m := seconds/3600, seconds%3600/60
+	if m == 0 {
+		return fmt.Sprintf("%s%dh", sign, h)
+	}
+	return fmt.Sprintf("%s%dh %02dm", sign, h, m)
+}
+
+/**
+ * CalendarDayDiff returns how many calendar days the local date of a is ahead of the local date of b.
+ *
+ * @param a - A time in its own location.
+ * @param b - A time in its own location.
+ * @returns The difference in calendar days (e.g. 1 when a is already "tomorrow").
+ */
+func CalendarDayDiff(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(da.Sub(db).Hours() / 24)
+}
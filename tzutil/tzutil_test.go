@@ -0,0 +1,78 @@
+package tzutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		name     string
+		offset   int // seconds east of UTC on 2024-01-15, for fixed zones and IANA zones alike
+		wantErr  bool
+	}{
+		{location: "Asia/Manila", name: "Asia/Manila", offset: 8 * 3600},
+		{location: "UTC", name: "UTC", offset: 0},
+		{location: "+08:30", name: "UTC+08:30", offset: 8*3600 + 30*60},
+		{location: "-0500", name: "UTC-05:00", offset: -5 * 3600},
+		{location: "+5", name: "UTC+05:00", offset: 5 * 3600},
+		{location: "UTC+8", name: "UTC+08:00", offset: 8 * 3600},
+		{location: "gmt-3", name: "UTC-03:00", offset: -3 * 3600},
+		{location: " +14:00 ", name: "UTC+14:00", offset: 14 * 3600},
+		{location: "+14:01", wantErr: true},
+		{location: "+15", wantErr: true},
+		{location: "+08:60", wantErr: true},
+		{location: "Mars/Olympus_Mons", wantErr: true},
+		{location: "../../etc/passwd", wantErr: true},
+	}
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			loc, err := LoadLocation(tt.location)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadLocation(%q) = %v, want an error", tt.location, loc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadLocation(%q) failed: %v", tt.location, err)
+			}
+			if loc.String() != tt.name {
+				t.Errorf("LoadLocation(%q) is named %q, want %q", tt.location, loc.String(), tt.name)
+			}
+			if _, offset := at.In(loc).Zone(); offset != tt.offset {
+				t.Errorf("LoadLocation(%q) has offset %d, want %d", tt.location, offset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestCalendarDayDiff(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	la, _ := time.LoadLocation("America/Los_Angeles")
+	kiritimati, _ := time.LoadLocation("Pacific/Kiritimati")
+	pagoPago, _ := time.LoadLocation("Pacific/Pago_Pago")
+	instant := time.Date(2024, 3, 10, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		a, b time.Time
+		want int
+	}{
+		{"same zone", instant, instant, 0},
+		{"Tokyo is tomorrow", instant.In(tokyo), instant.In(la), 1},
+		{"Los Angeles is yesterday", instant.In(la), instant.In(tokyo), -1},
+		{"across the date line", instant.In(kiritimati), instant.In(pagoPago), 1},
+		{"a week apart", instant.AddDate(0, 0, 7), instant, 7},
+		{"across a DST change", time.Date(2024, 3, 11, 0, 30, 0, 0, la), time.Date(2024, 3, 9, 23, 30, 0, 0, la), 2},
+		{"across the new year", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalendarDayDiff(tt.a, tt.b); got != tt.want {
+				t.Errorf("CalendarDayDiff(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package workhours
+
+import (
+	"testing"
+	"time"
+)
+
+// FuzzWorkhoursParse checks that any hours option parses or is refused, and that what
+// parses is a window inside the day that formats back to itself.
+func FuzzWorkhoursParse(f *testing.F) {
+	for _, seed := range []string{
+		"09:00-17:00", "22:00-06:00", " 9:00 - 17:00 ", "09:00-12:00,13:00-18:00", "09:00-17:00,,",
+		"09:00-09:00", "24:00-25:00", "09:00", "9-5", "-", ",", "",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if b, err := Parse(s); err == nil {
+			if b.Start < 0 || b.Start >= 24*60 || b.End < 0 || b.End >= 24*60 || b.Start == b.End {
+				t.Fatalf("Parse(%q) = %+v, not a window inside a day", s, b)
+			}
+			if again, err := Parse(b.String()); err != nil || again != b {
+				t.Fatalf("Parse(%q) = %+v, but its String %q parses to %+v, %v", s, b, b.String(), again, err)
+			}
+		}
+		sched, err := ParseSchedule(s)
+		if err != nil {
+			return
+		}
+		if length := sched.Length(); length <= 0 || length > time.Duration(len(sched))*24*time.Hour {
+			t.Fatalf("ParseSchedule(%q) has length %v", s, length)
+		}
+		if again, err := ParseSchedule(sched.String()); err != nil || again.String() != sched.String() {
+			t.Fatalf("ParseSchedule(%q) formats as %q, which parses to %v, %v", s, sched.String(), again, err)
+		}
+	})
+}
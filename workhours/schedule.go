@@ -0,0 +1,119 @@
+package workhours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule is a day's business hours made of one or more windows, such as a morning and
+// an afternoon around a lunch break, or the two halves of a split shift.
+type Schedule []Hours
+
+// DefaultSchedule is the Default window alone.
+var DefaultSchedule = Schedule{Default}
+
+/**
+ * ParseSchedule parses comma-separated business-hours ranges such as "09:00-17:00" or
+ * "09:00-12:00,13:00-18:00".
+ *
+ * @param s - The ranges, each in 24-hour HH:MM-HH:MM form.
+ * @returns The schedule, or an error if a range is malformed.
+ */
+func ParseSchedule(s string) (Schedule, error) {
+	var sched Schedule
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		b, err := Parse(part)
+		if err != nil {
+			return nil, err
+		}
+		sched = append(sched, b)
+	}
+	if len(sched) == 0 {
+		return nil, fmt.Errorf("invalid hours '%s' (expected HH:MM-HH:MM, or several separated by commas)", s)
+	}
+	return sched, nil
+}
+
+// Length returns the total working time of a day.
+func (s Schedule) Length() time.Duration {
+	var total time.Duration
+	for _, b := range s {
+		total += b.Length()
+	}
+	return total
+}
+
+// Contains reports whether now falls within any of the windows on a weekday (Monday to Friday).
+func (s Schedule) Contains(now time.Time) bool {
+	for _, b := range s {
+		if b.Contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * Window returns the window that contains now, or else the one that most recently
+ * started before now.
+ *
+ * @param now - The current time in the timezone.
+ * @returns The start and end of the window.
+ */
+func (s Schedule) Window(now time.Time) (start, end time.Time) {
+	for _, b := range s {
+		st, en := b.Window(now)
+		if !now.Before(st) && now.Before(en) {
+			return st, en
+		}
+		if start.IsZero() || st.After(start) {
+			start, end = st, en
+		}
+	}
+	return start, end
+}
+
+// NextOpen returns the start of the next window after now, e.g. the end of a lunch break.
+func (s Schedule) NextOpen(now time.Time) time.Time {
+	var next time.Time
+	for _, b := range s {
+		if t := b.NextOpen(now); next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	return next
+}
+
+// Span returns a single window from the start of the first window to the last end, e.g. 09:00-18:00 for "09:00-12:00,13:00-18:00".
+func (s Schedule) Span() Hours {
+	if len(s) == 0 {
+		return Default
+	}
+	start := s[0].Start
+	last := start + int(s[0].Length().Minutes())
+	for _, b := range s[1:] {
+		// Windows that start earlier in the clock than the first one belong to the next day.
+		st := b.Start
+		if st < start {
+			st += 24 * 60
+		}
+		last = max(last, st+int(b.Length().Minutes()))
+	}
+	if last-start >= 24*60 {
+		return s[0]
+	}
+	return Hours{Start: start, End: last % (24 * 60)}
+}
+
+// String formats the schedule back into comma-separated HH:MM-HH:MM form.
+func (s Schedule) String() string {
+	parts := make([]string, len(s))
+	for i, b := range s {
+		parts[i] = b.String()
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,139 @@
+// Package workhours models daily business hours, including windows that run past
+// midnight, and answers whether a local time falls inside them and when they next
+// open or close. A Schedule combines several windows, e.g. around a lunch break.
+// Weekends (Saturday and Sunday) are always closed.
+package workhours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Hours is a daily working window, in minutes after local midnight.
+// An End at or before Start denotes a window that runs past midnight.
+type Hours struct {
+	Start int
+	End   int
+}
+
+// Default is the conventional 9:00 AM to 5:00 PM window, used for zones without an "hours" option.
+var Default = Hours{Start: 9 * 60, End: 17 * 60}
+
+/**
+ * Parse parses a business-hours range such as "09:00-17:00" or "22:00-06:00".
+ *
+ * @param s - The range in 24-hour HH:MM-HH:MM form.
+ * @returns The parsed hours, or an error if the range is malformed.
+ */
+func Parse(s string) (Hours, error) {
+	parts := strings.Split(strings.ReplaceAll(s, " ", ""), "-")
+	if len(parts) != 2 {
+		return Hours{}, fmt.Errorf("invalid hours '%s' (expected HH:MM-HH:MM)", s)
+	}
+	start, err := time.Parse("15:04", parts[0])
+	if err != nil {
+		return Hours{}, fmt.Errorf("invalid start time '%s' (expected HH:MM)", parts[0])
+	}
+	end, err := time.Parse("15:04", parts[1])
+	if err != nil {
+		return Hours{}, fmt.Errorf("invalid end time '%s' (expected HH:MM)", parts[1])
+	}
+	b := Hours{Start: start.Hour()*60 + start.Minute(), End: end.Hour()*60 + end.Minute()}
+	if b.Start == b.End {
+		return Hours{}, fmt.Errorf("invalid hours '%s' (start and end are the same)", s)
+	}
+	return b, nil
+}
+
+// Length returns the duration of the working window.
+func (b Hours) Length() time.Duration {
+	minutes := b.End - b.Start
+	if minutes <= 0 {
+		minutes += 24 * 60
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+/**
+ * Window returns the working window that contains or most recently started before now.
+ * Windows that begin on Saturday or Sunday are skipped, so the window is always on a weekday.
+ *
+ * @param now - The current time in the timezone.
+ * @returns The start and end of the window.
+ */
+func (b Hours) Window(now time.Time) (start, end time.Time) {
+	start = b.StartOn(now)
+	// If today's window hasn't started yet, an overnight window from yesterday may still be running.
+	if start.After(now) {
+		start = start.AddDate(0, 0, -1)
+	}
+	for start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+		start = start.AddDate(0, 0, -1)
+	}
+	return start, start.Add(b.Length())
+}
+
+/**
+ * Contains reports whether now falls within the business hours on a weekday (Monday to Friday).
+ *
+ * @param now - The current time in the timezone.
+ * @returns True during business hours.
+ */
+func (b Hours) Contains(now time.Time) bool {
+	start, end := b.Window(now)
+	return !now.Before(start) && now.Before(end)
+}
+
+/**
+ * CoversClock reports whether the clock time of t falls within the window, on any day of
+ * the week, e.g. for quiet hours such as "22:00-07:00".
+ *
+ * @param t - The time in the relevant location.
+ * @returns True inside the window.
+ */
+func (b Hours) CoversClock(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if b.Start < b.End {
+		return m >= b.Start && m < b.End
+	}
+	return m >= b.Start || m < b.End
+}
+
+/**
+ * NextOpen returns the start of the next working window after now.
+ *
+ * @param now - The current time in the timezone.
+ * @returns The instant the next window opens.
+ */
+func (b Hours) NextOpen(now time.Time) time.Time {
+	start := b.StartOn(now)
+	for !start.After(now) || start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+/**
+ * FormatCountdown formats a duration as a compact countdown such as "1h 03m" or "2d 4h".
+ *
+ * @param d - The duration to format.
+ * @returns The countdown text.
+ */
+func FormatCountdown(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes >= 24*60 {
+		return fmt.Sprintf("%dd %dh", minutes/(24*60), minutes%(24*60)/60)
+	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
+
+// StartOn returns the window start on the local date of day, using wall-clock time so DST days are handled.
+func (b Hours) StartOn(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), b.Start/60, b.Start%60, 0, 0, day.Location())
+}
+
+// String formats the hours back into HH:MM-HH:MM form.
+func (b Hours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", b.Start/60, b.Start%60, b.End/60, b.End%60)
+}
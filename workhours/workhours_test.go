@@ -0,0 +1,107 @@
+package workhours
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Hours
+		wantErr bool
+	}{
+		{in: "09:00-17:00", want: Hours{Start: 9 * 60, End: 17 * 60}},
+		{in: "9:30-17:45", want: Hours{Start: 9*60 + 30, End: 17*60 + 45}},
+		{in: " 08:00 - 12:00 ", want: Hours{Start: 8 * 60, End: 12 * 60}},
+		{in: "22:00-06:00", want: Hours{Start: 22 * 60, End: 6 * 60}},
+		{in: "00:00-23:59", want: Hours{Start: 0, End: 23*60 + 59}},
+		{in: "09:00-09:00", wantErr: true},
+		{in: "09:00", wantErr: true},
+		{in: "09:00-17:00-18:00", wantErr: true},
+		{in: "25:00-17:00", wantErr: true},
+		{in: "09:00-17:60", wantErr: true},
+		{in: "nine-five", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+			if again, err := Parse(got.String()); err != nil || again != got {
+				t.Errorf("Parse(%q) does not round-trip through String: %q", tt.in, got.String())
+			}
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		length  time.Duration
+		wantErr bool
+	}{
+		{in: "09:00-17:00", want: "09:00-17:00", length: 8 * time.Hour},
+		{in: "09:00-12:00,13:00-18:00", want: "09:00-12:00,13:00-18:00", length: 8 * time.Hour},
+		{in: "09:00-12:00, 13:00-18:00,", want: "09:00-12:00,13:00-18:00", length: 8 * time.Hour},
+		{in: "22:00-02:00,06:00-08:00", want: "22:00-02:00,06:00-08:00", length: 6 * time.Hour},
+		{in: "", wantErr: true},
+		{in: ",", wantErr: true},
+		{in: "09:00-12:00,lunch", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseSchedule(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSchedule(%q) = %v, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) failed: %v", tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseSchedule(%q) = %q, want %q", tt.in, got.String(), tt.want)
+			}
+			if got.Length() != tt.length {
+				t.Errorf("ParseSchedule(%q).Length() = %v, want %v", tt.in, got.Length(), tt.length)
+			}
+		})
+	}
+}
+
+func TestScheduleContains(t *testing.T) {
+	split, _ := ParseSchedule("09:00-12:00,13:00-18:00")
+	// 2024-01-15 is a Monday.
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"morning", time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), true},
+		{"lunch break", time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC), false},
+		{"afternoon", time.Date(2024, 1, 15, 17, 59, 0, 0, time.UTC), true},
+		{"evening", time.Date(2024, 1, 15, 18, 0, 0, 0, time.UTC), false},
+		{"Saturday", time.Date(2024, 1, 20, 10, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := split.Contains(tt.at); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package zonemeta describes IANA locations: the country each one belongs to, its flag,
+// and the coordinates of its principal city. The data comes from the tz database's
+// zone.tab and iso3166.tab, embedded so that it works where no tzdata is installed.
+package zonemeta
+
+import (
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	//go:embed zone.tab
+	zoneTab string
+	//go:embed iso3166.tab
+	countryTab string
+
+	loadOnce  sync.Once
+	zones     map[string]Info
+	countries map[string]string
+)
+
+// Info is the metadata of one IANA location.
+type Info struct {
+	// CountryCode is the ISO 3166 alpha-2 code, e.g. "JP".
+	CountryCode string
+	// Country is the country's name, e.g. "Japan".
+	Country string
+	// Lat and Lon are the principal city's position in decimal degrees.
+	Lat, Lon float64
+}
+
+// Flag returns the country's flag emoji, built from its regional indicator symbols.
+func (i Info) Flag() string {
+	if len(i.CountryCode) != 2 {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range strings.ToUpper(i.CountryCode) {
+		b.WriteRune(0x1F1E6 + c - 'A')
+	}
+	return b.String()
+}
+
+/**
+ * Lookup returns the metadata of an IANA location such as "Asia/Tokyo".
+ * Fixed offsets and locations not in zone.tab (e.g. "UTC") have none.
+ *
+ * @param location - The IANA location.
+ * @returns The metadata, and whether the location was found.
+ */
+func Lookup(location string) (Info, bool) {
+	loadOnce.Do(load)
+	info, ok := zones[location]
+	return info, ok
+}
+
+// load parses the embedded tables.
+func load() {
+	countries = map[string]string{}
+	for _, line := range strings.Split(countryTab, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) >= 2 && !strings.HasPrefix(line, "#") {
+			countries[fields[0]] = fields[1]
+		}
+	}
+	zones = map[string]Info{}
+	for _, line := range strings.Split(zoneTab, "\n") {
+		// Each line is "country coordinates TZ [comments]".
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lat, lon, ok := parseISO6709(fields[1])
+		if !ok {
+			continue
+		}
+		zones[fields[2]] = Info{CountryCode: fields[0], Country: countries[fields[0]], Lat: lat, Lon: lon}
+	}
+}
+
+/**
+ * parseISO6709 parses the coordinates used by zone.tab: ±DDMM±DDDMM, or ±DDMMSS±DDDMMSS.
+ *
+ * @param s - The coordinates, e.g. "+3541+13946".
+ * @returns The latitude and longitude in decimal degrees, and whether they parsed.
+ */
+func parseISO6709(s string) (lat, lon float64, ok bool) {
+	split := strings.IndexAny(s[1:], "+-") + 1
+	if split <= 0 {
+		return 0, 0, false
+	}
+	lat, ok1 := parseDegrees(s[:split], 2)
+	lon, ok2 := parseDegrees(s[split:], 3)
+	return lat, lon, ok1 && ok2
+}
+
+// parseDegrees parses a signed ±DD[D]MM[SS] angle whose degrees have the given number of digits.
+func parseDegrees(s string, degreeDigits int) (float64, bool) {
+	digits := s[1:]
+	if len(digits) != degreeDigits+2 && len(digits) != degreeDigits+4 {
+		return 0, false
+	}
+	value := 0.0
+	for i, scale := 0, 1.0; i < len(digits); scale *= 60 {
+		n := degreeDigits
+		if i > 0 {
+			n = 2
+		}
+		part, err := strconv.Atoi(digits[i : i+n])
+		if err != nil {
+			return 0, false
+		}
+		value += float64(part) / scale
+		i += n
+	}
+	if s[0] == '-' {
+		value = -value
+	}
+	return value, true
+}
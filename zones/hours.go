@@ -0,0 +1,91 @@
+package zones
+
+import (
+	"time"
+
+	"github.com/iamstoick/kairos/config"
+	"github.com/iamstoick/kairos/workhours"
+)
+
+/**
+ * BusinessHours returns the business hours configured for a timezone, one or more windows
+ * a day (e.g. around a lunch break), falling back to the default 9:00 AM to 5:00 PM window
+ * when none (or an invalid one) is configured.
+ *
+ * @param tz - The timezone configuration.
+ * @returns The timezone's business hours.
+ */
+func BusinessHours(tz config.TimezoneConfig) workhours.Schedule {
+	if tz.Hours == "" {
+		return workhours.DefaultSchedule
+	}
+	b, err := workhours.ParseSchedule(tz.Hours)
+	if err != nil {
+		return workhours.DefaultSchedule
+	}
+	return b
+}
+
+/**
+ * Countdown describes when the business-hours state of a timezone next changes,
+ * e.g. "closes in 1h 03m" during business hours or "opens in 6h 12m" outside them.
+ *
+ * @param now - The current time in the timezone.
+ * @param tz - The timezone configuration holding the business hours.
+ * @returns The countdown text.
+ */
+func Countdown(now time.Time, tz config.TimezoneConfig) string {
+	b := BusinessHours(tz)
+	if b.Contains(now) {
+		_, end := b.Window(now)
+		return "closes in " + workhours.FormatCountdown(end.Sub(now))
+	}
+	return "opens in " + workhours.FormatCountdown(b.NextOpen(now).Sub(now))
+}
+
+// FocusBlocks returns the zone's focus blocks (no-meeting windows, Monday to Friday), if it has any.
+func FocusBlocks(tz config.TimezoneConfig) (workhours.Schedule, bool) {
+	if tz.Focus == "" {
+		return nil, false
+	}
+	s, err := workhours.ParseSchedule(tz.Focus)
+	return s, err == nil
+}
+
+/**
+ * InFocusBlock reports whether a stretch of time touches one of a zone's focus blocks,
+ * checked every quarter of an hour, so a meeting slot that cuts into deep work is caught
+ * even when it starts outside the block.
+ *
+ * @param tz - The timezone configuration.
+ * @param start - The start of the stretch, in the zone's location.
+ * @param end - The end of the stretch.
+ * @returns Whether any part of it is in a focus block.
+ */
+func InFocusBlock(tz config.TimezoneConfig, start, end time.Time) bool {
+	blocks, ok := FocusBlocks(tz)
+	if !ok {
+		return false
+	}
+	for t := start; t.Before(end); t = t.Add(15 * time.Minute) {
+		if blocks.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultAwakeHours are the humane hours for pinging someone, used for zones without an "awake" option.
+var DefaultAwakeHours = workhours.Hours{Start: 8 * 60, End: 22 * 60}
+
+// AwakeHours returns a zone's humane hours, which unlike business hours apply every day of the week.
+func AwakeHours(tz config.TimezoneConfig) workhours.Hours {
+	if tz.Awake == "" {
+		return DefaultAwakeHours
+	}
+	b, err := workhours.Parse(tz.Awake)
+	if err != nil {
+		return DefaultAwakeHours
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package zones
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/config"
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// SortOrders are the orders `kairos sort --by` and the auto_sort setting accept.
+var SortOrders = []string{"offset", "opens"}
+
+/**
+ * Sort orders zones in place: "offset" puts the zones furthest east (the largest UTC
+ * offset) first, and "opens" puts the zones that are open now first, then the rest by
+ * how soon their business hours begin. Zones that compare equal keep their order, as do
+ * zones whose location cannot be loaded, which go last.
+ *
+ * @param list - The zones to sort.
+ * @param by - The sort order.
+ * @param now - The current time.
+ * @returns An error if the order is unknown.
+ */
+func Sort(list []config.TimezoneConfig, by string, now time.Time) error {
+	if !slices.Contains(SortOrders, by) {
+		return fmt.Errorf("unknown sort order '%s' (expected one of: %s)", by, strings.Join(SortOrders, ", "))
+	}
+	// The sort key is the UTC offset in seconds, negated so that east comes first, or the
+	// seconds until the zone opens.
+	key := func(tz config.TimezoneConfig) (int64, bool) {
+		loc, err := tzutil.LoadLocation(tz.Location)
+		if err != nil {
+			return 0, false
+		}
+		local := now.In(loc)
+		if by == "offset" {
+			_, offset := local.Zone()
+			return -int64(offset), true
+		}
+		b := BusinessHours(tz)
+		if b.Contains(local) {
+			return 0, true
+		}
+		return int64(b.NextOpen(local).Sub(local) / time.Second), true
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		ki, oki := key(list[i])
+		kj, okj := key(list[j])
+		if oki != okj {
+			return oki
+		}
+		return ki < kj
+	})
+	return nil
+}
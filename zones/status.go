@@ -0,0 +1,53 @@
+package zones
+
+import (
+	"time"
+
+	"github.com/iamstoick/kairos/config"
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+// Status is a configured timezone with its current state, for `kairos list --json/--csv`.
+type Status struct {
+	config.TimezoneConfig
+	Primary bool `json:"primary"`
+	// LocalTime and NextChange are RFC 3339 times; they are empty if the location is invalid.
+	LocalTime     string `json:"local_time"`
+	UTCOffset     string `json:"utc_offset"`
+	Abbreviation  string `json:"abbreviation"`
+	BusinessHours string `json:"business_hours"`
+	Open          bool   `json:"open"`
+	// NextChange is when the zone next opens (or closes, while it is open).
+	NextChange string `json:"next_change"`
+}
+
+/**
+ * Statuses describes every timezone in list at an instant: its local time, UTC
+ * offset, and whether it is within business hours.
+ *
+ * @param list - The configured timezones; the first is the primary one.
+ * @param now - The instant.
+ * @returns One status per timezone, in config order.
+ */
+func Statuses(list []config.TimezoneConfig, now time.Time) []Status {
+	statuses := []Status{}
+	for i, tz := range list {
+		s := Status{TimezoneConfig: tz, Primary: i == 0, BusinessHours: BusinessHours(tz).String()}
+		if loc, err := tzutil.LoadLocation(tz.Location); err == nil {
+			local := now.In(loc)
+			b := BusinessHours(tz)
+			s.LocalTime = local.Format(time.RFC3339)
+			s.UTCOffset = local.Format("-07:00")
+			s.Abbreviation, _ = local.Zone()
+			s.Open = b.Contains(local)
+			if s.Open {
+				_, end := b.Window(local)
+				s.NextChange = end.Format(time.RFC3339)
+			} else {
+				s.NextChange = b.NextOpen(local).Format(time.RFC3339)
+			}
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
@@ -0,0 +1,62 @@
+// Package zones answers questions about the zones in a kairos config: looking them up by
+// name, their business, focus, and humane hours, their current state, and their order.
+// It works on the list it is given and keeps no state of its own.
+package zones
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/iamstoick/kairos/config"
+	"github.com/iamstoick/kairos/tzutil"
+)
+
+/**
+ * Find looks up a timezone by its display name (case-insensitive) and loads its location.
+ *
+ * @param list - The configured timezones.
+ * @param name - The display name, e.g. "NYC".
+ * @returns The timezone configuration and its location, or an error if not found or invalid.
+ */
+func Find(list []config.TimezoneConfig, name string) (config.TimezoneConfig, *time.Location, error) {
+	for _, tz := range list {
+		if strings.EqualFold(tz.Name, name) {
+			loc, err := tzutil.LoadLocation(tz.Location)
+			if err != nil {
+				return tz, nil, fmt.Errorf("timezone '%s' has an invalid location '%s': %v", tz.Name, tz.Location, err)
+			}
+			return tz, loc, nil
+		}
+	}
+	return config.TimezoneConfig{}, nil, fmt.Errorf("timezone '%s' not found", name)
+}
+
+/**
+ * Resolve resolves a zone given either as a configured display name or as a location
+ * string (e.g. "UTC" or "Europe/Berlin"), preferring configured names.
+ *
+ * @param list - The configured timezones.
+ * @param name - The display name or location.
+ * @returns The location and a label to show for it, or an error if neither matches.
+ */
+func Resolve(list []config.TimezoneConfig, name string) (*time.Location, string, error) {
+	if tz, loc, err := Find(list, name); err == nil {
+		return loc, tz.Name, nil
+	}
+	loc, err := tzutil.LoadLocation(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("'%s' is neither a configured timezone nor a known location", name)
+	}
+	return loc, name, nil
+}
+
+// Index returns the index of a timezone in list by display name (case-insensitive), or -1.
+func Index(list []config.TimezoneConfig, name string) int {
+	for i, tz := range list {
+		if strings.EqualFold(tz.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package zones
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iamstoick/kairos/config"
+)
+
+var testZones = []config.TimezoneConfig{
+	{Name: "Manila", Location: "Asia/Manila"},
+	{Name: "New York", Location: "America/New_York", Hours: "08:00-16:00"},
+	{Name: "Berlin", Location: "Europe/Berlin"},
+	{Name: "Broken", Location: "Mars/Olympus_Mons"},
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		in        string
+		wantLabel string
+		wantErr   bool
+	}{
+		{in: "Manila", wantLabel: "Manila"},
+		{in: "new york", wantLabel: "New York"},
+		{in: "UTC", wantLabel: "UTC"},
+		{in: "+08:30", wantLabel: "+08:30"},
+		{in: "Broken", wantErr: true},
+		{in: "Nowhere", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			loc, label, err := Resolve(testZones, tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Resolve(%q) = %v, %q, want an error", tt.in, loc, label)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) failed: %v", tt.in, err)
+			}
+			if label != tt.wantLabel {
+				t.Errorf("Resolve(%q) label = %q, want %q", tt.in, label, tt.wantLabel)
+			}
+		})
+	}
+	if i := Index(testZones, "BERLIN"); i != 2 {
+		t.Errorf("Index(BERLIN) = %d, want 2", i)
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	// 13:30 UTC on a Wednesday: 21:30 in Manila, 09:30 in New York, 15:30 in Berlin.
+	now := time.Date(2024, 6, 12, 13, 30, 0, 0, time.UTC)
+	got := Statuses(testZones, now)
+	if len(got) != len(testZones) {
+		t.Fatalf("Statuses returned %d statuses, want %d", len(got), len(testZones))
+	}
+	want := []struct {
+		open       bool
+		nextChange string
+	}{
+		{false, "2024-06-13T09:00:00+08:00"},
+		{true, "2024-06-12T16:00:00-04:00"},
+		{true, "2024-06-12T17:00:00+02:00"},
+		{false, ""},
+	}
+	for i, w := range want {
+		if got[i].Open != w.open || got[i].NextChange != w.nextChange {
+			t.Errorf("%s: open %v, next change %q; want %v, %q", got[i].Name, got[i].Open, got[i].NextChange, w.open, w.nextChange)
+		}
+	}
+	if !got[0].Primary || got[1].Primary {
+		t.Errorf("only the first zone should be primary")
+	}
+}
+
+func TestSort(t *testing.T) {
+	now := time.Date(2024, 6, 12, 13, 30, 0, 0, time.UTC)
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{by: "offset", want: []string{"Manila", "Berlin", "New York", "Broken"}},
+		{by: "opens", want: []string{"New York", "Berlin", "Manila", "Broken"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			list := append([]config.TimezoneConfig(nil), testZones...)
+			if err := Sort(list, tt.by, now); err != nil {
+				t.Fatalf("Sort(%q) failed: %v", tt.by, err)
+			}
+			for i, name := range tt.want {
+				if list[i].Name != name {
+					t.Fatalf("Sort(%q) put %s at %d, want %s", tt.by, list[i].Name, i, name)
+				}
+			}
+		})
+	}
+	if err := Sort(nil, "alphabetical", now); err == nil {
+		t.Errorf("Sort accepted an unknown order")
+	}
+}